@@ -20,6 +20,9 @@ import (
 	"math/rand"
 	"os"
 	"time"
+	// embed the time zone database for validating the timezone of trigger timers,
+	// since the base image may not have it
+	_ "time/tzdata"
 
 	"k8s.io/component-base/logs"
 
@@ -22,6 +22,38 @@ type LocalControllerOptions struct {
 	NodeName          string
 	BindPort          string
 	VolumeMountPrefix string
+
+	// BindCertFile and BindKeyFile enable TLS of the server of the workers on the bind port
+	BindCertFile string
+	BindKeyFile  string
+
+	// GMTLS enables TLS of the connection to GM
+	GMTLS bool
+	// GMCAFile is the CA file verifying the certificate of GM, the system CAs are used if empty
+	GMCAFile string
+	// GMCertFile and GMKeyFile are the client certificate of mTLS, whose common name is the node name
+	GMCertFile string
+	GMKeyFile  string
+	// GMTokenFile is the file of the bearer token authenticating to GM, e.g. the service account token.
+	// It's read on each connection, so the rotated token is used.
+	GMTokenFile string
+
+	// GMTransport is the transport to GM: websocket, longpoll, grpc or mqtt, default websocket.
+	// The websocket client falls back to longpoll if the upgrades fail repeatedly.
+	// GMAddr is the address of the broker if mqtt, and GMTokenFile is the password of the node.
+	GMTransport string
+	// GMMQTTTopicPrefix is the prefix of the node topics on the broker, default sedna
+	GMMQTTTopicPrefix string
+
+	// GMMessageEncoding is the encoding of the messages with GM: json or protobuf, default json.
+	// GMCompression is the compression of the large contents: gzip or none, default gzip.
+	// The protobuf encoding and the compression are used only if GM has them.
+	GMMessageEncoding string
+	GMCompression     string
+
+	// DatasetMaxVersions is the max number of the versions kept by each dataset, no limit if not positive.
+	// The versions pinned by the live jobs and the latest version are kept beyond it.
+	DatasetMaxVersions int
 }
 
 // NewLocalControllerOptions create options object
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strconv"
 
 	"github.com/spf13/cobra"
 	cliflag "k8s.io/component-base/cli/flag"
@@ -81,17 +82,43 @@ It manages dataset and models, and controls ai features in local nodes.`, cmdNam
 	if Options.BindPort = os.Getenv(constants.BindPortENV); Options.BindPort == "" {
 		Options.BindPort = "9100"
 	}
+	Options.BindCertFile = os.Getenv(constants.BindCertFileENV)
+	Options.BindKeyFile = os.Getenv(constants.BindKeyFileENV)
+
+	Options.GMCAFile = os.Getenv(constants.GMCAFileENV)
+	Options.GMCertFile = os.Getenv(constants.GMCertFileENV)
+	Options.GMKeyFile = os.Getenv(constants.GMKeyFileENV)
+	Options.GMTokenFile = os.Getenv(constants.GMTokenFileENV)
+	Options.GMTLS, _ = strconv.ParseBool(os.Getenv(constants.GMTLSENV))
+	Options.GMTLS = Options.GMTLS || Options.GMCAFile != "" || Options.GMCertFile != ""
+	Options.GMTransport = os.Getenv(constants.GMTransportENV)
+	Options.GMMQTTTopicPrefix = os.Getenv(constants.GMMQTTTopicPrefixENV)
+	Options.GMMessageEncoding = os.Getenv(constants.GMMessageEncodingENV)
+	Options.GMCompression = os.Getenv(constants.GMCompressionENV)
+
+	Options.DatasetMaxVersions = dataset.DefaultMaxVersions
+	if v := os.Getenv(constants.DatasetMaxVersionsENV); v != "" {
+		if n, err := strconv.Atoi(v); err != nil {
+			klog.Errorf("invalid %s(%s), use the default %d", constants.DatasetMaxVersionsENV, v, Options.DatasetMaxVersions)
+		} else {
+			Options.DatasetMaxVersions = n
+		}
+	}
 
 	return cmd
 }
 
 // runServer runs server
 func runServer() {
-	c := gmclient.NewWebSocketClient(Options)
-	if err := c.Start(); err != nil {
+	c, err := gmclient.NewClient(Options)
+	if err != nil {
+		klog.Errorf("failed to create the client of global manager: %v", err)
 		return
 	}
 
+	// the server handles the messages of the connection, so it's created before the client starts
+	s := server.New(Options, c)
+
 	dm := dataset.New(c, Options)
 
 	mm := model.New(c)
@@ -104,11 +131,11 @@ func runServer() {
 
 	lm := lifelonglearning.New(c, dm, Options)
 
-	s := server.New(Options)
-
-	for _, m := range []managers.FeatureManager{
+	// the datasets and models are recovered before the jobs using them
+	ms := []managers.FeatureManager{
 		dm, mm, jm, fm, im, lm,
-	} {
+	}
+	for _, m := range ms {
 		s.AddFeatureManager(m)
 		c.Subscribe(m)
 		err := m.Start()
@@ -120,5 +147,14 @@ func runServer() {
 		klog.Infof("manager %s is started", m.GetName())
 	}
 
+	// the resources saved in db keep running before GM is connected, e.g. the node is offline after a power cycle
+	if err := managers.Recover(ms); err != nil {
+		klog.Errorf("failed to recover the managers from db: %v", err)
+	}
+
+	if err := c.Start(); err != nil {
+		return
+	}
+
 	s.ListenAndServe()
 }
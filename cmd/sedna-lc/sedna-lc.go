@@ -18,6 +18,9 @@ package main
 
 import (
 	"os"
+	// embed the time zone database for the timezone of trigger timers,
+	// since the base image may not have it
+	_ "time/tzdata"
 
 	"k8s.io/component-base/logs"
 
@@ -3,7 +3,11 @@ module github.com/kubeedge/sedna
 go 1.16
 
 require (
+	github.com/apache/thrift v0.14.2
+	github.com/eclipse/paho.mqtt.golang v1.3.5
 	github.com/emicklei/go-restful/v3 v3.4.0
+	github.com/evanphx/json-patch v4.9.0+incompatible
+	github.com/golang/protobuf v1.4.3
 	github.com/gorilla/websocket v1.4.2
 	github.com/microcosm-cc/bluemonday v1.0.18
 	github.com/minio/minio-go/v7 v7.0.10
@@ -11,6 +15,11 @@ require (
 	github.com/onsi/gomega v1.7.0
 	github.com/spf13/cobra v1.1.1
 	github.com/spf13/pflag v1.0.5
+	github.com/xitongsys/parquet-go v1.6.2
+	golang.org/x/net v0.0.0-20210614182718-04defd469f4e
+	golang.org/x/time v0.0.0-20210220033141-f8bda1e9f3ba
+	google.golang.org/grpc v1.38.0
+	google.golang.org/protobuf v1.25.0
 	gorm.io/driver/sqlite v1.1.4
 	gorm.io/gorm v1.20.9
 	k8s.io/api v0.21.4
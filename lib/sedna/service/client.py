@@ -127,6 +127,19 @@ class LCReporter(threading.Thread):
             self.period_increment = 0
 
 
+def worker_token():
+    """the token issued by GM authenticating the worker to LC, which is
+    read from the file each time since GM renews it before it expires"""
+    token_file = os.getenv("WORKER_TOKEN_FILE")
+    if token_file:
+        try:
+            with open(token_file) as f:
+                return f.read().strip()
+        except OSError as err:
+            LOGGER.warning(f"read worker token failed - with {err}")
+    return os.getenv("WORKER_TOKEN")
+
+
 class LCClient:
     """send info to LC by http"""
     @classmethod
@@ -134,7 +147,105 @@ class LCClient:
         url = '{0}/sedna/workers/{1}/info'.format(
             lc_server, worker_name
         )
-        return http_request(url=url, method="POST", json=message)
+        kwargs = {}
+        token = worker_token()
+        if token:
+            kwargs["headers"] = {"Authorization": f"Bearer {token}"}
+        # the CA verifies LC serving in TLS
+        ca_file = os.getenv("LC_CA_FILE")
+        if ca_file:
+            kwargs["verify"] = ca_file
+        return http_request(url=url, method="POST", json=message, **kwargs)
+
+
+class LCStreamClient(threading.Thread):
+    """Keep a stream to LC, which sends the worker messages and receives
+    the LC commands, e.g. update-model, stop, update-hem and
+    upload-hard-examples. The messages and the commands not acknowledged
+    are sent again after reconnecting."""
+
+    _reconnect_interval = 3
+
+    def __init__(self, lc_server, message: dict, on_command=None):
+        threading.Thread.__init__(self, daemon=True)
+        url = '{0}/sedna/workers/{1}/stream'.format(
+            lc_server, message["name"]
+        )
+        url = url.replace("https://", "wss://", 1).replace(
+            "http://", "ws://", 1)
+        self.uri = "{0}?namespace={1}&ownerKind={2}&ownerName={3}".format(
+            url, message["namespace"], message["ownerKind"],
+            message["ownerName"]
+        )
+        self.on_command = on_command
+        self.kwargs = {}
+        ca_file = os.getenv("LC_CA_FILE")
+        if ca_file and self.uri.startswith("wss://"):
+            import ssl
+            self.kwargs["ssl"] = ssl.create_default_context(cafile=ca_file)
+
+        self.lock = threading.Lock()
+        self.next_id = 0
+        # the messages not acknowledged by LC, by their ids
+        self.pending = {}
+        self.loop = asyncio.new_event_loop()
+        self.ws = None
+
+    def send(self, message: dict):
+        """queue the message, which is sent when connected"""
+        with self.lock:
+            self.next_id += 1
+            frame = json.dumps({
+                "type": "message", "id": self.next_id, "message": message
+            })
+            self.pending[self.next_id] = frame
+        if self.ws is not None:
+            asyncio.run_coroutine_threadsafe(self._send(frame), self.loop)
+
+    async def _send(self, frame):
+        try:
+            await self.ws.send(frame)
+        except Exception as err:
+            LOGGER.warning(f"{self.uri} send message failed - with {err}")
+
+    async def _serve(self):
+        kwargs = dict(self.kwargs)
+        # the token may be renewed since the last connection
+        token = worker_token()
+        if token:
+            kwargs["extra_headers"] = {"Authorization": f"Bearer {token}"}
+        async with websockets.connect(self.uri, **kwargs) as ws:
+            self.ws = ws
+            with self.lock:
+                frames = [self.pending[i] for i in sorted(self.pending)]
+            for frame in frames:
+                await ws.send(frame)
+
+            async for data in ws:
+                frame = json.loads(data)
+                if frame.get("type") == "ack":
+                    with self.lock:
+                        self.pending.pop(frame.get("id"), None)
+                    if frame.get("error"):
+                        LOGGER.warning(
+                            f"message rejected by LC: {frame['error']}")
+                elif frame.get("type") == "command":
+                    command = frame.get("command") or {}
+                    if callable(self.on_command):
+                        self.on_command(command.get("name"),
+                                        command.get("parameters") or {})
+                    await ws.send(json.dumps(
+                        {"type": "ack", "id": frame.get("id")}))
+
+    def run(self):
+        asyncio.set_event_loop(self.loop)
+        while True:
+            try:
+                self.loop.run_until_complete(self._serve())
+            except Exception as err:
+                LOGGER.warning(f"{self.uri} stream failed - with {err}")
+            self.ws = None
+            time.sleep(self._reconnect_interval)
 
 
 class AggregationClient:
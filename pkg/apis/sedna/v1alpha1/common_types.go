@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Metric describes the data that a resource model metric should have
@@ -42,3 +43,31 @@ type TrackingWorker struct {
 type ReidWorkers struct {
 	appsv1.DeploymentSpec `json:",inline"`
 }
+
+// NodeConditionType defines the condition type of a node bound to a resource
+type NodeConditionType string
+
+const (
+	// NodeCondDisconnected is true when LC of the node is disconnected from GM,
+	// so the updates of the resource are not synced to and from the node.
+	NodeCondDisconnected NodeConditionType = "NodeDisconnected"
+)
+
+// NodeCondition describes the connectivity of an edge node the resource is bound to
+type NodeCondition struct {
+	// NodeName is the name of the node
+	NodeName string `json:"nodeName"`
+	// Type of the condition, NodeDisconnected.
+	Type NodeConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status v1.ConditionStatus `json:"status"`
+	// Last time the condition transit from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// (brief) reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Human readable message indicating details about last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
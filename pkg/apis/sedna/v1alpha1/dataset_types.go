@@ -41,6 +41,33 @@ type DatasetSpec struct {
 	NodeName string `json:"nodeName"`
 
 	CredentialName string `json:"credentialName,omitempty"`
+
+	// Checksum is the optional checksum of the file of url, e.g. "sha256:<hex>" or "md5:<hex>",
+	// which is verified when the dataset is downloaded.
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// The formats of the dataset supported by the dataset manager of LC.
+const (
+	// DatasetFormatTXT is line separated format.
+	// It can be used in unstructured data scenarios.
+	DatasetFormatTXT = "txt"
+	// DatasetFormatCSV is commas separated value format with a extra header.
+	// It can be used in structured data scenarios.
+	DatasetFormatCSV = "csv"
+	// DatasetFormatJSONL is JSON Lines format, each line is a JSON object of a sample.
+	DatasetFormatJSONL = "jsonl"
+	// DatasetFormatParquet is columnar parquet format, each row is a sample,
+	// which is converted to a JSON object line for the workers.
+	DatasetFormatParquet = "parquet"
+	// DatasetFormatImageFolder is a directory of images, labeled by the sub directories
+	// or the labels sidecar file.
+	DatasetFormatImageFolder = "imagefolder"
+)
+
+// DatasetFormats are the names of the dataset formats, which GM accepts and LC registers
+var DatasetFormats = []string{
+	DatasetFormatTXT, DatasetFormatCSV, DatasetFormatJSONL, DatasetFormatParquet, DatasetFormatImageFolder,
 }
 
 // DatasetStatus represents information about the status of a dataset
@@ -48,6 +75,53 @@ type DatasetSpec struct {
 type DatasetStatus struct {
 	UpdateTime      *metav1.Time `json:"updateTime,omitempty" protobuf:"bytes,1,opt,name=updateTime"`
 	NumberOfSamples int          `json:"numberOfSamples"`
+
+	// Versions are the immutable snapshots of the samples, e.g. the samples a training round used
+	Versions []DatasetVersion `json:"versions,omitempty"`
+
+	// Quality is the quality statistics of the samples computed at the edge
+	Quality *DatasetQuality `json:"quality,omitempty"`
+}
+
+// DatasetQuality is the quality statistics of the samples of a dataset
+type DatasetQuality struct {
+	// LabelDistribution is the number of samples of each label
+	LabelDistribution map[string]int `json:"labelDistribution,omitempty"`
+	// DuplicateSamples is the number of samples which are the same as a previous sample
+	DuplicateSamples int `json:"duplicateSamples"`
+	// DuplicateRate is the ratio of the duplicate samples, e.g. "0.0125"
+	DuplicateRate string `json:"duplicateRate,omitempty"`
+	// MissingFiles is the number of index entries whose files don't exist on the node
+	MissingFiles int `json:"missingFiles"`
+	// Columns are the statistics of the columns of csv samples
+	Columns []ColumnStats `json:"columns,omitempty"`
+}
+
+// ColumnStats is the statistics of a column of the samples
+type ColumnStats struct {
+	Name string `json:"name"`
+	// Type is "number" if all non-empty values are numbers, otherwise "string"
+	Type string `json:"type"`
+	// Missing is the number of the empty values
+	Missing int `json:"missing"`
+	// Distinct is the number of the distinct values, which is at most the limit of LC
+	Distinct int `json:"distinct"`
+	// Min, Max and Mean are the statistics of the number column
+	Min  string `json:"min,omitempty"`
+	Max  string `json:"max,omitempty"`
+	Mean string `json:"mean,omitempty"`
+}
+
+// DatasetVersion is an immutable snapshot of the samples of a dataset
+type DatasetVersion struct {
+	// Name is the name of the version, e.g. "v1"
+	Name string `json:"name"`
+	// Hash is the content hash of the samples, e.g. "sha256:<hex>"
+	Hash            string `json:"hash"`
+	NumberOfSamples int    `json:"numberOfSamples"`
+	// URL is the directory of the snapshot on the node of the dataset
+	URL        string       `json:"url,omitempty"`
+	CreateTime *metav1.Time `json:"createTime,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+
+// EdgeNode describes the connection of LC of an edge node to GM,
+// whose name is the node name and which is maintained by GM
+type EdgeNode struct {
+	metav1.TypeMeta `json:",inline"`
+
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status EdgeNodeStatus `json:"status,omitempty"`
+}
+
+// EdgeNodePhase is the connection phase of LC
+type EdgeNodePhase string
+
+// These are the valid phases of an edge node.
+const (
+	EdgeNodeConnected    EdgeNodePhase = "Connected"
+	EdgeNodeDisconnected EdgeNodePhase = "Disconnected"
+)
+
+// EdgeNodeStatus represents the connection of LC
+type EdgeNodeStatus struct {
+	// Phase is Connected if LC is connected to GM
+	// +optional
+	Phase EdgeNodePhase `json:"phase,omitempty"`
+
+	// Transport is the transport of the messages between GM and LC
+	// +optional
+	Transport string `json:"transport,omitempty"`
+
+	// LCVersion is the version of LC reported when connected
+	// +optional
+	LCVersion string `json:"lcVersion,omitempty"`
+
+	// ProtocolVersion is the version of the protocol LC speaks
+	// +optional
+	ProtocolVersion int32 `json:"protocolVersion,omitempty"`
+
+	// Capabilities are the features of the protocol used between GM and LC
+	// +optional
+	Capabilities []string `json:"capabilities,omitempty"`
+
+	// Last time GM received a message or a heartbeat from LC
+	// +optional
+	LastHeartbeatTime *metav1.Time `json:"lastHeartbeatTime,omitempty"`
+
+	// Last time LC connected to GM
+	// +optional
+	LastConnectedTime *metav1.Time `json:"lastConnectedTime,omitempty"`
+
+	// Last time LC disconnected from GM
+	// +optional
+	LastDisconnectedTime *metav1.Time `json:"lastDisconnectedTime,omitempty"`
+
+	// PendingMessages is the number of the downstream messages not sent to LC yet
+	// +optional
+	PendingMessages int32 `json:"pendingMessages"`
+
+	// BytesSent and BytesReceived are the sizes of the encoded messages sent to and received from LC
+	// +optional
+	BytesSent int64 `json:"bytesSent,omitempty"`
+	// +optional
+	BytesReceived int64 `json:"bytesReceived,omitempty"`
+
+	// PlainBytesSent and PlainBytesReceived are the sizes of the messages in JSON without compression,
+	// the savings of the negotiated encoding and compression are the differences from the encoded sizes
+	// +optional
+	PlainBytesSent int64 `json:"plainBytesSent,omitempty"`
+	// +optional
+	PlainBytesReceived int64 `json:"plainBytesReceived,omitempty"`
+
+	// LastError is the reason of the last disconnection
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// EdgeNodeList is a list of EdgeNodes
+type EdgeNodeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []EdgeNode `json:"items"`
+}
@@ -74,6 +74,8 @@ type TrainingWorker struct {
 // TrainDataset defines dataset of federated learning job
 type TrainDataset struct {
 	Name string `json:"name"`
+	// Version is the name of the dataset version to train on, which is optional
+	Version string `json:"version,omitempty"`
 }
 
 type TrainModel struct {
@@ -128,6 +130,10 @@ type FLJobStatus struct {
 	// The phase of the federatedlearning job.
 	// +optional
 	Phase FLJobPhase `json:"phase,omitempty"`
+
+	// The connectivity of the edge nodes bound to the job, which is maintained by GM.
+	// +optional
+	NodeConditions []NodeCondition `json:"nodeConditions,omitempty"`
 }
 
 type FLJobConditionType string
@@ -103,6 +103,10 @@ type JointInferenceServiceStatus struct {
 
 	// Metrics of the joint inference service.
 	Metrics []Metric `json:"metrics,omitempty"`
+
+	// The connectivity of the edge nodes bound to the service, which is maintained by GM.
+	// +optional
+	NodeConditions []NodeCondition `json:"nodeConditions,omitempty"`
 }
 
 // JointInferenceServiceConditionType defines the condition type
@@ -47,6 +47,9 @@ type LLJobSpec struct {
 type LLDataset struct {
 	Name      string  `json:"name"`
 	TrainProb float64 `json:"trainProb"`
+	// Version is the name of the dataset version to train on,
+	// the samples of the dataset are tailed if empty.
+	Version string `json:"version,omitempty"`
 }
 
 // LLTrainSpec describes the data an train worker should have
@@ -56,14 +59,46 @@ type LLTrainSpec struct {
 }
 
 type LLTrigger struct {
-	CheckPeriodSeconds int         `json:"checkPeriodSeconds,omitempty"`
-	Timer              *LLTimer    `json:"timer,omitempty"`
-	Condition          LLCondition `json:"condition"`
+	CheckPeriodSeconds int      `json:"checkPeriodSeconds,omitempty"`
+	Timer              *LLTimer `json:"timer,omitempty"`
+	// Condition is optional when Expression is specified
+	// +optional
+	Condition LLCondition `json:"condition,omitempty"`
+	// Expression is a boolean expression over metrics, which takes precedence over Condition,
+	// e.g. "num_of_samples > 500 and not time in 09:00-18:00".
+	// +optional
+	Expression string `json:"expression,omitempty"`
+	// ConsecutiveChecks is the number of consecutive checks the condition must hold before firing.
+	// +optional
+	ConsecutiveChecks int `json:"consecutiveChecks,omitempty"`
+	// CooldownSeconds is the minimum interval in seconds since the last fire.
+	// +optional
+	CooldownSeconds int `json:"cooldownSeconds,omitempty"`
 }
 
 type LLTimer struct {
-	Start string `json:"start"`
-	End   string `json:"end"`
+	// Start and End of the range, whose format depends on Type:
+	// "15:04" for daily, "Sat 15:04" for weekly and "02 15:04" for monthly.
+	// +optional
+	Start string `json:"start,omitempty"`
+	// +optional
+	End string `json:"end,omitempty"`
+	// Type is one of daily, weekly, monthly and cron, default is daily.
+	// +kubebuilder:validation:Enum=daily;weekly;monthly;cron
+	// +optional
+	Type string `json:"type,omitempty"`
+	// Timezone is the IANA time zone name, e.g. "Asia/Shanghai", default is the local zone of LC.
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+	// Weekdays limits the daily range to the weekdays, e.g. ["Sat", "Sun"].
+	// +optional
+	Weekdays []string `json:"weekdays,omitempty"`
+	// Cron is the standard 5-field cron expression opening the window of cron type, e.g. "0 1 * * sat,sun".
+	// +optional
+	Cron string `json:"cron,omitempty"`
+	// DurationSeconds is the length of the window opened by the cron expression.
+	// +optional
+	DurationSeconds int `json:"durationSeconds,omitempty"`
 }
 
 type LLCondition struct {
@@ -148,6 +183,10 @@ type LLJobStatus struct {
 	// It is represented in RFC3339 form and is in UTC.
 	// +optional
 	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The connectivity of the edge nodes bound to the job, which is maintained by GM.
+	// +optional
+	NodeConditions []NodeCondition `json:"nodeConditions,omitempty"`
 }
 
 type LLJobStageConditionType string
@@ -41,6 +41,10 @@ type ModelSpec struct {
 	Devices []string `json:"device_soc_versions,omitempty"`
 
 	CredentialName string `json:"credentialName,omitempty"`
+
+	// Checksum is the optional checksum of the file of url, e.g. "sha256:<hex>" or "md5:<hex>",
+	// which is verified when the model is downloaded.
+	Checksum string `json:"checksum,omitempty"`
 }
 
 // ModelStatus represents information about the status of a model
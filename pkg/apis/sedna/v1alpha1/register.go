@@ -53,6 +53,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&ModelList{},
 		&JointInferenceService{},
 		&JointInferenceServiceList{},
+		&ObjectSearchService{},
+		&ObjectSearchServiceList{},
 		&FeatureExtractionService{},
 		&FeatureExtractionServiceList{},
 		&FederatedLearningJob{},
@@ -65,6 +67,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&ReidJobList{},
 		&VideoAnalyticsJob{},
 		&VideoAnalyticsJobList{},
+		&EdgeNode{},
+		&EdgeNodeList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -0,0 +1,133 @@
+/*
+Copyright The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeEdgeNodes implements EdgeNodeInterface
+type FakeEdgeNodes struct {
+	Fake *FakeSednaV1alpha1
+}
+
+var edgenodesResource = schema.GroupVersionResource{Group: "sedna.io", Version: "v1alpha1", Resource: "edgenodes"}
+
+var edgenodesKind = schema.GroupVersionKind{Group: "sedna.io", Version: "v1alpha1", Kind: "EdgeNode"}
+
+// Get takes name of the edgeNode, and returns the corresponding edgeNode object, and an error if there is any.
+func (c *FakeEdgeNodes) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.EdgeNode, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(edgenodesResource, name), &v1alpha1.EdgeNode{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.EdgeNode), err
+}
+
+// List takes label and field selectors, and returns the list of EdgeNodes that match those selectors.
+func (c *FakeEdgeNodes) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.EdgeNodeList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(edgenodesResource, edgenodesKind, opts), &v1alpha1.EdgeNodeList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.EdgeNodeList{ListMeta: obj.(*v1alpha1.EdgeNodeList).ListMeta}
+	for _, item := range obj.(*v1alpha1.EdgeNodeList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested edgeNodes.
+func (c *FakeEdgeNodes) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(edgenodesResource, opts))
+}
+
+// Create takes the representation of a edgeNode and creates it.  Returns the server's representation of the edgeNode, and an error, if there is any.
+func (c *FakeEdgeNodes) Create(ctx context.Context, edgeNode *v1alpha1.EdgeNode, opts v1.CreateOptions) (result *v1alpha1.EdgeNode, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(edgenodesResource, edgeNode), &v1alpha1.EdgeNode{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.EdgeNode), err
+}
+
+// Update takes the representation of a edgeNode and updates it. Returns the server's representation of the edgeNode, and an error, if there is any.
+func (c *FakeEdgeNodes) Update(ctx context.Context, edgeNode *v1alpha1.EdgeNode, opts v1.UpdateOptions) (result *v1alpha1.EdgeNode, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(edgenodesResource, edgeNode), &v1alpha1.EdgeNode{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.EdgeNode), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeEdgeNodes) UpdateStatus(ctx context.Context, edgeNode *v1alpha1.EdgeNode, opts v1.UpdateOptions) (*v1alpha1.EdgeNode, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateSubresourceAction(edgenodesResource, "status", edgeNode), &v1alpha1.EdgeNode{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.EdgeNode), err
+}
+
+// Delete takes name of the edgeNode and deletes it. Returns an error if one occurs.
+func (c *FakeEdgeNodes) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(edgenodesResource, name), &v1alpha1.EdgeNode{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeEdgeNodes) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(edgenodesResource, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.EdgeNodeList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched edgeNode.
+func (c *FakeEdgeNodes) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.EdgeNode, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(edgenodesResource, name, pt, data, subresources...), &v1alpha1.EdgeNode{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.EdgeNode), err
+}
@@ -32,6 +32,10 @@ func (c *FakeSednaV1alpha1) Datasets(namespace string) v1alpha1.DatasetInterface
 	return &FakeDatasets{c, namespace}
 }
 
+func (c *FakeSednaV1alpha1) EdgeNodes() v1alpha1.EdgeNodeInterface {
+	return &FakeEdgeNodes{c}
+}
+
 func (c *FakeSednaV1alpha1) FeatureExtractionServices(namespace string) v1alpha1.FeatureExtractionServiceInterface {
 	return &FakeFeatureExtractionServices{c, namespace}
 }
@@ -20,6 +20,8 @@ package v1alpha1
 
 type DatasetExpansion interface{}
 
+type EdgeNodeExpansion interface{}
+
 type FeatureExtractionServiceExpansion interface{}
 
 type FederatedLearningJobExpansion interface{}
@@ -27,6 +27,7 @@ import (
 type SednaV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	DatasetsGetter
+	EdgeNodesGetter
 	FeatureExtractionServicesGetter
 	FederatedLearningJobsGetter
 	IncrementalLearningJobsGetter
@@ -48,6 +49,10 @@ func (c *SednaV1alpha1Client) Datasets(namespace string) DatasetInterface {
 	return newDatasets(c, namespace)
 }
 
+func (c *SednaV1alpha1Client) EdgeNodes() EdgeNodeInterface {
+	return newEdgeNodes(c)
+}
+
 func (c *SednaV1alpha1Client) FeatureExtractionServices(namespace string) FeatureExtractionServiceInterface {
 	return newFeatureExtractionServices(c, namespace)
 }
@@ -55,6 +55,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=sedna.io, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("datasets"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Sedna().V1alpha1().Datasets().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("edgenodes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Sedna().V1alpha1().EdgeNodes().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("featureextractionservices"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Sedna().V1alpha1().FeatureExtractionServices().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("federatedlearningjobs"):
@@ -26,6 +26,8 @@ import (
 type Interface interface {
 	// Datasets returns a DatasetInformer.
 	Datasets() DatasetInformer
+	// EdgeNodes returns a EdgeNodeInformer.
+	EdgeNodes() EdgeNodeInformer
 	// FeatureExtractionServices returns a FeatureExtractionServiceInformer.
 	FeatureExtractionServices() FeatureExtractionServiceInformer
 	// FederatedLearningJobs returns a FederatedLearningJobInformer.
@@ -64,6 +66,11 @@ func (v *version) Datasets() DatasetInformer {
 	return &datasetInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// EdgeNodes returns a EdgeNodeInformer.
+func (v *version) EdgeNodes() EdgeNodeInformer {
+	return &edgeNodeInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // FeatureExtractionServices returns a FeatureExtractionServiceInformer.
 func (v *version) FeatureExtractionServices() FeatureExtractionServiceInformer {
 	return &featureExtractionServiceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
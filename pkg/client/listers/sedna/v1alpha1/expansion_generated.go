@@ -26,6 +26,10 @@ type DatasetListerExpansion interface{}
 // DatasetNamespaceLister.
 type DatasetNamespaceListerExpansion interface{}
 
+// EdgeNodeListerExpansion allows custom methods to be added to
+// EdgeNodeLister.
+type EdgeNodeListerExpansion interface{}
+
 // FeatureExtractionServiceListerExpansion allows custom methods to be added to
 // FeatureExtractionServiceLister.
 type FeatureExtractionServiceListerExpansion interface{}
@@ -18,6 +18,7 @@ package config
 
 import (
 	"io/ioutil"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -35,6 +36,20 @@ const (
 	defaultLCServer         = "http://localhost:9100"
 	defaultKBServer         = "http://localhost:9020"
 	defaultPeriod           = 30
+	defaultTransport        = "websocket"
+	defaultMQTTClientID     = "sedna-gm"
+	defaultMQTTTopicPrefix  = "sedna"
+	defaultUpstreamQueue    = 100
+	defaultOverflowPolicy   = "block"
+	defaultMetricsAddress   = "0.0.0.0"
+	defaultLeaseDuration    = 15
+	defaultRenewDeadline    = 10
+	defaultRetryPeriod      = 2
+	defaultLeaseNamespace   = "sedna"
+	defaultLeaseName        = "sedna-gm"
+	defaultWorkerTokenNS    = "sedna"
+	defaultWorkerTokenKey   = "sedna-gm-worker-token"
+	defaultWorkerTokenTTL   = 3600
 )
 
 // ControllerConfig indicates the config of controller
@@ -54,6 +69,23 @@ type ControllerConfig struct {
 	// Since the current limit of kubeedge(1.5), GM needs to build the websocket channel for communicating between GM and LCs.
 	WebSocket WebSocket `json:"websocket,omitempty"`
 
+	// Transport is the transport of the messages between GM and LCs: websocket, grpc or mqtt.
+	// The grpc stream is served with the address and security options of WebSocket.
+	// default websocket
+	Transport string `json:"transport,omitempty"`
+
+	// MQTT describes the broker bridged by GM if the transport is mqtt
+	MQTT MQTT `json:"mqtt,omitempty"`
+
+	// Upstream describes how the messages from each LC are queued
+	Upstream Upstream `json:"upstream,omitempty"`
+
+	// Metrics describes the server of the metrics of GM
+	Metrics Metrics `json:"metrics,omitempty"`
+
+	// LeaderElection describes the leader election of the GM replicas
+	LeaderElection LeaderElection `json:"leaderElection,omitempty"`
+
 	// lc config to info the worker
 	LC LCConfig `json:"localController,omitempty"`
 
@@ -71,12 +103,125 @@ type WebSocket struct {
 	Address string `json:"address,omitempty"`
 	// default defaultWebsocketPort
 	Port int64 `json:"port,omitempty"`
+
+	// TLS enables TLS of the websocket server if set
+	TLS *WebSocketTLS `json:"tls,omitempty"`
+
+	// Auth describes how the LCs are authenticated,
+	// the node name claimed by LC is trusted if no authentication is enabled
+	Auth WebSocketAuth `json:"auth,omitempty"`
+}
+
+// WebSocketTLS describes the TLS config of the websocket server
+type WebSocketTLS struct {
+	CertFile string `json:"certFile"`
+	KeyFile  string `json:"keyFile"`
+	// ClientCAFile enables mTLS, the common name of the client certificate
+	// must be the node name or "system:node:<node name>"
+	ClientCAFile string `json:"clientCAFile,omitempty"`
+}
+
+// WebSocketAuth describes the token authentication of LCs
+type WebSocketAuth struct {
+	// TokenFile is the csv file of the static bearer tokens, each line is "token,node name"
+	TokenFile string `json:"tokenFile,omitempty"`
+	// ServiceAccounts are the service accounts "namespace/name" of LCs whose tokens are accepted,
+	// the token must be bound to the LC pod running on the node
+	ServiceAccounts []string `json:"serviceAccounts,omitempty"`
+}
+
+// MQTT describes how GM connects to the broker, which authenticates LCs
+// and authorizes them to their node topics
+type MQTT struct {
+	// Broker is the address of the broker, e.g. tcp://broker:1883 or ssl://broker:8883
+	Broker string `json:"broker"`
+	// default defaultMQTTClientID
+	ClientID string `json:"clientID,omitempty"`
+	Username string `json:"username,omitempty"`
+	// PasswordFile is the file of the password of the username
+	PasswordFile string `json:"passwordFile,omitempty"`
+
+	// CAFile verifies the broker of the ssl scheme
+	CAFile   string `json:"caFile,omitempty"`
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+
+	// TopicPrefix is the prefix of the node topics <prefix>/nodes/<node name>/{up,down}
+	// default defaultMQTTTopicPrefix
+	TopicPrefix string `json:"topicPrefix,omitempty"`
+}
+
+// Upstream describes the queue of the upstream messages of each LC, which are handled
+// in turn, so a chatty LC doesn't stall the messages of the others
+type Upstream struct {
+	// QueueSize is the max number of the pending messages of each LC
+	// default defaultUpstreamQueue
+	QueueSize int `json:"queueSize,omitempty"`
+	// RateLimit is the max number of the messages per second handled of each LC,
+	// and Burst is the max number handled at once.
+	// default 0, which is unlimited
+	RateLimit float64 `json:"rateLimit,omitempty"`
+	Burst     int     `json:"burst,omitempty"`
+	// Coalesce replaces the pending status message of a resource with the newer one
+	Coalesce bool `json:"coalesce,omitempty"`
+	// OverflowPolicy is what to do when the queue of a LC is full: block reading the messages of the LC,
+	// or drop and reject its oldest status message.
+	// default defaultOverflowPolicy
+	OverflowPolicy string `json:"overflowPolicy,omitempty"`
+}
+
+// Metrics describes the server of the metrics in the Prometheus text format at /metrics
+type Metrics struct {
+	// default defaultMetricsAddress
+	Address string `json:"address,omitempty"`
+	// default 0, which disables the server
+	Port int64 `json:"port,omitempty"`
+}
+
+// LeaderElection describes the leader election of the GM replicas by a Lease.
+// Only the leader runs the controllers and serves LCs, the others wait to take over.
+type LeaderElection struct {
+	// LeaderElect enables the leader election
+	LeaderElect bool `json:"leaderElect,omitempty"`
+	// LeaseDurationSeconds is how long the followers wait before taking over the leadership not renewed
+	// default defaultLeaseDuration
+	LeaseDurationSeconds int64 `json:"leaseDurationSeconds,omitempty"`
+	// RenewDeadlineSeconds is how long the leader retries renewing the leadership before giving up
+	// default defaultRenewDeadline
+	RenewDeadlineSeconds int64 `json:"renewDeadlineSeconds,omitempty"`
+	// RetryPeriodSeconds is the interval of trying to acquire or renew the leadership
+	// default defaultRetryPeriod
+	RetryPeriodSeconds int64 `json:"retryPeriodSeconds,omitempty"`
+	// ResourceNamespace and ResourceName are of the Lease
+	// default defaultLeaseNamespace and defaultLeaseName
+	ResourceNamespace string `json:"resourceNamespace,omitempty"`
+	ResourceName      string `json:"resourceName,omitempty"`
 }
 
 // LCConfig describes LC config to inject the worker
 type LCConfig struct {
 	// default defaultLCServer
 	Server string `json:"server"`
+
+	// WorkerToken describes the tokens injected into the workers authenticating their messages to LC
+	WorkerToken WorkerToken `json:"workerToken,omitempty"`
+}
+
+// WorkerToken describes the tokens of the workers signed by GM, which are bound to the owners of the workers.
+// LCs are told the public key when connected, and then reject the worker messages without a valid token.
+type WorkerToken struct {
+	// Enable injects the tokens into the workers created
+	Enable bool `json:"enable,omitempty"`
+	// TTLSeconds is the lifetime of the tokens, which are renewed by GM in the secrets mounted into the workers
+	// before they expire.
+	// default defaultWorkerTokenTTL
+	TTLSeconds int64 `json:"ttlSeconds,omitempty"`
+	// NoExpiry issues the tokens valid until the owners are deleted instead of the ttl
+	NoExpiry bool `json:"noExpiry,omitempty"`
+	// SecretNamespace and SecretName are of the secret of the signing key, which is created if not existing
+	// default defaultWorkerTokenNS and defaultWorkerTokenKey
+	SecretNamespace string `json:"secretNamespace,omitempty"`
+	SecretName      string `json:"secretName,omitempty"`
 }
 
 // KBConfig describes KB config to inject the worker
@@ -106,6 +251,81 @@ func (c *ControllerConfig) Validate() field.ErrorList {
 	if c.KubeConfig != "" && !util.FileIsExist(c.KubeConfig) {
 		allErrs = append(allErrs, field.Invalid(field.NewPath("kubeconfig"), c.KubeConfig, "kubeconfig not exist"))
 	}
+	if tls := c.WebSocket.TLS; tls != nil {
+		tlsPath := field.NewPath("websocket", "tls")
+		if tls.CertFile == "" || tls.KeyFile == "" {
+			allErrs = append(allErrs, field.Required(tlsPath, "certFile and keyFile are required"))
+		}
+		for name, file := range map[string]string{"certFile": tls.CertFile, "keyFile": tls.KeyFile, "clientCAFile": tls.ClientCAFile} {
+			if file != "" && !util.FileIsExist(file) {
+				allErrs = append(allErrs, field.Invalid(tlsPath.Child(name), file, "file not exist"))
+			}
+		}
+	}
+	if file := c.WebSocket.Auth.TokenFile; file != "" && !util.FileIsExist(file) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("websocket", "auth", "tokenFile"), file, "file not exist"))
+	}
+	switch c.Transport {
+	case "", "websocket", "grpc":
+	case "mqtt":
+		allErrs = append(allErrs, c.MQTT.validate(field.NewPath("mqtt"))...)
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("transport"), c.Transport, []string{"websocket", "grpc", "mqtt"}))
+	}
+	upstreamPath := field.NewPath("upstream")
+	if c.Upstream.QueueSize < 0 {
+		allErrs = append(allErrs, field.Invalid(upstreamPath.Child("queueSize"), c.Upstream.QueueSize, "must be non-negative"))
+	}
+	if c.Upstream.RateLimit < 0 {
+		allErrs = append(allErrs, field.Invalid(upstreamPath.Child("rateLimit"), c.Upstream.RateLimit, "must be non-negative"))
+	}
+	switch c.Upstream.OverflowPolicy {
+	case "", "block", "drop":
+	default:
+		allErrs = append(allErrs, field.NotSupported(upstreamPath.Child("overflowPolicy"), c.Upstream.OverflowPolicy, []string{"block", "drop"}))
+	}
+	if le := c.LeaderElection; le.LeaderElect {
+		lePath := field.NewPath("leaderElection")
+		if le.LeaseDurationSeconds <= le.RenewDeadlineSeconds {
+			allErrs = append(allErrs, field.Invalid(lePath.Child("leaseDurationSeconds"), le.LeaseDurationSeconds, "must be greater than renewDeadlineSeconds"))
+		}
+		if le.RetryPeriodSeconds <= 0 || le.RenewDeadlineSeconds <= le.RetryPeriodSeconds {
+			allErrs = append(allErrs, field.Invalid(lePath.Child("retryPeriodSeconds"), le.RetryPeriodSeconds, "must be positive and less than renewDeadlineSeconds"))
+		}
+		if le.ResourceNamespace == "" || le.ResourceName == "" {
+			allErrs = append(allErrs, field.Required(lePath, "resourceNamespace and resourceName are required"))
+		}
+	}
+	if wt := c.LC.WorkerToken; wt.Enable {
+		wtPath := field.NewPath("localController", "workerToken")
+		if !wt.NoExpiry && wt.TTLSeconds <= 0 {
+			allErrs = append(allErrs, field.Invalid(wtPath.Child("ttlSeconds"), wt.TTLSeconds, "must be positive unless noExpiry is set"))
+		}
+		if wt.SecretNamespace == "" || wt.SecretName == "" {
+			allErrs = append(allErrs, field.Required(wtPath, "secretNamespace and secretName are required"))
+		}
+	}
+	for i, sa := range c.WebSocket.Auth.ServiceAccounts {
+		if parts := strings.Split(sa, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("websocket", "auth", "serviceAccounts").Index(i), sa, "must be namespace/name"))
+		}
+	}
+	return allErrs
+}
+
+func (m *MQTT) validate(path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if m.Broker == "" {
+		allErrs = append(allErrs, field.Required(path.Child("broker"), "broker is required by the mqtt transport"))
+	}
+	if (m.CertFile == "") != (m.KeyFile == "") {
+		allErrs = append(allErrs, field.Required(path, "certFile and keyFile must be set together"))
+	}
+	for name, file := range map[string]string{"passwordFile": m.PasswordFile, "caFile": m.CAFile, "certFile": m.CertFile, "keyFile": m.KeyFile} {
+		if file != "" && !util.FileIsExist(file) {
+			allErrs = append(allErrs, field.Invalid(path.Child(name), file, "file not exist"))
+		}
+	}
 	return allErrs
 }
 
@@ -119,8 +339,32 @@ func NewDefaultControllerConfig() *ControllerConfig {
 			Address: defaultWebsocketAddress,
 			Port:    defaultWebsocketPort,
 		},
+		Transport: defaultTransport,
+		MQTT: MQTT{
+			ClientID:    defaultMQTTClientID,
+			TopicPrefix: defaultMQTTTopicPrefix,
+		},
+		Upstream: Upstream{
+			QueueSize:      defaultUpstreamQueue,
+			OverflowPolicy: defaultOverflowPolicy,
+		},
+		Metrics: Metrics{
+			Address: defaultMetricsAddress,
+		},
+		LeaderElection: LeaderElection{
+			LeaseDurationSeconds: defaultLeaseDuration,
+			RenewDeadlineSeconds: defaultRenewDeadline,
+			RetryPeriodSeconds:   defaultRetryPeriod,
+			ResourceNamespace:    defaultLeaseNamespace,
+			ResourceName:         defaultLeaseName,
+		},
 		LC: LCConfig{
 			Server: defaultLCServer,
+			WorkerToken: WorkerToken{
+				TTLSeconds:      defaultWorkerTokenTTL,
+				SecretNamespace: defaultWorkerTokenNS,
+				SecretName:      defaultWorkerTokenKey,
+			},
 		},
 		KB: KBConfig{
 			Server: defaultKBServer,
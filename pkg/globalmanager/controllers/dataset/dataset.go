@@ -17,11 +17,16 @@ limitations under the License.
 package dataset
 
 import (
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 
 	sednaclientset "github.com/kubeedge/sedna/pkg/client/clientset/versioned/typed/sedna/v1alpha1"
+	sednav1listers "github.com/kubeedge/sedna/pkg/client/listers/sedna/v1alpha1"
 	"github.com/kubeedge/sedna/pkg/globalmanager/config"
 	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
 )
@@ -39,9 +44,14 @@ type Controller struct {
 	kubeClient kubernetes.Interface
 	client     sednaclientset.SednaV1alpha1Interface
 
+	// A store of datasets
+	datasetLister sednav1listers.DatasetLister
+
 	cfg *config.ControllerConfig
 
 	sendToEdgeFunc runtime.DownstreamSendFunc
+
+	recorder record.EventRecorder
 }
 
 func (c *Controller) Run(stopCh <-chan struct{}) {
@@ -50,11 +60,17 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 
 // New creates a dataset controller
 func New(cc *runtime.ControllerContext) (runtime.FeatureControllerI, error) {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: cc.KubeClient.CoreV1().Events("")})
+
 	c := &Controller{
 		client:     cc.SednaClient.SednaV1alpha1(),
 		kubeClient: cc.KubeClient,
+		recorder:   eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: Name + "-controller"}),
 	}
-	informer := cc.SednaInformerFactory.Sedna().V1alpha1().Datasets().Informer()
+	datasetInformer := cc.SednaInformerFactory.Sedna().V1alpha1().Datasets()
+	c.datasetLister = datasetInformer.Lister()
+	informer := datasetInformer.Informer()
 	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 
 		AddFunc: func(obj interface{}) {
@@ -62,7 +78,9 @@ func New(cc *runtime.ControllerContext) (runtime.FeatureControllerI, error) {
 		},
 
 		UpdateFunc: func(old, cur interface{}) {
-			c.syncToEdge(watch.Added, cur)
+			if runtime.GenerationChanged(old, cur) {
+				c.syncToEdge(watch.Modified, cur)
+			}
 		},
 
 		DeleteFunc: func(obj interface{}) {
@@ -18,13 +18,26 @@ package dataset
 
 import (
 	"fmt"
+	"strings"
 
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/watch"
 
 	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
 	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
 )
 
+// validateFormat checks the format of the dataset is supported by the dataset manager of LC
+func validateFormat(format string) error {
+	for _, f := range sednav1.DatasetFormats {
+		if strings.ToLower(format) == f {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("dataset format(%s) is invalid, support format: %+v", format, sednav1.DatasetFormats)
+}
+
 // syncToEdge syncs the dataset resources
 func (c *Controller) syncToEdge(eventType watch.EventType, obj interface{}) error {
 	dataset, ok := obj.(*sednav1.Dataset)
@@ -37,6 +50,13 @@ func (c *Controller) syncToEdge(eventType watch.EventType, obj interface{}) erro
 	// more details at https://github.com/kubernetes/kubernetes/issues/3030
 	dataset.Kind = KindName
 
+	if eventType != watch.Deleted {
+		if err := validateFormat(dataset.Spec.Format); err != nil {
+			c.recorder.Event(dataset, v1.EventTypeWarning, "InvalidFormat", err.Error())
+			return err
+		}
+	}
+
 	// Here only propagate to the nodes with non empty name
 	nodeName := dataset.Spec.NodeName
 	if len(nodeName) == 0 {
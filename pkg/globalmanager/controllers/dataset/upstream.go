@@ -60,3 +60,17 @@ func (c *Controller) updateStatus(name, namespace string, status sednav1.Dataset
 func (c *Controller) SetUpstreamHandler(addFunc runtime.UpstreamHandlerAddFunc) error {
 	return addFunc(KindName, c.updateFromEdge)
 }
+
+// authorizeFromEdge checks the dataset is on the node
+func (c *Controller) authorizeFromEdge(nodeName, namespace, name string) error {
+	dataset, err := c.datasetLister.Datasets(namespace).Get(name)
+	if err != nil {
+		return err
+	}
+
+	return runtime.CheckBoundNode(nodeName, dataset.Spec.NodeName)
+}
+
+func (c *Controller) SetUpstreamAuthorizer(addFunc runtime.UpstreamAuthorizerAddFunc) error {
+	return addFunc(KindName, c.authorizeFromEdge)
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
+	sednaclientset "github.com/kubeedge/sedna/pkg/client/clientset/versioned/typed/sedna/v1alpha1"
+	sednav1listers "github.com/kubeedge/sedna/pkg/client/listers/sedna/v1alpha1"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
+)
+
+const (
+	// edgeNodeSyncPeriod is the period of syncing the heartbeats and the pending messages of the nodes
+	edgeNodeSyncPeriod = 30 * time.Second
+
+	// edgeNodeGracePeriod is how long the nodes connected before GM starts are waited to reconnect
+	edgeNodeGracePeriod = 2 * model.PongWait
+
+	// edgeNodeDeletedSize is the size of the channel of the deleted EdgeNodes
+	edgeNodeDeletedSize = 100
+
+	// notReconnectedMessage is the error of the nodes not reconnected after GM starts
+	notReconnectedMessage = "not reconnected since GM started"
+)
+
+// EdgeNodeController maintains the EdgeNode resources from the connections of LCs,
+// and notifies the feature controllers when the nodes are connected or disconnected
+type EdgeNodeController struct {
+	client     sednaclientset.SednaV1alpha1Interface
+	nodeLister sednav1listers.EdgeNodeLister
+	nodeSynced cache.InformerSynced
+
+	nodeStatus messagelayer.NodeStatusLayer
+	transport  string
+	startTime  time.Time
+
+	handlers []runtime.NodeConnectivityHandler
+	// connected is the connectivity of the nodes which the handlers are notified with
+	connected map[string]bool
+
+	// deleted receives the names of the deleted EdgeNodes
+	deleted chan string
+}
+
+// AddHandler adds the handler of the connectivity of the nodes
+func (nc *EdgeNodeController) AddHandler(handler runtime.NodeConnectivityHandler) error {
+	nc.handlers = append(nc.handlers, handler)
+	return nil
+}
+
+// Run starts the edge node controller
+func (nc *EdgeNodeController) Run(stopCh <-chan struct{}) {
+	klog.Info("Start the sedna edge node controller")
+
+	if !cache.WaitForNamedCacheSync("edgenode", stopCh, nc.nodeSynced) {
+		return
+	}
+
+	ticker := time.NewTicker(edgeNodeSyncPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case nodeName := <-nc.nodeStatus.NodeEvents():
+			if status, ok := nc.nodeStatus.GetNodeStatus(nodeName); ok {
+				nc.syncNode(status)
+			}
+		case nodeName := <-nc.deleted:
+			nc.forgetNode(nodeName)
+		case <-ticker.C:
+			nc.syncAll()
+		case <-stopCh:
+			klog.Info("Stop sedna edge node controller")
+			return
+		}
+	}
+}
+
+// syncAll syncs the nodes ever connected, and marks the nodes connected before GM starts
+// disconnected if they don't reconnect in the grace period
+func (nc *EdgeNodeController) syncAll() {
+	synced := make(map[string]bool)
+	for _, status := range nc.nodeStatus.ListNodeStatus() {
+		nc.syncNode(status)
+		synced[status.NodeName] = true
+	}
+
+	if time.Since(nc.startTime) < edgeNodeGracePeriod {
+		return
+	}
+
+	nodes, err := nc.nodeLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list edge nodes: %v", err)
+		return
+	}
+
+	for _, node := range nodes {
+		if synced[node.Name] || node.Status.Phase == sednav1.EdgeNodeDisconnected {
+			continue
+		}
+
+		status := node.Status.DeepCopy()
+		now := metav1.Now().Rfc3339Copy()
+		status.Phase = sednav1.EdgeNodeDisconnected
+		status.LastDisconnectedTime = &now
+		status.LastError = notReconnectedMessage
+		if err := nc.updateEdgeNode(node.Name, *status); err != nil {
+			klog.Errorf("failed to update edge node %s: %v", node.Name, err)
+		}
+		nc.notify(node.Name, false, notReconnectedMessage)
+	}
+}
+
+// forgetNode forgets the node whose EdgeNode is deleted if it's not connected,
+// otherwise its EdgeNode is created again in the next sync
+func (nc *EdgeNodeController) forgetNode(nodeName string) {
+	if !nc.nodeStatus.ForgetNode(nodeName) {
+		return
+	}
+	delete(nc.connected, nodeName)
+	klog.V(4).Infof("forgot the deleted edge node %s", nodeName)
+}
+
+// syncNode updates the EdgeNode of the node, and notifies the handlers if the connectivity changed
+func (nc *EdgeNodeController) syncNode(status messagelayer.NodeStatus) {
+	if err := nc.updateEdgeNode(status.NodeName, nc.newEdgeNodeStatus(status)); err != nil {
+		klog.Errorf("failed to update edge node %s: %v", status.NodeName, err)
+	}
+	nc.notify(status.NodeName, status.Connected, status.LastError)
+}
+
+func (nc *EdgeNodeController) newEdgeNodeStatus(status messagelayer.NodeStatus) sednav1.EdgeNodeStatus {
+	phase := sednav1.EdgeNodeDisconnected
+	if status.Connected {
+		phase = sednav1.EdgeNodeConnected
+	}
+
+	return sednav1.EdgeNodeStatus{
+		Phase:                phase,
+		Transport:            nc.transport,
+		LCVersion:            status.LCVersion,
+		ProtocolVersion:      int32(status.ProtocolVersion),
+		Capabilities:         status.Capabilities,
+		LastHeartbeatTime:    newTime(status.LastHeartbeat),
+		LastConnectedTime:    newTime(status.ConnectedAt),
+		LastDisconnectedTime: newTime(status.DisconnectedAt),
+		PendingMessages:      int32(status.PendingMessages),
+		BytesSent:            status.BytesSent,
+		BytesReceived:        status.BytesReceived,
+		PlainBytesSent:       status.PlainBytesSent,
+		PlainBytesReceived:   status.PlainBytesReceived,
+		LastError:            status.LastError,
+	}
+}
+
+// newTime returns the time in the precision of the serialized time, nil if it's zero
+func newTime(t time.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
+	}
+	mt := metav1.NewTime(t).Rfc3339Copy()
+	return &mt
+}
+
+// updateEdgeNode creates the EdgeNode of the node if not exists, and updates its status
+func (nc *EdgeNodeController) updateEdgeNode(name string, status sednav1.EdgeNodeStatus) error {
+	if node, err := nc.nodeLister.Get(name); err == nil && apiequality.Semantic.DeepEqual(node.Status, status) {
+		return nil
+	}
+
+	client := nc.client.EdgeNodes()
+	return runtime.RetryUpdateStatus(name, "", func() error {
+		node, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			node, err = client.Create(context.TODO(), &sednav1.EdgeNode{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+			}, metav1.CreateOptions{})
+		}
+		if err != nil {
+			return err
+		}
+
+		node.Status = status
+		_, err = client.UpdateStatus(context.TODO(), node, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// notify notifies the handlers when the connectivity of the node changed
+func (nc *EdgeNodeController) notify(nodeName string, connected bool, message string) {
+	if last, ok := nc.connected[nodeName]; ok && last == connected {
+		return
+	}
+	nc.connected[nodeName] = connected
+
+	if connected {
+		klog.Infof("edge node %s is connected", nodeName)
+	} else {
+		klog.Warningf("edge node %s is disconnected: %s", nodeName, message)
+	}
+	for _, handler := range nc.handlers {
+		handler(nodeName, connected, message)
+	}
+}
+
+// NewEdgeNodeController creates a new edge node controller from config
+func NewEdgeNodeController(cc *runtime.ControllerContext) (*EdgeNodeController, error) {
+	nodeInformer := cc.SednaInformerFactory.Sedna().V1alpha1().EdgeNodes()
+
+	nc := &EdgeNodeController{
+		client:     cc.SednaClient.SednaV1alpha1(),
+		nodeLister: nodeInformer.Lister(),
+		nodeSynced: nodeInformer.Informer().HasSynced,
+		nodeStatus: &messagelayer.ContextMessageLayer{},
+		transport:  cc.Config.Transport,
+		startTime:  time.Now(),
+		connected:  make(map[string]bool),
+		deleted:    make(chan string, edgeNodeDeletedSize),
+	}
+
+	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if node, ok := obj.(*sednav1.EdgeNode); ok {
+				nc.deleted <- node.Name
+			}
+		},
+	})
+
+	return nc, nil
+}
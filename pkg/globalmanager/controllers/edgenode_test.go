@@ -0,0 +1,181 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
+	"github.com/kubeedge/sedna/pkg/client/clientset/versioned/fake"
+	sednainformers "github.com/kubeedge/sedna/pkg/client/informers/externalversions"
+	"github.com/kubeedge/sedna/pkg/globalmanager/config"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer"
+	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
+)
+
+// fakeNodeStatusLayer returns the status of the nodes set by the test
+type fakeNodeStatusLayer struct {
+	status map[string]messagelayer.NodeStatus
+}
+
+func (l *fakeNodeStatusLayer) NodeEvents() <-chan string {
+	return nil
+}
+
+func (l *fakeNodeStatusLayer) GetNodeStatus(nodeName string) (messagelayer.NodeStatus, bool) {
+	s, ok := l.status[nodeName]
+	return s, ok
+}
+
+func (l *fakeNodeStatusLayer) ForgetNode(nodeName string) bool {
+	if l.status[nodeName].Connected {
+		return false
+	}
+	delete(l.status, nodeName)
+	return true
+}
+
+func (l *fakeNodeStatusLayer) ListNodeStatus() []messagelayer.NodeStatus {
+	var list []messagelayer.NodeStatus
+	for _, s := range l.status {
+		list = append(list, s)
+	}
+	return list
+}
+
+func TestEdgeNodeConnectivity(t *testing.T) {
+	// edge2 was connected before GM started
+	client := fake.NewSimpleClientset(&sednav1.EdgeNode{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge2"},
+		Status:     sednav1.EdgeNodeStatus{Phase: sednav1.EdgeNodeConnected},
+	})
+	factory := sednainformers.NewSharedInformerFactory(client, 0)
+
+	cc := &runtime.ControllerContext{
+		Config:               &config.ControllerConfig{Transport: "websocket"},
+		SednaClient:          client,
+		SednaInformerFactory: factory,
+	}
+	nc, err := NewEdgeNodeController(cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	layer := &fakeNodeStatusLayer{status: map[string]messagelayer.NodeStatus{
+		"edge1": {NodeName: "edge1", Connected: true, LCVersion: "v0.4.0", ConnectedAt: now, LastHeartbeat: now, PendingMessages: 2},
+	}}
+	nc.nodeStatus = layer
+
+	var notified []string
+	_ = nc.AddHandler(func(nodeName string, connected bool, message string) {
+		notified = append(notified, fmt.Sprintf("%s:%v:%s", nodeName, connected, message))
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	getStatus := func(name string) sednav1.EdgeNodeStatus {
+		node, err := client.SednaV1alpha1().EdgeNodes().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return node.Status
+	}
+
+	nc.syncNode(layer.status["edge1"])
+	status := getStatus("edge1")
+	if status.Phase != sednav1.EdgeNodeConnected || status.LCVersion != "v0.4.0" ||
+		status.PendingMessages != 2 || status.Transport != "websocket" || status.LastHeartbeatTime == nil {
+		t.Errorf("unexpected status of the connected node: %+v", status)
+	}
+
+	// the handlers are notified only when the connectivity changes
+	nc.syncNode(layer.status["edge1"])
+	layer.status["edge1"] = messagelayer.NodeStatus{
+		NodeName:       "edge1",
+		DisconnectedAt: now,
+		LastError:      "i/o timeout",
+	}
+	nc.syncNode(layer.status["edge1"])
+	status = getStatus("edge1")
+	if status.Phase != sednav1.EdgeNodeDisconnected || status.LastError != "i/o timeout" {
+		t.Errorf("unexpected status of the disconnected node: %+v", status)
+	}
+
+	// edge2 doesn't reconnect in the grace period
+	nc.syncAll()
+	if phase := getStatus("edge2").Phase; phase != sednav1.EdgeNodeConnected {
+		t.Errorf("expected edge2 waited to reconnect, got %s", phase)
+	}
+	nc.startTime = now.Add(-edgeNodeGracePeriod)
+	nc.syncAll()
+	if status := getStatus("edge2"); status.Phase != sednav1.EdgeNodeDisconnected || status.LastError != notReconnectedMessage {
+		t.Errorf("expected edge2 disconnected, got %+v", status)
+	}
+
+	want := "[edge1:true: edge1:false:i/o timeout edge2:false:" + notReconnectedMessage + "]"
+	if got := fmt.Sprint(notified); got != want {
+		t.Errorf("expected notified %s, got %s", want, got)
+	}
+
+	// the deleted EdgeNode of the disconnected node is not created again
+	if err := client.SednaV1alpha1().EdgeNodes().Delete(context.TODO(), "edge1", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	nc.forgetNode(<-nc.deleted)
+	nc.syncAll()
+	if _, err := client.SednaV1alpha1().EdgeNodes().Get(context.TODO(), "edge1", metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("expected edge1 forgotten, got %v", err)
+	}
+	if _, ok := nc.connected["edge1"]; ok {
+		t.Error("expected the connectivity of edge1 forgotten")
+	}
+}
+
+func TestSetNodeCondition(t *testing.T) {
+	// the node always connected has no condition
+	conditions, changed := runtime.SetNodeCondition(nil, "edge1", true, "")
+	if changed || len(conditions) != 0 {
+		t.Fatalf("expected no condition of the connected node, got %+v", conditions)
+	}
+
+	conditions, changed = runtime.SetNodeCondition(conditions, "edge1", false, "i/o timeout")
+	if !changed || len(conditions) != 1 || conditions[0].Status != "True" || conditions[0].Message != "i/o timeout" {
+		t.Fatalf("expected the disconnected condition, got %+v", conditions)
+	}
+
+	if _, changed = runtime.SetNodeCondition(conditions, "edge1", false, "EOF"); changed {
+		t.Error("expected the condition unchanged when still disconnected")
+	}
+
+	reconnected, changed := runtime.SetNodeCondition(conditions, "edge1", true, "")
+	if !changed || len(reconnected) != 1 || reconnected[0].Status != "False" || reconnected[0].Reason != "Connected" {
+		t.Errorf("expected the condition of the reconnected node false, got %+v", reconnected)
+	}
+	if conditions[0].Status != "True" {
+		t.Error("expected the original conditions not modified")
+	}
+}
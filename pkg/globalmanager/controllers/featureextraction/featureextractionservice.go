@@ -578,7 +578,9 @@ func New(cc *runtime.ControllerContext) (runtime.FeatureControllerI, error) {
 
 		UpdateFunc: func(old, cur interface{}) {
 			c.enqueueController(cur, true)
-			c.syncToEdge(watch.Added, cur)
+			if runtime.GenerationChanged(old, cur) {
+				c.syncToEdge(watch.Modified, cur)
+			}
 		},
 
 		DeleteFunc: func(obj interface{}) {
@@ -581,6 +581,28 @@ func (c *Controller) createPod(job *sednav1.FederatedLearningJob) (active int32,
 		c.addWorkerMount(&workerParam, dataset.Spec.URL, "TRAIN_DATASET_URL",
 			datasetSecret, true)
 
+		var datasetVersion *sednav1.DatasetVersion
+		if name := trainingWorker.Dataset.Version; name != "" {
+			for i := range dataset.Status.Versions {
+				if dataset.Status.Versions[i].Name == name {
+					datasetVersion = &dataset.Status.Versions[i]
+					break
+				}
+			}
+			if datasetVersion == nil {
+				return active, fmt.Errorf("dataset %s has no version %s", datasetName, name)
+			}
+
+			// the snapshot of the version is on the node of the dataset
+			workerParam.Mounts = append(workerParam.Mounts, runtime.WorkerMount{
+				URL: &runtime.MountURL{
+					URL:   datasetVersion.URL,
+					IsDir: true,
+				},
+				EnvName: "TRAIN_DATASET_VERSION_URL",
+			})
+		}
+
 		workerParam.Env = map[string]string{
 			"AGG_PORT": strconv.Itoa(int(aggPort)),
 			"AGG_IP":   aggServiceHost,
@@ -593,6 +615,9 @@ func (c *Controller) createPod(job *sednav1.FederatedLearningJob) (active int32,
 			"DATASET_NAME":       datasetName,
 			"LC_SERVER":          c.cfg.LC.Server,
 		}
+		if datasetVersion != nil {
+			workerParam.Env["DATASET_VERSION"] = datasetVersion.Name
+		}
 		workerParam.WorkerType = runtime.TrainPodType
 		workerParam.HostNetwork = true
 		workerParam.RestartPolicy = v1.RestartPolicyOnFailure
@@ -643,9 +668,11 @@ func New(cc *runtime.ControllerContext) (runtime.FeatureControllerI, error) {
 		UpdateFunc: func(old, cur interface{}) {
 			fc.enqueueController(cur, true)
 
-			// when a federated learning job is updated,
-			// send it to edge's LC as Added event.
-			fc.syncToEdge(watch.Added, cur)
+			// when the spec of a federated learning job is updated,
+			// send it to edge's LC as Modified event.
+			if runtime.GenerationChanged(old, cur) {
+				fc.syncToEdge(watch.Modified, cur)
+			}
 		},
 		DeleteFunc: func(obj interface{}) {
 			fc.enqueueController(obj, true)
@@ -51,6 +51,19 @@ func (c *Controller) syncModelWithName(nodeName, modelName, namespace string) er
 	return nil
 }
 
+// stageChanged returns true if the latest condition of the job, i.e. the stage to run on the edge, is changed,
+// the other status updates aren't synced to the edge
+func stageChanged(old, cur *sednav1.IncrementalLearningJob) bool {
+	latest := func(job *sednav1.IncrementalLearningJob) (c sednav1.ILJobCondition) {
+		if n := len(job.Status.Conditions); n > 0 {
+			c = job.Status.Conditions[n-1]
+		}
+		return
+	}
+	o, c := latest(old), latest(cur)
+	return o.Type != c.Type || o.Stage != c.Stage
+}
+
 func (c *Controller) syncToEdge(eventType watch.EventType, obj interface{}) error {
 	job, ok := obj.(*sednav1.IncrementalLearningJob)
 	if !ok {
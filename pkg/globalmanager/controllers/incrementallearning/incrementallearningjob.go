@@ -32,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
@@ -45,6 +46,7 @@ import (
 	sednav1listers "github.com/kubeedge/sedna/pkg/client/listers/sedna/v1alpha1"
 	"github.com/kubeedge/sedna/pkg/globalmanager/config"
 	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
+	"github.com/kubeedge/sedna/pkg/trigger"
 )
 
 const (
@@ -74,6 +76,9 @@ type Controller struct {
 	// A store of jobs
 	jobLister sednav1listers.IncrementalLearningJobLister
 
+	// A store of datasets
+	datasetLister sednav1listers.DatasetLister
+
 	// A store of pods, populated by the podController
 	podStore corelisters.PodLister
 
@@ -82,6 +87,8 @@ type Controller struct {
 
 	cfg *config.ControllerConfig
 
+	recorder record.EventRecorder
+
 	sendToEdgeFunc runtime.DownstreamSendFunc
 }
 
@@ -264,6 +271,11 @@ func (c *Controller) sync(key string) (bool, error) {
 	// set kind in case that the kind is None
 	job.SetGroupVersionKind(Kind)
 
+	if err := validateTriggers(&job); err != nil {
+		c.recorder.Event(&job, v1.EventTypeWarning, "InvalidTrigger", err.Error())
+		return true, nil
+	}
+
 	// when job is handled at first, create pod for inference
 	if job.Status.StartTime == nil {
 		now := metav1.Now()
@@ -312,6 +324,32 @@ func (c *Controller) sync(key string) (bool, error) {
 	return forget, err
 }
 
+// validateTriggers validates the train and deploy triggers of the job
+func validateTriggers(job *sednav1.IncrementalLearningJob) error {
+	train := job.Spec.TrainSpec.Trigger
+	if err := trigger.Validate(train.Expression, train.Condition.Metric, trigger.NumOfSamplesMetric); err != nil {
+		return fmt.Errorf("invalid train trigger: %w", err)
+	}
+	if train.Timer != nil {
+		if _, err := trigger.NewTimerRangeTrigger(trigger.TimerConfig(*train.Timer)); err != nil {
+			return fmt.Errorf("invalid train trigger timer: %w", err)
+		}
+	}
+
+	// the metrics of deploy trigger are reported by eval worker, so only check the syntax
+	deploy := job.Spec.DeploySpec.Trigger
+	if err := trigger.Validate(deploy.Expression, deploy.Condition.Metric); err != nil {
+		return fmt.Errorf("invalid deploy trigger: %w", err)
+	}
+	if deploy.Timer != nil {
+		if _, err := trigger.NewTimerRangeTrigger(trigger.TimerConfig(*deploy.Timer)); err != nil {
+			return fmt.Errorf("invalid deploy trigger timer: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // setWorkerNodeNameOfJob sets the worker nodeName of the specified job
 // which is used for downstream to sync job info to the specified LC located in nodeName.
 func (c *Controller) setWorkerNodeNameOfJob(job *sednav1.IncrementalLearningJob, jobStage string, nodeName string) error {
@@ -666,6 +704,9 @@ func (c *Controller) createPod(job *sednav1.IncrementalLearningJob, podtype sedn
 
 			"LC_SERVER": c.cfg.LC.Server,
 		}
+		if cond.Input.DatasetVersion != "" {
+			workerParam.Env["DATASET_VERSION"] = cond.Input.DatasetVersion
+		}
 
 		baseModelURL := inputmodelURLs[0]
 		var baseModelSecret *v1.Secret
@@ -877,6 +918,8 @@ func New(cc *runtime.ControllerContext) (runtime.FeatureControllerI, error) {
 		queue: workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(runtime.DefaultBackOff, runtime.MaxBackOff), Name),
 
 		cfg: cc.Config,
+
+		recorder: eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: Name + "-controller"}),
 	}
 
 	jobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -886,7 +929,14 @@ func New(cc *runtime.ControllerContext) (runtime.FeatureControllerI, error) {
 		},
 		UpdateFunc: func(old, cur interface{}) {
 			jc.enqueueController(cur, true)
-			jc.syncToEdge(watch.Added, cur)
+
+			switch {
+			case runtime.GenerationChanged(old, cur):
+				jc.syncToEdge(watch.Modified, cur)
+			case stageChanged(old.(*sednav1.IncrementalLearningJob), cur.(*sednav1.IncrementalLearningJob)) || runtime.AnnotationsChanged(old, cur):
+				// the stage of the job on the edge is driven by its latest condition and annotations
+				jc.syncToEdge(watch.Modified, cur)
+			}
 		},
 		DeleteFunc: func(obj interface{}) {
 			jc.enqueueController(obj, true)
@@ -894,6 +944,7 @@ func New(cc *runtime.ControllerContext) (runtime.FeatureControllerI, error) {
 		},
 	})
 	jc.jobLister = jobInformer.Lister()
+	jc.datasetLister = cc.SednaInformerFactory.Sedna().V1alpha1().Datasets().Lister()
 	jc.jobStoreSynced = jobInformer.Informer().HasSynced
 
 	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -552,7 +552,9 @@ func New(cc *runtime.ControllerContext) (runtime.FeatureControllerI, error) {
 
 		UpdateFunc: func(old, cur interface{}) {
 			jc.enqueueController(cur, true)
-			jc.syncToEdge(watch.Added, cur)
+			if runtime.GenerationChanged(old, cur) {
+				jc.syncToEdge(watch.Modified, cur)
+			}
 		},
 
 		DeleteFunc: func(obj interface{}) {
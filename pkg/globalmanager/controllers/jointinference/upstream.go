@@ -24,6 +24,7 @@ import (
 	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
 	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/klog/v2"
 )
 
@@ -90,3 +91,62 @@ func (c *Controller) updateFromEdge(name, namespace, operation string, content [
 func (c *Controller) SetUpstreamHandler(addFunc runtime.UpstreamHandlerAddFunc) error {
 	return addFunc(KindName, c.updateFromEdge)
 }
+
+// authorizeFromEdge checks the node is the node of the edge worker of the service
+func (c *Controller) authorizeFromEdge(nodeName, namespace, name string) error {
+	service, err := c.serviceLister.JointInferenceServices(namespace).Get(name)
+	if err != nil {
+		return err
+	}
+
+	return runtime.CheckBoundNode(nodeName, service.Spec.EdgeWorker.Template.Spec.NodeName)
+}
+
+func (c *Controller) SetUpstreamAuthorizer(addFunc runtime.UpstreamAuthorizerAddFunc) error {
+	return addFunc(KindName, c.authorizeFromEdge)
+}
+
+// updateNodeCondition sets the connectivity condition of the node in the service's status
+func (c *Controller) updateNodeCondition(name, namespace, nodeName string, connected bool, message string) error {
+	client := c.client.JointInferenceServices(namespace)
+
+	return runtime.RetryUpdateStatus(name, namespace, func() error {
+		service, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		conditions, changed := runtime.SetNodeCondition(service.Status.NodeConditions, nodeName, connected, message)
+		if !changed {
+			return nil
+		}
+		service.Status.NodeConditions = conditions
+		_, err = client.UpdateStatus(context.TODO(), service, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// updateNodeConnectivity surfaces the connectivity of the node on the services bound to it
+func (c *Controller) updateNodeConnectivity(nodeName string, connected bool, message string) {
+	services, err := c.serviceLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list services for the connectivity of node %s: %v", nodeName, err)
+		return
+	}
+
+	for _, service := range services {
+		if c.authorizeFromEdge(nodeName, service.Namespace, service.Name) != nil {
+			continue
+		}
+		if _, changed := runtime.SetNodeCondition(service.Status.NodeConditions, nodeName, connected, message); !changed {
+			continue
+		}
+		if err := c.updateNodeCondition(service.Name, service.Namespace, nodeName, connected, message); err != nil {
+			klog.Errorf("failed to update the condition of node %s of service %s/%s: %v",
+				nodeName, service.Namespace, service.Name, err)
+		}
+	}
+}
+
+func (c *Controller) SetNodeConnectivityHandler(addFunc runtime.NodeConnectivityHandlerAddFunc) error {
+	return addFunc(c.updateNodeConnectivity)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	coordinationv1 "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+
+	clientset "github.com/kubeedge/sedna/pkg/client/clientset/versioned"
+	"github.com/kubeedge/sedna/pkg/globalmanager/config"
+)
+
+// runWithLeaderElection runs the controllers and serves LCs once elected as the leader.
+// The followers don't listen for LCs, so only the leader is ready to serve them.
+// The new leader rebuilds the downstream state of the nodes from the informers, and
+// the LCs resync their resources when reconnected, so the delivery is resumed.
+func (m *Manager) runWithLeaderElection(kubeClient kubernetes.Interface, sednaClient clientset.Interface) error {
+	cfg := m.Config.LeaderElection
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("failed to get the hostname: %w", err)
+	}
+	// the identity is unique even if the replicas share the hostname
+	id := hostname + "_" + string(uuid.NewUUID())
+
+	lock := newLeaseLock(cfg, kubeClient.CoordinationV1(), id)
+
+	klog.Infof("%s is waiting to be elected as the leader of lease %s/%s", id, cfg.ResourceNamespace, cfg.ResourceName)
+	leaderelection.RunOrDie(context.Background(), newLeaderElectionConfig(cfg, lock,
+		func() {
+			if err := m.run(kubeClient, sednaClient); err != nil {
+				klog.Fatalf("failed to run as the leader: %v", err)
+			}
+		},
+		func() {
+			// the controllers can't be stopped, so exit and restart as a follower
+			klog.Fatalf("%s lost the leadership", id)
+		}))
+	return fmt.Errorf("leader election of lease %s/%s ended", cfg.ResourceNamespace, cfg.ResourceName)
+}
+
+// newLeaseLock creates the lock of the lease in the config held by the identity
+func newLeaseLock(cfg config.LeaderElection, leases coordinationv1.LeasesGetter, id string) *resourcelock.LeaseLock {
+	return &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Namespace: cfg.ResourceNamespace,
+			Name:      cfg.ResourceName,
+		},
+		Client:     leases,
+		LockConfig: resourcelock.ResourceLockConfig{Identity: id},
+	}
+}
+
+// newLeaderElectionConfig creates the config of the leader election by the lock,
+// lead is called once elected, and stopLeading is called when the leadership is lost
+func newLeaderElectionConfig(cfg config.LeaderElection, lock *resourcelock.LeaseLock,
+	lead func(), stopLeading func()) leaderelection.LeaderElectionConfig {
+	id := lock.Identity()
+	return leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: time.Duration(cfg.LeaseDurationSeconds) * time.Second,
+		RenewDeadline: time.Duration(cfg.RenewDeadlineSeconds) * time.Second,
+		RetryPeriod:   time.Duration(cfg.RetryPeriodSeconds) * time.Second,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) {
+				klog.Infof("%s is elected as the leader", id)
+				lead()
+			},
+			OnStoppedLeading: stopLeading,
+			OnNewLeader: func(identity string) {
+				if identity != id {
+					klog.Infof("the leader is %s", identity)
+				}
+			},
+		},
+		Name: cfg.ResourceName,
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	coordv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	coordinationv1 "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/client-go/tools/leaderelection"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/config"
+)
+
+// fakeLeases keeps the leases in memory
+type fakeLeases struct {
+	lock   sync.Mutex
+	leases map[string]*coordv1.Lease
+}
+
+func (f *fakeLeases) Leases(namespace string) coordinationv1.LeaseInterface {
+	return &fakeLeaseClient{fakeLeases: f, namespace: namespace}
+}
+
+func (f *fakeLeases) get(namespace, name string) (*coordv1.Lease, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	lease, ok := f.leases[namespace+"/"+name]
+	if !ok {
+		return nil, false
+	}
+	return lease.DeepCopy(), true
+}
+
+// fakeLeaseClient implements the methods of the lease client used by the lock
+type fakeLeaseClient struct {
+	coordinationv1.LeaseInterface
+	*fakeLeases
+	namespace string
+}
+
+func (c *fakeLeaseClient) Get(_ context.Context, name string, _ metav1.GetOptions) (*coordv1.Lease, error) {
+	if lease, ok := c.get(c.namespace, name); ok {
+		return lease, nil
+	}
+	return nil, errors.NewNotFound(schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}, name)
+}
+
+func (c *fakeLeaseClient) Create(_ context.Context, lease *coordv1.Lease, _ metav1.CreateOptions) (*coordv1.Lease, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := c.namespace + "/" + lease.Name
+	if _, ok := c.leases[key]; ok {
+		return nil, errors.NewAlreadyExists(schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}, lease.Name)
+	}
+	lease = lease.DeepCopy()
+	lease.Namespace = c.namespace
+	lease.ResourceVersion = "1"
+	c.leases[key] = lease
+	return lease.DeepCopy(), nil
+}
+
+func (c *fakeLeaseClient) Update(_ context.Context, lease *coordv1.Lease, _ metav1.UpdateOptions) (*coordv1.Lease, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := c.namespace + "/" + lease.Name
+	current, ok := c.leases[key]
+	if !ok {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}, lease.Name)
+	}
+	if current.ResourceVersion != lease.ResourceVersion {
+		return nil, errors.NewConflict(schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}, lease.Name, nil)
+	}
+	lease = lease.DeepCopy()
+	lease.ResourceVersion = current.ResourceVersion + "1"
+	c.leases[key] = lease
+	return lease.DeepCopy(), nil
+}
+
+func TestLeaderElection(t *testing.T) {
+	cfg := config.NewDefaultControllerConfig().LeaderElection
+	cfg.LeaseDurationSeconds = 3
+	cfg.RenewDeadlineSeconds = 2
+	cfg.RetryPeriodSeconds = 1
+	leases := &fakeLeases{leases: make(map[string]*coordv1.Lease)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// run runs the candidate, and returns the channel closed when it's elected
+	run := func(id string) <-chan struct{} {
+		led := make(chan struct{})
+		le, err := leaderelection.NewLeaderElector(newLeaderElectionConfig(cfg, newLeaseLock(cfg, leases, id),
+			func() { close(led) }, func() {}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		go le.Run(ctx)
+		return led
+	}
+
+	first := run("gm-1")
+	select {
+	case <-first:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected gm-1 elected as the leader")
+	}
+
+	lease, ok := leases.get(cfg.ResourceNamespace, cfg.ResourceName)
+	if !ok || lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != "gm-1" {
+		t.Fatalf("expected lease %s/%s held by gm-1, got %+v", cfg.ResourceNamespace, cfg.ResourceName, lease)
+	}
+	if lease.Spec.LeaseDurationSeconds == nil || *lease.Spec.LeaseDurationSeconds != 3 {
+		t.Errorf("expected the lease duration 3s, got %v", lease.Spec.LeaseDurationSeconds)
+	}
+
+	// the follower doesn't lead while the leader renews the lease
+	second := run("gm-2")
+	select {
+	case <-second:
+		t.Fatal("expected gm-2 not elected while gm-1 leads")
+	case <-time.After(2 * time.Second):
+	}
+}
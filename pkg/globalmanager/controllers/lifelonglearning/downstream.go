@@ -28,6 +28,19 @@ import (
 	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
 )
 
+// stageChanged returns true if the latest condition of the job, i.e. the stage to run on the edge, is changed,
+// the other status updates aren't synced to the edge
+func stageChanged(old, cur *sednav1.LifelongLearningJob) bool {
+	latest := func(job *sednav1.LifelongLearningJob) (c sednav1.LLJobCondition) {
+		if n := len(job.Status.Conditions); n > 0 {
+			c = job.Status.Conditions[n-1]
+		}
+		return
+	}
+	o, c := latest(old), latest(cur)
+	return o.Type != c.Type || o.Stage != c.Stage
+}
+
 func (c *Controller) syncToEdge(eventType watch.EventType, obj interface{}) error {
 	job, ok := obj.(*sednav1.LifelongLearningJob)
 	if !ok {
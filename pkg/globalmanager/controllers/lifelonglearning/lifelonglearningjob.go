@@ -33,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
@@ -46,6 +47,7 @@ import (
 	sednav1listers "github.com/kubeedge/sedna/pkg/client/listers/sedna/v1alpha1"
 	"github.com/kubeedge/sedna/pkg/globalmanager/config"
 	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
+	"github.com/kubeedge/sedna/pkg/trigger"
 )
 
 const (
@@ -74,6 +76,9 @@ type Controller struct {
 	// A store of jobs
 	jobLister sednav1listers.LifelongLearningJobLister
 
+	// A store of datasets
+	datasetLister sednav1listers.DatasetLister
+
 	// A store of pods, populated by the podController
 	podStore corelisters.PodLister
 
@@ -82,6 +87,8 @@ type Controller struct {
 
 	cfg *config.ControllerConfig
 
+	recorder record.EventRecorder
+
 	sendToEdgeFunc runtime.DownstreamSendFunc
 }
 
@@ -260,6 +267,11 @@ func (c *Controller) sync(key string) (bool, error) {
 	// set kind for lifelonglearningjob in case that the kind is None
 	job.SetGroupVersionKind(Kind)
 
+	if err := validateTrigger(&job); err != nil {
+		c.recorder.Event(&job, v1.EventTypeWarning, "InvalidTrigger", err.Error())
+		return true, nil
+	}
+
 	if job.Status.StartTime == nil {
 		// job is first in
 		now := metav1.Now()
@@ -339,6 +351,21 @@ func (c *Controller) addJobAnnotations(job *sednav1.LifelongLearningJob, key str
 	})
 }
 
+// validateTrigger validates the train trigger of the job
+func validateTrigger(job *sednav1.LifelongLearningJob) error {
+	train := job.Spec.TrainSpec.Trigger
+	if err := trigger.Validate(train.Expression, train.Condition.Metric, trigger.NumOfSamplesMetric); err != nil {
+		return fmt.Errorf("invalid train trigger: %w", err)
+	}
+	if train.Timer != nil {
+		if _, err := trigger.NewTimerRangeTrigger(trigger.TimerConfig(*train.Timer)); err != nil {
+			return fmt.Errorf("invalid train trigger timer: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // transitJobState transit job to next state
 func (c *Controller) transitJobState(job *sednav1.LifelongLearningJob) (bool, error) {
 	var initialType sednav1.LLJobStageConditionType
@@ -649,6 +676,9 @@ func (c *Controller) createPod(job *sednav1.LifelongLearningJob, podtype sednav1
 		if hasCompletedInitialTraining {
 			workerParam.Env["CLOUD_KB_INDEX"] = c.getCloudKBIndex(jobConditions)
 		}
+		if cond.Input.DatasetVersion != "" {
+			workerParam.Env["DATASET_VERSION"] = cond.Input.DatasetVersion
+		}
 
 		workerParam.Mounts = append(workerParam.Mounts,
 			runtime.WorkerMount{
@@ -813,6 +843,7 @@ func New(cc *runtime.ControllerContext) (runtime.FeatureControllerI, error) {
 		client:     cc.SednaClient.SednaV1alpha1(),
 		queue:      workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(runtime.DefaultBackOff, runtime.MaxBackOff), Name),
 		cfg:        cfg,
+		recorder:   eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: Name + "-controller"}),
 	}
 
 	jobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -822,7 +853,14 @@ func New(cc *runtime.ControllerContext) (runtime.FeatureControllerI, error) {
 		},
 		UpdateFunc: func(old, cur interface{}) {
 			jc.enqueueController(cur, true)
-			jc.syncToEdge(watch.Added, cur)
+
+			switch {
+			case runtime.GenerationChanged(old, cur):
+				jc.syncToEdge(watch.Modified, cur)
+			case stageChanged(old.(*sednav1.LifelongLearningJob), cur.(*sednav1.LifelongLearningJob)) || runtime.AnnotationsChanged(old, cur):
+				// the stage of the job on the edge is driven by its latest condition and annotations
+				jc.syncToEdge(watch.Modified, cur)
+			}
 		},
 		DeleteFunc: func(obj interface{}) {
 			jc.enqueueController(obj, true)
@@ -830,6 +868,7 @@ func New(cc *runtime.ControllerContext) (runtime.FeatureControllerI, error) {
 		},
 	})
 	jc.jobLister = jobInformer.Lister()
+	jc.datasetLister = cc.SednaInformerFactory.Sedna().V1alpha1().Datasets().Lister()
 	jc.jobStoreSynced = jobInformer.Informer().HasSynced
 
 	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
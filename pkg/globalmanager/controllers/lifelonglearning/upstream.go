@@ -25,6 +25,7 @@ import (
 	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/klog/v2"
 
 	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
@@ -50,6 +51,9 @@ type ConditionData struct {
 		DataIndexURL string `json:"dataIndexURL,omitempty"`
 
 		OutputDir string `json:"outputDir,omitempty"`
+
+		// the version of the dataset pinned to the train round
+		DatasetVersion string `json:"datasetVersion,omitempty"`
 	} `json:"input,omitempty"`
 
 	Output *struct {
@@ -237,3 +241,73 @@ func (c *Controller) updateFromEdge(name, namespace, operation string, content [
 func (c *Controller) SetUpstreamHandler(addFunc runtime.UpstreamHandlerAddFunc) error {
 	return addFunc(KindName, c.updateFromEdge)
 }
+
+// authorizeFromEdge checks the node is the node of the dataset or the node of any stage of the job
+func (c *Controller) authorizeFromEdge(nodeName, namespace, name string) error {
+	job, err := c.jobLister.LifelongLearningJobs(namespace).Get(name)
+	if err != nil {
+		return err
+	}
+
+	nodes := []string{}
+	ds, err := c.datasetLister.Datasets(namespace).Get(job.Spec.Dataset.Name)
+	if err == nil {
+		nodes = append(nodes, ds.Spec.NodeName)
+	}
+
+	ann := job.GetAnnotations()
+	for _, stage := range []sednav1.LLJobStage{sednav1.LLJobTrain, sednav1.LLJobEval, sednav1.LLJobDeploy} {
+		nodes = append(nodes, ann[runtime.AnnotationsKeyPrefix+string(stage)])
+	}
+
+	return runtime.CheckBoundNode(nodeName, nodes...)
+}
+
+func (c *Controller) SetUpstreamAuthorizer(addFunc runtime.UpstreamAuthorizerAddFunc) error {
+	return addFunc(KindName, c.authorizeFromEdge)
+}
+
+// updateNodeCondition sets the connectivity condition of the node in the job's status
+func (c *Controller) updateNodeCondition(name, namespace, nodeName string, connected bool, message string) error {
+	client := c.client.LifelongLearningJobs(namespace)
+
+	return runtime.RetryUpdateStatus(name, namespace, func() error {
+		job, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		conditions, changed := runtime.SetNodeCondition(job.Status.NodeConditions, nodeName, connected, message)
+		if !changed {
+			return nil
+		}
+		job.Status.NodeConditions = conditions
+		_, err = client.UpdateStatus(context.TODO(), job, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// updateNodeConnectivity surfaces the connectivity of the node on the jobs bound to it
+func (c *Controller) updateNodeConnectivity(nodeName string, connected bool, message string) {
+	jobs, err := c.jobLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list jobs for the connectivity of node %s: %v", nodeName, err)
+		return
+	}
+
+	for _, job := range jobs {
+		if c.authorizeFromEdge(nodeName, job.Namespace, job.Name) != nil {
+			continue
+		}
+		if _, changed := runtime.SetNodeCondition(job.Status.NodeConditions, nodeName, connected, message); !changed {
+			continue
+		}
+		if err := c.updateNodeCondition(job.Name, job.Namespace, nodeName, connected, message); err != nil {
+			klog.Errorf("failed to update the condition of node %s of job %s/%s: %v",
+				nodeName, job.Namespace, job.Name, err)
+		}
+	}
+}
+
+func (c *Controller) SetNodeConnectivityHandler(addFunc runtime.NodeConnectivityHandlerAddFunc) error {
+	return addFunc(c.updateNodeConnectivity)
+}
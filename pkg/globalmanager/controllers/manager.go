@@ -18,17 +18,26 @@ package controllers
 
 import (
 	"fmt"
+	"io/ioutil"
 	"math/rand"
+	"net/http"
+	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 
+	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
 	clientset "github.com/kubeedge/sedna/pkg/client/clientset/versioned"
 	sednainformers "github.com/kubeedge/sedna/pkg/client/informers/externalversions"
 	"github.com/kubeedge/sedna/pkg/globalmanager/config"
 	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/grpcstream"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/mqtt"
 	websocket "github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/ws"
 	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
 	"github.com/kubeedge/sedna/pkg/globalmanager/utils"
@@ -53,7 +62,8 @@ func genResyncPeriod(minPeriod time.Duration) time.Duration {
 	return time.Duration(factor * float64(minPeriod.Nanoseconds()))
 }
 
-// Start starts the controllers it has managed
+// Start starts the controllers it has managed,
+// which waits to be elected as the leader if the leader election is enabled
 func (m *Manager) Start() error {
 	kubeClient, err := utils.KubeClient()
 	if err != nil {
@@ -70,6 +80,20 @@ func (m *Manager) Start() error {
 		return err
 	}
 
+	cfg := m.Config
+	// every replica serves the metrics, which are empty on the followers
+	if cfg.Metrics.Port != 0 {
+		go serveMetrics(fmt.Sprintf("%s:%d", cfg.Metrics.Address, cfg.Metrics.Port))
+	}
+
+	if cfg.LeaderElection.LeaderElect {
+		return m.runWithLeaderElection(kubeClient, sednaClient)
+	}
+	return m.run(kubeClient, sednaClient)
+}
+
+// run runs the controllers and serves LCs until the transport server fails
+func (m *Manager) run(kubeClient kubernetes.Interface, sednaClient clientset.Interface) error {
 	cfg := m.Config
 	namespace := cfg.Namespace
 	if namespace == "" {
@@ -92,7 +116,30 @@ func (m *Manager) Start() error {
 		SednaInformerFactory: sednaInformerFactory,
 	}
 
+	var workerTokenIssuer *runtime.WorkerTokenIssuer
+	if wt := cfg.LC.WorkerToken; wt.Enable {
+		key, err := runtime.LoadWorkerTokenKey(kubeClient, wt.SecretNamespace, wt.SecretName)
+		if err != nil {
+			return err
+		}
+		ttl := time.Duration(wt.TTLSeconds) * time.Second
+		if wt.NoExpiry {
+			ttl = 0
+		}
+		workerTokenIssuer = runtime.NewWorkerTokenIssuer(key, ttl)
+		runtime.SetWorkerTokenIssuer(workerTokenIssuer)
+		websocket.SetWorkerTokenKey(workerTokenIssuer.PublicKey())
+		klog.Infof("injecting the tokens into the workers, signed by the key in secret %s/%s", wt.SecretNamespace, wt.SecretName)
+	}
+
+	lister, err := newResourceLister(sednaInformerFactory)
+	if err != nil {
+		return err
+	}
+	websocket.SetResourceLister(lister)
+
 	uc, _ := NewUpstreamController(context)
+	nc, _ := NewEdgeNodeController(context)
 
 	downstreamSendFunc := messagelayer.NewContextMessageLayer().SendResourceObject
 
@@ -100,6 +147,10 @@ func (m *Manager) Start() error {
 
 	go uc.Run(stopCh)
 
+	if workerTokenIssuer != nil {
+		go runtime.RenewWorkerTokens(kubeClient, workerTokenIssuer, namespace, stopCh)
+	}
+
 	for name, factory := range NewRegistry() {
 		f, err := factory(context)
 		if err != nil {
@@ -107,21 +158,186 @@ func (m *Manager) Start() error {
 		}
 		f.SetDownstreamSendFunc(downstreamSendFunc)
 		f.SetUpstreamHandler(uc.Add)
+		if s, ok := f.(runtime.UpstreamAuthorizerSetter); ok {
+			s.SetUpstreamAuthorizer(uc.AddAuthorizer)
+		}
+		if s, ok := f.(runtime.NodeConnectivityHandlerSetter); ok {
+			s.SetNodeConnectivityHandler(nc.AddHandler)
+		}
 
 		klog.Infof("initialized controller %s", name)
 		go f.Run(stopCh)
 	}
 
+	// the handlers of the node connectivity are all added
+	go nc.Run(stopCh)
+
 	kubeInformerFactory.Start(stopCh)
 	sednaInformerFactory.Start(stopCh)
 
-	addr := fmt.Sprintf("%s:%d", m.Config.WebSocket.Address, m.Config.WebSocket.Port)
+	// the resources synced to the nodes are known before LCs connect and resync
+	kubeInformerFactory.WaitForCacheSync(stopCh)
+	sednaInformerFactory.WaitForCacheSync(stopCh)
 
-	ws := websocket.NewServer(addr)
-	err = ws.ListenAndServe()
+	websocket.SetUpstreamOptions(websocket.UpstreamOptions{
+		QueueSize:      cfg.Upstream.QueueSize,
+		RateLimit:      cfg.Upstream.RateLimit,
+		Burst:          cfg.Upstream.Burst,
+		Coalesce:       cfg.Upstream.Coalesce,
+		OverflowPolicy: cfg.Upstream.OverflowPolicy,
+	})
+
+	server, err := newTransportServer(cfg, kubeClient)
+	if err != nil {
+		close(stopCh)
+		return err
+	}
+
+	err = server.ListenAndServe()
 	if err != nil {
 		close(stopCh)
-		return fmt.Errorf("failed to listen websocket at %s: %v", addr, err)
+		return fmt.Errorf("failed to serve %s transport: %v", cfg.Transport, err)
 	}
 	return nil
 }
+
+// edgeResources are the plural names of the resources synced to LCs
+var edgeResources = []string{
+	"datasets", "models", "jointinferenceservices", "federatedlearningjobs", "incrementallearningjobs",
+	"lifelonglearningjobs", "objectsearchservices", "reidjobs", "videoanalyticsjobs", "featureextractionservices",
+}
+
+// newResourceLister creates the lister of the resources synced to LCs by the informers,
+// which must be called before the informers start
+func newResourceLister(factory sednainformers.SharedInformerFactory) (websocket.ResourceLister, error) {
+	informers := make(map[string]sednainformers.GenericInformer, len(edgeResources))
+	for _, resource := range edgeResources {
+		informer, err := factory.ForResource(sednav1.SchemeGroupVersion.WithResource(resource))
+		if err != nil {
+			return nil, err
+		}
+		// the kind of the resource on LC is the lowercase singular name
+		informers[strings.TrimSuffix(resource, "s")] = informer
+	}
+
+	return func(kind, namespace, name string) (bool, error) {
+		informer, ok := informers[strings.ToLower(kind)]
+		if !ok {
+			return false, fmt.Errorf("unknown kind %s", kind)
+		}
+		_, err := informer.Lister().ByNamespace(namespace).Get(name)
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return err == nil, err
+	}, nil
+}
+
+// serveMetrics serves the metrics of GM at /metrics
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", websocket.MetricsHandler())
+
+	klog.Infof("serving metrics at %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		klog.Errorf("failed to serve metrics at %s: %v", addr, err)
+	}
+}
+
+// transportServer serves the messages between GM and LCs
+type transportServer interface {
+	ListenAndServe() error
+}
+
+// newTransportServer creates the server of the transport in the config
+func newTransportServer(cfg *config.ControllerConfig, kubeClient kubernetes.Interface) (transportServer, error) {
+	if cfg.Transport == model.MQTTTransport {
+		options, err := newMQTTServerOptions(&cfg.MQTT)
+		if err != nil {
+			return nil, err
+		}
+		klog.Infof("bridging LCs through the mqtt broker %s", options.Broker)
+		return mqtt.NewServer(options), nil
+	}
+
+	addr := fmt.Sprintf("%s:%d", cfg.WebSocket.Address, cfg.WebSocket.Port)
+	wsOptions, err := newWebSocketServerOptions(&cfg.WebSocket, kubeClient)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Transport == model.GRPCTransport {
+		klog.Infof("serving gRPC streams of LCs at %s", addr)
+		return grpcstream.NewServer(addr, wsOptions), nil
+	}
+	return websocket.NewServer(addr, wsOptions), nil
+}
+
+// newMQTTServerOptions creates the options of connecting to the broker from the config
+func newMQTTServerOptions(cfg *config.MQTT) (mqtt.ServerOptions, error) {
+	options := mqtt.ServerOptions{
+		ClientOptions: mqtt.ClientOptions{
+			Broker:   cfg.Broker,
+			ClientID: cfg.ClientID,
+			Username: cfg.Username,
+		},
+		TopicPrefix: cfg.TopicPrefix,
+	}
+
+	if cfg.PasswordFile != "" {
+		data, err := ioutil.ReadFile(cfg.PasswordFile)
+		if err != nil {
+			return options, fmt.Errorf("failed to read the mqtt password file: %w", err)
+		}
+		options.Password = strings.TrimSpace(string(data))
+	}
+
+	if cfg.CAFile != "" || cfg.CertFile != "" {
+		tlsConfig, err := mqtt.NewTLSConfig(cfg.CAFile, cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return options, err
+		}
+		options.TLSConfig = tlsConfig
+	}
+	return options, nil
+}
+
+// newWebSocketServerOptions creates the security options of the websocket server from the config
+func newWebSocketServerOptions(cfg *config.WebSocket, kubeClient kubernetes.Interface) (websocket.ServerOptions, error) {
+	var options websocket.ServerOptions
+	auth := cfg.Auth
+	tokenAuth := auth.TokenFile != "" || len(auth.ServiceAccounts) > 0
+
+	if cfg.TLS != nil {
+		// the client certificate is optional if the token is accepted
+		tlsConfig, err := websocket.NewTLSConfig(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ClientCAFile, tokenAuth)
+		if err != nil {
+			return options, err
+		}
+		options.TLSConfig = tlsConfig
+		if cfg.TLS.ClientCAFile != "" {
+			options.Authenticators = append(options.Authenticators, websocket.NewCertAuthenticator())
+		}
+	}
+
+	if auth.TokenFile != "" {
+		a, err := websocket.NewTokenFileAuthenticator(auth.TokenFile)
+		if err != nil {
+			return options, err
+		}
+		options.Authenticators = append(options.Authenticators, a)
+	}
+
+	if len(auth.ServiceAccounts) > 0 {
+		options.Authenticators = append(options.Authenticators,
+			websocket.NewServiceAccountAuthenticator(kubeClient, auth.ServiceAccounts))
+	}
+
+	if len(options.Authenticators) == 0 {
+		klog.Warningf("no authentication of the websocket server, the node names claimed by LCs are trusted")
+	} else if options.TLSConfig == nil {
+		klog.Warningf("the tokens of LCs are sent in plain text since TLS of the websocket server is disabled")
+	}
+
+	return options, nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
+	"github.com/kubeedge/sedna/pkg/client/clientset/versioned/fake"
+	sednainformers "github.com/kubeedge/sedna/pkg/client/informers/externalversions"
+)
+
+func TestResourceLister(t *testing.T) {
+	client := fake.NewSimpleClientset(&sednav1.IncrementalLearningJob{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "job"},
+	})
+	factory := sednainformers.NewSharedInformerFactory(client, 0)
+	lister, err := newResourceLister(factory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	for _, c := range []struct {
+		kind   string
+		name   string
+		exists bool
+	}{
+		{"incrementallearningjob", "job", true},
+		{"IncrementalLearningJob", "job", true},
+		{"incrementallearningjob", "other", false},
+		{"dataset", "job", false},
+	} {
+		exists, err := lister(c.kind, "default", c.name)
+		if err != nil || exists != c.exists {
+			t.Errorf("%s %s: expected exists %v, got %v: %v", c.kind, c.name, c.exists, exists, err)
+		}
+	}
+	if _, err := lister("unknown", "default", "job"); err == nil {
+		t.Error("expected the error of the unknown kind")
+	}
+}
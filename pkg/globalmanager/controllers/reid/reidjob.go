@@ -512,9 +512,11 @@ func New(cc *runtime.ControllerContext) (runtime.FeatureControllerI, error) {
 		UpdateFunc: func(old, cur interface{}) {
 			rc.enqueueController(cur, true)
 
-			// when a reid job is updated,
-			// send it to edge's LC as Added event.
-			rc.syncToEdge(watch.Added, cur)
+			// when the spec of a reid job is updated,
+			// send it to edge's LC as Modified event.
+			if runtime.GenerationChanged(old, cur) {
+				rc.syncToEdge(watch.Modified, cur)
+			}
 		},
 		DeleteFunc: func(obj interface{}) {
 			rc.enqueueController(obj, true)
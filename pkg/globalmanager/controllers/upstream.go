@@ -23,6 +23,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
 	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
 )
 
@@ -30,11 +31,12 @@ import (
 type UpstreamController struct {
 	messageLayer   messagelayer.MessageLayer
 	updateHandlers map[string]runtime.UpstreamHandler
+	authorizers    map[string]runtime.UpstreamAuthorizer
 }
 
 func (uc *UpstreamController) checkOperation(operation string) error {
 	// current only support the 'status' operation
-	if operation != "status" {
+	if operation != model.StatusOperation {
 		return fmt.Errorf("unknown operation '%s'", operation)
 	}
 	return nil
@@ -51,6 +53,13 @@ func (uc *UpstreamController) syncEdgeUpdate() {
 		}
 
 		update, err := uc.messageLayer.ReceiveResourceUpdate()
+		if err == nil && update.Operation == model.SyncOperation {
+			// the node is (re)connected
+			if err := uc.messageLayer.ResyncNode(update.NodeName, update.Content); err != nil {
+				klog.Errorf("Error to resync node %s: %+v", update.NodeName, err)
+			}
+			continue
+		}
 		if err == nil {
 			err = uc.checkOperation(update.Operation)
 		}
@@ -65,17 +74,45 @@ func (uc *UpstreamController) syncEdgeUpdate() {
 		operation := update.Operation
 
 		handler, ok := uc.updateHandlers[kind]
-		if ok {
-			err := handler(name, namespace, operation, update.Content)
-			if err != nil {
-				klog.Errorf("Error to handle %s %s/%s operation(%s): %+v", kind, namespace, name, operation, err)
-			}
-		} else {
+		if !ok {
 			klog.Warningf("No handler for resource kind %s", kind)
+			continue
+		}
+
+		if err := uc.authorize(update); err != nil {
+			uc.reject(update, err)
+			continue
+		}
+
+		err = handler(name, namespace, operation, update.Content)
+		if err != nil {
+			klog.Errorf("Error to handle %s %s/%s operation(%s): %+v", kind, namespace, name, operation, err)
 		}
 	}
 }
 
+// authorize checks the node sending the update is bound to the resource.
+// The updates of the kinds without authorizer are not checked.
+func (uc *UpstreamController) authorize(update *messagelayer.ResourceUpdateSpec) error {
+	authorizer, ok := uc.authorizers[update.Kind]
+	if !ok {
+		return nil
+	}
+
+	return authorizer(update.NodeName, update.Namespace, update.Name)
+}
+
+// reject tells the node why the update is rejected
+func (uc *UpstreamController) reject(update *messagelayer.ResourceUpdateSpec, reason error) {
+	klog.Warningf("Rejected %s %s/%s operation(%s) from node %s: %v",
+		update.Kind, update.Namespace, update.Name, update.Operation, update.NodeName, reason)
+
+	if err := uc.messageLayer.RejectResourceUpdate(update, reason.Error()); err != nil {
+		klog.Errorf("Failed to send the rejection of %s %s/%s to node %s: %v",
+			update.Kind, update.Namespace, update.Name, update.NodeName, err)
+	}
+}
+
 // Run starts the upstream controller
 func (uc *UpstreamController) Run(stopCh <-chan struct{}) {
 	klog.Info("Start the sedna upstream controller")
@@ -94,11 +131,23 @@ func (uc *UpstreamController) Add(kind string, handler runtime.UpstreamHandler)
 	return nil
 }
 
+// AddAuthorizer adds the authorizer of the upstream updates of the kind
+func (uc *UpstreamController) AddAuthorizer(kind string, authorizer runtime.UpstreamAuthorizer) error {
+	kind = strings.ToLower(kind)
+	if _, ok := uc.authorizers[kind]; ok {
+		return fmt.Errorf("a upstream authorizer for kind %s already exists", kind)
+	}
+	uc.authorizers[kind] = authorizer
+
+	return nil
+}
+
 // NewUpstreamController creates a new Upstream controller from config
 func NewUpstreamController(cc *runtime.ControllerContext) (*UpstreamController, error) {
 	uc := &UpstreamController{
 		messageLayer:   messagelayer.NewContextMessageLayer(),
 		updateHandlers: make(map[string]runtime.UpstreamHandler),
+		authorizers:    make(map[string]runtime.UpstreamAuthorizer),
 	}
 
 	return uc, nil
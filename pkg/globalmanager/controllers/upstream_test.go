@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer"
+	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
+)
+
+// fakeMessageLayer returns the updates in order, and is done when all are received
+type fakeMessageLayer struct {
+	updates  []*messagelayer.ResourceUpdateSpec
+	rejected []string
+	done     chan struct{}
+}
+
+func (l *fakeMessageLayer) SendResourceObject(nodeName string, eventType watch.EventType, obj interface{}) error {
+	return nil
+}
+
+func (l *fakeMessageLayer) ReceiveResourceUpdate() (*messagelayer.ResourceUpdateSpec, error) {
+	update := l.updates[0]
+	l.updates = l.updates[1:]
+	if len(l.updates) == 0 {
+		close(l.done)
+	}
+	return update, nil
+}
+
+func (l *fakeMessageLayer) RejectResourceUpdate(update *messagelayer.ResourceUpdateSpec, reason string) error {
+	l.rejected = append(l.rejected, update.NodeName+":"+update.Kind+"/"+update.Name)
+	return nil
+}
+
+func (l *fakeMessageLayer) ResyncNode(nodeName string, inventory []byte) error {
+	return nil
+}
+
+func (l *fakeMessageLayer) Done() <-chan struct{} {
+	return l.done
+}
+
+func TestUpstreamAuthorization(t *testing.T) {
+	update := func(nodeName, kind, name string) *messagelayer.ResourceUpdateSpec {
+		return &messagelayer.ResourceUpdateSpec{
+			NodeName:  nodeName,
+			Kind:      kind,
+			Namespace: "default",
+			Name:      name,
+			Operation: "status",
+		}
+	}
+
+	layer := &fakeMessageLayer{
+		updates: []*messagelayer.ResourceUpdateSpec{
+			update("edge1", "dataset", "ds1"),
+			update("edge2", "dataset", "ds1"),
+			update("edge2", "dataset", "unknown"),
+			update("edge1", "reid", "job1"),
+		},
+		done: make(chan struct{}),
+	}
+
+	uc := &UpstreamController{
+		messageLayer:   layer,
+		updateHandlers: make(map[string]runtime.UpstreamHandler),
+		authorizers:    make(map[string]runtime.UpstreamAuthorizer),
+	}
+
+	var handled []string
+	handler := func(kind string) runtime.UpstreamHandler {
+		return func(name, namespace, operation string, content []byte) error {
+			handled = append(handled, kind+"/"+name)
+			return nil
+		}
+	}
+	_ = uc.Add("Dataset", handler("dataset"))
+	_ = uc.Add("Reid", handler("reid"))
+
+	// ds1 is bound to edge1
+	_ = uc.AddAuthorizer("Dataset", func(nodeName, namespace, name string) error {
+		if name != "ds1" {
+			return fmt.Errorf("dataset %s not found", name)
+		}
+		return runtime.CheckBoundNode(nodeName, "edge1")
+	})
+
+	uc.syncEdgeUpdate()
+
+	// reid has no authorizer, so its updates are not checked
+	if fmt.Sprint(handled) != "[dataset/ds1 reid/job1]" {
+		t.Errorf("expected only the updates of the bound node and of reid handled, got %v", handled)
+	}
+
+	if want := "[edge2:dataset/ds1 edge2:dataset/unknown]"; fmt.Sprint(layer.rejected) != want {
+		t.Errorf("expected rejections %s, got %v", want, layer.rejected)
+	}
+}
@@ -528,9 +528,11 @@ func New(cc *runtime.ControllerContext) (runtime.FeatureControllerI, error) {
 		UpdateFunc: func(old, cur interface{}) {
 			fc.enqueueController(cur, true)
 
-			// when a video analytics job is updated,
-			// send it to edge's LC as Added event.
-			fc.syncToEdge(watch.Added, cur)
+			// when the spec of a video analytics job is updated,
+			// send it to edge's LC as Modified event.
+			if runtime.GenerationChanged(old, cur) {
+				fc.syncToEdge(watch.Modified, cur)
+			}
 		},
 		DeleteFunc: func(obj interface{}) {
 			fc.enqueueController(obj, true)
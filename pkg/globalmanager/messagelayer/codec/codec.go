@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package codec encodes the messages between GM and LC. A message is encoded in JSON by default,
+// or in the protobuf wire format if both sides have the protobuf capability, and its large content
+// is compressed if both sides have the gzip capability. The encoding is told by the first byte,
+// so the messages in either encoding are decoded.
+package codec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+)
+
+const (
+	// CompressionThreshold is the min size of the content to compress
+	CompressionThreshold = 1024
+	// MaxContentSize is the max size of the decompressed content
+	MaxContentSize = 64 << 20
+
+	// GzipCompression is the compression of the content by gzip
+	GzipCompression = "gzip"
+)
+
+// Options defines how the messages are encoded
+type Options struct {
+	// Protobuf encodes the messages in the protobuf wire format
+	Protobuf bool
+	// Gzip compresses the contents not less than CompressionThreshold
+	Gzip bool
+}
+
+// OptionsOf returns the options allowed by the negotiated capabilities
+func OptionsOf(capabilities []string) Options {
+	var options Options
+	for _, c := range capabilities {
+		switch c {
+		case model.CapabilityProtobuf:
+			options.Protobuf = true
+		case model.CapabilityGzip:
+			options.Gzip = true
+		}
+	}
+	return options
+}
+
+// Marshal encodes the message by the options
+func Marshal(msg *model.Message, options Options) ([]byte, error) {
+	if options.Gzip && msg.Compression == "" && len(msg.Content) >= CompressionThreshold {
+		compressed, err := compress(msg.Content)
+		if err != nil {
+			return nil, err
+		}
+		if len(compressed) < len(msg.Content) {
+			m := *msg
+			m.Content = compressed
+			m.Compression = GzipCompression
+			msg = &m
+		}
+	}
+
+	if options.Protobuf {
+		return marshalProto(msg), nil
+	}
+	return json.Marshal(msg)
+}
+
+// Unmarshal decodes the message in either encoding, and decompresses its content
+func Unmarshal(data []byte, msg *model.Message) error {
+	var err error
+	if IsJSON(data) {
+		err = json.Unmarshal(data, msg)
+	} else {
+		err = unmarshalProto(data, msg)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch msg.Compression {
+	case "":
+	case GzipCompression:
+		content, err := decompress(msg.Content)
+		if err != nil {
+			return fmt.Errorf("failed to decompress the content: %w", err)
+		}
+		msg.Content = content
+		msg.Compression = ""
+	default:
+		return fmt.Errorf("unknown compression %s", msg.Compression)
+	}
+	return nil
+}
+
+// IsJSON checks whether the encoded message is in JSON, which is an object.
+// The first byte of the protobuf message is a tag or it's empty.
+func IsJSON(data []byte) bool {
+	return len(data) > 0 && data[0] == '{'
+}
+
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(r, MaxContentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > MaxContentSize {
+		return nil, fmt.Errorf("content exceeds the max size %d", MaxContentSize)
+	}
+	return content, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package codec
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+)
+
+func newMessage(content []byte) *model.Message {
+	return &model.Message{
+		MessageHeader: model.MessageHeader{
+			Namespace:       "default",
+			ResourceKind:    "dataset",
+			ResourceName:    "ds",
+			Operation:       "status",
+			MessageID:       42,
+			ProtocolVersion: model.ProtocolVersion,
+			Capabilities:    model.Capabilities(),
+		},
+		Content: content,
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	large := []byte(`{"samples":"` + string(bytes.Repeat([]byte("a"), 4*CompressionThreshold)) + `"}`)
+
+	for _, tc := range []struct {
+		name       string
+		options    Options
+		content    []byte
+		json       bool
+		compressed bool
+	}{
+		{name: "json", content: large, json: true},
+		{name: "gzip", options: Options{Gzip: true}, content: large, json: true, compressed: true},
+		{name: "small content", options: Options{Gzip: true}, content: []byte(`{}`), json: true},
+		{name: "protobuf", options: Options{Protobuf: true}, content: large},
+		{name: "protobuf gzip", options: Options{Protobuf: true, Gzip: true}, content: large, compressed: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := newMessage(tc.content)
+			data, err := Marshal(msg, tc.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if IsJSON(data) != tc.json {
+				t.Errorf("expected json %v, got %q", tc.json, data[:1])
+			}
+			if compressed := len(data) < len(tc.content); compressed != tc.compressed {
+				t.Errorf("expected compressed %v, got %d bytes of content %d bytes", tc.compressed, len(data), len(tc.content))
+			}
+
+			var got model.Message
+			if err := Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(&got, msg) {
+				t.Errorf("expected %+v, got %+v", msg.MessageHeader, got.MessageHeader)
+			}
+		})
+	}
+}
+
+func TestUnmarshalUnknownFields(t *testing.T) {
+	msg := newMessage([]byte(`{}`))
+	data := marshalProto(msg)
+	// the fields of a later version
+	data = protowire.AppendTag(data, 100, protowire.BytesType)
+	data = protowire.AppendString(data, "unknown")
+	data = protowire.AppendTag(data, 101, protowire.VarintType)
+	data = protowire.AppendVarint(data, 1)
+
+	var got model.Message
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, msg) {
+		t.Errorf("expected %+v, got %+v", msg, &got)
+	}
+}
+
+func TestUnmarshalMalformed(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"truncated protobuf":  marshalProto(newMessage([]byte(`{}`)))[:10],
+		"unknown compression": []byte(`{"header":{"compression":"zstd"},"content":"e30="}`),
+		"corrupted gzip":      []byte(`{"header":{"compression":"gzip"},"content":"e30="}`),
+	} {
+		var msg model.Message
+		if err := Unmarshal(data, &msg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
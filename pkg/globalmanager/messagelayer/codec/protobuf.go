@@ -0,0 +1,162 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package codec
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+)
+
+// The field numbers of the message in the protobuf wire format:
+//
+//	message Message {
+//	  string namespace = 1;
+//	  string resource_kind = 2;
+//	  string resource_name = 3;
+//	  string operation = 4;
+//	  uint64 message_id = 5;
+//	  int64 protocol_version = 6;
+//	  repeated string capabilities = 7;
+//	  string compression = 8;
+//	  bytes content = 9;
+//	  bytes patch = 10;
+//	}
+const (
+	fieldNamespace       protowire.Number = 1
+	fieldResourceKind    protowire.Number = 2
+	fieldResourceName    protowire.Number = 3
+	fieldOperation       protowire.Number = 4
+	fieldMessageID       protowire.Number = 5
+	fieldProtocolVersion protowire.Number = 6
+	fieldCapabilities    protowire.Number = 7
+	fieldCompression     protowire.Number = 8
+	fieldContent         protowire.Number = 9
+	fieldPatch           protowire.Number = 10
+)
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func marshalProto(msg *model.Message) []byte {
+	b := make([]byte, 0, len(msg.Content)+len(msg.Patch)+128)
+	b = appendString(b, fieldNamespace, msg.Namespace)
+	b = appendString(b, fieldResourceKind, msg.ResourceKind)
+	b = appendString(b, fieldResourceName, msg.ResourceName)
+	b = appendString(b, fieldOperation, msg.Operation)
+	b = appendVarint(b, fieldMessageID, msg.MessageID)
+	b = appendVarint(b, fieldProtocolVersion, uint64(msg.ProtocolVersion))
+	for _, c := range msg.Capabilities {
+		b = protowire.AppendTag(b, fieldCapabilities, protowire.BytesType)
+		b = protowire.AppendString(b, c)
+	}
+	b = appendString(b, fieldCompression, msg.Compression)
+	b = appendBytes(b, fieldContent, msg.Content)
+	b = appendBytes(b, fieldPatch, msg.Patch)
+	return b
+}
+
+func unmarshalProto(b []byte, msg *model.Message) error {
+	*msg = model.Message{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		switch {
+		case typ == protowire.VarintType && (num == fieldMessageID || num == fieldProtocolVersion):
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+			if num == fieldMessageID {
+				msg.MessageID = v
+			} else {
+				msg.ProtocolVersion = int(v)
+			}
+
+		case typ == protowire.BytesType && num >= fieldNamespace && num <= fieldPatch:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+			if err := setBytesField(msg, num, v); err != nil {
+				return err
+			}
+
+		default:
+			// the unknown fields of the later versions are skipped
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+		}
+	}
+	return nil
+}
+
+func setBytesField(msg *model.Message, num protowire.Number, v []byte) error {
+	switch num {
+	case fieldNamespace:
+		msg.Namespace = string(v)
+	case fieldResourceKind:
+		msg.ResourceKind = string(v)
+	case fieldResourceName:
+		msg.ResourceName = string(v)
+	case fieldOperation:
+		msg.Operation = string(v)
+	case fieldCapabilities:
+		msg.Capabilities = append(msg.Capabilities, string(v))
+	case fieldCompression:
+		msg.Compression = string(v)
+	case fieldContent:
+		msg.Content = append([]byte(nil), v...)
+	case fieldPatch:
+		msg.Patch = append([]byte(nil), v...)
+	default:
+		return fmt.Errorf("unexpected bytes field %d", num)
+	}
+	return nil
+}
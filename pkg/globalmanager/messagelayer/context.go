@@ -33,15 +33,33 @@ import (
 type MessageLayer interface {
 	SendResourceObject(nodeName string, eventType watch.EventType, obj interface{}) error
 	ReceiveResourceUpdate() (*ResourceUpdateSpec, error)
+	RejectResourceUpdate(update *ResourceUpdateSpec, reason string) error
+	ResyncNode(nodeName string, inventory []byte) error
 	Done() <-chan struct{}
 }
 
+// NodeStatus is the connection status of the LC of a node
+type NodeStatus = wsContext.NodeStatus
+
+// NodeStatusLayer tells the connection status of the nodes
+type NodeStatusLayer interface {
+	// NodeEvents returns the channel of the names of the nodes whose connection changed
+	NodeEvents() <-chan string
+	GetNodeStatus(nodeName string) (NodeStatus, bool)
+	ListNodeStatus() []NodeStatus
+	// ForgetNode forgets the status of the node if it's not connected, true if it's forgotten
+	ForgetNode(nodeName string) bool
+}
+
 // ContextMessageLayer build on context
 type ContextMessageLayer struct {
 }
 
 // ResourceUpdateSpec describes the resource update from upstream
 type ResourceUpdateSpec struct {
+	// NodeName is the node sending the update
+	NodeName  string
+	MessageID uint64
 	Kind      string
 	Namespace string
 	Name      string
@@ -54,11 +72,11 @@ func (cml *ContextMessageLayer) SendResourceObject(nodeName string, eventType wa
 	var operation string
 	switch eventType {
 	case watch.Added:
-		operation = "insert"
+		operation = model.InsertOperation
 	case watch.Modified:
-		operation = "update"
+		operation = model.UpdateOperation
 	case watch.Deleted:
-		operation = "delete"
+		operation = model.DeleteOperation
 	default:
 		// should never get here
 		return fmt.Errorf("event type: %s unsupported", eventType)
@@ -107,6 +125,8 @@ func (cml *ContextMessageLayer) ReceiveResourceUpdate() (*ResourceUpdateSpec, er
 	content := msg.Content
 
 	return &ResourceUpdateSpec{
+		NodeName:  nodeName,
+		MessageID: msg.MessageID,
 		Kind:      kind,
 		Namespace: namespace,
 		Name:      name,
@@ -115,11 +135,59 @@ func (cml *ContextMessageLayer) ReceiveResourceUpdate() (*ResourceUpdateSpec, er
 	}, nil
 }
 
+// RejectResourceUpdate tells the node the reason why its update is rejected
+func (cml *ContextMessageLayer) RejectResourceUpdate(update *ResourceUpdateSpec, reason string) error {
+	payload, _ := json.Marshal(&model.Rejection{
+		MessageID: update.MessageID,
+		Operation: update.Operation,
+		Reason:    reason,
+	})
+
+	var msg model.Message
+	msg.Content = payload
+	msg.Namespace = update.Namespace
+	msg.ResourceKind = update.Kind
+	msg.ResourceName = update.Name
+	msg.Operation = model.RejectOperation
+
+	return wsContext.SendToEdge(update.NodeName, &msg)
+}
+
+// ResyncNode sends the differences between the inventory of the node and the resources synced to it
+func (cml *ContextMessageLayer) ResyncNode(nodeName string, inventory []byte) error {
+	var inv model.Inventory
+	if err := json.Unmarshal(inventory, &inv); err != nil {
+		return fmt.Errorf("invalid inventory of node %s: %w", nodeName, err)
+	}
+
+	return wsContext.ResyncNode(nodeName, &inv)
+}
+
 // Done signals the message layer is done
 func (cml *ContextMessageLayer) Done() <-chan struct{} {
 	return wsContext.Done()
 }
 
+// NodeEvents returns the channel of the names of the nodes whose connection changed
+func (cml *ContextMessageLayer) NodeEvents() <-chan string {
+	return wsContext.NodeEvents()
+}
+
+// GetNodeStatus returns the connection status of the node, false if the node never connects
+func (cml *ContextMessageLayer) GetNodeStatus(nodeName string) (NodeStatus, bool) {
+	return wsContext.GetNodeStatus(nodeName)
+}
+
+// ListNodeStatus returns the connection status of the nodes ever connected
+func (cml *ContextMessageLayer) ListNodeStatus() []NodeStatus {
+	return wsContext.ListNodeStatus()
+}
+
+// ForgetNode forgets the connection status of the node if it's not connected
+func (cml *ContextMessageLayer) ForgetNode(nodeName string) bool {
+	return wsContext.ForgetNode(nodeName)
+}
+
 // NewContextMessageLayer create a ContextMessageLayer
 func NewContextMessageLayer() MessageLayer {
 	return &ContextMessageLayer{}
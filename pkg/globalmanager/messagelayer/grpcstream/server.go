@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcstream
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/ws"
+)
+
+// Server serves the gRPC streams of LCs, with the same security options as the websocket server
+type Server struct {
+	UnimplementedMessageLayerServer
+
+	address        string
+	server         *grpc.Server
+	authenticators []ws.Authenticator
+}
+
+// NewServer creates a gRPC stream server
+func NewServer(address string, options ws.ServerOptions) *Server {
+	srv := &Server{
+		address:        address,
+		authenticators: options.Authenticators,
+	}
+
+	serverOptions := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(MaxMessageSize),
+		grpc.MaxSendMsgSize(MaxMessageSize),
+		// the half-open connection is closed when the ping isn't answered
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    model.PingPeriod,
+			Timeout: model.PongWait - model.PingPeriod,
+		}),
+		// LCs ping in the same period
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             model.PingPeriod / 2,
+			PermitWithoutStream: true,
+		}),
+	}
+	if options.TLSConfig != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(options.TLSConfig)))
+	}
+
+	srv.server = grpc.NewServer(serverOptions...)
+	RegisterMessageLayerServer(srv.server, srv)
+	return srv
+}
+
+// Stream serves the stream of a node until it's closed by the message layer or LC
+func (srv *Server) Stream(stream MessageLayer_StreamServer) error {
+	req := requestOfStream(stream.Context())
+	nodeName := req.Header.Get(NodeNameKey)
+	if code, err := ws.Authenticate(srv.authenticators, req); err != nil {
+		klog.Warningf("rejected the stream of node %s from %s: %v", nodeName, req.RemoteAddr, err)
+		return status.Error(codeFromHTTP(code), err.Error())
+	}
+	// LC waits for the node name in the headers to know the stream is accepted
+	if err := stream.SendHeader(metadata.Pairs(NodeNameKey, nodeName)); err != nil {
+		return err
+	}
+
+	klog.Infof("established stream for node %s", nodeName)
+
+	s := &nodeStream{stream: stream}
+	closeCh := make(chan struct{}, 2)
+	ws.AddNode(nodeName, s.read, s.write, closeCh)
+
+	select {
+	case <-closeCh:
+	case <-stream.Context().Done():
+	}
+	s.close()
+
+	klog.Infof("closed stream for node %s", nodeName)
+	return status.Error(codes.Unavailable, "stream closed")
+}
+
+// ListenAndServe listens and serves the server
+func (srv *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", srv.address)
+	if err != nil {
+		return err
+	}
+	return srv.Serve(listener)
+}
+
+// Serve serves the streams of the listener
+func (srv *Server) Serve(listener net.Listener) error {
+	return srv.server.Serve(listener)
+}
+
+// Close closes the server and the streams
+func (srv *Server) Close() error {
+	srv.server.Stop()
+	return nil
+}
+
+// nodeStream is the stream of a node, which is written until the handler returns
+type nodeStream struct {
+	stream MessageLayer_StreamServer
+
+	lock   sync.Mutex
+	closed bool
+}
+
+func (s *nodeStream) read() ([]byte, error) {
+	frame, err := s.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return frame.Data, nil
+}
+
+func (s *nodeStream) write(data []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("stream closed")
+	}
+	return s.stream.Send(&Frame{Data: data})
+}
+
+// close stops the writes, the stream ends when the handler returns
+func (s *nodeStream) close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.closed = true
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package grpcstream implements the bidirectional gRPC stream between GM and LCs.
+// Each frame of the stream carries a message encoded by the codec of the message layer
+// like the websocket ones, so the same encodings and compression are negotiated.
+package grpcstream
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative stream.proto
+
+const (
+	// MaxMessageSize is the max size of a message in the stream
+	MaxMessageSize = 16 << 20
+
+	// NodeNameKey is the metadata of the node name of the stream
+	NodeNameKey = "node-name"
+	// AuthorizationKey is the metadata of the bearer token of the node
+	AuthorizationKey = "authorization"
+)
+
+// requestOfStream returns the request of the stream with the metadata as the headers,
+// so the stream is authenticated by the same authenticators as the websocket connections
+func requestOfStream(ctx context.Context) *http.Request {
+	req := &http.Request{Header: make(http.Header)}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, values := range md {
+			for _, v := range values {
+				req.Header.Add(key, v)
+			}
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		req.RemoteAddr = p.Addr.String()
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			state := info.State
+			req.TLS = &state
+		}
+	}
+	return req
+}
+
+// codeFromHTTP converts the http status code of the authentication to the gRPC status code
+func codeFromHTTP(code int) codes.Code {
+	switch code {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	default:
+		return codes.Unavailable
+	}
+}
@@ -0,0 +1,137 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package grpcstream
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// MessageLayerClient is the client API for MessageLayer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MessageLayerClient interface {
+	// Stream is the bidirectional stream of a node, the upstream messages are sent by LC
+	// and the downstream messages are sent by GM.
+	Stream(ctx context.Context, opts ...grpc.CallOption) (MessageLayer_StreamClient, error)
+}
+
+type messageLayerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMessageLayerClient(cc grpc.ClientConnInterface) MessageLayerClient {
+	return &messageLayerClient{cc}
+}
+
+func (c *messageLayerClient) Stream(ctx context.Context, opts ...grpc.CallOption) (MessageLayer_StreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MessageLayer_ServiceDesc.Streams[0], "/sedna.MessageLayer/Stream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &messageLayerStreamClient{stream}
+	return x, nil
+}
+
+type MessageLayer_StreamClient interface {
+	Send(*Frame) error
+	Recv() (*Frame, error)
+	grpc.ClientStream
+}
+
+type messageLayerStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *messageLayerStreamClient) Send(m *Frame) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *messageLayerStreamClient) Recv() (*Frame, error) {
+	m := new(Frame)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MessageLayerServer is the server API for MessageLayer service.
+// All implementations must embed UnimplementedMessageLayerServer
+// for forward compatibility
+type MessageLayerServer interface {
+	// Stream is the bidirectional stream of a node, the upstream messages are sent by LC
+	// and the downstream messages are sent by GM.
+	Stream(MessageLayer_StreamServer) error
+	mustEmbedUnimplementedMessageLayerServer()
+}
+
+// UnimplementedMessageLayerServer must be embedded to have forward compatible implementations.
+type UnimplementedMessageLayerServer struct {
+}
+
+func (UnimplementedMessageLayerServer) Stream(MessageLayer_StreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method Stream not implemented")
+}
+func (UnimplementedMessageLayerServer) mustEmbedUnimplementedMessageLayerServer() {}
+
+// UnsafeMessageLayerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MessageLayerServer will
+// result in compilation errors.
+type UnsafeMessageLayerServer interface {
+	mustEmbedUnimplementedMessageLayerServer()
+}
+
+func RegisterMessageLayerServer(s grpc.ServiceRegistrar, srv MessageLayerServer) {
+	s.RegisterService(&MessageLayer_ServiceDesc, srv)
+}
+
+func _MessageLayer_Stream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MessageLayerServer).Stream(&messageLayerStreamServer{stream})
+}
+
+type MessageLayer_StreamServer interface {
+	Send(*Frame) error
+	Recv() (*Frame, error)
+	grpc.ServerStream
+}
+
+type messageLayerStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *messageLayerStreamServer) Send(m *Frame) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *messageLayerStreamServer) Recv() (*Frame, error) {
+	m := new(Frame)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MessageLayer_ServiceDesc is the grpc.ServiceDesc for MessageLayer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MessageLayer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sedna.MessageLayer",
+	HandlerType: (*MessageLayerServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			Handler:       _MessageLayer_Stream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "stream.proto",
+}
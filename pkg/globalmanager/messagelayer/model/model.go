@@ -16,7 +16,124 @@ limitations under the License.
 
 package model
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// InsertOperation is the operation of the message creating the resource on the edge
+	InsertOperation = "insert"
+	// UpdateOperation is the operation of the message updating the resource on the edge,
+	// which is handled by the LCs with CapabilityPatch only
+	UpdateOperation = "update"
+	// DeleteOperation is the operation of the message deleting the resource on the edge
+	DeleteOperation = "delete"
+	// AckOperation is the operation of the message acknowledging the message received
+	AckOperation = "ack"
+	// RejectOperation is the operation of the message telling the edge its upstream update is rejected
+	RejectOperation = "reject"
+	// SyncOperation is the operation of the message sent by the edge when connected,
+	// whose content is the inventory of the edge
+	SyncOperation = "sync"
+	// StatusOperation is the operation of the message reporting the status of the resource from the edge
+	StatusOperation = "status"
+)
+
+// The transports of the messages between GM and LCs
+const (
+	// WebSocketTransport is the websocket connection, the default transport
+	WebSocketTransport = "websocket"
+	// LongPollTransport is the long-poll fallback of the websocket server for LCs behind the proxies,
+	// which is served along with the websocket transport
+	LongPollTransport = "longpoll"
+	// GRPCTransport is the bidirectional gRPC stream
+	GRPCTransport = "grpc"
+	// MQTTTransport is the topics of the node on a MQTT broker
+	MQTTTransport = "mqtt"
+)
+
+// ProtocolVersion is the version of the protocol between GM and LC, which is told with the
+// capabilities in the sync message when LC connects, and in the reply of GM.
+// The legacy LCs not sending the sync message speak LegacyProtocolVersion without any capability.
+const (
+	ProtocolVersion       = 2
+	LegacyProtocolVersion = 1
+)
+
+// The capabilities of the protocol, the features are used only if both GM and LC have them
+const (
+	// CapabilityAck is that the upstream messages are kept by LC until acknowledged
+	CapabilityAck = "ack"
+	// CapabilityReject is that the rejections of the upstream updates are handled by LC
+	CapabilityReject = "reject"
+	// CapabilityPatch is that the update messages carry the spec patch
+	CapabilityPatch = "patch"
+	// CapabilityGzip is that the large contents are compressed by gzip
+	CapabilityGzip = "gzip"
+	// CapabilityProtobuf is that the messages are encoded in the protobuf wire format instead of JSON
+	CapabilityProtobuf = "protobuf"
+)
+
+// Capabilities returns the capabilities of this version
+func Capabilities() []string {
+	return []string{CapabilityAck, CapabilityReject, CapabilityPatch, CapabilityGzip, CapabilityProtobuf}
+}
+
+// NegotiateCapabilities returns the capabilities of both sides, in the order of the local ones
+func NegotiateCapabilities(local, remote []string) []string {
+	negotiated := []string{}
+	for _, l := range local {
+		for _, r := range remote {
+			if l == r {
+				negotiated = append(negotiated, l)
+				break
+			}
+		}
+	}
+	return negotiated
+}
+
+// The heartbeat of the websocket connection between GM and LC
+const (
+	// PingPeriod is the period GM pings LC
+	PingPeriod = 30 * time.Second
+	// PongWait is how long the connection is kept alive without any message,
+	// after which the link is regarded as half-open and closed
+	PongWait = 2 * PingPeriod
+)
+
+// ResourceRef refers to a resource synced to the edge
+type ResourceRef struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// ResourceVersion is empty if the edge needs the resource to be sent again
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// Inventory is the resources the edge has
+type Inventory struct {
+	Resources []ResourceRef `json:"resources"`
+	// LCVersion is the version of LC
+	LCVersion string `json:"lcVersion,omitempty"`
+}
+
+// SyncReply is the content of the reply of the sync message
+type SyncReply struct {
+	// WorkerTokenKey is the ed25519 public key verifying the tokens of the workers,
+	// empty if GM doesn't issue the tokens
+	WorkerTokenKey []byte `json:"workerTokenKey,omitempty"`
+}
+
+// Rejection is the content of the message of the reject operation
+type Rejection struct {
+	// MessageID is the id of the rejected upstream message
+	MessageID uint64 `json:"messageID,omitempty"`
+	// Operation is the operation of the rejected upstream message
+	Operation string `json:"operation"`
+	Reason    string `json:"reason"`
+}
 
 // MessageHeader defines the header between LC and GM
 type MessageHeader struct {
@@ -27,12 +144,26 @@ type MessageHeader struct {
 	ResourceName string `json:"resourceName"`
 
 	Operation string `json:"operation"`
+
+	// MessageID is the id of the upstream message, which is acknowledged by GM when received.
+	// It's the id of the acknowledged message for the ack operation.
+	MessageID uint64 `json:"messageID,omitempty"`
+
+	// ProtocolVersion and Capabilities are of the sender of the sync message
+	ProtocolVersion int      `json:"protocolVersion,omitempty"`
+	Capabilities    []string `json:"capabilities,omitempty"`
+
+	// Compression is the compression of the content, e.g. gzip
+	Compression string `json:"compression,omitempty"`
 }
 
 // Message defines the message between LC and GM
 type Message struct {
 	MessageHeader `json:"header"`
 	Content       []byte `json:"content"`
+	// Patch is the JSON merge patch of the spec for the update operation,
+	// relative to the resource last sent to the edge
+	Patch []byte `json:"patch,omitempty"`
 }
 
 func (m *Message) String() string {
@@ -0,0 +1,335 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mqtt
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Broker is a minimal MQTT 3.1.1 broker for the tests.
+// It supports clean sessions with QoS 0 and 1, without retained messages and persistence.
+type Broker struct {
+	// Authenticate authenticates the client by the username and password if set
+	Authenticate func(clientID, username, password string) bool
+	// Authorize authorizes the user to publish to or subscribe the topic if set,
+	// the unauthorized messages are dropped like mosquitto does
+	Authorize func(username, topic string, write bool) bool
+
+	lock      sync.Mutex
+	listener  net.Listener
+	sessions  map[string]*brokerSession
+	closed    bool
+	closeOnce sync.Once
+}
+
+// brokerSession is the connection of a client
+type brokerSession struct {
+	clientID string
+	username string
+	conn     net.Conn
+
+	writeLock sync.Mutex
+
+	lock    sync.Mutex
+	filters map[string]byte
+	nextID  uint16
+}
+
+// NewBroker creates an embedded broker
+func NewBroker() *Broker {
+	return &Broker{sessions: make(map[string]*brokerSession)}
+}
+
+// Serve serves the clients from the listener
+func (b *Broker) Serve(listener net.Listener) error {
+	b.lock.Lock()
+	if b.closed {
+		b.lock.Unlock()
+		_ = listener.Close()
+		return fmt.Errorf("broker closed")
+	}
+	b.listener = listener
+	b.lock.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go b.serveConn(conn)
+	}
+}
+
+// Close stops the broker and disconnects all clients
+func (b *Broker) Close() error {
+	b.closeOnce.Do(func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+
+		b.closed = true
+		if b.listener != nil {
+			_ = b.listener.Close()
+		}
+		for _, s := range b.sessions {
+			_ = s.conn.Close()
+		}
+	})
+	return nil
+}
+
+// Subscribed checks whether the client is connected and has subscribed any topic
+func (b *Broker) Subscribed(clientID string) bool {
+	b.lock.Lock()
+	s, ok := b.sessions[clientID]
+	b.lock.Unlock()
+	if !ok {
+		return false
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.filters) > 0
+}
+
+func (b *Broker) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	_ = conn.SetReadDeadline(time.Now().Add(ConnectTimeout))
+	pkt, err := readPacket(reader)
+	if err != nil || pkt.typ != packetConnect {
+		return
+	}
+
+	s, keepAlive, err := b.connect(conn, pkt)
+	if err != nil {
+		klog.V(4).Infof("refused mqtt client from %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+	defer b.disconnect(s)
+
+	for {
+		if keepAlive > 0 {
+			_ = conn.SetReadDeadline(time.Now().Add(keepAlive * 3 / 2))
+		} else {
+			_ = conn.SetReadDeadline(time.Time{})
+		}
+
+		pkt, err := readPacket(reader)
+		if err != nil {
+			return
+		}
+
+		switch pkt.typ {
+		case packetPublish:
+			p, err := parsePublish(pkt)
+			if err != nil {
+				return
+			}
+			if b.Authorize == nil || b.Authorize(s.username, p.topic, true) {
+				b.route(p.topic, p.payload)
+			}
+			if p.qos == 1 {
+				err = s.write(packetPuback, 0, appendUint16(nil, p.packetID))
+			}
+
+		case packetSubscribe:
+			err = s.subscribe(pkt, b.Authorize)
+
+		case packetPuback:
+			// the clients are trusted to receive, nothing is redelivered
+
+		case packetPingreq:
+			err = s.write(packetPingresp, 0, nil)
+
+		case packetDisconnect:
+			return
+
+		default:
+			err = fmt.Errorf("unexpected packet type %d", pkt.typ)
+		}
+
+		if err != nil {
+			klog.V(4).Infof("disconnect mqtt client %s: %v", s.clientID, err)
+			return
+		}
+	}
+}
+
+// connect handles the CONNECT packet, the session of the same client id is taken over
+func (b *Broker) connect(conn net.Conn, pkt *packet) (*brokerSession, time.Duration, error) {
+	d := &decoder{b: pkt.body}
+	name := d.string()
+	level := d.byte()
+	flags := d.byte()
+	keepAlive := time.Duration(d.uint16()) * time.Second
+	clientID := d.string()
+
+	var username, password string
+	if flags&connectFlagUsername != 0 {
+		username = d.string()
+	}
+	if flags&connectFlagPassword != 0 {
+		password = d.string()
+	}
+	if d.err != nil {
+		return nil, 0, d.err
+	}
+	if name != protocolName || level != protocolLevel {
+		return nil, 0, fmt.Errorf("unsupported protocol %s level %d", name, level)
+	}
+
+	s := &brokerSession{
+		clientID: clientID,
+		username: username,
+		conn:     conn,
+		filters:  make(map[string]byte),
+	}
+
+	if b.Authenticate != nil && !b.Authenticate(clientID, username, password) {
+		_ = s.write(packetConnack, 0, []byte{0, connackBadCredentials})
+		return nil, 0, fmt.Errorf("bad credentials of client %s", clientID)
+	}
+
+	b.lock.Lock()
+	if b.closed {
+		b.lock.Unlock()
+		return nil, 0, fmt.Errorf("broker closed")
+	}
+	if previous, ok := b.sessions[clientID]; ok {
+		_ = previous.conn.Close()
+	}
+	b.sessions[clientID] = s
+	b.lock.Unlock()
+
+	if err := s.write(packetConnack, 0, []byte{0, connackAccepted}); err != nil {
+		b.disconnect(s)
+		return nil, 0, err
+	}
+	return s, keepAlive, nil
+}
+
+func (b *Broker) disconnect(s *brokerSession) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.sessions[s.clientID] == s {
+		delete(b.sessions, s.clientID)
+	}
+}
+
+// route delivers the message to the sessions subscribing the topic
+func (b *Broker) route(topic string, payload []byte) {
+	b.lock.Lock()
+	sessions := make([]*brokerSession, 0, len(b.sessions))
+	for _, s := range b.sessions {
+		sessions = append(sessions, s)
+	}
+	b.lock.Unlock()
+
+	for _, s := range sessions {
+		qos, ok := s.match(topic)
+		if !ok {
+			continue
+		}
+		if err := s.publish(topic, qos, payload); err != nil {
+			_ = s.conn.Close()
+		}
+	}
+}
+
+func (s *brokerSession) write(typ, flags byte, body []byte) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	_ = s.conn.SetWriteDeadline(time.Now().Add(AckTimeout))
+	return writePacket(s.conn, typ, flags, body)
+}
+
+func (s *brokerSession) publish(topic string, qos byte, payload []byte) error {
+	p := &publishPacket{topic: topic, qos: qos, payload: payload}
+	if qos > 0 {
+		s.lock.Lock()
+		s.nextID++
+		if s.nextID == 0 {
+			s.nextID++
+		}
+		p.packetID = s.nextID
+		s.lock.Unlock()
+	}
+
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	_ = s.conn.SetWriteDeadline(time.Now().Add(AckTimeout))
+	return p.write(s.conn)
+}
+
+// subscribe handles the SUBSCRIBE packet, the granted QoS is at most 1
+func (s *brokerSession) subscribe(pkt *packet, authorize func(username, topic string, write bool) bool) error {
+	d := &decoder{b: pkt.body}
+	id := d.uint16()
+	body := appendUint16(nil, id)
+
+	s.lock.Lock()
+	for d.err == nil && len(d.b) > 0 {
+		filter := d.string()
+		qos := d.byte()
+		if d.err != nil {
+			break
+		}
+		if authorize != nil && !authorize(s.username, filter, false) {
+			body = append(body, subackFailure)
+			continue
+		}
+		if qos > 1 {
+			qos = 1
+		}
+		s.filters[filter] = qos
+		body = append(body, qos)
+	}
+	s.lock.Unlock()
+
+	if d.err != nil {
+		return d.err
+	}
+	return s.write(packetSuback, 0, body)
+}
+
+// match returns the max QoS of the filters matching the topic
+func (s *brokerSession) match(topic string) (byte, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var maxQoS byte
+	matched := false
+	for filter, qos := range s.filters {
+		if matchTopic(filter, topic) {
+			matched = true
+			if qos > maxQoS {
+				maxQoS = qos
+			}
+		}
+	}
+	return maxQoS, matched
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mqtt implements the transport of the messages between GM and LCs through
+// the topics of the nodes on a MQTT broker.
+//
+// GM can't tell who publishes a message, so the node of an upstream message is the node
+// of its topic. The broker must authenticate LCs and only allow the node of the username
+// to publish to its upstream topic and subscribe its downstream topic, e.g. by the ACL
+// patterns of mosquitto:
+//
+//	pattern write sedna/nodes/%u/up
+//	pattern read sedna/nodes/%u/down
+package mqtt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	// DefaultKeepAlive is the default keep alive interval of the client
+	DefaultKeepAlive = 30 * time.Second
+	// ConnectTimeout is the timeout of connecting to the broker
+	ConnectTimeout = 10 * time.Second
+	// AckTimeout is the timeout of waiting for the acknowledgement of the broker
+	AckTimeout = 30 * time.Second
+
+	// disconnectQuiesce is how long the work in progress is waited when disconnecting, in milliseconds
+	disconnectQuiesce = 250
+	// messageChannelSize is the size of the channel of the received messages
+	messageChannelSize = 100
+)
+
+// ErrClosed is the error of the operations on the closed client
+var ErrClosed = fmt.Errorf("mqtt client closed")
+
+// ClientOptions defines how the client connects to the broker
+type ClientOptions struct {
+	// Broker is the address of the broker, e.g. "tcp://broker:1883" or "ssl://broker:8883".
+	// The scheme is "tcp" if omitted.
+	Broker   string
+	ClientID string
+	Username string
+	Password string
+	// TLSConfig is used by the "ssl", "tls" and "mqtts" schemes
+	TLSConfig *tls.Config
+	// KeepAlive is the keep alive interval, default DefaultKeepAlive
+	KeepAlive time.Duration
+}
+
+// Message is a message received from the subscribed topics
+type Message struct {
+	Topic   string
+	Payload []byte
+}
+
+// Client is a MQTT client with clean session, which is closed when the connection is lost
+type Client struct {
+	client paho.Client
+
+	// the received messages are queued, so the acknowledgements are never blocked by the consumer
+	inboxLock  sync.Mutex
+	inbox      []Message
+	inboxReady chan struct{}
+	messages   chan Message
+
+	done      chan struct{}
+	err       error
+	closeOnce sync.Once
+}
+
+// Connect connects to the broker
+func Connect(options ClientOptions) (*Client, error) {
+	keepAlive := options.KeepAlive
+	if keepAlive <= 0 {
+		keepAlive = DefaultKeepAlive
+	}
+	broker := options.Broker
+	if !strings.Contains(broker, "://") {
+		broker = "tcp://" + broker
+	}
+
+	c := &Client{
+		inboxReady: make(chan struct{}, 1),
+		messages:   make(chan Message, messageChannelSize),
+		done:       make(chan struct{}),
+	}
+
+	clientOptions := paho.NewClientOptions().
+		AddBroker(broker).
+		SetClientID(options.ClientID).
+		SetUsername(options.Username).
+		SetPassword(options.Password).
+		SetTLSConfig(options.TLSConfig).
+		SetKeepAlive(keepAlive).
+		SetCleanSession(true).
+		SetAutoReconnect(false).
+		SetConnectTimeout(ConnectTimeout).
+		SetWriteTimeout(AckTimeout).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			c.close(err)
+		})
+	c.client = paho.NewClient(clientOptions)
+
+	if err := wait(c.client.Connect(), ConnectTimeout); err != nil {
+		return nil, err
+	}
+
+	go c.deliverLoop()
+	return c, nil
+}
+
+// wait waits for the token completed
+func wait(token paho.Token, timeout time.Duration) error {
+	if !token.WaitTimeout(timeout) {
+		return fmt.Errorf("timeout waiting for the broker")
+	}
+	return token.Error()
+}
+
+// Messages returns the channel of the messages received from the subscribed topics
+func (c *Client) Messages() <-chan Message {
+	return c.messages
+}
+
+// Done returns a channel that's closed when the client is closed
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the reason why the client is closed
+func (c *Client) Err() error {
+	<-c.done
+	return c.err
+}
+
+// Close disconnects from the broker
+func (c *Client) Close() error {
+	c.client.Disconnect(disconnectQuiesce)
+	c.close(ErrClosed)
+	return nil
+}
+
+func (c *Client) close(err error) {
+	c.closeOnce.Do(func() {
+		c.err = err
+		close(c.done)
+	})
+}
+
+// Publish publishes the payload to the topic, it returns when the broker receives it if QoS 1
+func (c *Client) Publish(topic string, qos byte, payload []byte) error {
+	select {
+	case <-c.done:
+		return c.err
+	default:
+	}
+
+	return wait(c.client.Publish(topic, qos, false, payload), AckTimeout)
+}
+
+// Subscribe subscribes the topic filter with the max QoS
+func (c *Client) Subscribe(filter string, qos byte) error {
+	token := c.client.Subscribe(filter, qos, c.receive)
+	if err := wait(token, AckTimeout); err != nil {
+		return err
+	}
+
+	// the broker returns 0x80 if the subscription is refused
+	if granted, ok := token.(*paho.SubscribeToken).Result()[filter]; !ok || granted == 0x80 {
+		return fmt.Errorf("subscription of %s refused by the broker", filter)
+	}
+	return nil
+}
+
+// receive queues the message received, which is called in the order of the messages
+func (c *Client) receive(_ paho.Client, m paho.Message) {
+	c.inboxLock.Lock()
+	c.inbox = append(c.inbox, Message{Topic: m.Topic(), Payload: m.Payload()})
+	c.inboxLock.Unlock()
+
+	select {
+	case c.inboxReady <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Client) deliverLoop() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-c.inboxReady:
+		}
+
+		c.inboxLock.Lock()
+		messages := c.inbox
+		c.inbox = nil
+		c.inboxLock.Unlock()
+
+		for _, m := range messages {
+			select {
+			case c.messages <- m:
+			case <-c.done:
+				return
+			}
+		}
+	}
+}
+
+// NewTLSConfig creates the TLS config verifying the broker by the CA file,
+// with the client certificate if the cert file is set
+func NewTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		data, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the CA file %s: %w", caFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificate in the CA file %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mqtt
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/ws"
+)
+
+// startBroker starts an embedded broker on a random port
+func startBroker(t *testing.T, broker *Broker) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = broker.Serve(listener) }()
+	t.Cleanup(func() { _ = broker.Close() })
+	return "tcp://" + listener.Addr().String()
+}
+
+func connect(t *testing.T, address, clientID string) *Client {
+	client, err := Connect(ClientOptions{Broker: address, ClientID: clientID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func receive(t *testing.T, client *Client) Message {
+	select {
+	case m := <-client.Messages():
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the message")
+		return Message{}
+	}
+}
+
+func TestMatchTopic(t *testing.T) {
+	for _, c := range []struct {
+		filter, topic string
+		match         bool
+	}{
+		{"sedna/nodes/+/up", "sedna/nodes/edge/up", true},
+		{"sedna/nodes/+/up", "sedna/nodes/edge/down", false},
+		{"sedna/nodes/+/up", "sedna/nodes/a/b/up", false},
+		{"sedna/#", "sedna/nodes/edge/up", true},
+		{"sedna/#", "sedna", true},
+		{"sedna/nodes", "sedna/nodes/edge", false},
+		{"sedna/nodes/edge", "sedna/nodes", false},
+		{"+", "sedna", true},
+	} {
+		if got := matchTopic(c.filter, c.topic); got != c.match {
+			t.Errorf("expected %s matching %s is %v, got %v", c.filter, c.topic, c.match, got)
+		}
+	}
+}
+
+func TestBrokerPublishSubscribe(t *testing.T) {
+	address := startBroker(t, NewBroker())
+
+	subscriber := connect(t, address, "subscriber")
+	if err := subscriber.Subscribe("sedna/nodes/+/up", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	publisher := connect(t, address, "publisher")
+	if err := publisher.Publish("sedna/nodes/edge/down", 1, []byte("ignored")); err != nil {
+		t.Fatal(err)
+	}
+	if err := publisher.Publish("sedna/nodes/edge/up", 1, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	m := receive(t, subscriber)
+	if m.Topic != "sedna/nodes/edge/up" || string(m.Payload) != "hello" {
+		t.Errorf("unexpected message %s: %s", m.Topic, m.Payload)
+	}
+
+	// the session of the same client id is taken over
+	connect(t, address, "subscriber")
+	select {
+	case <-subscriber.Done():
+	case <-time.After(5 * time.Second):
+		t.Error("expected the previous session closed")
+	}
+}
+
+func TestBrokerAuthenticate(t *testing.T) {
+	broker := NewBroker()
+	broker.Authenticate = func(clientID, username, password string) bool {
+		return username == "edge" && password == "token"
+	}
+	address := startBroker(t, broker)
+
+	if _, err := Connect(ClientOptions{Broker: address, ClientID: "bad", Username: "edge", Password: "wrong"}); err == nil {
+		t.Error("expected the bad credentials refused")
+	}
+
+	client, err := Connect(ClientOptions{Broker: address, ClientID: "good", Username: "edge", Password: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = client.Close()
+}
+
+func TestServerBridgesNodeTopics(t *testing.T) {
+	const node = "mqtt-edge"
+	address := startBroker(t, NewBroker())
+
+	srv := NewServer(ServerOptions{ClientOptions: ClientOptions{Broker: address, ClientID: "gm"}})
+	go func() { _ = srv.ListenAndServe() }()
+	defer srv.Close()
+
+	edge := connect(t, address, node)
+	if err := edge.Subscribe(DownstreamTopic(DefaultTopicPrefix, node), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// the upstream message is received by GM, then acknowledged
+	upstream, _ := json.Marshal(&model.Message{MessageHeader: model.MessageHeader{
+		ResourceKind: "dataset",
+		ResourceName: "ds",
+		Operation:    "status",
+		MessageID:    3,
+	}})
+	received := make(chan string, 1)
+	go func() {
+		nodeName, msg, _ := ws.ReceiveFromEdge()
+		received <- nodeName + ":" + msg.ResourceName
+	}()
+	deadline := time.Now().Add(10 * time.Second)
+	var got string
+	for got == "" {
+		// GM may not subscribe yet
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for the upstream message")
+		}
+		if err := edge.Publish(UpstreamTopic(DefaultTopicPrefix, node), 1, upstream); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case got = <-received:
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+	if got != node+":ds" {
+		t.Errorf("expected message ds of node %s, got %s", node, got)
+	}
+
+	var ack model.Message
+	for ack.Operation != model.AckOperation {
+		if err := json.Unmarshal(receive(t, edge).Payload, &ack); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ack.MessageID != 3 {
+		t.Errorf("expected message 3 acknowledged, got %d", ack.MessageID)
+	}
+
+	// the downstream message is published to the topic of the node
+	err := ws.SendToEdge(node, &model.Message{
+		MessageHeader: model.MessageHeader{
+			Namespace:    "default",
+			ResourceKind: "dataset",
+			ResourceName: "ds",
+			Operation:    "insert",
+		},
+		Content: []byte(`{"metadata":{"name":"ds","namespace":"default"}}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the acks of the retried upstream messages are skipped
+	var downstream model.Message
+	for downstream.Operation == "" || downstream.Operation == model.AckOperation {
+		if err := json.Unmarshal(receive(t, edge).Payload, &downstream); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if downstream.Operation != "insert" || downstream.ResourceName != "ds" {
+		t.Errorf("unexpected downstream message %+v", downstream.MessageHeader)
+	}
+}
+
+func TestNodeTopicsACL(t *testing.T) {
+	// the ACL patterns of the package doc, GM is allowed all topics
+	broker := NewBroker()
+	broker.Authorize = func(username, topic string, write bool) bool {
+		if username == "gm" {
+			return true
+		}
+		if write {
+			return topic == UpstreamTopic(DefaultTopicPrefix, username)
+		}
+		return topic == DownstreamTopic(DefaultTopicPrefix, username)
+	}
+	address := startBroker(t, broker)
+
+	srv := NewServer(ServerOptions{ClientOptions: ClientOptions{Broker: address, ClientID: "gm", Username: "gm"}})
+	go func() { _ = srv.ListenAndServe() }()
+	defer srv.Close()
+
+	edge, err := Connect(ClientOptions{Broker: address, ClientID: "acl-edge", Username: "acl-edge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer edge.Close()
+
+	if err := edge.Subscribe(DownstreamTopic(DefaultTopicPrefix, "acl-other"), 1); err == nil {
+		t.Error("expected the subscription of the topic of another node refused")
+	}
+	if err := edge.Subscribe(DownstreamTopic(DefaultTopicPrefix, "acl-edge"), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !broker.Subscribed("gm") {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for GM subscribing")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// the message impersonating another node is dropped by the broker
+	for _, nodeName := range []string{"acl-other", "acl-edge"} {
+		upstream, _ := json.Marshal(&model.Message{MessageHeader: model.MessageHeader{
+			ResourceKind: "dataset",
+			ResourceName: nodeName,
+			Operation:    "status",
+		}})
+		if err := edge.Publish(UpstreamTopic(DefaultTopicPrefix, nodeName), 1, upstream); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	nodeName, msg, _ := ws.ReceiveFromEdge()
+	for nodeName == "mqtt-edge" {
+		// the retried messages of the bridge test
+		nodeName, msg, _ = ws.ReceiveFromEdge()
+	}
+	if nodeName != "acl-edge" || msg.ResourceName != "acl-edge" {
+		t.Errorf("expected only the message of the node itself received, got %s of node %s", msg.ResourceName, nodeName)
+	}
+}
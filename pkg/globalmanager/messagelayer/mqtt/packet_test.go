@@ -0,0 +1,234 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// The control packet types of MQTT 3.1.1 supported
+const (
+	packetConnect    byte = 1
+	packetConnack    byte = 2
+	packetPublish    byte = 3
+	packetPuback     byte = 4
+	packetSubscribe  byte = 8
+	packetSuback     byte = 9
+	packetPingreq    byte = 12
+	packetPingresp   byte = 13
+	packetDisconnect byte = 14
+)
+
+const (
+	protocolName  = "MQTT"
+	protocolLevel = 4
+
+	connectFlagPassword = 0x40
+	connectFlagUsername = 0x80
+
+	connackAccepted       = 0
+	connackBadCredentials = 4
+
+	subackFailure = 0x80
+
+	// maxPacketSize is the max remaining length of a packet
+	maxPacketSize = 268435455
+)
+
+// packet is a control packet
+type packet struct {
+	typ   byte
+	flags byte
+	body  []byte
+}
+
+// readPacket reads a control packet
+func readPacket(r *bufio.Reader) (*packet, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	// the remaining length is encoded in up to 4 bytes
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return nil, fmt.Errorf("malformed remaining length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return &packet{typ: first >> 4, flags: first & 0x0f, body: body}, nil
+}
+
+// writePacket writes a control packet
+func writePacket(w io.Writer, typ, flags byte, body []byte) error {
+	length := len(body)
+	if length > maxPacketSize {
+		return fmt.Errorf("packet size %d exceeds the max size", length)
+	}
+
+	buf := make([]byte, 0, 5+length)
+	buf = append(buf, typ<<4|flags)
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if length == 0 {
+			break
+		}
+	}
+
+	_, err := w.Write(append(buf, body...))
+	return err
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+func appendString(b []byte, s string) []byte {
+	return append(appendUint16(b, uint16(len(s))), s...)
+}
+
+// decoder reads the fields of a packet body, the first error is kept
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) uint16() uint16 {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.b) < 2 {
+		d.err = fmt.Errorf("malformed packet")
+		return 0
+	}
+	v := binary.BigEndian.Uint16(d.b)
+	d.b = d.b[2:]
+	return v
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.b) < 1 {
+		d.err = fmt.Errorf("malformed packet")
+		return 0
+	}
+	v := d.b[0]
+	d.b = d.b[1:]
+	return v
+}
+
+func (d *decoder) string() string {
+	n := int(d.uint16())
+	if d.err != nil {
+		return ""
+	}
+	if len(d.b) < n {
+		d.err = fmt.Errorf("malformed packet")
+		return ""
+	}
+	s := string(d.b[:n])
+	d.b = d.b[n:]
+	return s
+}
+
+// publishPacket is the body of a PUBLISH packet
+type publishPacket struct {
+	topic    string
+	qos      byte
+	packetID uint16
+	payload  []byte
+}
+
+func (p *publishPacket) write(w io.Writer) error {
+	body := appendString(nil, p.topic)
+	if p.qos > 0 {
+		body = appendUint16(body, p.packetID)
+	}
+	return writePacket(w, packetPublish, p.qos<<1, append(body, p.payload...))
+}
+
+func parsePublish(pkt *packet) (*publishPacket, error) {
+	d := &decoder{b: pkt.body}
+	p := &publishPacket{qos: (pkt.flags >> 1) & 0x03}
+	p.topic = d.string()
+	if p.qos > 0 {
+		p.packetID = d.uint16()
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if p.qos > 1 {
+		return nil, fmt.Errorf("QoS %d is not supported", p.qos)
+	}
+	p.payload = d.b
+	return p, nil
+}
+
+// matchTopic checks the topic matches the filter with the wildcards "+" and "#"
+func matchTopic(filter, topic string) bool {
+	for {
+		filterLevel, filterRest, filterMore := cutLevel(filter)
+		topicLevel, topicRest, topicMore := cutLevel(topic)
+
+		if filterLevel == "#" {
+			return true
+		}
+		if filterLevel != "+" && filterLevel != topicLevel {
+			return false
+		}
+		if !filterMore || !topicMore {
+			// "a/#" matches "a" as well
+			return filterMore == topicMore || (filterMore && filterRest == "#")
+		}
+
+		filter, topic = filterRest, topicRest
+	}
+}
+
+// cutLevel cuts the first level of the topic
+func cutLevel(topic string) (level, rest string, more bool) {
+	for i := 0; i < len(topic); i++ {
+		if topic[i] == '/' {
+			return topic[:i], topic[i+1:], true
+		}
+	}
+	return topic, "", false
+}
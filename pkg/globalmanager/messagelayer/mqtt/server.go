@@ -0,0 +1,260 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mqtt
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/ws"
+)
+
+const (
+	// DefaultTopicPrefix is the default prefix of the topics of the nodes
+	DefaultTopicPrefix = "sedna"
+
+	// the backoff of reconnecting to the broker
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+)
+
+// UpstreamTopic returns the topic which the node publishes the messages to GM to
+func UpstreamTopic(prefix, nodeName string) string {
+	return prefix + "/nodes/" + nodeName + "/up"
+}
+
+// DownstreamTopic returns the topic which GM publishes the messages to the node to
+func DownstreamTopic(prefix, nodeName string) string {
+	return prefix + "/nodes/" + nodeName + "/down"
+}
+
+// nodeNameOfTopic returns the node name of the upstream topic
+func nodeNameOfTopic(prefix, topic string) (string, bool) {
+	rest := strings.TrimPrefix(topic, prefix+"/nodes/")
+	if rest == topic || !strings.HasSuffix(rest, "/up") {
+		return "", false
+	}
+	nodeName := strings.TrimSuffix(rest, "/up")
+	return nodeName, nodeName != "" && !strings.Contains(nodeName, "/")
+}
+
+// ServerOptions defines how GM bridges the topics of the nodes
+type ServerOptions struct {
+	ClientOptions
+	// TopicPrefix is the prefix of the topics of the nodes, default DefaultTopicPrefix
+	TopicPrefix string
+}
+
+// Server bridges the topics of the nodes on the broker to the message layer.
+// The broker is trusted to authenticate LCs and authorize the topics of their nodes.
+type Server struct {
+	options ServerOptions
+
+	lock     sync.Mutex
+	client   *Client
+	sessions map[string]*nodeSession
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// nodeSession is the messages of a node, which lasts as long as the connection of GM to the broker.
+// The node is registered when its first message is received, and LC sends its inventory when connected,
+// so the downstream messages lost while LC is offline are resynced.
+type nodeSession struct {
+	nodeName string
+	server   *Server
+	client   *Client
+	messages chan []byte
+	done     chan struct{}
+}
+
+// NewServer creates the bridge server
+func NewServer(options ServerOptions) *Server {
+	if options.TopicPrefix == "" {
+		options.TopicPrefix = DefaultTopicPrefix
+	}
+	return &Server{
+		options:  options,
+		sessions: make(map[string]*nodeSession),
+		closed:   make(chan struct{}),
+	}
+}
+
+// ListenAndServe connects to the broker and serves the topics of the nodes,
+// and reconnects when the connection is lost until the server is closed
+func (srv *Server) ListenAndServe() error {
+	delay := minReconnectDelay
+	for {
+		err := srv.serve()
+
+		select {
+		case <-srv.closed:
+			return nil
+		default:
+		}
+
+		klog.Warningf("connection to the mqtt broker %s lost, reconnect in %v: %v", srv.options.Broker, delay, err)
+		select {
+		case <-srv.closed:
+			return nil
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// serve serves a connection to the broker until it's lost
+func (srv *Server) serve() error {
+	client, err := Connect(srv.options.ClientOptions)
+	if err != nil {
+		return err
+	}
+	defer srv.closeSessions(client)
+
+	srv.lock.Lock()
+	srv.client = client
+	srv.lock.Unlock()
+
+	select {
+	case <-srv.closed:
+		// closed while connecting
+		_ = client.Close()
+		return nil
+	default:
+	}
+
+	if err := client.Subscribe(UpstreamTopic(srv.options.TopicPrefix, "+"), 1); err != nil {
+		_ = client.Close()
+		return err
+	}
+	klog.Infof("serving the node topics of prefix %s on the mqtt broker %s", srv.options.TopicPrefix, srv.options.Broker)
+
+	for {
+		select {
+		case <-client.Done():
+			return client.Err()
+		case m := <-client.Messages():
+			nodeName, ok := nodeNameOfTopic(srv.options.TopicPrefix, m.Topic)
+			if !ok {
+				klog.Warningf("ignored the message of unknown topic %s", m.Topic)
+				continue
+			}
+
+			s := srv.getSession(client, nodeName)
+			select {
+			case s.messages <- m.Payload:
+			case <-s.done:
+			}
+		}
+	}
+}
+
+// getSession returns the session of the node, and registers it if not exists
+func (srv *Server) getSession(client *Client, nodeName string) *nodeSession {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+
+	if s, ok := srv.sessions[nodeName]; ok {
+		return s
+	}
+
+	s := &nodeSession{
+		nodeName: nodeName,
+		server:   srv,
+		client:   client,
+		messages: make(chan []byte),
+		done:     make(chan struct{}),
+	}
+	srv.sessions[nodeName] = s
+
+	klog.Infof("established session for node %s", nodeName)
+	closeCh := make(chan struct{}, 2)
+	ws.AddNode(nodeName, s.read, s.write, closeCh)
+	go func() {
+		<-closeCh
+		srv.closeSession(s)
+		klog.Infof("closed session for node %s", nodeName)
+	}()
+	return s
+}
+
+func (srv *Server) closeSession(s *nodeSession) {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+
+	if srv.sessions[s.nodeName] == s {
+		delete(srv.sessions, s.nodeName)
+		close(s.done)
+	}
+}
+
+// closeSessions closes the sessions of the connection
+func (srv *Server) closeSessions(client *Client) {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+
+	for nodeName, s := range srv.sessions {
+		if s.client == client {
+			delete(srv.sessions, nodeName)
+			close(s.done)
+		}
+	}
+	if srv.client == client {
+		srv.client = nil
+	}
+}
+
+// Close disconnects from the broker
+func (srv *Server) Close() error {
+	srv.closeOnce.Do(func() {
+		close(srv.closed)
+
+		srv.lock.Lock()
+		client := srv.client
+		srv.lock.Unlock()
+		if client != nil {
+			_ = client.Close()
+		}
+	})
+	return nil
+}
+
+func (s *nodeSession) read() ([]byte, error) {
+	select {
+	case data := <-s.messages:
+		return data, nil
+	case <-s.done:
+		return nil, fmt.Errorf("session of node %s closed", s.nodeName)
+	}
+}
+
+func (s *nodeSession) write(data []byte) error {
+	select {
+	case <-s.done:
+		return fmt.Errorf("session of node %s closed", s.nodeName)
+	default:
+	}
+
+	return s.client.Publish(DownstreamTopic(s.server.options.TopicPrefix, s.nodeName), 1, data)
+}
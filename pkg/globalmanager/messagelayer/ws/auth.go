@@ -0,0 +1,219 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ws
+
+import (
+	gocontext "context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// nodeUserPrefix is the prefix of the node identity of the client certificate, e.g. "system:node:edge1"
+	nodeUserPrefix = "system:node:"
+	// serviceAccountUserPrefix is the prefix of the user of the service account token
+	serviceAccountUserPrefix = "system:serviceaccount:"
+	// podNameExtraKey is the extra info of the bound service account token giving the pod name
+	podNameExtraKey = "authentication.kubernetes.io/pod-name"
+)
+
+// Authenticator authenticates the node of the connection request
+type Authenticator interface {
+	// Authenticate returns the node name of the request's identity,
+	// and returns false if the request has no credential of the authenticator.
+	// An error is returned if the credential is rejected.
+	Authenticate(req *http.Request) (nodeName string, ok bool, err error)
+}
+
+// NewTLSConfig loads the server certificate, and the client certificates are verified
+// by the CA if clientCAFile is set. The client certificate is required unless optionalClientCert.
+func NewTLSConfig(certFile, keyFile, clientCAFile string, optionalClientCert bool) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the certificate of websocket server: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if clientCAFile != "" {
+		data, err := ioutil.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the client CA file %s: %w", clientCAFile, err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificate in the client CA file %s", clientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		if optionalClientCert {
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// certAuthenticator authenticates the node by the verified client certificate,
+// whose common name is the node name or "system:node:<node name>"
+type certAuthenticator struct{}
+
+// NewCertAuthenticator creates the authenticator of the client certificates
+func NewCertAuthenticator() Authenticator {
+	return certAuthenticator{}
+}
+
+func (certAuthenticator) Authenticate(req *http.Request) (string, bool, error) {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return "", false, nil
+	}
+
+	cn := req.TLS.VerifiedChains[0][0].Subject.CommonName
+	return strings.TrimPrefix(cn, nodeUserPrefix), true, nil
+}
+
+// bearerToken returns the bearer token of the request
+func bearerToken(req *http.Request) string {
+	auth := strings.TrimSpace(req.Header.Get("Authorization"))
+	const prefix = "bearer "
+	if len(auth) > len(prefix) && strings.EqualFold(auth[:len(prefix)], prefix) {
+		return strings.TrimSpace(auth[len(prefix):])
+	}
+	return ""
+}
+
+// tokenFileAuthenticator authenticates the node by the static tokens
+type tokenFileAuthenticator struct {
+	// tokens maps the token to the node name
+	tokens map[string]string
+}
+
+// NewTokenFileAuthenticator creates the authenticator of the static tokens in the csv file,
+// each line of which is "token,node name"
+func NewTokenFileAuthenticator(file string) (Authenticator, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the token file %s: %w", file, err)
+	}
+
+	tokens := make(map[string]string)
+	for i, record := range records {
+		if len(record) < 2 || strings.TrimSpace(record[0]) == "" || strings.TrimSpace(record[1]) == "" {
+			return nil, fmt.Errorf("line %d of the token file %s is not \"token,node name\"", i+1, file)
+		}
+		tokens[strings.TrimSpace(record[0])] = strings.TrimSpace(record[1])
+	}
+
+	return &tokenFileAuthenticator{tokens: tokens}, nil
+}
+
+func (a *tokenFileAuthenticator) Authenticate(req *http.Request) (string, bool, error) {
+	token := bearerToken(req)
+	if token == "" {
+		return "", false, nil
+	}
+
+	// the unknown token may be of other authenticators
+	nodeName, ok := a.tokens[token]
+	return nodeName, ok, nil
+}
+
+// serviceAccountAuthenticator authenticates the node by the bound service account token of the LC pod,
+// the node name is the node which the pod is running on.
+type serviceAccountAuthenticator struct {
+	client kubernetes.Interface
+	// serviceAccounts are the allowed service accounts "namespace/name"
+	serviceAccounts map[string]bool
+}
+
+// NewServiceAccountAuthenticator creates the authenticator of the service account tokens by the TokenReview,
+// only the tokens of the service accounts "namespace/name" are accepted.
+func NewServiceAccountAuthenticator(client kubernetes.Interface, serviceAccounts []string) Authenticator {
+	a := &serviceAccountAuthenticator{
+		client:          client,
+		serviceAccounts: make(map[string]bool),
+	}
+	for _, sa := range serviceAccounts {
+		a.serviceAccounts[sa] = true
+	}
+	return a
+}
+
+func (a *serviceAccountAuthenticator) Authenticate(req *http.Request) (string, bool, error) {
+	token := bearerToken(req)
+	if token == "" {
+		return "", false, nil
+	}
+
+	review, err := a.client.AuthenticationV1().TokenReviews().Create(gocontext.TODO(), &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", true, fmt.Errorf("failed to review the token: %w", err)
+	}
+	if !review.Status.Authenticated {
+		return "", true, fmt.Errorf("invalid token: %s", review.Status.Error)
+	}
+
+	// the user of the service account is "system:serviceaccount:<namespace>:<name>"
+	parts := strings.Split(strings.TrimPrefix(review.Status.User.Username, serviceAccountUserPrefix), ":")
+	if !strings.HasPrefix(review.Status.User.Username, serviceAccountUserPrefix) || len(parts) != 2 {
+		return "", true, fmt.Errorf("user %s is not a service account", review.Status.User.Username)
+	}
+	namespace, name := parts[0], parts[1]
+	if !a.serviceAccounts[namespace+"/"+name] {
+		return "", true, fmt.Errorf("service account %s/%s is not allowed", namespace, name)
+	}
+
+	pods := review.Status.User.Extra[podNameExtraKey]
+	if len(pods) == 0 {
+		return "", true, fmt.Errorf("token of service account %s/%s is not bound to a pod", namespace, name)
+	}
+
+	pod, err := a.client.CoreV1().Pods(namespace).Get(gocontext.TODO(), pods[0], metav1.GetOptions{})
+	if err != nil {
+		return "", true, fmt.Errorf("failed to get the pod %s/%s of the token: %w", namespace, pods[0], err)
+	}
+	if pod.Spec.ServiceAccountName != name {
+		return "", true, fmt.Errorf("pod %s/%s is not of service account %s", namespace, pod.Name, name)
+	}
+
+	return pod.Spec.NodeName, true, nil
+}
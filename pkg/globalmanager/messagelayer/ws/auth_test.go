@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ws
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newRequest(nodeName, token string, certCN string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Node-Name", nodeName)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if certCN != "" {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: certCN}}
+		req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+	return req
+}
+
+func TestAuthenticate(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "tokens.csv")
+	if err := ioutil.WriteFile(tokenFile, []byte("# token,node\ntoken-a,edge-a\ntoken-b,edge-b\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := NewTokenFileAuthenticator(tokenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := fake.NewSimpleClientset(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "sedna", Name: "lc-a"},
+		Spec:       v1.PodSpec{NodeName: "edge-a", ServiceAccountName: "sedna-lc"},
+	})
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if review.Spec.Token == "sa-token-a" {
+			review.Status.Authenticated = true
+			review.Status.User = authenticationv1.UserInfo{
+				Username: "system:serviceaccount:sedna:sedna-lc",
+				Extra:    map[string]authenticationv1.ExtraValue{podNameExtraKey: {"lc-a"}},
+			}
+		}
+		return true, review, nil
+	})
+	serviceAccounts := NewServiceAccountAuthenticator(client, []string{"sedna/sedna-lc"})
+
+	srv := &Server{authenticators: []Authenticator{NewCertAuthenticator(), tokens, serviceAccounts}}
+
+	cases := []struct {
+		name string
+		req  *http.Request
+		code int
+	}{
+		{"static token", newRequest("edge-a", "token-a", ""), http.StatusOK},
+		{"static token of other node", newRequest("edge-a", "token-b", ""), http.StatusForbidden},
+		{"service account token", newRequest("edge-a", "sa-token-a", ""), http.StatusOK},
+		{"service account token of other node", newRequest("edge-b", "sa-token-a", ""), http.StatusForbidden},
+		{"invalid token", newRequest("edge-a", "unknown", ""), http.StatusUnauthorized},
+		{"node certificate", newRequest("edge-b", "", "system:node:edge-b"), http.StatusOK},
+		{"certificate of other node", newRequest("edge-b", "", "edge-a"), http.StatusForbidden},
+		{"no credential", newRequest("edge-a", "", ""), http.StatusUnauthorized},
+		{"no node name", newRequest("", "token-a", ""), http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		code, err := srv.authenticate(c.req)
+		if code != c.code {
+			t.Errorf("%s: expected status %d, got %d(error: %v)", c.name, c.code, code, err)
+		}
+	}
+
+	// the node name is trusted without authenticators
+	if code, err := (&Server{}).authenticate(newRequest("edge-a", "", "")); err != nil {
+		t.Errorf("expected the node name trusted without authenticators, got %d(error: %v)", code, err)
+	}
+}
@@ -26,26 +26,27 @@ import (
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/codec"
 	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
 )
 
-type nodeMessage struct {
-	nodeName string
-	msg      model.Message
-}
-
 // ChannelContext is object for Context channel
 type ChannelContext struct {
 	ctx    gocontext.Context
 	cancel gocontext.CancelFunc
 
-	upstreamChannel chan nodeMessage
-	//downstreamChannel chan nodeMessage
+	// upstream queues the messages of each node
+	upstream *upstreamQueues
 
 	// downstream map
 	// nodeName => queue
 	nodeQueue sync.Map
 	nodeStore sync.Map
+	// nodeName => resources synced to the node
+	nodeResources sync.Map
+	// nodeName => connection status of the node
+	nodeStatus sync.Map
+	nodeEvents chan string
 }
 
 var (
@@ -59,14 +60,14 @@ func init() {
 
 // NewChannelContext creates a ChannelContext
 func NewChannelContext() *ChannelContext {
-	upstreamSize := 1000
-	upstreamChannel := make(chan nodeMessage, upstreamSize)
+	nodeEventSize := 1000
 
 	ctx, cancel := gocontext.WithCancel(gocontext.Background())
 	return &ChannelContext{
-		upstreamChannel: upstreamChannel,
-		ctx:             ctx,
-		cancel:          cancel,
+		upstream:   newUpstreamQueues(),
+		nodeEvents: make(chan string, nodeEventSize),
+		ctx:        ctx,
+		cancel:     cancel,
 	}
 }
 
@@ -76,6 +77,10 @@ func getMsgKey(obj interface{}) (string, error) {
 	kind := msg.ResourceKind
 	namespace := msg.Namespace
 	name := msg.ResourceName
+	if msg.Operation == model.RejectOperation {
+		// the rejection doesn't replace the pending update of the resource
+		return strings.Join([]string{model.RejectOperation, kind, namespace, name}, "/"), nil
+	}
 	return strings.Join([]string{kind, namespace, name}, "/"), nil
 }
 
@@ -99,6 +104,8 @@ func getNodeStore(nodeName string) cache.Store {
 
 // SendToEdge sends the msg to nodeName
 func SendToEdge(nodeName string, msg *model.Message) error {
+	trackResource(nodeName, msg)
+
 	q := getNodeQueue(nodeName)
 	key, _ := getMsgKey(msg)
 	q.Add(key)
@@ -107,18 +114,15 @@ func SendToEdge(nodeName string, msg *model.Message) error {
 	return s.Add(msg)
 }
 
-// ReceiveFromEdge receives a message from edge
+// ReceiveFromEdge receives a message from edge, the messages of the nodes are received in turn
 func ReceiveFromEdge() (nodeName string, msg model.Message, err error) {
-	nodeMsg := <-context.upstreamChannel
-	nodeName = nodeMsg.nodeName
-	msg = nodeMsg.msg
-	return
+	return context.upstream.pop(context.ctx)
 }
 
-// SendToCloud sends the message to cloud from specified node name
+// SendToCloud sends the message to cloud from specified node name,
+// which blocks if the upstream queue of the node is full
 func SendToCloud(nodeName string, msg model.Message) error {
-	context.upstreamChannel <- nodeMessage{nodeName, msg}
-	return nil
+	return context.upstream.push(context.ctx, nodeName, msg)
 }
 
 // Done returns a channel that's closed when done
@@ -126,29 +130,93 @@ func Done() <-chan struct{} {
 	return context.ctx.Done()
 }
 
-// ReadMsgFunc defines read msg callback
-type ReadMsgFunc func() (model.Message, error)
+// ReadMsgFunc defines read msg callback, which returns the encoded message
+type ReadMsgFunc func() ([]byte, error)
+
+// WriteMsgFunc defines write msg callback of the encoded message
+type WriteMsgFunc func([]byte) error
 
-// WriteMsgFunc defines write msg callback
-type WriteMsgFunc func(model.Message) error
+// readMessage reads and decodes a message of the node
+func readMessage(nodeName string, read ReadMsgFunc) (model.Message, error) {
+	var msg model.Message
+	for {
+		data, err := read()
+		if err != nil {
+			return msg, err
+		}
+		if err := codec.Unmarshal(data, &msg); err != nil {
+			klog.Warningf("ignored the malformed message of node %s: %v", nodeName, err)
+			continue
+		}
+		recordReceived(nodeName, &msg, data, codec.OptionsOf(negotiatedCapabilities(nodeName)))
+		return msg, nil
+	}
+}
+
+// writeMessage encodes the message by the capabilities of the node, and writes it
+func writeMessage(nodeName string, write WriteMsgFunc, msg *model.Message) error {
+	options := codec.OptionsOf(negotiatedCapabilities(nodeName))
+	data, err := codec.Marshal(msg, options)
+	if err != nil {
+		return err
+	}
+	if err := write(data); err != nil {
+		return err
+	}
+	recordSent(nodeName, msg, data, options)
+	return nil
+}
 
 // AddNode registers a node
 func AddNode(nodeName string, read ReadMsgFunc, write WriteMsgFunc, closeCh chan struct{}) {
 	getNodeQueue(nodeName)
 	getNodeStore(nodeName)
+	markConnected(nodeName)
+
+	// the upstream messages are acked when taken by GM, and rejected when dropped,
+	// and the edge keeps the messages until then
+	detach := context.upstream.attach(nodeName, func(reply *model.Message) {
+		msg, ok := downgrade(nodeName, reply)
+		if !ok {
+			return
+		}
+		if err := writeMessage(nodeName, write, msg); err != nil {
+			klog.Warningf("failed to write the %s of message(id=%d) to node %s: %v",
+				msg.Operation, reply.MessageID, nodeName, err)
+		}
+	})
+
+	// the connection is closed once either loop exits
+	var disconnectOnce sync.Once
+	disconnect := func(err error) {
+		disconnectOnce.Do(func() {
+			detach()
+			markDisconnected(nodeName, err)
+		})
+	}
 
 	go func() {
 		// read loop
 		var msg model.Message
 		var err error
-		for {
-			msg, err = read()
+		for first := true; ; first = false {
+			msg, err = readMessage(nodeName, read)
 			if err != nil {
 				break
 			}
 			klog.V(4).Infof("received msg from %s: %+v", nodeName, msg)
-			_ = SendToCloud(nodeName, msg)
+			recordHeartbeat(nodeName)
+
+			if reply := negotiate(nodeName, &msg, first); reply != nil {
+				if err = writeMessage(nodeName, write, reply); err != nil {
+					break
+				}
+			}
+			if err = SendToCloud(nodeName, msg); err != nil {
+				break
+			}
 		}
+		disconnect(err)
 		closeCh <- struct{}{}
 		klog.Errorf("read loop of node %s closed, due to: %+v", nodeName, err)
 	}()
@@ -171,8 +239,15 @@ func AddNode(nodeName string, read ReadMsgFunc, write WriteMsgFunc, closeCh chan
 				q.Done(key)
 				continue
 			}
-			msg := obj.(*model.Message)
-			err = write(*msg)
+			msg, ok := downgrade(nodeName, obj.(*model.Message))
+			if !ok {
+				klog.V(4).Infof("dropped key %s unsupported by node %s", key, nodeName)
+				_ = s.Delete(obj)
+				q.Forget(key)
+				q.Done(key)
+				continue
+			}
+			err = writeMessage(nodeName, write, msg)
 			klog.V(4).Infof("writing msg to %s: %+v", nodeName, msg)
 			if err != nil {
 				klog.Warningf("failed to write key %s to node %s, requeue", key, nodeName)
@@ -186,6 +261,7 @@ func AddNode(nodeName string, read ReadMsgFunc, write WriteMsgFunc, closeCh chan
 			q.Forget(key)
 			q.Done(key)
 		}
+		disconnect(err)
 		closeCh <- struct{}{}
 		klog.Errorf("write loop of node %s closed, due to: %+v", nodeName, err)
 	}()
@@ -0,0 +1,314 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ws
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/codec"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+)
+
+// The long-poll fallback of the websocket server speaks plain HTTP(S) for the LCs behind the proxies
+// breaking the websocket upgrades:
+//
+//	POST   /longpoll/sessions            creates a session of the node, replies LongPollSession
+//	POST   /longpoll/sessions/<id>/up    sends a batch of the upstream messages
+//	GET    /longpoll/sessions/<id>/down  waits for the downstream messages, 204 if none
+//	DELETE /longpoll/sessions/<id>       closes the session
+//
+// A session is a connection of the node, which expires if no request is received in model.PongWait.
+// The bodies are the encoded messages each prefixed with its length in 4 bytes big endian.
+const (
+	// LongPollPath is the path prefix of the long-poll sessions
+	LongPollPath = "/longpoll/sessions"
+	// LongPollContentType is the content type of the messages
+	LongPollContentType = "application/octet-stream"
+	// LongPollTimeout is how long a poll waits for the downstream messages
+	LongPollTimeout = model.PongWait / 3
+
+	// maxDownstreamBatch is the max number of the downstream messages replied by a poll
+	maxDownstreamBatch = 100
+)
+
+// LongPollSession is the reply of the session created
+type LongPollSession struct {
+	ID string `json:"id"`
+}
+
+// WriteFrame writes the encoded message prefixed with its length
+func WriteFrame(w io.Writer, data []byte) error {
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(data)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// ReadFrame reads an encoded message prefixed with its length, io.EOF if no more message
+func ReadFrame(r io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > codec.MaxContentSize {
+		return nil, fmt.Errorf("message size %d exceeds the max size %d", size, codec.MaxContentSize)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// longPollSession is a long-poll connection of a node
+type longPollSession struct {
+	id       string
+	nodeName string
+
+	upstream chan []byte
+	// downstream are the messages waiting for a poll
+	downstream chan []byte
+	// activity is signaled on each request of the session
+	activity chan struct{}
+
+	closeOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s *longPollSession) read() ([]byte, error) {
+	select {
+	case data := <-s.upstream:
+		return data, nil
+	case <-s.done:
+		return nil, s.err
+	}
+}
+
+// write queues the message for the polls, which blocks if the queue is full.
+// The messages lost with the session are sent again by the resync of the node when reconnected.
+func (s *longPollSession) write(data []byte) error {
+	select {
+	case s.downstream <- data:
+		return nil
+	case <-s.done:
+		return s.err
+	}
+}
+
+func (s *longPollSession) close(err error) {
+	s.closeOnce.Do(func() {
+		s.err = err
+		close(s.done)
+	})
+}
+
+func (s *longPollSession) touch() {
+	recordHeartbeat(s.nodeName)
+	select {
+	case s.activity <- struct{}{}:
+	default:
+	}
+}
+
+// expire closes the session if no request is received in time
+func (s *longPollSession) expire() {
+	timer := time.NewTimer(model.PongWait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-s.activity:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(model.PongWait)
+		case <-timer.C:
+			s.close(fmt.Errorf("long-poll session expired"))
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// serveLongPoll serves the long-poll sessions
+func (srv *Server) serveLongPoll(w http.ResponseWriter, req *http.Request) {
+	nodeName := req.Header.Get("Node-Name")
+	if code, err := srv.authenticate(req); err != nil {
+		klog.Warningf("rejected the long-poll request of node %s from %s: %v", nodeName, req.RemoteAddr, err)
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(req.URL.Path, LongPollPath), "/")
+	if path == "" {
+		if req.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		srv.createSession(w, nodeName)
+		return
+	}
+
+	parts := strings.Split(path, "/")
+	v, ok := srv.sessions.Load(parts[0])
+	if !ok || v.(*longPollSession).nodeName != nodeName {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	s := v.(*longPollSession)
+	s.touch()
+	defer s.touch()
+
+	switch {
+	case len(parts) == 1 && req.Method == http.MethodDelete:
+		s.close(fmt.Errorf("long-poll session closed by the node"))
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 2 && parts[1] == "up" && req.Method == http.MethodPost:
+		s.receive(w, req)
+	case len(parts) == 2 && parts[1] == "down" && req.Method == http.MethodGet:
+		s.poll(w, req)
+	default:
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	}
+}
+
+// createSession creates the session of the node, which is served as a connection
+func (srv *Server) createSession(w http.ResponseWriter, nodeName string) {
+	id, err := newSessionID()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s := &longPollSession{
+		id:         id,
+		nodeName:   nodeName,
+		upstream:   make(chan []byte),
+		downstream: make(chan []byte, maxDownstreamBatch),
+		activity:   make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	srv.sessions.Store(id, s)
+	klog.Infof("established long-poll session for node %s", nodeName)
+
+	closeCh := make(chan struct{}, 2)
+	AddNode(nodeName, s.read, s.write, closeCh)
+	go s.expire()
+	go func() {
+		select {
+		case <-closeCh:
+			s.close(fmt.Errorf("long-poll session closed"))
+		case <-s.done:
+		}
+		srv.sessions.Delete(id)
+		klog.Infof("closed long-poll session for node %s: %v", nodeName, s.err)
+	}()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(LongPollSession{ID: id})
+}
+
+// receive passes the batch of the upstream messages to the read loop of the node
+func (s *longPollSession) receive(w http.ResponseWriter, req *http.Request) {
+	r := bufio.NewReader(req.Body)
+	for {
+		data, err := ReadFrame(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case s.upstream <- data:
+		case <-s.done:
+			http.Error(w, "session closed", http.StatusGone)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// poll waits for the downstream messages of the node, and replies all the queued ones
+// up to maxDownstreamBatch
+func (s *longPollSession) poll(w http.ResponseWriter, req *http.Request) {
+	timer := time.NewTimer(LongPollTimeout)
+	defer timer.Stop()
+
+	var batch [][]byte
+	select {
+	case data := <-s.downstream:
+		batch = append(batch, data)
+	case <-timer.C:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case <-req.Context().Done():
+		return
+	case <-s.done:
+		http.Error(w, "session closed", http.StatusGone)
+		return
+	}
+
+collect:
+	for len(batch) < maxDownstreamBatch {
+		select {
+		case data := <-s.downstream:
+			batch = append(batch, data)
+		default:
+			break collect
+		}
+	}
+
+	w.Header().Set("Content-Type", LongPollContentType)
+	for _, data := range batch {
+		if err := WriteFrame(w, data); err != nil {
+			klog.Warningf("failed to reply the downstream messages to node %s: %v", s.nodeName, err)
+			return
+		}
+	}
+}
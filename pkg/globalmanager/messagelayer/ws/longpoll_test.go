@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ws
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLongPollRepliesQueuedMessages(t *testing.T) {
+	s := &longPollSession{
+		nodeName:   "edge",
+		downstream: make(chan []byte, maxDownstreamBatch),
+		done:       make(chan struct{}),
+	}
+	for i := 0; i < 3; i++ {
+		if err := s.write([]byte(fmt.Sprint("message-", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	s.poll(rec, httptest.NewRequest(http.MethodGet, LongPollPath+"/id/down", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var got []string
+	r := bytes.NewReader(rec.Body.Bytes())
+	for {
+		data, err := ReadFrame(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(data))
+	}
+	if fmt.Sprint(got) != "[message-0 message-1 message-2]" {
+		t.Errorf("expected all the queued messages replied, got %v", got)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ws
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// upstreamMetrics are the metrics of the upstream queues of the nodes
+var upstreamMetrics = []struct {
+	name  string
+	help  string
+	kind  string
+	value func(s *UpstreamStats) int64
+}{
+	{
+		name:  "sedna_gm_upstream_queue_depth",
+		help:  "Number of the upstream messages of the node pending to be handled.",
+		kind:  "gauge",
+		value: func(s *UpstreamStats) int64 { return int64(s.Depth) },
+	},
+	{
+		name:  "sedna_gm_upstream_messages_received_total",
+		help:  "Total number of the upstream messages received from the node.",
+		kind:  "counter",
+		value: func(s *UpstreamStats) int64 { return s.Received },
+	},
+	{
+		name:  "sedna_gm_upstream_messages_dropped_total",
+		help:  "Total number of the upstream messages of the node dropped since its queue is full.",
+		kind:  "counter",
+		value: func(s *UpstreamStats) int64 { return s.Dropped },
+	},
+	{
+		name:  "sedna_gm_upstream_messages_coalesced_total",
+		help:  "Total number of the upstream status messages of the node superseded by the newer ones.",
+		kind:  "counter",
+		value: func(s *UpstreamStats) int64 { return s.Coalesced },
+	},
+}
+
+// WriteMetrics writes the metrics of the upstream queues in the Prometheus text format
+func WriteMetrics(w io.Writer) error {
+	return writeUpstreamMetrics(w, ListUpstreamStats())
+}
+
+func writeUpstreamMetrics(w io.Writer, stats []UpstreamStats) error {
+	bw := bufio.NewWriter(w)
+	for _, m := range upstreamMetrics {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for i := range stats {
+			fmt.Fprintf(bw, "%s{node=%s} %d\n", m.name, strconv.Quote(stats[i].NodeName), m.value(&stats[i]))
+		}
+	}
+	return bw.Flush()
+}
+
+// MetricsHandler serves the metrics of the upstream queues
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_ = WriteMetrics(w)
+	})
+}
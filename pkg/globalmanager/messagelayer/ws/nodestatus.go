@@ -0,0 +1,220 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ws
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/codec"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+)
+
+// NodeStatus is the connection status of the LC of a node
+type NodeStatus struct {
+	NodeName  string
+	Connected bool
+	LCVersion string
+
+	// ProtocolVersion and Capabilities are negotiated with LC,
+	// the protocol version is zero until the first message of LC is received
+	ProtocolVersion int
+	Capabilities    []string
+
+	// LastHeartbeat is the last time a message or a pong is received from the node
+	LastHeartbeat  time.Time
+	ConnectedAt    time.Time
+	DisconnectedAt time.Time
+	// LastError is the reason of the last disconnection
+	LastError string
+
+	// PendingMessages is the number of the downstream messages not written to the node
+	PendingMessages int
+
+	// BytesSent and BytesReceived are the sizes of the encoded messages, and PlainBytesSent
+	// and PlainBytesReceived are the sizes of them in JSON without compression
+	BytesSent          int64
+	BytesReceived      int64
+	PlainBytesSent     int64
+	PlainBytesReceived int64
+}
+
+// nodeState keeps the status of a node. A node may have more than one connection
+// for a while, since the new connection is served before the old one is closed.
+type nodeState struct {
+	lock        sync.Mutex
+	status      NodeStatus
+	connections int
+}
+
+func getNodeState(nodeName string) *nodeState {
+	s, ok := context.nodeStatus.Load(nodeName)
+	if !ok {
+		newS := &nodeState{status: NodeStatus{NodeName: nodeName}}
+		s, _ = context.nodeStatus.LoadOrStore(nodeName, newS)
+	}
+	return s.(*nodeState)
+}
+
+// notifyNode notifies the status of the node changed, the event is dropped if nobody consumes
+func notifyNode(nodeName string) {
+	select {
+	case context.nodeEvents <- nodeName:
+	default:
+	}
+}
+
+func markConnected(nodeName string) {
+	s := getNodeState(nodeName)
+	s.lock.Lock()
+	now := time.Now()
+	s.connections++
+	s.status.Connected = true
+	s.status.ConnectedAt = now
+	s.status.LastHeartbeat = now
+	// the protocol is told again by the new connection
+	s.status.ProtocolVersion = 0
+	s.status.Capabilities = nil
+	s.lock.Unlock()
+
+	notifyNode(nodeName)
+}
+
+func markDisconnected(nodeName string, err error) {
+	s := getNodeState(nodeName)
+	s.lock.Lock()
+	s.connections--
+	if err != nil {
+		s.status.LastError = err.Error()
+	}
+	if s.connections > 0 {
+		s.lock.Unlock()
+		return
+	}
+	s.connections = 0
+	s.status.Connected = false
+	s.status.DisconnectedAt = time.Now()
+	s.lock.Unlock()
+
+	notifyNode(nodeName)
+}
+
+// recordHeartbeat records the node is alive
+func recordHeartbeat(nodeName string) {
+	s := getNodeState(nodeName)
+	s.lock.Lock()
+	s.status.LastHeartbeat = time.Now()
+	s.lock.Unlock()
+}
+
+// plainSize returns the size of the message in JSON without compression,
+// which is the size of the encoded one if not encoded by the options
+func plainSize(msg *model.Message, data []byte, options codec.Options) int64 {
+	if options == (codec.Options{}) {
+		return int64(len(data))
+	}
+	plain, _ := json.Marshal(msg)
+	return int64(len(plain))
+}
+
+// recordReceived counts the bytes of the message received from the node
+func recordReceived(nodeName string, msg *model.Message, data []byte, options codec.Options) {
+	size := plainSize(msg, data, options)
+
+	s := getNodeState(nodeName)
+	s.lock.Lock()
+	s.status.BytesReceived += int64(len(data))
+	s.status.PlainBytesReceived += size
+	s.lock.Unlock()
+}
+
+// recordSent counts the bytes of the message sent to the node
+func recordSent(nodeName string, msg *model.Message, data []byte, options codec.Options) {
+	size := plainSize(msg, data, options)
+
+	s := getNodeState(nodeName)
+	s.lock.Lock()
+	s.status.BytesSent += int64(len(data))
+	s.status.PlainBytesSent += size
+	s.lock.Unlock()
+}
+
+func recordLCVersion(nodeName, version string) {
+	s := getNodeState(nodeName)
+	s.lock.Lock()
+	changed := s.status.LCVersion != version
+	s.status.LCVersion = version
+	s.lock.Unlock()
+
+	if changed {
+		notifyNode(nodeName)
+	}
+}
+
+// NodeEvents returns the channel of the names of the nodes whose connection changed
+func NodeEvents() <-chan string {
+	return context.nodeEvents
+}
+
+// GetNodeStatus returns the status of the node, false if the node never connects
+func GetNodeStatus(nodeName string) (NodeStatus, bool) {
+	s, ok := context.nodeStatus.Load(nodeName)
+	if !ok {
+		return NodeStatus{}, false
+	}
+	return s.(*nodeState).get(), true
+}
+
+// ForgetNode forgets the status of the node if it's not connected, true if it's forgotten
+func ForgetNode(nodeName string) bool {
+	v, ok := context.nodeStatus.Load(nodeName)
+	if !ok {
+		return true
+	}
+	s := v.(*nodeState)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.connections > 0 {
+		return false
+	}
+	context.nodeStatus.Delete(nodeName)
+	return true
+}
+
+// ListNodeStatus returns the status of the nodes ever connected, sorted by the node name
+func ListNodeStatus() []NodeStatus {
+	var list []NodeStatus
+	context.nodeStatus.Range(func(_, s interface{}) bool {
+		list = append(list, s.(*nodeState).get())
+		return true
+	})
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].NodeName < list[j].NodeName
+	})
+	return list
+}
+
+func (s *nodeState) get() NodeStatus {
+	s.lock.Lock()
+	status := s.status
+	s.lock.Unlock()
+
+	status.PendingMessages = len(getNodeStore(status.NodeName).ListKeys())
+	return status
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ws
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+)
+
+// fakeConn is a connection whose read fails with the error sent to it
+type fakeConn struct {
+	errCh   chan error
+	closeCh chan struct{}
+}
+
+func newFakeConn(nodeName string) *fakeConn {
+	c := &fakeConn{errCh: make(chan error), closeCh: make(chan struct{}, 2)}
+	AddNode(nodeName, c.read, c.write, c.closeCh)
+	return c
+}
+
+func (c *fakeConn) read() ([]byte, error) {
+	return nil, <-c.errCh
+}
+
+func (c *fakeConn) write([]byte) error {
+	// the downstream messages are kept pending
+	select {}
+}
+
+// close fails the read and waits for the connection closed
+func (c *fakeConn) close(err error) {
+	c.errCh <- err
+	<-c.closeCh
+}
+
+func TestNodeStatus(t *testing.T) {
+	const node = "status-node"
+
+	if _, ok := GetNodeStatus(node); ok {
+		t.Fatal("expected no status of the node never connected")
+	}
+
+	old := newFakeConn(node)
+	// the new connection is served before the old one is closed
+	cur := newFakeConn(node)
+
+	if err := ResyncNode(node, &model.Inventory{LCVersion: "v0.4.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	old.close(fmt.Errorf("connection reset"))
+	if ForgetNode(node) {
+		t.Error("expected the connected node not forgotten")
+	}
+	status, _ := GetNodeStatus(node)
+	if !status.Connected || status.LCVersion != "v0.4.0" || status.LastHeartbeat.IsZero() {
+		t.Errorf("expected the node connected with the new connection, got %+v", status)
+	}
+
+	before := time.Now()
+	cur.close(fmt.Errorf("i/o timeout"))
+	status, _ = GetNodeStatus(node)
+	if status.Connected || status.LastError != "i/o timeout" || status.DisconnectedAt.Before(before) {
+		t.Errorf("expected the node disconnected, got %+v", status)
+	}
+
+	if err := SendToEdge(node, newDatasetMessage("ds", "insert", "1")); err != nil {
+		t.Fatal(err)
+	}
+	status, _ = GetNodeStatus(node)
+	if status.PendingMessages != 1 {
+		t.Errorf("expected 1 pending message, got %d", status.PendingMessages)
+	}
+
+	found := false
+	for _, s := range ListNodeStatus() {
+		found = found || s.NodeName == node
+	}
+	if !found {
+		t.Errorf("expected node %s listed", node)
+	}
+
+	if !ForgetNode(node) {
+		t.Fatal("expected the disconnected node forgotten")
+	}
+	if _, ok := GetNodeStatus(node); ok {
+		t.Error("expected no status of the forgotten node")
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ws
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// specPatch returns the JSON merge patch(RFC 7386) of the spec between the two objects
+func specPatch(original, modified []byte) ([]byte, error) {
+	var o, m struct {
+		Spec map[string]interface{} `json:"spec"`
+	}
+	if err := json.Unmarshal(original, &o); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(modified, &m); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"spec": mergePatch(o.Spec, m.Spec),
+	})
+}
+
+// mergePatch returns the fields changed from original to modified,
+// the removed fields are null and the arrays are replaced wholly
+func mergePatch(original, modified map[string]interface{}) map[string]interface{} {
+	patch := make(map[string]interface{})
+	for key, value := range modified {
+		originalValue, ok := original[key]
+		if !ok {
+			patch[key] = value
+			continue
+		}
+
+		originalObject, ok1 := originalValue.(map[string]interface{})
+		object, ok2 := value.(map[string]interface{})
+		if ok1 && ok2 {
+			if p := mergePatch(originalObject, object); len(p) > 0 {
+				patch[key] = p
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(originalValue, value) {
+			patch[key] = value
+		}
+	}
+
+	for key := range original {
+		if _, ok := modified[key]; !ok {
+			patch[key] = nil
+		}
+	}
+	return patch
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ws
+
+import (
+	"encoding/json"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+)
+
+// workerTokenKey is the public key of the worker tokens told to the nodes in the sync reply
+var workerTokenKey []byte
+
+// SetWorkerTokenKey sets the public key of the worker tokens, which must be called before nodes connect
+func SetWorkerTokenKey(key []byte) {
+	workerTokenKey = key
+}
+
+// negotiate records the protocol of the node told by the message, and returns the reply telling
+// the protocol of GM. The node is legacy if the first message of the connection isn't the sync one.
+func negotiate(nodeName string, msg *model.Message, first bool) *model.Message {
+	version := msg.ProtocolVersion
+	capabilities := msg.Capabilities
+	if msg.Operation != model.SyncOperation {
+		if !first {
+			return nil
+		}
+		version, capabilities = model.LegacyProtocolVersion, nil
+	} else if version == 0 {
+		// the LC sending the inventory without the version
+		version = model.LegacyProtocolVersion
+	}
+
+	negotiated := model.NegotiateCapabilities(model.Capabilities(), capabilities)
+
+	s := getNodeState(nodeName)
+	s.lock.Lock()
+	s.status.ProtocolVersion = version
+	s.status.Capabilities = negotiated
+	s.lock.Unlock()
+	notifyNode(nodeName)
+
+	klog.Infof("node %s speaks protocol version %d, the negotiated capabilities are %v",
+		nodeName, version, negotiated)
+
+	if msg.Operation != model.SyncOperation || version == model.LegacyProtocolVersion {
+		return nil
+	}
+	content, _ := json.Marshal(&model.SyncReply{WorkerTokenKey: workerTokenKey})
+	return &model.Message{
+		MessageHeader: model.MessageHeader{
+			Operation:       model.SyncOperation,
+			ProtocolVersion: model.ProtocolVersion,
+			Capabilities:    negotiated,
+		},
+		Content: content,
+	}
+}
+
+// supports checks the node has the capability. The node is treated as legacy
+// without any capability until its protocol is known.
+func supports(nodeName, capability string) bool {
+	s := getNodeState(nodeName)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, c := range s.status.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiatedCapabilities returns the capabilities negotiated with the node, which are none
+// until the protocol of the node is known
+func negotiatedCapabilities(nodeName string) []string {
+	s := getNodeState(nodeName)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.status.Capabilities
+}
+
+// downgrade adapts the downstream message to the capabilities of the node,
+// and returns false if the message is dropped since the node can't handle it
+func downgrade(nodeName string, msg *model.Message) (*model.Message, bool) {
+	if msg.Operation == model.RejectOperation && !supports(nodeName, model.CapabilityReject) {
+		return nil, false
+	}
+
+	if (msg.Operation == model.UpdateOperation || len(msg.Patch) > 0) && !supports(nodeName, model.CapabilityPatch) {
+		// the legacy LC handles only insert and delete, and the insert of the full content
+		// applies the update
+		downgraded := *msg
+		downgraded.Patch = nil
+		if downgraded.Operation == model.UpdateOperation {
+			downgraded.Operation = model.InsertOperation
+		}
+		return &downgraded, true
+	}
+	return msg, true
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+)
+
+func TestNegotiateProtocol(t *testing.T) {
+	const node = "protocol-node"
+
+	update := newDatasetMessage("ds", "update", "2")
+	update.Patch = []byte(`{"spec":{}}`)
+	reject := &model.Message{MessageHeader: model.MessageHeader{Operation: model.RejectOperation}}
+
+	// the node is legacy until the protocol is known
+	if msg, ok := downgrade(node, update); !ok || msg.Patch != nil || msg.Operation != model.InsertOperation {
+		t.Errorf("expected the update sent as the insert to the node of unknown protocol, got %+v", msg)
+	}
+	if _, ok := downgrade(node, reject); ok {
+		t.Error("expected the rejection dropped for the node of unknown protocol")
+	}
+
+	// the capabilities unknown to GM are ignored
+	sync := &model.Message{MessageHeader: model.MessageHeader{
+		Operation:       model.SyncOperation,
+		ProtocolVersion: model.ProtocolVersion + 1,
+		Capabilities:    []string{"compression", model.CapabilityPatch, model.CapabilityAck},
+	}}
+	SetWorkerTokenKey([]byte("public-key"))
+	defer SetWorkerTokenKey(nil)
+	reply := negotiate(node, sync, true)
+	if reply == nil || reply.ProtocolVersion != model.ProtocolVersion {
+		t.Fatalf("expected the reply of protocol version %d, got %+v", model.ProtocolVersion, reply)
+	}
+	if got := fmt.Sprint(reply.Capabilities); got != "[ack patch]" {
+		t.Errorf("expected capabilities [ack patch], got %s", got)
+	}
+	var syncReply model.SyncReply
+	if err := json.Unmarshal(reply.Content, &syncReply); err != nil || string(syncReply.WorkerTokenKey) != "public-key" {
+		t.Errorf("expected the worker token key in the reply, got %s: %v", reply.Content, err)
+	}
+	if _, ok := downgrade(node, reject); ok {
+		t.Error("expected the rejection dropped for the node without the capability")
+	}
+	if msg, _ := downgrade(node, update); msg.Patch == nil || msg.Operation != model.UpdateOperation {
+		t.Error("expected the update with the patch sent to the node with the capability")
+	}
+
+	// the later messages don't change the protocol
+	if negotiate(node, update, false) != nil {
+		t.Error("expected no reply of the update")
+	}
+
+	// the legacy node doesn't send the sync message first
+	markConnected(node)
+	if negotiate(node, update, true) != nil {
+		t.Error("expected no reply to the legacy node")
+	}
+	status, _ := GetNodeStatus(node)
+	if status.ProtocolVersion != model.LegacyProtocolVersion || len(status.Capabilities) != 0 {
+		t.Errorf("expected the legacy protocol, got version %d with %v", status.ProtocolVersion, status.Capabilities)
+	}
+	msg, ok := downgrade(node, update)
+	if !ok || msg.Patch != nil || update.Patch == nil {
+		t.Error("expected the patch stripped from a copy of the update")
+	}
+	if msg.Operation != model.InsertOperation || update.Operation != model.UpdateOperation {
+		t.Errorf("expected the update sent as the insert to the legacy node, got %s", msg.Operation)
+	}
+
+	// the node negotiated without the patch capability
+	const noPatchNode = "no-patch-node"
+	negotiate(noPatchNode, &model.Message{MessageHeader: model.MessageHeader{
+		Operation:       model.SyncOperation,
+		ProtocolVersion: model.ProtocolVersion,
+		Capabilities:    []string{model.CapabilityAck},
+	}}, true)
+	plain := newDatasetMessage("ds", model.UpdateOperation, "3")
+	if msg, ok := downgrade(noPatchNode, plain); !ok || msg.Operation != model.InsertOperation {
+		t.Errorf("expected the update sent as the insert to the node without the patch capability, got %+v", msg)
+	}
+	if msg, _ := downgrade(noPatchNode, newDatasetMessage("ds", model.DeleteOperation, "4")); msg.Operation != model.DeleteOperation {
+		t.Errorf("expected the delete kept, got %s", msg.Operation)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ws
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+)
+
+// ResourceLister tells whether the resource exists in GM, e.g. by the informer listers
+type ResourceLister func(kind, namespace, name string) (bool, error)
+
+// resourceLister checks the resources on the nodes not synced to them before deleting them
+var resourceLister ResourceLister
+
+// SetResourceLister sets the lister of the resources in GM, which must be called before nodes connect.
+// Right after GM starts, the resources synced to the nodes are still being tracked as the informer
+// handlers send them, so the resources on the nodes are deleted only if they don't exist in GM.
+func SetResourceLister(lister ResourceLister) {
+	resourceLister = lister
+}
+
+// syncedResource is the latest message of the resource synced to the node
+type syncedResource struct {
+	msg             model.Message
+	resourceVersion string
+}
+
+// nodeResources keeps the resources synced to the node, i.e. the ones sent and not deleted
+type nodeResources struct {
+	lock      sync.Mutex
+	resources map[string]syncedResource
+}
+
+func getNodeResources(nodeName string) *nodeResources {
+	r, ok := context.nodeResources.Load(nodeName)
+	if !ok {
+		newR := &nodeResources{resources: make(map[string]syncedResource)}
+		r, _ = context.nodeResources.LoadOrStore(nodeName, newR)
+	}
+	return r.(*nodeResources)
+}
+
+// trackResource records the resource of the message sent to the node.
+// The update of the resource not on the node is sent as insert, otherwise the update
+// carries the spec patch relative to the resource last sent.
+func trackResource(nodeName string, msg *model.Message) {
+	if msg.Operation == model.RejectOperation || msg.Operation == model.AckOperation {
+		return
+	}
+
+	key, _ := getMsgKey(msg)
+	r := getNodeResources(nodeName)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if msg.Operation == model.DeleteOperation {
+		delete(r.resources, key)
+		return
+	}
+
+	if msg.Operation == model.UpdateOperation {
+		if previous, ok := r.resources[key]; ok {
+			patch, err := specPatch(previous.msg.Content, msg.Content)
+			if err != nil {
+				klog.Warningf("failed to create the spec patch of %s for node %s: %v", key, nodeName, err)
+			}
+			msg.Patch = patch
+		} else {
+			msg.Operation = model.InsertOperation
+		}
+	}
+
+	var om metav1.PartialObjectMetadata
+	_ = json.Unmarshal(msg.Content, &om)
+	r.resources[key] = syncedResource{msg: *msg, resourceVersion: om.ResourceVersion}
+}
+
+// ResyncNode sends the differences between the inventory of the node and the resources synced to it:
+// the resources missing or outdated on the node are sent again, and the others on the node are deleted.
+func ResyncNode(nodeName string, inventory *model.Inventory) error {
+	recordLCVersion(nodeName, inventory.LCVersion)
+
+	r := getNodeResources(nodeName)
+	r.lock.Lock()
+	synced := make(map[string]syncedResource, len(r.resources))
+	for key, resource := range r.resources {
+		synced[key] = resource
+	}
+	r.lock.Unlock()
+
+	existing := make(map[string]model.ResourceRef, len(inventory.Resources))
+	for _, ref := range inventory.Resources {
+		ref.Kind = strings.ToLower(ref.Kind)
+		key, _ := getMsgKey(&model.Message{MessageHeader: model.MessageHeader{
+			ResourceKind: ref.Kind,
+			Namespace:    ref.Namespace,
+			ResourceName: ref.Name,
+		}})
+		existing[key] = ref
+	}
+
+	var sent, deleted int
+	for key, resource := range synced {
+		if ref, ok := existing[key]; ok && ref.ResourceVersion != "" && ref.ResourceVersion == resource.resourceVersion {
+			continue
+		}
+
+		msg := resource.msg
+		if err := SendToEdge(nodeName, &msg); err != nil {
+			return err
+		}
+		sent++
+	}
+
+	var kept int
+	for key, ref := range existing {
+		if _, ok := synced[key]; ok {
+			continue
+		}
+
+		if resourceLister != nil {
+			exists, err := resourceLister(ref.Kind, ref.Namespace, ref.Name)
+			if err != nil || exists {
+				// the resource is sent or deleted by its controller
+				if err != nil {
+					klog.Warningf("kept %s on node %s since failed to check it exists: %v", key, nodeName, err)
+				}
+				kept++
+				continue
+			}
+		}
+
+		content, _ := json.Marshal(&metav1.PartialObjectMetadata{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ref.Namespace, Name: ref.Name},
+		})
+		msg := &model.Message{
+			MessageHeader: model.MessageHeader{
+				Namespace:    ref.Namespace,
+				ResourceKind: ref.Kind,
+				ResourceName: ref.Name,
+				Operation:    model.DeleteOperation,
+			},
+			Content: content,
+		}
+		if err := SendToEdge(nodeName, msg); err != nil {
+			return err
+		}
+		deleted++
+	}
+
+	klog.Infof("resynced node %s: %d resources on the node, %d sent, %d deleted, %d left to the controllers",
+		nodeName, len(existing), sent, deleted, kept)
+	return nil
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ws
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+)
+
+func newDatasetMessage(name, operation, resourceVersion string) *model.Message {
+	return &model.Message{
+		MessageHeader: model.MessageHeader{
+			Namespace:    "default",
+			ResourceKind: "dataset",
+			ResourceName: name,
+			Operation:    operation,
+		},
+		Content: []byte(fmt.Sprintf(`{"metadata":{"name":%q,"namespace":"default","resourceVersion":%q}}`,
+			name, resourceVersion)),
+	}
+}
+
+// pendingMessages returns the messages pending to send to the node, and clears them
+func pendingMessages(nodeName string) []string {
+	s := getNodeStore(nodeName)
+	var pending []string
+	for _, obj := range s.List() {
+		msg := obj.(*model.Message)
+		pending = append(pending, msg.Operation+":"+msg.ResourceName)
+		_ = s.Delete(obj)
+	}
+	sort.Strings(pending)
+	return pending
+}
+
+func TestResyncNode(t *testing.T) {
+	const node = "resync-node"
+
+	for _, msg := range []*model.Message{
+		newDatasetMessage("unchanged", "insert", "1"),
+		newDatasetMessage("deleted", "insert", "2"),
+		newDatasetMessage("deleted", "delete", "3"),
+		newDatasetMessage("updated", "insert", "4"),
+		newDatasetMessage("missing", "insert", "5"),
+		newDatasetMessage("unversioned", "insert", "6"),
+	} {
+		if err := SendToEdge(node, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pendingMessages(node)
+
+	inventory := &model.Inventory{Resources: []model.ResourceRef{
+		{Kind: "Dataset", Namespace: "default", Name: "unchanged", ResourceVersion: "1"},
+		{Kind: "dataset", Namespace: "default", Name: "deleted", ResourceVersion: "2"},
+		{Kind: "dataset", Namespace: "default", Name: "updated", ResourceVersion: "3"},
+		{Kind: "dataset", Namespace: "default", Name: "unversioned"},
+		{Kind: "dataset", Namespace: "default", Name: "unknown", ResourceVersion: "7"},
+	}}
+	if err := ResyncNode(node, inventory); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "[delete:deleted delete:unknown insert:missing insert:unversioned insert:updated]"
+	if got := fmt.Sprint(pendingMessages(node)); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+
+	// the node converges, nothing is sent again
+	inventory = &model.Inventory{Resources: []model.ResourceRef{
+		{Kind: "dataset", Namespace: "default", Name: "unchanged", ResourceVersion: "1"},
+		{Kind: "dataset", Namespace: "default", Name: "updated", ResourceVersion: "4"},
+		{Kind: "dataset", Namespace: "default", Name: "missing", ResourceVersion: "5"},
+		{Kind: "dataset", Namespace: "default", Name: "unversioned", ResourceVersion: "6"},
+	}}
+	if err := ResyncNode(node, inventory); err != nil {
+		t.Fatal(err)
+	}
+	if got := pendingMessages(node); len(got) != 0 {
+		t.Errorf("expected nothing sent after converged, got %v", got)
+	}
+}
+
+func TestResyncNodeAfterRestart(t *testing.T) {
+	const node = "restarted-node"
+
+	// GM restarted, and the informer handlers haven't sent the live resources to the node yet
+	SetResourceLister(func(kind, namespace, name string) (bool, error) {
+		if kind != "dataset" || namespace != "default" {
+			return false, fmt.Errorf("unexpected resource %s %s/%s", kind, namespace, name)
+		}
+		return name == "live", nil
+	})
+	defer SetResourceLister(nil)
+
+	inventory := &model.Inventory{Resources: []model.ResourceRef{
+		{Kind: "Dataset", Namespace: "default", Name: "live", ResourceVersion: "1"},
+		{Kind: "dataset", Namespace: "default", Name: "gone", ResourceVersion: "2"},
+	}}
+	if err := ResyncNode(node, inventory); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(pendingMessages(node)); got != "[delete:gone]" {
+		t.Errorf("expected only the resource not in GM deleted, got %s", got)
+	}
+
+	// the live resource is sent when its handler runs
+	if err := SendToEdge(node, newDatasetMessage("live", model.InsertOperation, "1")); err != nil {
+		t.Fatal(err)
+	}
+	pendingMessages(node)
+	if err := ResyncNode(node, inventory); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(pendingMessages(node)); got != "[delete:gone]" {
+		t.Errorf("expected the synced resource kept, got %s", got)
+	}
+}
+
+func TestSendUpdate(t *testing.T) {
+	const node = "update-node"
+
+	newJob := func(operation, spec string) *model.Message {
+		return &model.Message{
+			MessageHeader: model.MessageHeader{
+				Namespace:    "default",
+				ResourceKind: "incrementallearningjob",
+				ResourceName: "job",
+				Operation:    operation,
+			},
+			Content: []byte(`{"metadata":{"name":"job","namespace":"default"},"spec":` + spec + `}`),
+		}
+	}
+
+	// the update of the resource not on the node is sent as insert
+	msg := newJob("update", `{"dataset":{"name":"ds"},"trainSpec":{"trigger":{"threshold":100}}}`)
+	if err := SendToEdge(node, msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Operation != "insert" || msg.Patch != nil {
+		t.Errorf("expected insert without patch, got %s with patch %s", msg.Operation, msg.Patch)
+	}
+
+	msg = newJob("update", `{"trainSpec":{"trigger":{"threshold":50}},"credentialName":"s3"}`)
+	if err := SendToEdge(node, msg); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"spec":{"credentialName":"s3","dataset":null,"trainSpec":{"trigger":{"threshold":50}}}}`
+	if msg.Operation != "update" || string(msg.Patch) != want {
+		t.Errorf("expected update with patch %s, got %s with patch %s", want, msg.Operation, msg.Patch)
+	}
+}
@@ -17,28 +17,49 @@ limitations under the License.
 package ws
 
 import (
+	"crypto/tls"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"k8s.io/klog/v2"
 
 	"github.com/gorilla/websocket"
 
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/codec"
 	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
 )
 
+// ServerOptions defines the security options of the websocket server
+type ServerOptions struct {
+	// TLSConfig enables TLS if not nil
+	TLSConfig *tls.Config
+	// Authenticators authenticate the node of the connection, the first one having
+	// the credential of the request decides. The Node-Name header is trusted if empty.
+	Authenticators []Authenticator
+}
+
 // Server defines websocket protocol server
 type Server struct {
-	server *http.Server
+	server         *http.Server
+	authenticators []Authenticator
+
+	// sessions are the long-poll sessions by the id
+	sessions sync.Map
 }
 
 // NewServer creates a websocket server
-func NewServer(address string) *Server {
+func NewServer(address string, options ServerOptions) *Server {
 	server := http.Server{
-		Addr: address,
+		Addr:      address,
+		TLSConfig: options.TLSConfig,
 	}
 
 	wsServer := &Server{
-		server: &server,
+		server:         &server,
+		authenticators: options.Authenticators,
 	}
 	http.HandleFunc("/", wsServer.ServeHTTP)
 	return wsServer
@@ -56,8 +77,53 @@ func (srv *Server) upgrade(w http.ResponseWriter, r *http.Request) *websocket.Co
 	return conn
 }
 
+// authenticate checks the identity of the request is the node of the Node-Name header
+func (srv *Server) authenticate(req *http.Request) (int, error) {
+	return Authenticate(srv.authenticators, req)
+}
+
+// Authenticate checks the identity of the request is the node of the Node-Name header,
+// and returns the http status code of the result
+func Authenticate(authenticators []Authenticator, req *http.Request) (int, error) {
+	nodeName := req.Header.Get("Node-Name")
+	if nodeName == "" {
+		return http.StatusBadRequest, fmt.Errorf("no node name")
+	}
+
+	if len(authenticators) == 0 {
+		return http.StatusOK, nil
+	}
+
+	for _, a := range authenticators {
+		identity, ok, err := a.Authenticate(req)
+		if !ok {
+			continue
+		}
+		if err != nil {
+			return http.StatusUnauthorized, err
+		}
+		if identity != nodeName {
+			return http.StatusForbidden, fmt.Errorf("identity of node %s can't connect as node %s", identity, nodeName)
+		}
+		return http.StatusOK, nil
+	}
+
+	return http.StatusUnauthorized, fmt.Errorf("no valid credential")
+}
+
 func (srv *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if strings.HasPrefix(req.URL.Path, LongPollPath) {
+		srv.serveLongPoll(w, req)
+		return
+	}
+
 	nodeName := req.Header.Get("Node-Name")
+	if code, err := srv.authenticate(req); err != nil {
+		klog.Warningf("rejected the connection of node %s from %s: %v", nodeName, req.RemoteAddr, err)
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
 	wsConn := srv.upgrade(w, req)
 	if wsConn == nil {
 		klog.Errorf("failed to upgrade to websocket for node %s", nodeName)
@@ -71,6 +137,10 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 // ListenAndServe listens and serves the server
 func (srv *Server) ListenAndServe() error {
+	if srv.server.TLSConfig != nil {
+		// the certificates are in the TLS config
+		return srv.server.ListenAndServeTLS("", "")
+	}
 	return srv.server.ListenAndServe()
 }
 
@@ -86,21 +156,29 @@ type nodeClient struct {
 	conn     *websocket.Conn
 	req      *http.Request
 	nodeName string
+	// writeLock serializes the writes of the messages and the acks
+	writeLock sync.Mutex
 }
 
-func (nc *nodeClient) readOneMsg() (model.Message, error) {
-	var msg model.Message
-
-	err := nc.conn.ReadJSON(&msg)
+func (nc *nodeClient) readOneMsg() ([]byte, error) {
+	_, data, err := nc.conn.ReadMessage()
 	if err != nil {
-		return msg, err
+		return nil, err
 	}
 
-	return msg, nil
+	return data, nc.conn.SetReadDeadline(time.Now().Add(model.PongWait))
 }
 
-func (nc *nodeClient) writeOneMsg(msg model.Message) error {
-	return nc.conn.WriteJSON(&msg)
+func (nc *nodeClient) writeOneMsg(data []byte) error {
+	nc.writeLock.Lock()
+	defer nc.writeLock.Unlock()
+
+	// the messages not in JSON are binary
+	messageType := websocket.TextMessage
+	if !codec.IsJSON(data) {
+		messageType = websocket.BinaryMessage
+	}
+	return nc.conn.WriteMessage(messageType, data)
 }
 
 func (nc *nodeClient) Serve() {
@@ -109,9 +187,40 @@ func (nc *nodeClient) Serve() {
 	klog.Infof("established connection for node %s", nodeName)
 	// nc.conn.SetCloseHandler
 	closeCh := make(chan struct{}, 2)
+
+	// the half-open connection is closed when no message nor pong is received
+	_ = nc.conn.SetReadDeadline(time.Now().Add(model.PongWait))
+	nc.conn.SetPongHandler(func(string) error {
+		recordHeartbeat(nodeName)
+		return nc.conn.SetReadDeadline(time.Now().Add(model.PongWait))
+	})
+
 	AddNode(nodeName, nc.readOneMsg, nc.writeOneMsg, closeCh)
+
+	stopCh := make(chan struct{})
+	go nc.ping(stopCh)
 	<-closeCh
+	close(stopCh)
 
 	klog.Infof("closed connection for node %s", nodeName)
 	_ = nc.conn.Close()
 }
+
+// ping pings the node periodically until stopped
+func (nc *nodeClient) ping(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(model.PingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := nc.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(model.PongWait))
+			if err != nil {
+				klog.Warningf("failed to ping node %s: %v", nc.nodeName, err)
+				return
+			}
+		case <-stopCh:
+			return
+		}
+	}
+}
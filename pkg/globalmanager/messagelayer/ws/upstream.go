@@ -0,0 +1,411 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ws
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+)
+
+const (
+	// DefaultUpstreamQueueSize is the default size of the upstream queue of each node
+	DefaultUpstreamQueueSize = 100
+
+	// OverflowBlock blocks reading the messages of the node until its queue has room,
+	// the unacknowledged messages are kept by LC meanwhile
+	OverflowBlock = "block"
+	// OverflowDrop drops the oldest status message of the node to make room,
+	// the dropped message is rejected and kept by LC
+	OverflowDrop = "drop"
+
+	// overflowReason is the reason of the rejection of the dropped messages
+	overflowReason = "dropped since the upstream queue of the node is full"
+)
+
+// UpstreamOptions defines how the upstream messages of each node are queued
+type UpstreamOptions struct {
+	// QueueSize is the max number of the pending messages of each node
+	QueueSize int
+	// RateLimit is the max number of the messages per second of each node, zero is unlimited
+	RateLimit float64
+	// Burst is the max number of the messages of each node handled at once
+	Burst int
+	// Coalesce replaces the pending status message of a resource with the newer one
+	Coalesce bool
+	// OverflowPolicy is what to do when the queue of a node is full: block or drop
+	OverflowPolicy string
+}
+
+// UpstreamStats is the statistics of the upstream queue of a node
+type UpstreamStats struct {
+	NodeName string
+	// Depth is the number of the pending messages
+	Depth int
+	// Received, Dropped and Coalesced are the total numbers of the messages
+	// received, dropped since the queue is full, and superseded by the newer ones
+	Received  int64
+	Dropped   int64
+	Coalesced int64
+}
+
+// ReplyFunc sends the ack or reject of an upstream message to the node
+type ReplyFunc func(msg *model.Message)
+
+// upstreamConn is the connection of a node replying to its upstream messages
+type upstreamConn struct {
+	reply ReplyFunc
+}
+
+// nodeUpstream is the upstream queue of a node
+type nodeUpstream struct {
+	messages []model.Message
+	limiter  *rate.Limiter
+	// space is closed when a message is taken from the queue
+	space chan struct{}
+	stats UpstreamStats
+	// conn is the current connection of the node, nil when disconnected
+	conn *upstreamConn
+}
+
+// pendingReply is a reply to send once the lock of the queues is released
+type pendingReply struct {
+	reply ReplyFunc
+	msg   model.Message
+}
+
+// ackMessage returns the ack of the message
+func ackMessage(msg *model.Message) model.Message {
+	return model.Message{MessageHeader: model.MessageHeader{
+		Operation: model.AckOperation,
+		MessageID: msg.MessageID,
+	}}
+}
+
+// rejectMessage returns the rejection of the message
+func rejectMessage(msg *model.Message, reason string) model.Message {
+	payload, _ := json.Marshal(&model.Rejection{
+		MessageID: msg.MessageID,
+		Operation: msg.Operation,
+		Reason:    reason,
+	})
+	return model.Message{
+		MessageHeader: model.MessageHeader{
+			Namespace:    msg.Namespace,
+			ResourceKind: msg.ResourceKind,
+			ResourceName: msg.ResourceName,
+			Operation:    model.RejectOperation,
+		},
+		Content: payload,
+	}
+}
+
+// replyTo queues the reply to the message of the node if the edge keeps the message until replied
+func (n *nodeUpstream) replyTo(replies []pendingReply, msg *model.Message, reply model.Message) []pendingReply {
+	if n.conn == nil || msg.MessageID == 0 {
+		return replies
+	}
+	return append(replies, pendingReply{reply: n.conn.reply, msg: reply})
+}
+
+func sendReplies(replies []pendingReply) {
+	for i := range replies {
+		replies[i].reply(&replies[i].msg)
+	}
+}
+
+// upstreamQueues queues the upstream messages by node, and drains them in round robin,
+// so a chatty node doesn't stall the messages of the others
+type upstreamQueues struct {
+	lock    sync.Mutex
+	options UpstreamOptions
+	nodes   map[string]*nodeUpstream
+	// order is the round robin order of the nodes, and next is the index of the next node
+	order []string
+	next  int
+	// notify is signaled when a message is queued
+	notify chan struct{}
+}
+
+func newUpstreamQueues() *upstreamQueues {
+	return &upstreamQueues{
+		options: UpstreamOptions{QueueSize: DefaultUpstreamQueueSize, OverflowPolicy: OverflowBlock},
+		nodes:   make(map[string]*nodeUpstream),
+		notify:  make(chan struct{}, 1),
+	}
+}
+
+// SetUpstreamOptions sets the options of the upstream queues, which must be called before nodes connect
+func SetUpstreamOptions(options UpstreamOptions) {
+	if options.QueueSize <= 0 {
+		options.QueueSize = DefaultUpstreamQueueSize
+	}
+	if options.Burst <= 0 {
+		options.Burst = 1
+	}
+	if options.OverflowPolicy == "" {
+		options.OverflowPolicy = OverflowBlock
+	}
+
+	q := context.upstream
+	q.lock.Lock()
+	q.options = options
+	q.lock.Unlock()
+}
+
+// attach sets the connection of the node replying to its messages,
+// and returns the function detaching the connection when disconnected
+func (q *upstreamQueues) attach(nodeName string, reply ReplyFunc) func() {
+	conn := &upstreamConn{reply: reply}
+
+	q.lock.Lock()
+	q.getNode(nodeName).conn = conn
+	q.lock.Unlock()
+
+	return func() {
+		q.lock.Lock()
+		defer q.lock.Unlock()
+
+		n, ok := q.nodes[nodeName]
+		if !ok || n.conn != conn {
+			// replaced by the new connection
+			return
+		}
+		n.conn = nil
+		q.removeIfIdle(nodeName)
+	}
+}
+
+// removeIfIdle removes the node disconnected and without pending messages,
+// so the queues don't grow with the nodes come and gone
+func (q *upstreamQueues) removeIfIdle(nodeName string) {
+	n := q.nodes[nodeName]
+	if n.conn != nil || len(n.messages) > 0 || n.space != nil {
+		return
+	}
+
+	delete(q.nodes, nodeName)
+	for i, name := range q.order {
+		if name != nodeName {
+			continue
+		}
+		q.order = append(q.order[:i], q.order[i+1:]...)
+		if i < q.next {
+			q.next--
+		}
+		break
+	}
+	if q.next >= len(q.order) {
+		q.next = 0
+	}
+}
+
+func (q *upstreamQueues) getNode(nodeName string) *nodeUpstream {
+	n, ok := q.nodes[nodeName]
+	if !ok {
+		n = &nodeUpstream{stats: UpstreamStats{NodeName: nodeName}}
+		if q.options.RateLimit > 0 {
+			n.limiter = rate.NewLimiter(rate.Limit(q.options.RateLimit), q.options.Burst)
+		}
+		q.nodes[nodeName] = n
+		q.order = append(q.order, nodeName)
+	}
+	return n
+}
+
+// coalesce replaces the pending status message of the same resource, which is superseded and acked
+func (n *nodeUpstream) coalesce(msg *model.Message, replies []pendingReply) (bool, []pendingReply) {
+	if msg.Operation != model.StatusOperation {
+		return false, replies
+	}
+	for i := range n.messages {
+		m := &n.messages[i]
+		if m.Operation == msg.Operation && m.ResourceKind == msg.ResourceKind &&
+			m.Namespace == msg.Namespace && m.ResourceName == msg.ResourceName {
+			replies = n.replyTo(replies, m, ackMessage(m))
+			*m = *msg
+			n.stats.Coalesced++
+			return true, replies
+		}
+	}
+	return false, replies
+}
+
+// dropOldest drops and rejects the oldest status message, the other messages are never dropped
+func (n *nodeUpstream) dropOldest(replies []pendingReply) (bool, []pendingReply) {
+	for i := range n.messages {
+		if n.messages[i].Operation == model.StatusOperation {
+			replies = n.replyTo(replies, &n.messages[i], rejectMessage(&n.messages[i], overflowReason))
+			n.messages = append(n.messages[:i], n.messages[i+1:]...)
+			n.stats.Dropped++
+			return true, replies
+		}
+	}
+	return false, replies
+}
+
+// push queues the message of the node, blocks if the queue is full and no message can be dropped.
+// The message is acked when taken from the queue, and rejected when dropped.
+func (q *upstreamQueues) push(ctx gocontext.Context, nodeName string, msg model.Message) error {
+	for {
+		var replies []pendingReply
+		var queued, dropped bool
+
+		q.lock.Lock()
+		n := q.getNode(nodeName)
+		if q.options.Coalesce {
+			queued, replies = n.coalesce(&msg, replies)
+		}
+		if !queued && len(n.messages) >= q.options.QueueSize && q.options.OverflowPolicy == OverflowDrop {
+			if dropped, replies = n.dropOldest(replies); dropped {
+				klog.Warningf("dropped the oldest status message of node %s since its upstream queue is full", nodeName)
+			}
+		}
+		if !queued && len(n.messages) < q.options.QueueSize {
+			n.messages = append(n.messages, msg)
+			queued = true
+		}
+		if queued {
+			n.stats.Received++
+			q.lock.Unlock()
+
+			sendReplies(replies)
+
+			select {
+			case q.notify <- struct{}{}:
+			default:
+			}
+			return nil
+		}
+
+		if n.space == nil {
+			n.space = make(chan struct{})
+		}
+		space := n.space
+		q.lock.Unlock()
+
+		select {
+		case <-space:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// tryPop takes a message of the next node allowed by its rate limit, and acks it.
+// It returns how long to wait for a rate limited node if no message is taken.
+func (q *upstreamQueues) tryPop() (string, model.Message, bool, time.Duration) {
+	nodeName, msg, replies, ok, wait := q.take()
+	sendReplies(replies)
+	return nodeName, msg, ok, wait
+}
+
+// take takes a message of the next node allowed by its rate limit, with the ack of the message
+func (q *upstreamQueues) take() (string, model.Message, []pendingReply, bool, time.Duration) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	now := time.Now()
+	var wait time.Duration
+	for i := 0; i < len(q.order); i++ {
+		index := (q.next + i) % len(q.order)
+		nodeName := q.order[index]
+		n := q.nodes[nodeName]
+		if len(n.messages) == 0 {
+			continue
+		}
+
+		if n.limiter != nil {
+			r := n.limiter.ReserveN(now, 1)
+			if delay := r.DelayFrom(now); delay > 0 {
+				r.CancelAt(now)
+				if wait == 0 || delay < wait {
+					wait = delay
+				}
+				continue
+			}
+		}
+
+		msg := n.messages[0]
+		n.messages[0] = model.Message{}
+		n.messages = n.messages[1:]
+		if n.space != nil {
+			close(n.space)
+			n.space = nil
+		}
+		q.next = (index + 1) % len(q.order)
+		replies := n.replyTo(nil, &msg, ackMessage(&msg))
+		q.removeIfIdle(nodeName)
+		return nodeName, msg, replies, true, 0
+	}
+	return "", model.Message{}, nil, false, wait
+}
+
+// pop takes a message of the nodes in round robin, blocks until there is one
+func (q *upstreamQueues) pop(ctx gocontext.Context) (string, model.Message, error) {
+	for {
+		nodeName, msg, ok, wait := q.tryPop()
+		if ok {
+			return nodeName, msg, nil
+		}
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+		select {
+		case <-q.notify:
+		case <-timeout:
+		case <-ctx.Done():
+			return "", model.Message{}, ctx.Err()
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// stats returns the statistics of the upstream queues, sorted by the node name
+func (q *upstreamQueues) stats() []UpstreamStats {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	list := make([]UpstreamStats, 0, len(q.nodes))
+	for _, n := range q.nodes {
+		s := n.stats
+		s.Depth = len(n.messages)
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].NodeName < list[j].NodeName
+	})
+	return list
+}
+
+// ListUpstreamStats returns the statistics of the upstream queues of the nodes
+func ListUpstreamStats() []UpstreamStats {
+	return context.upstream.stats()
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ws
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+)
+
+func newStatusMessage(name, content string) model.Message {
+	return model.Message{
+		MessageHeader: model.MessageHeader{
+			Namespace:    "default",
+			ResourceKind: "dataset",
+			ResourceName: name,
+			Operation:    model.StatusOperation,
+		},
+		Content: []byte(content),
+	}
+}
+
+func newTestQueues(options UpstreamOptions) *upstreamQueues {
+	q := newUpstreamQueues()
+	q.options = options
+	return q
+}
+
+// popAll pops the messages as "node:name:content" until there is none
+func popAll(t *testing.T, q *upstreamQueues) []string {
+	var popped []string
+	for {
+		nodeName, msg, ok, wait := q.tryPop()
+		if !ok {
+			if wait > 0 {
+				t.Fatalf("unexpected rate limited for %s", wait)
+			}
+			return popped
+		}
+		popped = append(popped, fmt.Sprintf("%s:%s:%s", nodeName, msg.ResourceName, msg.Content))
+	}
+}
+
+func TestUpstreamFairness(t *testing.T) {
+	q := newTestQueues(UpstreamOptions{QueueSize: 10, OverflowPolicy: OverflowBlock})
+	ctx := gocontext.Background()
+
+	for i := 0; i < 4; i++ {
+		_ = q.push(ctx, "chatty", newStatusMessage(fmt.Sprint("ds-", i), "1"))
+	}
+	_ = q.push(ctx, "quiet", newStatusMessage("ds", "1"))
+
+	want := "chatty:ds-0:1 quiet:ds:1 chatty:ds-1:1 chatty:ds-2:1 chatty:ds-3:1"
+	if got := strings.Join(popAll(t, q), " "); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestUpstreamOverflow(t *testing.T) {
+	ctx := gocontext.Background()
+
+	q := newTestQueues(UpstreamOptions{QueueSize: 2, Coalesce: true, OverflowPolicy: OverflowDrop})
+	var replies []string
+	q.attach("node", func(msg *model.Message) {
+		id := msg.MessageID
+		if msg.Operation == model.RejectOperation {
+			var rejection model.Rejection
+			_ = json.Unmarshal(msg.Content, &rejection)
+			id = rejection.MessageID
+		}
+		replies = append(replies, fmt.Sprintf("%s:%d", msg.Operation, id))
+	})
+	withID := func(msg model.Message, id uint64) model.Message {
+		msg.MessageID = id
+		return msg
+	}
+
+	_ = q.push(ctx, "node", model.Message{MessageHeader: model.MessageHeader{Operation: model.SyncOperation, MessageID: 1}})
+	_ = q.push(ctx, "node", withID(newStatusMessage("a", "1"), 2))
+	// superseded
+	_ = q.push(ctx, "node", withID(newStatusMessage("a", "2"), 3))
+	// the oldest status is dropped, but the sync message is kept
+	_ = q.push(ctx, "node", withID(newStatusMessage("b", "1"), 4))
+
+	want := "node:: node:b:1"
+	if got := strings.Join(popAll(t, q), " "); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	stats := q.stats()
+	if len(stats) != 1 || stats[0].Received != 4 || stats[0].Coalesced != 1 || stats[0].Dropped != 1 || stats[0].Depth != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// the superseded message is acked, the dropped one is rejected, and the others are acked when taken
+	want = "ack:2 reject:3 ack:1 ack:4"
+	if got := strings.Join(replies, " "); got != want {
+		t.Errorf("expected replies %s, got %s", want, got)
+	}
+
+	// the push blocks until there is room
+	q = newTestQueues(UpstreamOptions{QueueSize: 1, OverflowPolicy: OverflowBlock})
+	_ = q.push(ctx, "node", newStatusMessage("a", "1"))
+	pushed := make(chan error)
+	go func() {
+		pushed <- q.push(ctx, "node", newStatusMessage("b", "1"))
+	}()
+	select {
+	case <-pushed:
+		t.Fatal("expected the push blocked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, msg, err := q.pop(ctx); err != nil || msg.ResourceName != "a" {
+		t.Fatalf("expected message a, got %s: %v", msg.ResourceName, err)
+	}
+	if err := <-pushed; err != nil {
+		t.Fatal(err)
+	}
+	if _, msg, err := q.pop(ctx); err != nil || msg.ResourceName != "b" {
+		t.Fatalf("expected message b, got %s: %v", msg.ResourceName, err)
+	}
+}
+
+func TestUpstreamRemovesIdleNodes(t *testing.T) {
+	q := newTestQueues(UpstreamOptions{QueueSize: 10, OverflowPolicy: OverflowBlock})
+	ctx := gocontext.Background()
+	nodes := func() string {
+		return fmt.Sprint(len(q.nodes), q.order)
+	}
+
+	detachOld := q.attach("edge1", func(*model.Message) {})
+	// reconnected before the old connection is closed
+	detach := q.attach("edge1", func(*model.Message) {})
+	detachOld()
+	q.attach("edge2", func(*model.Message) {})
+	if got := nodes(); got != "2 [edge1 edge2]" {
+		t.Fatalf("expected the nodes connected kept, got %s", got)
+	}
+
+	// the pending messages of the disconnected node are still taken
+	_ = q.push(ctx, "edge1", newStatusMessage("a", "1"))
+	detach()
+	if got := nodes(); got != "2 [edge1 edge2]" {
+		t.Fatalf("expected the node with pending messages kept, got %s", got)
+	}
+	if got := strings.Join(popAll(t, q), " "); got != "edge1:a:1" {
+		t.Errorf("expected the message of edge1, got %s", got)
+	}
+	if got := nodes(); got != "1 [edge2]" {
+		t.Errorf("expected the idle node removed, got %s", got)
+	}
+}
+
+func TestUpstreamRateLimit(t *testing.T) {
+	q := newTestQueues(UpstreamOptions{QueueSize: 10, RateLimit: 20, Burst: 1, OverflowPolicy: OverflowBlock})
+	ctx := gocontext.Background()
+
+	_ = q.push(ctx, "limited", newStatusMessage("a", "1"))
+	_ = q.push(ctx, "limited", newStatusMessage("b", "1"))
+
+	if _, _, ok, _ := q.tryPop(); !ok {
+		t.Fatal("expected the first message allowed by the burst")
+	}
+	_, _, ok, wait := q.tryPop()
+	if ok || wait <= 0 {
+		t.Fatalf("expected the second message rate limited, got %v, %s", ok, wait)
+	}
+
+	// the other nodes aren't limited by the limited one
+	_ = q.push(ctx, "other", newStatusMessage("c", "1"))
+	if nodeName, _, ok, _ := q.tryPop(); !ok || nodeName != "other" {
+		t.Fatalf("expected the message of node other, got %s", nodeName)
+	}
+
+	start := time.Now()
+	if _, msg, err := q.pop(ctx); err != nil || msg.ResourceName != "b" {
+		t.Fatalf("expected message b, got %s: %v", msg.ResourceName, err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("expected the message delayed by the rate limit, got %s", elapsed)
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	q := newTestQueues(UpstreamOptions{QueueSize: 10, OverflowPolicy: OverflowBlock})
+	_ = q.push(gocontext.Background(), "metrics-node", newStatusMessage("ds", "1"))
+
+	var buf bytes.Buffer
+	if err := writeUpstreamMetrics(&buf, q.stats()); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"# TYPE sedna_gm_upstream_queue_depth gauge",
+		`sedna_gm_upstream_queue_depth{node="metrics-node"} 1`,
+		`sedna_gm_upstream_messages_received_total{node="metrics-node"} 1`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("expected %q in the metrics:\n%s", line, buf.String())
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"reflect"
 	"strings"
 	"time"
 
@@ -177,3 +178,78 @@ func RetryUpdateStatus(name, namespace string, updateStatusFunc func() error) er
 	}
 	return err
 }
+
+// CheckBoundNode checks the node is one of the nodes which the resource is bound to
+func CheckBoundNode(nodeName string, boundNodes ...string) error {
+	for _, n := range boundNodes {
+		if n != "" && n == nodeName {
+			return nil
+		}
+	}
+	return fmt.Errorf("node %s is not bound to the resource", nodeName)
+}
+
+// SetNodeCondition sets the connectivity condition of the node, and returns the conditions and
+// whether they are changed. The node always connected has no condition.
+func SetNodeCondition(conditions []sednav1.NodeCondition, nodeName string, connected bool, message string) ([]sednav1.NodeCondition, bool) {
+	status, reason := v1.ConditionTrue, "Disconnected"
+	if connected {
+		status, reason, message = v1.ConditionFalse, "Connected", ""
+	}
+
+	// the conditions may be of the cached object, which are copied before changed
+	for i, c := range conditions {
+		if c.NodeName != nodeName || c.Type != sednav1.NodeCondDisconnected {
+			continue
+		}
+		if c.Status == status {
+			return conditions, false
+		}
+
+		newConditions := append([]sednav1.NodeCondition(nil), conditions...)
+		newConditions[i].Status = status
+		newConditions[i].LastTransitionTime = metav1.Now()
+		newConditions[i].Reason = reason
+		newConditions[i].Message = message
+		return newConditions, true
+	}
+
+	if connected {
+		return conditions, false
+	}
+	return append(append([]sednav1.NodeCondition(nil), conditions...), sednav1.NodeCondition{
+		NodeName:           nodeName,
+		Type:               sednav1.NodeCondDisconnected,
+		Status:             status,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}), true
+}
+
+// GenerationChanged returns true if the spec of the object is changed, which is told by
+// the generation since the status of the sedna resources is a subresource
+func GenerationChanged(old, cur interface{}) bool {
+	oldObj, ok := old.(metav1.Object)
+	if !ok {
+		return false
+	}
+	curObj, ok := cur.(metav1.Object)
+	if !ok {
+		return false
+	}
+	return oldObj.GetGeneration() != curObj.GetGeneration()
+}
+
+// AnnotationsChanged returns true if the annotations of the object are changed
+func AnnotationsChanged(old, cur interface{}) bool {
+	oldObj, ok := old.(metav1.Object)
+	if !ok {
+		return false
+	}
+	curObj, ok := cur.(metav1.Object)
+	if !ok {
+		return false
+	}
+	return !reflect.DeepEqual(oldObj.GetAnnotations(), curObj.GetAnnotations())
+}
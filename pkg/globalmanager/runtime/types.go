@@ -90,6 +90,20 @@ type UpstreamHandler = func(namespace, name, operation string, content []byte) e
 // UpstreamHandlerAddFunc defines the upstream controller register function for adding handler
 type UpstreamHandlerAddFunc = func(kind string, updateHandler UpstreamHandler) error
 
+// UpstreamAuthorizer checks whether the node is allowed to send the upstream updates of the resource,
+// i.e. the resource is bound to the node
+type UpstreamAuthorizer = func(nodeName, namespace, name string) error
+
+// UpstreamAuthorizerAddFunc defines the upstream controller register function for adding authorizer
+type UpstreamAuthorizerAddFunc = func(kind string, authorizer UpstreamAuthorizer) error
+
+// NodeConnectivityHandler handles the LC of the edge node connected to or disconnected from GM,
+// the message tells the reason of the disconnection
+type NodeConnectivityHandler = func(nodeName string, connected bool, message string)
+
+// NodeConnectivityHandlerAddFunc defines the edge node controller register function for adding handler
+type NodeConnectivityHandlerAddFunc = func(handler NodeConnectivityHandler) error
+
 // DownstreamSendFunc is the send function for feature controllers to sync the resource updates(spec and status) to LC
 type DownstreamSendFunc = func(nodeName string, eventType watch.EventType, obj interface{}) error
 
@@ -109,6 +123,20 @@ type FeatureControllerI interface {
 	SetUpstreamHandler(add UpstreamHandlerAddFunc) error
 }
 
+// UpstreamAuthorizerSetter is implemented by the feature controllers receiving the upstream updates,
+// the updates of the kinds without the authorizer are rejected.
+type UpstreamAuthorizerSetter interface {
+	// SetUpstreamAuthorizer sets up the upstream authorizer function for the feature controller
+	SetUpstreamAuthorizer(add UpstreamAuthorizerAddFunc) error
+}
+
+// NodeConnectivityHandlerSetter is implemented by the feature controllers whose resources are bound to
+// the edge nodes, which surface the connectivity of the nodes in the status of the resources.
+type NodeConnectivityHandlerSetter interface {
+	// SetNodeConnectivityHandler sets up the node connectivity handler function for the feature controller
+	SetNodeConnectivityHandler(add NodeConnectivityHandlerAddFunc) error
+}
+
 // ControllerContext defines the context that all feature controller share and belong to
 type ControllerContext struct {
 	Config *config.ControllerConfig
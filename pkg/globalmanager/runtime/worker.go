@@ -127,8 +127,8 @@ func CreateKubernetesService(kubeClient kubernetes.Interface, object CommonInter
 	return service.Spec.Ports[0].NodePort, nil
 }
 
-// injectWorkerParam modifies pod in-place
-func injectWorkerParam(pod *v1.Pod, workerParam *WorkerParam, object CommonInterface) {
+// injectWorkerParam modifies pod in-place, and returns the name of the secret of the worker token if injected
+func injectWorkerParam(pod *v1.Pod, workerParam *WorkerParam, object CommonInterface) string {
 	InjectStorageInitializer(pod, workerParam)
 
 	if workerParam.WorkerType == InferencePodType && workerParam.ModelHotUpdate.Enable {
@@ -136,6 +136,7 @@ func injectWorkerParam(pod *v1.Pod, workerParam *WorkerParam, object CommonInter
 		setModelHotUpdateEnv(workerParam)
 	}
 
+	tokenSecret := injectWorkerToken(&pod.Spec, workerParam, object)
 	envs := createEnvVars(workerParam.Env)
 	for idx := range pod.Spec.Containers {
 		pod.Spec.Containers[idx].Env = append(
@@ -169,13 +170,15 @@ func injectWorkerParam(pod *v1.Pod, workerParam *WorkerParam, object CommonInter
 	if workerParam.DNSPolicy != "" {
 		pod.Spec.DNSPolicy = workerParam.DNSPolicy
 	}
+
+	return tokenSecret
 }
 
 // CreatePodWithTemplate creates and returns a pod object given a crd object, pod template, and workerParam
 func CreatePodWithTemplate(client kubernetes.Interface, object CommonInterface, spec *v1.PodTemplateSpec, workerParam *WorkerParam) (*v1.Pod, error) {
 	objectKind := object.GroupVersionKind()
 	pod, _ := k8scontroller.GetPodFromTemplate(spec, object, metav1.NewControllerRef(object, objectKind))
-	injectWorkerParam(pod, workerParam, object)
+	tokenSecret := injectWorkerParam(pod, workerParam, object)
 
 	createdPod, err := client.CoreV1().Pods(object.GetNamespace()).Create(context.TODO(), pod, metav1.CreateOptions{})
 	objectName := object.GetNamespace() + "/" + object.GetName()
@@ -183,6 +186,14 @@ func CreatePodWithTemplate(client kubernetes.Interface, object CommonInterface,
 		klog.Warningf("failed to create pod(type=%s) for %s %s, err:%s", workerParam.WorkerType, objectKind, objectName, err)
 		return nil, err
 	}
+	if tokenSecret != "" {
+		owner := *metav1.NewControllerRef(createdPod, v1.SchemeGroupVersion.WithKind("Pod"))
+		if err := createWorkerTokenSecret(client, object, workerParam, tokenSecret, owner); err != nil {
+			klog.Warningf("failed to create pod(type=%s) for %s %s, err:%s", workerParam.WorkerType, objectKind, objectName, err)
+			_ = client.CoreV1().Pods(createdPod.Namespace).Delete(context.TODO(), createdPod.Name, metav1.DeleteOptions{})
+			return nil, err
+		}
+	}
 	klog.V(2).Infof("pod %s is created successfully for %s %s", createdPod.Name, objectKind, objectName)
 	return createdPod, nil
 }
@@ -237,13 +248,21 @@ func CreateDeploymentWithTemplate(client kubernetes.Interface, object CommonInte
 	objectName := object.GetNamespace() + "/" + object.GetName()
 	deployment := newDeployment(object, spec, workerParam)
 
-	injectDeploymentParam(deployment, workerParam, object, port)
+	tokenSecret := injectDeploymentParam(deployment, workerParam, object, port)
 
 	createdDeployment, err := client.AppsV1().Deployments(object.GetNamespace()).Create(context.TODO(), deployment, metav1.CreateOptions{})
 	if err != nil {
 		klog.Warningf("failed to create deployment for %s %s, err:%s", objectKind, objectName, err)
 		return nil, err
 	}
+	if tokenSecret != "" {
+		owner := *metav1.NewControllerRef(createdDeployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))
+		if err := createWorkerTokenSecret(client, object, workerParam, tokenSecret, owner); err != nil {
+			klog.Warningf("failed to create deployment for %s %s, err:%s", objectKind, objectName, err)
+			_ = client.AppsV1().Deployments(createdDeployment.Namespace).Delete(context.TODO(), createdDeployment.Name, metav1.DeleteOptions{})
+			return nil, err
+		}
+	}
 	klog.V(2).Infof("deployment %s is created successfully for %s %s", createdDeployment.Name, objectKind, objectName)
 	return createdDeployment, nil
 }
@@ -270,8 +289,8 @@ func newDeployment(object CommonInterface, spec *appsv1.DeploymentSpec, workerPa
 	}
 }
 
-// injectDeploymentParam modifies deployment in-place
-func injectDeploymentParam(deployment *appsv1.Deployment, workerParam *WorkerParam, object CommonInterface, _port int32) {
+// injectDeploymentParam modifies deployment in-place, and returns the name of the secret of the worker token if injected
+func injectDeploymentParam(deployment *appsv1.Deployment, workerParam *WorkerParam, object CommonInterface, _port int32) string {
 	var appLabelKey = "app.sedna.io"
 	var appLabelValue = object.GetName() + "-" + workerParam.WorkerType + "-" + "svc"
 
@@ -304,12 +323,15 @@ func injectDeploymentParam(deployment *appsv1.Deployment, workerParam *WorkerPar
 	deployment.Spec.Selector.MatchLabels[appLabelKey] = appLabelValue
 
 	// Env variables injection
+	tokenSecret := injectWorkerToken(&deployment.Spec.Template.Spec, workerParam, object)
 	envs := createEnvVars(workerParam.Env)
 	for idx := range deployment.Spec.Template.Spec.Containers {
 		deployment.Spec.Template.Spec.Containers[idx].Env = append(
 			deployment.Spec.Template.Spec.Containers[idx].Env, envs...,
 		)
 	}
+
+	return tokenSecret
 }
 
 // createEnvVars creates EnvMap for container
@@ -0,0 +1,309 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// WorkerTokenFileEnv is the env of the file of the token authenticating the messages of the worker to LC,
+	// the file is renewed before the token expires
+	WorkerTokenFileEnv = "WORKER_TOKEN_FILE"
+
+	// workerTokenSeedKey is the key of the seed of the signing key in the secret
+	workerTokenSeedKey = "ed25519-seed"
+
+	// workerTokenKey is the key of the token in the secret of the worker
+	workerTokenKey = "token"
+	// workerTokenLabel labels the secrets of the worker tokens, which are renewed by GM
+	workerTokenLabel  = "sedna.io/worker-token"
+	workerTokenVolume = "sedna-worker-token"
+	workerTokenDir    = "/var/run/sedna/worker-token"
+)
+
+// WorkerTokenClaims are what the token of a worker asserts, i.e. the worker belongs to the owner
+type WorkerTokenClaims struct {
+	// Kind is the lower case kind of the owner, e.g. incrementallearningjob
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// UID is of the owner, so the tokens of a deleted owner are invalid for the new one of the same name
+	UID string `json:"uid"`
+	// Worker is the name of the worker if known when issued
+	Worker string `json:"worker,omitempty"`
+
+	IssuedAt int64 `json:"iat"`
+	// ExpiresAt is zero if the token is valid as long as the owner
+	ExpiresAt int64 `json:"exp,omitempty"`
+}
+
+// WorkerTokenIssuer signs the tokens of the workers, which are verified by LC with the public key
+type WorkerTokenIssuer struct {
+	key ed25519.PrivateKey
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewWorkerTokenIssuer creates the issuer of the tokens valid for ttl, zero ttl is unlimited
+// and the tokens are never renewed
+func NewWorkerTokenIssuer(key ed25519.PrivateKey, ttl time.Duration) *WorkerTokenIssuer {
+	return &WorkerTokenIssuer{key: key, ttl: ttl, now: time.Now}
+}
+
+// PublicKey returns the public key verifying the tokens
+func (i *WorkerTokenIssuer) PublicKey() ed25519.PublicKey {
+	return i.key.Public().(ed25519.PublicKey)
+}
+
+// Issue issues the token of the worker of the object, whose format is "<claims>.<signature>" in base64url
+func (i *WorkerTokenIssuer) Issue(object CommonInterface, worker string) (string, error) {
+	now := i.now()
+	claims := WorkerTokenClaims{
+		Kind:      strings.ToLower(object.GroupVersionKind().Kind),
+		Namespace: object.GetNamespace(),
+		Name:      object.GetName(),
+		UID:       string(object.GetUID()),
+		Worker:    worker,
+		IssuedAt:  now.Unix(),
+	}
+	if i.ttl > 0 {
+		claims.ExpiresAt = now.Add(i.ttl).Unix()
+	}
+	return i.sign(&claims)
+}
+
+// Renew re-issues the token signed by the issuer with the same claims if it expires within half of the ttl,
+// and returns false if it isn't renewed
+func (i *WorkerTokenIssuer) Renew(token string) (string, bool, error) {
+	if i.ttl <= 0 {
+		return token, false, nil
+	}
+
+	claims, err := parseWorkerToken(i.PublicKey(), token)
+	if err != nil {
+		return "", false, err
+	}
+
+	now := i.now()
+	if claims.ExpiresAt != 0 && time.Unix(claims.ExpiresAt, 0).Sub(now) > i.ttl/2 {
+		return token, false, nil
+	}
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(i.ttl).Unix()
+	renewed, err := i.sign(claims)
+	return renewed, err == nil, err
+}
+
+// sign signs the claims, the format of the token is "<claims>.<signature>" in base64url
+func (i *WorkerTokenIssuer) sign(claims *WorkerTokenClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	signature := ed25519.Sign(i.key, []byte(encoded))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// VerifyWorkerToken verifies the token is signed by the key and not expired, and returns its claims
+func VerifyWorkerToken(key ed25519.PublicKey, token string, now time.Time) (*WorkerTokenClaims, error) {
+	claims, err := parseWorkerToken(key, token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.ExpiresAt != 0 && now.Unix() >= claims.ExpiresAt {
+		return nil, fmt.Errorf("token expired at %s", time.Unix(claims.ExpiresAt, 0).UTC().Format(time.RFC3339))
+	}
+	return claims, nil
+}
+
+// parseWorkerToken verifies the token is signed by the key, and returns its claims
+func parseWorkerToken(key ed25519.PublicKey, token string) (*WorkerTokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("malformed token")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+	if !ed25519.Verify(key, []byte(parts[0]), signature) {
+		return nil, fmt.Errorf("invalid token signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	var claims WorkerTokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	return &claims, nil
+}
+
+// LoadWorkerTokenKey gets the signing key of the worker tokens from the secret, which is created
+// with a new key if not existing, so the key is kept across the restarts and the replicas of GM
+func LoadWorkerTokenKey(client kubernetes.Interface, namespace, name string) (ed25519.PrivateKey, error) {
+	secrets := client.CoreV1().Secrets(namespace)
+	secret, err := secrets.Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		seed := make([]byte, ed25519.SeedSize)
+		if _, err := rand.Read(seed); err != nil {
+			return nil, err
+		}
+		secret, err = secrets.Create(context.TODO(), &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Data:       map[string][]byte{workerTokenSeedKey: seed},
+		}, metav1.CreateOptions{})
+		if errors.IsAlreadyExists(err) {
+			// created by another replica meanwhile
+			secret, err = secrets.Get(context.TODO(), name, metav1.GetOptions{})
+		} else if err == nil {
+			klog.Infof("created the signing key of the worker tokens in secret %s/%s", namespace, name)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the signing key of the worker tokens from secret %s/%s: %w", namespace, name, err)
+	}
+
+	seed := secret.Data[workerTokenSeedKey]
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("secret %s/%s has no %d bytes %s", namespace, name, ed25519.SeedSize, workerTokenSeedKey)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// workerTokenIssuer issues the tokens injected into the workers, nil if disabled
+var workerTokenIssuer *WorkerTokenIssuer
+
+// SetWorkerTokenIssuer sets the issuer of the worker tokens, which must be called before any worker created
+func SetWorkerTokenIssuer(issuer *WorkerTokenIssuer) {
+	workerTokenIssuer = issuer
+}
+
+// injectWorkerToken mounts the secret of the worker token into the containers of the pod if the issuer is set,
+// and returns the name of the secret, which is created by createWorkerTokenSecret after the worker is created
+func injectWorkerToken(podSpec *v1.PodSpec, workerParam *WorkerParam, object CommonInterface) string {
+	if workerTokenIssuer == nil {
+		return ""
+	}
+
+	secretName := strings.ToLower(object.GetName()+"-"+workerParam.WorkerType) + "-token-" + utilrand.String(5)
+	podSpec.Volumes = append(podSpec.Volumes, v1.Volume{
+		Name: workerTokenVolume,
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{SecretName: secretName},
+		},
+	})
+	for idx := range podSpec.Containers {
+		podSpec.Containers[idx].VolumeMounts = append(podSpec.Containers[idx].VolumeMounts, v1.VolumeMount{
+			Name:      workerTokenVolume,
+			MountPath: workerTokenDir,
+			ReadOnly:  true,
+		})
+	}
+
+	if workerParam.Env == nil {
+		workerParam.Env = make(map[string]string)
+	}
+	workerParam.Env[WorkerTokenFileEnv] = filepath.Join(workerTokenDir, workerTokenKey)
+	return secretName
+}
+
+// createWorkerTokenSecret creates the secret of the token of the worker, which is owned by the created worker,
+// so it's deleted with the worker. The worker waits for the secret to start.
+func createWorkerTokenSecret(client kubernetes.Interface, object CommonInterface, workerParam *WorkerParam,
+	secretName string, owner metav1.OwnerReference) error {
+	token, err := workerTokenIssuer.Issue(object, workerParam.Env["WORKER_NAME"])
+	if err != nil {
+		return fmt.Errorf("failed to issue the worker token: %w", err)
+	}
+
+	_, err = client.CoreV1().Secrets(object.GetNamespace()).Create(context.TODO(), &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       object.GetNamespace(),
+			Name:            secretName,
+			Labels:          map[string]string{workerTokenLabel: "true"},
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Data: map[string][]byte{workerTokenKey: []byte(token)},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create the secret %s of the worker token: %w", secretName, err)
+	}
+	return nil
+}
+
+// RenewWorkerTokens renews the tokens in the secrets of the workers in the namespace before they expire,
+// which the kubelet syncs into the workers, until stopCh is closed
+func RenewWorkerTokens(client kubernetes.Interface, issuer *WorkerTokenIssuer, namespace string, stopCh <-chan struct{}) {
+	if issuer.ttl <= 0 {
+		return
+	}
+
+	// the tokens are renewed within half of the ttl, so checked more often than that
+	wait.Until(func() {
+		renewWorkerTokens(client, issuer, namespace)
+	}, issuer.ttl/4, stopCh)
+}
+
+func renewWorkerTokens(client kubernetes.Interface, issuer *WorkerTokenIssuer, namespace string) {
+	secrets, err := client.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: workerTokenLabel + "=true",
+	})
+	if err != nil {
+		klog.Warningf("failed to list the secrets of the worker tokens: %v", err)
+		return
+	}
+
+	for idx := range secrets.Items {
+		secret := &secrets.Items[idx]
+		token, renewed, err := issuer.Renew(string(secret.Data[workerTokenKey]))
+		if err != nil {
+			klog.Warningf("failed to renew the worker token in secret %s/%s: %v", secret.Namespace, secret.Name, err)
+			continue
+		}
+		if !renewed {
+			continue
+		}
+
+		secret.Data[workerTokenKey] = []byte(token)
+		if _, err := client.CoreV1().Secrets(secret.Namespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+			klog.Warningf("failed to renew the worker token in secret %s/%s: %v", secret.Namespace, secret.Name, err)
+			continue
+		}
+		klog.V(4).Infof("renewed the worker token in secret %s/%s", secret.Namespace, secret.Name)
+	}
+}
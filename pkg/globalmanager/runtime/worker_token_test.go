@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
+)
+
+func TestWorkerTokenRenewed(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1600000000, 0)
+	issuer := NewWorkerTokenIssuer(key, time.Hour)
+	issuer.now = func() time.Time { return now }
+	SetWorkerTokenIssuer(issuer)
+	defer SetWorkerTokenIssuer(nil)
+
+	client := fake.NewSimpleClientset()
+	job := &sednav1.IncrementalLearningJob{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "job", UID: "uid"}}
+	job.SetGroupVersionKind(sednav1.SchemeGroupVersion.WithKind("IncrementalLearningJob"))
+	template := &v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "train"}}}}
+	workerParam := &WorkerParam{WorkerType: "train", Env: map[string]string{"WORKER_NAME": "train-worker"}}
+
+	pod, err := CreatePodWithTemplate(client, job, template, workerParam)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pod.Spec.Volumes) != 1 || pod.Spec.Volumes[0].Secret == nil {
+		t.Fatalf("expected the secret of the token mounted, got %+v", pod.Spec.Volumes)
+	}
+	if workerParam.Env[WorkerTokenFileEnv] != workerTokenDir+"/"+workerTokenKey {
+		t.Errorf("unexpected token file %q", workerParam.Env[WorkerTokenFileEnv])
+	}
+
+	secretName := pod.Spec.Volumes[0].Secret.SecretName
+	tokenOf := func() string {
+		secret, err := client.CoreV1().Secrets("default").Get(context.TODO(), secretName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(secret.Data[workerTokenKey])
+	}
+	token := tokenOf()
+	if _, err := VerifyWorkerToken(issuer.PublicKey(), token, now); err != nil {
+		t.Fatalf("invalid token: %v", err)
+	}
+
+	// not renewed until half of the ttl left
+	now = now.Add(20 * time.Minute)
+	renewWorkerTokens(client, issuer, metav1.NamespaceAll)
+	if tokenOf() != token {
+		t.Errorf("expected the token not renewed")
+	}
+
+	now = now.Add(20 * time.Minute)
+	renewWorkerTokens(client, issuer, metav1.NamespaceAll)
+	renewed := tokenOf()
+	if renewed == token {
+		t.Fatalf("expected the token renewed")
+	}
+	claims, err := VerifyWorkerToken(issuer.PublicKey(), renewed, now.Add(50*time.Minute))
+	if err != nil {
+		t.Fatalf("expected the renewed token valid for the ttl: %v", err)
+	}
+	if claims.Name != "job" || claims.UID != "uid" || claims.Worker != "train-worker" {
+		t.Errorf("expected the claims kept, got %+v", claims)
+	}
+}
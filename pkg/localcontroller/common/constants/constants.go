@@ -23,9 +23,21 @@ const (
 	// DataBaseURL is url of database
 	DataBaseURL = "/var/lib/sedna/database.db"
 
+	// CacheDir is the dir of the cache of the downloaded files
+	CacheDir = "/var/lib/sedna/cache"
+
+	// DatasetDir is the dir of the samples of the datasets
+	DatasetDir = "/var/lib/sedna/datasets"
+
+	// WorkerTokenKeyFile is the file of the public key of the worker tokens told by GM
+	WorkerTokenKeyFile = "/var/lib/sedna/worker-token.pub"
+
 	// WSScheme is the scheme of websocket
 	WSScheme = "ws"
 
+	// WSSScheme is the scheme of websocket over TLS
+	WSSScheme = "wss"
+
 	// WSHeaderNodeName is the name of header of websocket
 	WSHeaderNodeName = "Node-Name"
 
@@ -43,4 +55,43 @@ const (
 
 	// BindPortENV is the env of binding port
 	BindPortENV = "BIND_PORT"
+
+	// BindCertFileENV is the env of the certificate file of TLS on the binding port
+	BindCertFileENV = "BIND_CERT_FILE"
+
+	// BindKeyFileENV is the env of the key file of TLS on the binding port
+	BindKeyFileENV = "BIND_KEY_FILE"
+
+	// GMTLSENV is the env enabling TLS of the connection to GM, which is enabled if any GM TLS file is set
+	GMTLSENV = "GM_TLS"
+
+	// GMCAFileENV is the env of the CA file verifying GM
+	GMCAFileENV = "GM_CA_FILE"
+
+	// GMCertFileENV is the env of the client certificate file of mTLS to GM
+	GMCertFileENV = "GM_CERT_FILE"
+
+	// GMKeyFileENV is the env of the client key file of mTLS to GM
+	GMKeyFileENV = "GM_KEY_FILE"
+
+	// GMTokenFileENV is the env of the bearer token file authenticating to GM
+	GMTokenFileENV = "GM_TOKEN_FILE"
+
+	// GMTransportENV is the env of the transport to GM: websocket, grpc or mqtt
+	GMTransportENV = "GM_TRANSPORT"
+
+	// GMMQTTTopicPrefixENV is the env of the prefix of the node topics on the broker bridged by GM
+	GMMQTTTopicPrefixENV = "GM_MQTT_TOPIC_PREFIX"
+
+	// GMMessageEncodingENV is the env of the encoding of the messages with GM: json or protobuf
+	GMMessageEncodingENV = "GM_MESSAGE_ENCODING"
+
+	// GMCompressionENV is the env of the compression of the large contents with GM: gzip or none
+	GMCompressionENV = "GM_COMPRESSION"
+
+	// DatasetMaxVersionsENV is the env of the max number of the versions kept by each dataset
+	DatasetMaxVersionsENV = "DATASET_MAX_VERSIONS"
+
+	// CacheMaxSizeENV is the env of max size in MB of the cache of the downloaded files
+	CacheMaxSizeENV = "CACHE_MAX_SIZE_MB"
 )
@@ -39,6 +39,21 @@ type Resource struct {
 	Spec       string
 }
 
+// TriggerState defines the persisted state of a trigger (e.g., the train trigger of a job) table
+type TriggerState struct {
+	gorm.Model
+	Name  string `gorm:"unique"`
+	State string
+}
+
+// UpstreamMessage defines the message queued to send to GM table,
+// it's deleted when acknowledged by GM. Its id is AUTOINCREMENT, so the ids of the deleted
+// messages are never reused and the messages are sent and acknowledged by their ids.
+type UpstreamMessage struct {
+	gorm.Model
+	Message string
+}
+
 var dbClient *gorm.DB
 
 // SaveResource saves resource info in db
@@ -91,6 +106,16 @@ func GetResource(name string) (*Resource, error) {
 	return &r, nil
 }
 
+// ListResources lists all resources in db
+func ListResources() ([]Resource, error) {
+	var resources []Resource
+	if err := dbClient.Find(&resources).Error; err != nil {
+		return nil, err
+	}
+
+	return resources, nil
+}
+
 // DeleteResource deletes resource info in db
 func DeleteResource(name string) error {
 	var err error
@@ -112,6 +137,108 @@ func DeleteResource(name string) error {
 	return nil
 }
 
+// SaveTriggerState saves trigger state in db
+func SaveTriggerState(name string, state []byte) error {
+	r := TriggerState{}
+
+	queryResult := dbClient.Where("name = ?", name).First(&r)
+	if queryResult.RowsAffected == 0 {
+		newR := &TriggerState{
+			Name:  name,
+			State: string(state),
+		}
+		if err := dbClient.Create(newR).Error; err != nil {
+			klog.Errorf("failed to save trigger state(name=%s): %v", name, err)
+			return err
+		}
+		return nil
+	}
+
+	r.State = string(state)
+	if err := dbClient.Save(&r).Error; err != nil {
+		klog.Errorf("failed to update trigger state(name=%s): %v", name, err)
+		return err
+	}
+
+	return nil
+}
+
+// GetTriggerState gets trigger state in db
+func GetTriggerState(name string) ([]byte, error) {
+	r := TriggerState{}
+
+	queryResult := dbClient.Where("name = ?", name).First(&r)
+	if queryResult.RowsAffected == 0 {
+		return nil, fmt.Errorf("trigger state(name=%s) not in db", name)
+	}
+
+	return []byte(r.State), nil
+}
+
+// DeleteTriggerState deletes trigger state in db
+func DeleteTriggerState(name string) error {
+	if err := dbClient.Unscoped().Where("name = ?", name).Delete(&TriggerState{}).Error; err != nil {
+		klog.Errorf("failed to delete trigger state(name=%s): %v", name, err)
+		return err
+	}
+
+	return nil
+}
+
+// AddUpstreamMessage appends the message to the upstream queue in db, and returns its id
+func AddUpstreamMessage(message []byte) (uint, error) {
+	r := &UpstreamMessage{Message: string(message)}
+	if err := dbClient.Create(r).Error; err != nil {
+		klog.Errorf("failed to save upstream message: %v", err)
+		return 0, err
+	}
+
+	return r.ID, nil
+}
+
+// ListUpstreamMessages lists at most limit messages whose id is greater than afterID in order
+func ListUpstreamMessages(afterID uint, limit int) ([]UpstreamMessage, error) {
+	var messages []UpstreamMessage
+	if err := dbClient.Where("id > ?", afterID).Order("id").Limit(limit).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// DeleteUpstreamMessage deletes the message of the upstream queue in db
+func DeleteUpstreamMessage(id uint) error {
+	if err := dbClient.Unscoped().Delete(&UpstreamMessage{}, id).Error; err != nil {
+		klog.Errorf("failed to delete upstream message(id=%d): %v", id, err)
+		return err
+	}
+
+	return nil
+}
+
+// TrimUpstreamMessages deletes the oldest messages of the upstream queue in db
+// to keep at most max messages, and returns the number of the deleted messages
+func TrimUpstreamMessages(max int) (int, error) {
+	var count int64
+	if err := dbClient.Model(&UpstreamMessage{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	if count <= int64(max) {
+		return 0, nil
+	}
+
+	var oldest []UpstreamMessage
+	if err := dbClient.Select("id").Order("id").Limit(int(count) - max).Find(&oldest).Error; err != nil {
+		return 0, err
+	}
+	if len(oldest) == 0 {
+		return 0, nil
+	}
+
+	result := dbClient.Unscoped().Where("id <= ?", oldest[len(oldest)-1].ID).Delete(&UpstreamMessage{})
+	return int(result.RowsAffected), result.Error
+}
+
 func init() {
 	dbClient = getClient()
 }
@@ -134,12 +261,31 @@ func getClient() *gorm.DB {
 		}
 	}
 
+	return openClient(dbURL)
+}
+
+// openClient opens the db of the url, and migrates the tables
+func openClient(dbURL string) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open(dbURL), &gorm.Config{})
 	if err != nil {
 		klog.Errorf("try to connect the db failed, error: %v", err)
 	}
 
-	_ = db.AutoMigrate(&Resource{})
+	for _, stmt := range upstreamMessagesTable {
+		if err := db.Exec(stmt).Error; err != nil {
+			klog.Errorf("failed to create the upstream messages table, error: %v", err)
+		}
+	}
+	_ = db.AutoMigrate(&Resource{}, &TriggerState{}, &UpstreamMessage{})
 
 	return db
 }
+
+// upstreamMessagesTable defines the upstream messages table whose id is AUTOINCREMENT,
+// which gorm doesn't create for the primary key of sqlite
+var upstreamMessagesTable = []string{
+	"CREATE TABLE IF NOT EXISTS `upstream_messages` (" +
+		"`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`updated_at` datetime," +
+		"`deleted_at` datetime,`message` text)",
+	"CREATE INDEX IF NOT EXISTS `idx_upstream_messages_deleted_at` ON `upstream_messages`(`deleted_at`)",
+}
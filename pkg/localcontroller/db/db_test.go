@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// useTestClient uses a new db in the temp dir
+func useTestClient(t *testing.T) string {
+	dbURL := filepath.Join(t.TempDir(), "database.db")
+	previous := dbClient
+	dbClient = openClient(dbURL)
+	t.Cleanup(func() {
+		dbClient = previous
+	})
+	return dbURL
+}
+
+func TestUpstreamMessageIDsNotReused(t *testing.T) {
+	useTestClient(t)
+
+	var lastID uint
+	for i := 0; i < 3; i++ {
+		id, err := AddUpstreamMessage([]byte("message"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= lastID {
+			t.Fatalf("expected the id greater than %d after the queue drained, got %d", lastID, id)
+		}
+
+		// the message after the last sent one is listed
+		messages, err := ListUpstreamMessages(lastID, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(messages) != 1 || messages[0].ID != id {
+			t.Fatalf("expected the message %d listed after %d, got %+v", id, lastID, messages)
+		}
+
+		if err := DeleteUpstreamMessage(id); err != nil {
+			t.Fatal(err)
+		}
+		lastID = id
+	}
+
+	// the late ack of the deleted message doesn't delete the new one
+	id, _ := AddUpstreamMessage([]byte("new"))
+	if err := DeleteUpstreamMessage(lastID); err != nil {
+		t.Fatal(err)
+	}
+	if messages, _ := ListUpstreamMessages(0, 10); len(messages) != 1 || messages[0].ID != id {
+		t.Errorf("expected the new message %d kept, got %+v", id, messages)
+	}
+}
@@ -0,0 +1,538 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gmclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubeedge/sedna/cmd/sedna-lc/app/options"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/codec"
+	messagetypes "github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+	"github.com/kubeedge/sedna/pkg/version"
+)
+
+// Conn is a connection to GM of a transport, which reads and writes the encoded messages
+type Conn interface {
+	ReadMessage() ([]byte, error)
+	WriteMessage(data []byte) error
+	Close() error
+}
+
+// dialFunc connects to GM
+type dialFunc func() (Conn, error)
+
+// gmClient defines the client of GM, whose connection is dialed by the transport
+type gmClient struct {
+	Options             *options.LocalControllerOptions
+	SubscribeMessageMap map[string]MessageResourceHandler
+
+	// dial connects to GM by the transport
+	dial dialFunc
+	conn Conn
+
+	// queue keeps the upstream messages until acknowledged by GM
+	queue *upstreamQueue
+	// dispatcher handles the downstream messages of each resource in order
+	dispatcher *dispatcher
+
+	// inventory lists the resources of LC sent to GM when connected
+	inventory func(versioned bool) (*Inventory, error)
+	// connected is true if LC has connected to GM since it starts
+	connected bool
+	// upgradeFailures is the number of the consecutive failed websocket upgrades
+	upgradeFailures int
+	// webSocketRetryAt is when the websocket is tried again after falling back to the long-poll,
+	// and webSocketRetryBackoff is the interval doubled by each failed retry
+	webSocketRetryAt      time.Time
+	webSocketRetryBackoff time.Duration
+
+	protocolLock sync.Mutex
+	// gmProtocol is the protocol version and the negotiated capabilities told by GM,
+	// whose version is zero until GM replies the sync message
+	gmProtocol MessageHeader
+
+	// workerTokenKeyHandler handles the public key of the worker tokens told by GM
+	workerTokenKeyHandler func(key []byte)
+
+	statusLock sync.Mutex
+	// status is the state of the connection, recorded for the LC API
+	status ConnectionStatus
+}
+
+const (
+	encodingJSON     = "json"
+	encodingProtobuf = "protobuf"
+	compressionNone  = "none"
+)
+
+const (
+	// RetryCount is count of retrying to connecting to global manager
+	RetryCount = 5
+	// RetryConnectIntervalSeconds is interval time of retrying to connecting to global manager
+	RetryConnectIntervalSeconds = 5
+)
+
+// NewClient creates the client of the transport of the options,
+// the upstream messages are persisted in the LC db
+func NewClient(options *options.LocalControllerOptions) (ClientI, error) {
+	return newClient(options, dbMessageStore{})
+}
+
+// NewWebSocketClient creates the websocket client, the upstream messages are persisted in the LC db
+func NewWebSocketClient(options *options.LocalControllerOptions) ClientI {
+	return newWebSocketClient(options, dbMessageStore{})
+}
+
+func newClient(options *options.LocalControllerOptions, store MessageStore) (*gmClient, error) {
+	switch options.GMMessageEncoding {
+	case "", encodingJSON, encodingProtobuf:
+	default:
+		return nil, fmt.Errorf("unknown message encoding %s of global manager", options.GMMessageEncoding)
+	}
+	switch options.GMCompression {
+	case "", codec.GzipCompression, compressionNone:
+	default:
+		return nil, fmt.Errorf("unknown compression %s of global manager", options.GMCompression)
+	}
+
+	switch options.GMTransport {
+	case "", messagetypes.WebSocketTransport:
+		return newWebSocketClient(options, store), nil
+	case messagetypes.LongPollTransport:
+		return newLongPollClient(options, store), nil
+	case messagetypes.GRPCTransport:
+		return newGRPCClient(options, store), nil
+	case messagetypes.MQTTTransport:
+		return newMQTTClient(options, store), nil
+	default:
+		return nil, fmt.Errorf("unknown transport %s of global manager", options.GMTransport)
+	}
+}
+
+func newBaseClient(options *options.LocalControllerOptions, store MessageStore) *gmClient {
+	c := gmClient{
+		Options:             options,
+		SubscribeMessageMap: make(map[string]MessageResourceHandler),
+		queue:               newUpstreamQueue(store),
+		inventory:           dbInventory,
+	}
+	c.status.Transport = c.transport()
+	c.dispatcher = newDispatcher(c.handleResourceMessage)
+
+	return &c
+}
+
+// Subscribe registers in client
+func (c *gmClient) Subscribe(m MessageResourceHandler) error {
+	name := m.GetName()
+	if c.SubscribeMessageMap[name] == nil {
+		c.SubscribeMessageMap[name] = m
+	} else {
+		klog.Warningf("%s had been registered in the client of global manager", name)
+	}
+
+	return nil
+}
+
+// handleReceivedMessage handles received message
+func (c *gmClient) handleReceivedMessage(stop chan struct{}) {
+	defer func() {
+		stop <- struct{}{}
+	}()
+
+	conn := c.conn
+
+	for {
+		data, err := conn.ReadMessage()
+		if err != nil {
+			klog.Errorf("client received message from global manager(address: %s) failed, error: %v",
+				c.Options.GMAddr, err)
+			return
+		}
+
+		message := Message{}
+		if err := decode(data, &message); err != nil {
+			klog.Errorf("client received malformed message from global manager(address: %s), error: %v",
+				c.Options.GMAddr, err)
+			continue
+		}
+
+		klog.V(2).Infof("client received message header: %+v from global manager(address: %s)",
+			message.Header, c.Options.GMAddr)
+		klog.V(4).Infof("client received message content: %s from global manager(address: %s)",
+			message.Content, c.Options.GMAddr)
+
+		if message.Header.Operation == AckOperation {
+			if err := c.queue.Ack(message.Header.MessageID); err != nil {
+				klog.Errorf("failed to ack upstream message(id=%d): %v", message.Header.MessageID, err)
+			}
+			continue
+		}
+
+		if message.Header.Operation == SyncOperation {
+			c.setGMProtocol(message.Header)
+			c.handleSyncReply(message.Content)
+			continue
+		}
+
+		if message.Header.Operation == RejectOperation {
+			var rejection Rejection
+			_ = json.Unmarshal(message.Content, &rejection)
+			klog.Errorf("global manager rejected the %s update(id=%d) of %s %s/%s: %s",
+				rejection.Operation, rejection.MessageID, message.Header.ResourceKind,
+				message.Header.Namespace, message.Header.ResourceName, rejection.Reason)
+			continue
+		}
+
+		if c.SubscribeMessageMap[message.Header.ResourceKind] == nil {
+			klog.Errorf("%s hadn't registered in the client of global manager", message.Header.ResourceKind)
+			continue
+		}
+		c.dispatcher.Dispatch(&message)
+	}
+}
+
+// handleResourceMessage inserts, updates or deletes the resource of the message by its manager
+func (c *gmClient) handleResourceMessage(message *Message) {
+	m := c.SubscribeMessageMap[message.Header.ResourceKind]
+
+	var err error
+	switch message.Header.Operation {
+	case InsertOperation:
+		err = m.Insert(message)
+
+	case UpdateOperation:
+		err = m.Update(message)
+
+	case DeleteOperation:
+		err = m.Delete(message)
+	default:
+		err = fmt.Errorf("unknown operation: %s", message.Header.Operation)
+	}
+	if err != nil {
+		klog.Errorf("failed to handle message(%+v): %v", message.Header, err)
+	}
+}
+
+// WriteMessage saves message in the upstream queue, which is sent until acknowledged by GM
+func (c *gmClient) WriteMessage(messageBody interface{}, messageHeader MessageHeader) error {
+	content, err := json.Marshal(&messageBody)
+	if err != nil {
+		return err
+	}
+
+	message := Message{
+		Content: content,
+		Header:  messageHeader,
+	}
+
+	return c.queue.Push(message)
+}
+
+// sendMessage sends the messages of the upstream queue through the connection until done.
+// The messages not acknowledged by the previous connections are sent again.
+func (c *gmClient) sendMessage(stop chan struct{}, done <-chan struct{}) {
+	defer func() {
+		stop <- struct{}{}
+	}()
+
+	conn := c.conn
+
+	var lastID uint64
+	for {
+		messages, err := c.queue.Next(lastID, done)
+		if err != nil {
+			klog.Errorf("client failed to read the upstream queue, error: %v", err)
+			return
+		}
+		if len(messages) == 0 {
+			return
+		}
+
+		for i := range messages {
+			if err := c.sendOneMessage(conn, &messages[i]); err != nil {
+				return
+			}
+			lastID = messages[i].Header.MessageID
+		}
+	}
+}
+
+// sendOneMessage sends the message through the connection
+func (c *gmClient) sendOneMessage(conn Conn, message *Message) error {
+	data, err := c.encode(message)
+	if err != nil {
+		klog.Errorf("client failed to encode message(%+v), error: %v", message.Header, err)
+		return err
+	}
+
+	if err := conn.WriteMessage(data); err != nil {
+		klog.Errorf("client sent message to global manager(address: %s) failed, error: %v",
+			c.Options.GMAddr, err)
+		return err
+	}
+
+	klog.V(2).Infof("client sent message header: %+v to global manager(address: %s)",
+		message.Header, c.Options.GMAddr)
+	klog.V(4).Infof("client sent message content: %s to global manager(address: %s)",
+		message.Content, c.Options.GMAddr)
+	return nil
+}
+
+// readToken reads the bearer token authenticating to GM, which is empty if no token file
+func (c *gmClient) readToken() (string, error) {
+	if c.Options.GMTokenFile == "" {
+		return "", nil
+	}
+
+	token, err := ioutil.ReadFile(c.Options.GMTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the token file %s: %w", c.Options.GMTokenFile, err)
+	}
+	return strings.TrimSpace(string(token)), nil
+}
+
+// sendInventory sends the resources of LC to GM, and GM sends the differences back
+// so that the resources of LC converge to GM's
+func (c *gmClient) sendInventory(conn Conn) error {
+	// the managers keep the resources in memory since the first connection
+	inventory, err := c.inventory(c.connected)
+	if err != nil {
+		return fmt.Errorf("failed to list the inventory: %w", err)
+	}
+	inventory.LCVersion = version.Get().GitVersion
+
+	content, err := json.Marshal(inventory)
+	if err != nil {
+		return err
+	}
+
+	klog.Infof("client sends the inventory of %d resources to global manager(address: %s)",
+		len(inventory.Resources), c.Options.GMAddr)
+	// the sync message is in JSON, since the protocol of GM is unknown
+	c.setGMProtocol(MessageHeader{})
+	data, err := c.encode(&Message{
+		Header: MessageHeader{
+			Operation:       SyncOperation,
+			ProtocolVersion: messagetypes.ProtocolVersion,
+			Capabilities:    c.capabilities(),
+		},
+		Content: content,
+	})
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(data)
+}
+
+// capabilities returns the capabilities of LC, without the encoding and compression not enabled
+func (c *gmClient) capabilities() []string {
+	var capabilities []string
+	for _, capability := range messagetypes.Capabilities() {
+		if capability == messagetypes.CapabilityProtobuf && c.Options.GMMessageEncoding != encodingProtobuf {
+			continue
+		}
+		if capability == messagetypes.CapabilityGzip && c.Options.GMCompression == compressionNone {
+			continue
+		}
+		capabilities = append(capabilities, capability)
+	}
+	return capabilities
+}
+
+// encode encodes the message by the capabilities negotiated with GM
+func (c *gmClient) encode(message *Message) ([]byte, error) {
+	c.protocolLock.Lock()
+	capabilities := c.gmProtocol.Capabilities
+	c.protocolLock.Unlock()
+
+	msg := messagetypes.Message{
+		MessageHeader: message.Header,
+		Content:       message.Content,
+		Patch:         message.Patch,
+	}
+	return codec.Marshal(&msg, codec.OptionsOf(capabilities))
+}
+
+// decode decodes the message in either encoding
+func decode(data []byte, message *Message) error {
+	var msg messagetypes.Message
+	if err := codec.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+
+	*message = Message{
+		Header:  msg.MessageHeader,
+		Content: msg.Content,
+		Patch:   msg.Patch,
+	}
+	return nil
+}
+
+// setGMProtocol records the protocol of GM told in the reply of the sync message
+func (c *gmClient) setGMProtocol(header MessageHeader) {
+	c.protocolLock.Lock()
+	c.gmProtocol = MessageHeader{
+		ProtocolVersion: header.ProtocolVersion,
+		Capabilities:    header.Capabilities,
+	}
+	c.protocolLock.Unlock()
+
+	if header.ProtocolVersion != 0 {
+		klog.Infof("global manager(address: %s) speaks protocol version %d, the negotiated capabilities are %v",
+			c.Options.GMAddr, header.ProtocolVersion, header.Capabilities)
+	}
+}
+
+// OnWorkerTokenKey registers the handler of the public key of the worker tokens told by GM when connected,
+// which is empty if GM doesn't issue the tokens. It must be called before the client starts.
+func (c *gmClient) OnWorkerTokenKey(handler func(key []byte)) {
+	c.workerTokenKeyHandler = handler
+}
+
+// handleSyncReply handles the content of the reply of the sync message
+func (c *gmClient) handleSyncReply(content []byte) {
+	var reply SyncReply
+	if len(content) > 0 {
+		if err := json.Unmarshal(content, &reply); err != nil {
+			klog.Errorf("client received malformed sync reply from global manager(address: %s), error: %v",
+				c.Options.GMAddr, err)
+			return
+		}
+	}
+
+	if c.workerTokenKeyHandler != nil {
+		c.workerTokenKeyHandler(reply.WorkerTokenKey)
+	}
+}
+
+// connect tries to connect remote server
+func (c *gmClient) connect() error {
+	klog.Infof("client starts to connect global manager(address: %s)", c.Options.GMAddr)
+
+	for i := 0; i < RetryCount; i++ {
+		conn, err := c.dial()
+		if err == nil {
+			if err = c.sendInventory(conn); err != nil {
+				_ = conn.Close()
+			}
+		}
+
+		if err == nil {
+			c.conn = conn
+			c.connected = true
+			c.setStatus(true, nil)
+			klog.Infof("client connects global manager(address: %s) successful", c.Options.GMAddr)
+
+			return nil
+		}
+
+		c.setStatus(false, err)
+		klog.Errorf("client tries to connect global manager(address: %s) failed, error: %v",
+			c.Options.GMAddr, err)
+
+		time.Sleep(time.Duration(RetryConnectIntervalSeconds) * time.Second)
+	}
+
+	errorMsg := fmt.Errorf("max retry count reached when connecting global manager(address: %s)",
+		c.Options.GMAddr)
+	klog.Errorf("%v", errorMsg)
+
+	return errorMsg
+}
+
+// Start starts the client
+func (c *gmClient) Start() error {
+	go c.reconnect()
+
+	return nil
+}
+
+// reconnect reconnects global manager
+func (c *gmClient) reconnect() {
+	for {
+		if err := c.connect(); err != nil {
+			continue
+		}
+		conn := c.conn
+
+		stop := make(chan struct{}, 2)
+		done := make(chan struct{})
+		go c.handleReceivedMessage(stop)
+		go c.sendMessage(stop, done)
+		<-stop
+
+		// wait for both loops to exit before the next connection
+		close(done)
+		_ = conn.Close()
+		<-stop
+		c.setStatus(false, nil)
+	}
+}
+
+// transport returns the transport in use, which must be called by the reconnecting loop
+func (c *gmClient) transport() string {
+	switch c.Options.GMTransport {
+	case "", messagetypes.WebSocketTransport:
+		if c.upgradeFailures >= LongPollFallbackCount {
+			return messagetypes.LongPollTransport
+		}
+		return messagetypes.WebSocketTransport
+	default:
+		return c.Options.GMTransport
+	}
+}
+
+// setStatus records the state of the connection, the last error is kept until connected
+func (c *gmClient) setStatus(connected bool, err error) {
+	c.statusLock.Lock()
+	defer c.statusLock.Unlock()
+
+	if c.status.Connected != connected || c.status.Since.IsZero() {
+		c.status.Since = time.Now()
+	}
+	c.status.Connected = connected
+	c.status.Transport = c.transport()
+	if connected {
+		c.status.LastError = ""
+	} else if err != nil {
+		c.status.LastError = err.Error()
+	}
+}
+
+// Status returns the state of the connection to GM
+func (c *gmClient) Status() ConnectionStatus {
+	c.statusLock.Lock()
+	status := c.status
+	c.statusLock.Unlock()
+
+	status.Address = c.Options.GMAddr
+
+	c.protocolLock.Lock()
+	status.ProtocolVersion = c.gmProtocol.ProtocolVersion
+	status.Capabilities = append([]string(nil), c.gmProtocol.Capabilities...)
+	c.protocolLock.Unlock()
+
+	return status
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gmclient
+
+import (
+	"sync"
+)
+
+// dispatcher handles the downstream messages of each resource one at a time in the received order,
+// e.g. an update is never applied before the insert of the resource, while the messages of different
+// resources are handled concurrently, so a slow handler doesn't block other resources.
+type dispatcher struct {
+	handle func(message *Message)
+
+	lock sync.Mutex
+	// queues are the pending messages of the resources being handled, keyed by the resource
+	queues map[string][]*Message
+}
+
+func newDispatcher(handle func(message *Message)) *dispatcher {
+	return &dispatcher{
+		handle: handle,
+		queues: make(map[string][]*Message),
+	}
+}
+
+// resourceKey returns the key of the resource of the message
+func resourceKey(header MessageHeader) string {
+	return header.ResourceKind + "/" + header.Namespace + "/" + header.ResourceName
+}
+
+// Dispatch queues the message after the pending messages of the same resource
+func (d *dispatcher) Dispatch(message *Message) {
+	key := resourceKey(message.Header)
+
+	d.lock.Lock()
+	queue, running := d.queues[key]
+	d.queues[key] = append(queue, message)
+	d.lock.Unlock()
+
+	if !running {
+		go d.run(key)
+	}
+}
+
+// run handles the messages of the resource until its queue is empty
+func (d *dispatcher) run(key string) {
+	for {
+		d.lock.Lock()
+		queue := d.queues[key]
+		if len(queue) == 0 {
+			delete(d.queues, key)
+			d.lock.Unlock()
+			return
+		}
+		message := queue[0]
+		// the empty queue is kept until the message is handled, so the next message waits
+		d.queues[key] = queue[1:]
+		d.lock.Unlock()
+
+		d.handle(message)
+	}
+}
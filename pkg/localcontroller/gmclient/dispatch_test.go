@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gmclient
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// orderHandler records the operations of the datasets in the order handled
+type orderHandler struct {
+	lock    sync.Mutex
+	handled []string
+
+	// blocked is the dataset whose insert waits for the release
+	blocked string
+	release chan struct{}
+	done    chan struct{}
+}
+
+func (h *orderHandler) GetName() string {
+	return "dataset"
+}
+
+func (h *orderHandler) record(operation string, message *Message) error {
+	name := message.Header.ResourceName
+	if operation == InsertOperation && name == h.blocked {
+		<-h.release
+	}
+	if operation == InsertOperation && name != h.blocked {
+		close(h.release)
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.handled = append(h.handled, operation+":"+name)
+	if len(h.handled) == 4 {
+		close(h.done)
+	}
+	return nil
+}
+
+func (h *orderHandler) Insert(message *Message) error {
+	return h.record(InsertOperation, message)
+}
+
+func (h *orderHandler) Update(message *Message) error {
+	return h.record(UpdateOperation, message)
+}
+
+func (h *orderHandler) Delete(message *Message) error {
+	return h.record(DeleteOperation, message)
+}
+
+func TestDownstreamMessagesInOrder(t *testing.T) {
+	message := func(operation, name string) Message {
+		return Message{Header: MessageHeader{
+			Namespace:    "default",
+			ResourceKind: "dataset",
+			ResourceName: name,
+			Operation:    operation,
+		}}
+	}
+
+	// the insert, update and delete of ds are sent back to back, while its insert is slow
+	gm := &fakeGM{downstream: []Message{
+		message(InsertOperation, "ds"),
+		message(UpdateOperation, "ds"),
+		message(DeleteOperation, "ds"),
+		message(InsertOperation, "other"),
+	}}
+	c := newTestClient(t, gm, &memoryMessageStore{})
+	handler := &orderHandler{blocked: "ds", release: make(chan struct{}), done: make(chan struct{})}
+	_ = c.Subscribe(handler)
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-handler.done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for the messages handled")
+	}
+
+	// the other dataset is not blocked by ds, and the messages of ds are handled in order
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+	expected := []string{"insert:other", "insert:ds", "update:ds", "delete:ds"}
+	if !reflect.DeepEqual(handler.handled, expected) {
+		t.Errorf("expected %v handled, got %v", expected, handler.handled)
+	}
+}
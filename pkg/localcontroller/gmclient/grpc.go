@@ -0,0 +1,140 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gmclient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/kubeedge/sedna/cmd/sedna-lc/app/options"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/grpcstream"
+	messagetypes "github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+)
+
+// ConnectTimeoutSeconds is the timeout of establishing the stream to GM
+const ConnectTimeoutSeconds = 10
+
+// grpcConn is the gRPC stream to GM
+type grpcConn struct {
+	conn   *grpc.ClientConn
+	stream grpcstream.MessageLayer_StreamClient
+	cancel context.CancelFunc
+
+	writeLock sync.Mutex
+}
+
+func (c *grpcConn) ReadMessage() ([]byte, error) {
+	frame, err := c.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return frame.Data, nil
+}
+
+func (c *grpcConn) WriteMessage(data []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	return c.stream.Send(&grpcstream.Frame{Data: data})
+}
+
+func (c *grpcConn) Close() error {
+	c.cancel()
+	return c.conn.Close()
+}
+
+func newGRPCClient(options *options.LocalControllerOptions, store MessageStore) *gmClient {
+	c := newBaseClient(options, store)
+	c.dial = c.dialGRPC
+	return c
+}
+
+// dialGRPC establishes the gRPC stream to GM, and waits for GM accepting it
+func (c *gmClient) dialGRPC() (Conn, error) {
+	tlsConfig, err := c.newTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	token, err := c.readToken()
+	if err != nil {
+		return nil, err
+	}
+
+	dialOptions := []grpc.DialOption{
+		grpc.WithBlock(),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(grpcstream.MaxMessageSize),
+			grpc.MaxCallSendMsgSize(grpcstream.MaxMessageSize),
+		),
+		// the half-open connection is closed when the ping isn't answered
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                messagetypes.PingPeriod,
+			Timeout:             messagetypes.PongWait - messagetypes.PingPeriod,
+			PermitWithoutStream: true,
+		}),
+	}
+	if tlsConfig != nil {
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		dialOptions = append(dialOptions, grpc.WithInsecure())
+	}
+
+	// the stream lasts after established, so only the establishment is timed out
+	dialCtx, cancelDial := context.WithTimeout(context.Background(), ConnectTimeoutSeconds*time.Second)
+	defer cancelDial()
+	conn, err := grpc.DialContext(dialCtx, c.Options.GMAddr, dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	md := metadata.Pairs(grpcstream.NodeNameKey, c.Options.NodeName)
+	if token != "" {
+		md.Set(grpcstream.AuthorizationKey, "Bearer "+token)
+	}
+	ctx, cancel := context.WithCancel(metadata.NewOutgoingContext(context.Background(), md))
+	stream, err := grpcstream.NewMessageLayerClient(conn).Stream(ctx)
+	if err == nil {
+		timer := time.AfterFunc(ConnectTimeoutSeconds*time.Second, cancel)
+		var header metadata.MD
+		header, err = stream.Header()
+		if err == nil && len(header.Get(grpcstream.NodeNameKey)) == 0 {
+			// the rejected stream ends with the status instead of the headers
+			if _, err = stream.Recv(); err == nil {
+				err = fmt.Errorf("stream not accepted by GM")
+			}
+		}
+		timer.Stop()
+	}
+	if err != nil {
+		cancel()
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return &grpcConn{
+		conn:   conn,
+		stream: stream,
+		cancel: cancel,
+	}, nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gmclient
+
+import (
+	"encoding/json"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeedge/sedna/pkg/localcontroller/db"
+)
+
+// dbInventory lists the resources saved in the LC db. The resource versions are omitted
+// if the resources need to be sent again, e.g. the managers have no resources since LC starts.
+func dbInventory(versioned bool) (*Inventory, error) {
+	resources, err := db.ListResources()
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := &Inventory{Resources: []ResourceRef{}}
+	for _, r := range resources {
+		// the name of resource is "namespace/kind/name"
+		parts := strings.SplitN(r.Name, "/", 3)
+		if len(parts) != 3 {
+			klog.Warningf("skip the resource(name=%s) of unknown name in the inventory", r.Name)
+			continue
+		}
+
+		ref := ResourceRef{Namespace: parts[0], Kind: parts[1], Name: parts[2]}
+		if versioned {
+			var objectMeta metav1.ObjectMeta
+			if err := json.Unmarshal([]byte(r.ObjectMeta), &objectMeta); err == nil {
+				ref.ResourceVersion = objectMeta.ResourceVersion
+			}
+		}
+		inventory.Resources = append(inventory.Resources, ref)
+	}
+
+	return inventory, nil
+}
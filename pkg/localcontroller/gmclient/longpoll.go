@@ -0,0 +1,297 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gmclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubeedge/sedna/cmd/sedna-lc/app/options"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/ws"
+)
+
+const (
+	// LongPollFallbackCount is the number of the consecutive failed websocket upgrades
+	// after which the client falls back to the long-poll
+	LongPollFallbackCount = 3
+	// WebSocketRetryInitialBackoff and WebSocketRetryMaxBackoff are the bounds of the interval
+	// to try the websocket again after falling back to the long-poll
+	WebSocketRetryInitialBackoff = time.Minute
+	WebSocketRetryMaxBackoff     = 30 * time.Minute
+
+	// maxUpstreamBatch is the max number of the upstream messages sent in a request
+	maxUpstreamBatch = 100
+)
+
+// longPollConn is the long-poll session to GM, the upstream messages are sent in batches
+// and the downstream messages are polled
+type longPollConn struct {
+	client     *http.Client
+	sessionURL string
+	header     http.Header
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// received are the downstream messages polled but not read yet
+	received [][]byte
+	outgoing chan []byte
+	// expiry closes the session to try the websocket again
+	expiry *time.Timer
+
+	errLock sync.Mutex
+	err     error
+}
+
+func (c *longPollConn) newRequest(method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(c.ctx, method, c.sessionURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	return req, nil
+}
+
+// fail closes the session with the error, which is returned by the reads afterwards
+func (c *longPollConn) fail(err error) {
+	c.errLock.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.errLock.Unlock()
+	c.cancel()
+}
+
+func (c *longPollConn) failure() error {
+	c.errLock.Lock()
+	defer c.errLock.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+	return fmt.Errorf("long-poll session closed")
+}
+
+func (c *longPollConn) ReadMessage() ([]byte, error) {
+	for len(c.received) == 0 {
+		if err := c.poll(); err != nil {
+			c.fail(err)
+			return nil, c.failure()
+		}
+	}
+
+	data := c.received[0]
+	c.received = c.received[1:]
+	return data, nil
+}
+
+// poll waits for the downstream messages
+func (c *longPollConn) poll() error {
+	req, err := c.newRequest(http.MethodGet, "/down", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil
+	case http.StatusOK:
+	default:
+		return fmt.Errorf("failed to poll the downstream messages, status: %s", resp.Status)
+	}
+
+	r := bufio.NewReader(resp.Body)
+	for {
+		data, err := ws.ReadFrame(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		c.received = append(c.received, data)
+	}
+}
+
+// WriteMessage queues the message sent in the next batch. The messages lost with the session
+// are sent again by the next connection since they aren't acknowledged.
+func (c *longPollConn) WriteMessage(data []byte) error {
+	select {
+	case c.outgoing <- data:
+		return nil
+	case <-c.ctx.Done():
+		return c.failure()
+	}
+}
+
+// sendLoop sends the queued messages in batches until the session is closed
+func (c *longPollConn) sendLoop() {
+	for {
+		var batch bytes.Buffer
+		select {
+		case data := <-c.outgoing:
+			_ = ws.WriteFrame(&batch, data)
+		case <-c.ctx.Done():
+			return
+		}
+
+	collect:
+		for i := 1; i < maxUpstreamBatch; i++ {
+			select {
+			case data := <-c.outgoing:
+				_ = ws.WriteFrame(&batch, data)
+			default:
+				break collect
+			}
+		}
+
+		if err := c.send(&batch); err != nil {
+			c.fail(err)
+			return
+		}
+	}
+}
+
+func (c *longPollConn) send(batch io.Reader) error {
+	req, err := c.newRequest(http.MethodPost, "/up", batch)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", ws.LongPollContentType)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("failed to send the upstream messages, status: %s", resp.Status)
+	}
+	return nil
+}
+
+// expireAt closes the session at the time, so the client reconnects
+func (c *longPollConn) expireAt(t time.Time) {
+	c.expiry = time.AfterFunc(time.Until(t), func() {
+		c.fail(errWebSocketRetry)
+	})
+}
+
+func (c *longPollConn) Close() error {
+	c.cancel()
+	if c.expiry != nil {
+		c.expiry.Stop()
+	}
+
+	// tell GM the session is closed, or it expires
+	ctx, cancel := context.WithTimeout(context.Background(), ConnectTimeoutSeconds*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.sessionURL, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func newLongPollClient(options *options.LocalControllerOptions, store MessageStore) *gmClient {
+	c := newBaseClient(options, store)
+	c.dial = c.dialLongPoll
+	return c
+}
+
+// dialLongPoll creates the long-poll session on the websocket server of GM
+func (c *gmClient) dialLongPoll() (Conn, error) {
+	tlsConfig, err := c.newTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	header, err := c.newHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	client := &http.Client{
+		Transport: transport,
+		// the poll is answered in the long-poll timeout
+		Timeout: ws.LongPollTimeout + ConnectTimeoutSeconds*time.Second,
+	}
+
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+	url := scheme + "://" + c.Options.GMAddr + ws.LongPollPath
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to create the long-poll session, status: %s, %s", resp.Status, body)
+	}
+	var session ws.LongPollSession
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return nil, fmt.Errorf("failed to decode the long-poll session: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	conn := &longPollConn{
+		client:     client,
+		sessionURL: url + "/" + session.ID,
+		header:     header,
+		ctx:        ctx,
+		cancel:     cancel,
+		outgoing:   make(chan []byte, maxUpstreamBatch),
+	}
+	go conn.sendLoop()
+
+	klog.Infof("created the long-poll session to global manager(address: %s)", c.Options.GMAddr)
+	return conn, nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gmclient
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubeedge/sedna/cmd/sedna-lc/app/options"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/mqtt"
+)
+
+// mqttConn is the node topics on the broker bridged by GM
+type mqttConn struct {
+	client   *mqtt.Client
+	upstream string
+}
+
+func (c *mqttConn) ReadMessage() ([]byte, error) {
+	select {
+	case m := <-c.client.Messages():
+		return m.Payload, nil
+	case <-c.client.Done():
+		return nil, c.client.Err()
+	}
+}
+
+func (c *mqttConn) WriteMessage(data []byte) error {
+	return c.client.Publish(c.upstream, 1, data)
+}
+
+func (c *mqttConn) Close() error {
+	return c.client.Close()
+}
+
+func newMQTTClient(options *options.LocalControllerOptions, store MessageStore) *gmClient {
+	c := newBaseClient(options, store)
+	c.dial = c.dialMQTT
+	return c
+}
+
+// dialMQTT connects to the broker at the GM address, with the node name as the username
+// and the token as the password, and subscribes the downstream topic of the node
+func (c *gmClient) dialMQTT() (Conn, error) {
+	clientOptions := mqtt.ClientOptions{
+		Broker:   c.Options.GMAddr,
+		ClientID: "sedna-lc-" + c.Options.NodeName,
+		Username: c.Options.NodeName,
+	}
+
+	token, err := c.readToken()
+	if err != nil {
+		return nil, err
+	}
+	clientOptions.Password = token
+
+	if c.Options.GMTLS {
+		if !strings.Contains(clientOptions.Broker, "://") {
+			clientOptions.Broker = "ssl://" + clientOptions.Broker
+		}
+		tlsConfig, err := mqtt.NewTLSConfig(c.Options.GMCAFile, c.Options.GMCertFile, c.Options.GMKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.TLSConfig = tlsConfig
+	}
+
+	client, err := mqtt.Connect(clientOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := c.Options.GMMQTTTopicPrefix
+	if prefix == "" {
+		prefix = mqtt.DefaultTopicPrefix
+	}
+	if err := client.Subscribe(mqtt.DownstreamTopic(prefix, c.Options.NodeName), 1); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to subscribe the downstream topic: %w", err)
+	}
+
+	return &mqttConn{
+		client:   client,
+		upstream: mqtt.UpstreamTopic(prefix, c.Options.NodeName),
+	}, nil
+}
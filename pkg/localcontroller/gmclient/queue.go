@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gmclient
+
+import (
+	"encoding/json"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubeedge/sedna/pkg/localcontroller/db"
+)
+
+const (
+	// MaxQueuedMessages is the max number of the upstream messages kept until acknowledged,
+	// the oldest messages are dropped when exceeded
+	MaxQueuedMessages = 10000
+	// queueReadBatchSize is the number of the messages read from the store at once
+	queueReadBatchSize = 100
+)
+
+// QueuedMessage is the upstream message persisted in the store
+type QueuedMessage struct {
+	ID   uint64
+	Data []byte
+}
+
+// MessageStore persists the upstream messages in order of the id
+type MessageStore interface {
+	// Append appends the message, and returns its id which is greater than the existing ones
+	Append(data []byte) (uint64, error)
+	// List lists at most limit messages whose id is greater than afterID in order
+	List(afterID uint64, limit int) ([]QueuedMessage, error)
+	// Delete deletes the message
+	Delete(id uint64) error
+	// Trim deletes the oldest messages to keep at most max messages, and returns the number of them
+	Trim(max int) (int, error)
+}
+
+// dbMessageStore keeps the upstream messages in the LC db
+type dbMessageStore struct{}
+
+func (dbMessageStore) Append(data []byte) (uint64, error) {
+	id, err := db.AddUpstreamMessage(data)
+	return uint64(id), err
+}
+
+func (dbMessageStore) List(afterID uint64, limit int) ([]QueuedMessage, error) {
+	messages, err := db.ListUpstreamMessages(uint(afterID), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	queued := make([]QueuedMessage, 0, len(messages))
+	for _, m := range messages {
+		queued = append(queued, QueuedMessage{ID: uint64(m.ID), Data: []byte(m.Message)})
+	}
+	return queued, nil
+}
+
+func (dbMessageStore) Delete(id uint64) error {
+	return db.DeleteUpstreamMessage(uint(id))
+}
+
+func (dbMessageStore) Trim(max int) (int, error) {
+	return db.TrimUpstreamMessages(max)
+}
+
+// upstreamQueue keeps the messages sent to GM until they are acknowledged by GM,
+// so the messages survive the lost connection and the restart of LC.
+type upstreamQueue struct {
+	store MessageStore
+	// notify signals the message pushed
+	notify chan struct{}
+}
+
+func newUpstreamQueue(store MessageStore) *upstreamQueue {
+	return &upstreamQueue{
+		store:  store,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// Push persists the message in the queue
+func (q *upstreamQueue) Push(message Message) error {
+	data, err := json.Marshal(&message)
+	if err != nil {
+		return err
+	}
+
+	if _, err := q.store.Append(data); err != nil {
+		return err
+	}
+
+	if n, err := q.store.Trim(MaxQueuedMessages); err != nil {
+		klog.Warningf("failed to trim the upstream queue: %v", err)
+	} else if n > 0 {
+		klog.Warningf("dropped %d oldest upstream messages not acknowledged by global manager", n)
+	}
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Next returns the messages whose id is greater than afterID, it waits until
+// any message is pushed, and returns nothing when the stop is closed.
+func (q *upstreamQueue) Next(afterID uint64, stop <-chan struct{}) ([]Message, error) {
+	for {
+		queued, err := q.store.List(afterID, queueReadBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(queued) > 0 {
+			messages := make([]Message, 0, len(queued))
+			for _, m := range queued {
+				var message Message
+				if err := json.Unmarshal(m.Data, &message); err != nil {
+					klog.Errorf("drop invalid upstream message(id=%d): %v", m.ID, err)
+					_ = q.store.Delete(m.ID)
+					continue
+				}
+				message.Header.MessageID = m.ID
+				messages = append(messages, message)
+			}
+			if len(messages) > 0 {
+				return messages, nil
+			}
+			afterID = queued[len(queued)-1].ID
+			continue
+		}
+
+		select {
+		case <-q.notify:
+		case <-stop:
+			return nil, nil
+		}
+	}
+}
+
+// Ack deletes the message acknowledged by GM
+func (q *upstreamQueue) Ack(id uint64) error {
+	return q.store.Delete(id)
+}
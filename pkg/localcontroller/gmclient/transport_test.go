@@ -0,0 +1,262 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gmclient
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kubeedge/sedna/cmd/sedna-lc/app/options"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/grpcstream"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/ws"
+)
+
+// datasetHandler records the names of the inserted datasets
+type datasetHandler struct {
+	inserted chan string
+}
+
+func (h *datasetHandler) GetName() string {
+	return "dataset"
+}
+
+func (h *datasetHandler) Insert(message *Message) error {
+	h.inserted <- message.Header.ResourceName
+	return nil
+}
+
+func (h *datasetHandler) Update(message *Message) error {
+	return h.Insert(message)
+}
+
+func (h *datasetHandler) Delete(*Message) error {
+	return nil
+}
+
+// testTransport exchanges the messages with the GM message layer through the client
+func testTransport(t *testing.T, c *gmClient, nodeName string) {
+	store := &memoryMessageStore{}
+	c.queue = newUpstreamQueue(store)
+	c.inventory = testInventory
+	handler := &datasetHandler{inserted: make(chan string, 1)}
+	_ = c.Subscribe(handler)
+
+	writeMessages(t, c, 0, 3)
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the inventory is received first, then the upstream messages in order
+	var received []string
+	for len(received) < 4 {
+		node, msg, _ := ws.ReceiveFromEdge()
+		if node != nodeName {
+			t.Fatalf("expected the messages of node %s, got %s", nodeName, node)
+		}
+		received = append(received, msg.Operation+":"+msg.ResourceName)
+	}
+	want := "sync: status:message-0 status:message-1 status:message-2"
+	if got := strings.Join(received, " "); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	// the messages taken by GM are acknowledged
+	waitForAcked(t, store)
+
+	// GM replies the protocol negotiated
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		c.protocolLock.Lock()
+		protocol := c.gmProtocol
+		c.protocolLock.Unlock()
+		if protocol.ProtocolVersion == model.ProtocolVersion {
+			if got, want := fmt.Sprint(protocol.Capabilities), fmt.Sprint(c.capabilities()); got != want {
+				t.Errorf("expected capabilities %s negotiated, got %s", want, got)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for the protocol of GM")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// the large content is compressed
+	description := strings.Repeat("dataset ", 1024)
+	err := ws.SendToEdge(nodeName, &model.Message{
+		MessageHeader: model.MessageHeader{
+			Namespace:    "default",
+			ResourceKind: "dataset",
+			ResourceName: "ds",
+			Operation:    InsertOperation,
+		},
+		Content: []byte(`{"metadata":{"name":"ds","namespace":"default","annotations":{"description":"` + description + `"}}}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case name := <-handler.inserted:
+		if name != "ds" {
+			t.Errorf("expected dataset ds inserted, got %s", name)
+		}
+	case <-time.After(10 * time.Second):
+		t.Error("timeout waiting for the dataset inserted")
+	}
+
+	// the savings are counted
+	for {
+		status, _ := ws.GetNodeStatus(nodeName)
+		if status.BytesSent > 0 && status.BytesSent < status.PlainBytesSent/2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the sent bytes compressed, got %d bytes of %d bytes", status.BytesSent, status.PlainBytesSent)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestGRPCTransport(t *testing.T) {
+	const node = "grpc-edge"
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("grpc-token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	authenticator, err := ws.NewTokenFileAuthenticator(writeTokens(t, dir, "grpc-token,"+node))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the certificate of the test server is reused
+	certServer := httptest.NewTLSServer(http.NotFoundHandler())
+	certServer.Close()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpcstream.NewServer("", ws.ServerOptions{
+		TLSConfig:      &tls.Config{Certificates: certServer.TLS.Certificates},
+		Authenticators: []ws.Authenticator{authenticator},
+	})
+	go func() { _ = srv.Serve(listener) }()
+	defer srv.Close()
+
+	caFile := filepath.Join(dir, "ca.crt")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certServer.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	newOptions := func(nodeName string) *options.LocalControllerOptions {
+		return &options.LocalControllerOptions{
+			GMAddr:      listener.Addr().String(),
+			NodeName:    nodeName,
+			GMTransport: model.GRPCTransport,
+			// the messages are in protobuf
+			GMMessageEncoding: encodingProtobuf,
+			GMTLS:             true,
+			GMCAFile:          caFile,
+			GMTokenFile:       tokenFile,
+		}
+	}
+
+	// the token is bound to the node
+	rejected, err := newClient(newOptions("other-edge"), &memoryMessageStore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rejected.dial(); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected the stream of another node denied, got %v", err)
+	}
+
+	c, err := newClient(newOptions(node), &memoryMessageStore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTransport(t, c, node)
+}
+
+func TestLongPollFallback(t *testing.T) {
+	const node = "longpoll-edge"
+
+	gm := ws.NewServer("", ws.ServerOptions{})
+	// the proxy breaks the websocket upgrades until fixed
+	var upgradeAllowed int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Upgrade") != "" && atomic.LoadInt32(&upgradeAllowed) == 0 {
+			http.Error(w, "upgrade not allowed", http.StatusBadGateway)
+			return
+		}
+		gm.ServeHTTP(w, req)
+	}))
+	defer srv.Close()
+
+	c, err := newClient(&options.LocalControllerOptions{
+		GMAddr:   strings.TrimPrefix(srv.URL, "http://"),
+		NodeName: node,
+	}, &memoryMessageStore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the upgrades failed before, so the next failure falls back
+	c.upgradeFailures = LongPollFallbackCount - 1
+	testTransport(t, c, node)
+
+	conn, ok := c.conn.(*longPollConn)
+	if !ok {
+		t.Fatalf("expected the long-poll connection, got %T", c.conn)
+	}
+
+	// the websocket is tried again after the backoff, and used once the upgrade is allowed
+	atomic.StoreInt32(&upgradeAllowed, 1)
+	c.webSocketRetryAt = time.Now()
+	conn.fail(errWebSocketRetry)
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		status := c.Status()
+		if status.Connected && status.Transport == model.WebSocketTransport {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected switched back to the websocket, got %+v", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func writeTokens(t *testing.T, dir, tokens string) string {
+	file := filepath.Join(dir, "tokens.csv")
+	if err := ioutil.WriteFile(file, []byte(tokens+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
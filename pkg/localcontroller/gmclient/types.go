@@ -17,6 +17,8 @@ limitations under the License.
 package gmclient
 
 import (
+	"time"
+
 	messagetypes "github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
 	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
 )
@@ -24,18 +26,40 @@ import (
 const (
 	// InsertOperation is the insert value
 	InsertOperation = "insert"
+	// UpdateOperation is the value of the spec update, whose message carries the spec patch
+	UpdateOperation = "update"
 	// DeleteOperation is the delete value
 	DeleteOperation = "delete"
 	// StatusOperation is the status value
 	StatusOperation = "status"
+	// AckOperation is the operation of GM acknowledging the upstream message
+	AckOperation = messagetypes.AckOperation
+	// RejectOperation is the operation of GM rejecting the upstream message
+	RejectOperation = messagetypes.RejectOperation
+	// SyncOperation is the operation of the inventory sent to GM when connected
+	SyncOperation = messagetypes.SyncOperation
 )
 
+// Inventory is the resources of LC sent to GM when connected
+type Inventory = messagetypes.Inventory
+
+// ResourceRef refers to a resource of LC in the inventory
+type ResourceRef = messagetypes.ResourceRef
+
+// SyncReply is the content of the reply of the sync message
+type SyncReply = messagetypes.SyncReply
+
+// Rejection is the content of the message of the reject operation
+type Rejection = messagetypes.Rejection
+
 type Model = runtime.Model
 
 // Message defines message between LC and GM
 type Message struct {
 	Header  MessageHeader `json:"header"`
 	Content []byte        `json:"content"`
+	// Patch is the JSON merge patch of the spec for the update operation
+	Patch []byte `json:"patch,omitempty"`
 }
 
 // MessageHeader defines the header between LC and GM
@@ -54,6 +78,8 @@ type Input struct {
 	DataURL      string  `json:"dataURL,omitempty"`
 	DataIndexURL string  `json:"dataIndexURL,omitempty"`
 	OutputDir    string  `json:"outputDir,omitempty"`
+	// DatasetVersion is the version of the dataset pinned to the train round
+	DatasetVersion string `json:"datasetVersion,omitempty"`
 }
 
 type Output struct {
@@ -65,11 +91,30 @@ type Output struct {
 type MessageResourceHandler interface {
 	GetName() string
 	Insert(*Message) error
+	Update(*Message) error
 	Delete(*Message) error
 }
 
+// ConnectionStatus defines the state of the connection to GM
+type ConnectionStatus struct {
+	Address string `json:"address"`
+	// Transport is the transport in use, which is longpoll after falling back from websocket
+	Transport string `json:"transport"`
+	Connected bool   `json:"connected"`
+	// Since is when the client connected or disconnected
+	Since     time.Time `json:"since,omitempty"`
+	LastError string    `json:"lastError,omitempty"`
+	// ProtocolVersion and Capabilities are told by GM, zero until GM replies the sync message
+	ProtocolVersion int      `json:"protocolVersion,omitempty"`
+	Capabilities    []string `json:"capabilities,omitempty"`
+}
+
 type ClientI interface {
 	Start() error
 	WriteMessage(messageBody interface{}, messageHeader MessageHeader) error
 	Subscribe(m MessageResourceHandler) error
+	// Status returns the state of the connection to GM
+	Status() ConnectionStatus
+	// OnWorkerTokenKey registers the handler of the public key of the worker tokens told by GM
+	OnWorkerTokenKey(handler func(key []byte))
 }
@@ -17,8 +17,11 @@ limitations under the License.
 package gmclient
 
 import (
-	"encoding/json"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"time"
@@ -27,201 +30,189 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/kubeedge/sedna/cmd/sedna-lc/app/options"
+	"github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/codec"
+	messagetypes "github.com/kubeedge/sedna/pkg/globalmanager/messagelayer/model"
 	"github.com/kubeedge/sedna/pkg/localcontroller/common/constants"
 )
 
-// wsClient defines a websocket client
-type wsClient struct {
-	Options             *options.LocalControllerOptions
-	WSConnection        *WSConnection
-	SubscribeMessageMap map[string]MessageResourceHandler
-	SendMessageChannel  chan Message
-	ReconnectChannel    chan struct{}
+// wsConn is the websocket connection to GM
+type wsConn struct {
+	conn *websocket.Conn
 }
 
-// WSConnection defines conn
-type WSConnection struct {
-	WSConn *websocket.Conn
+func (c *wsConn) ReadMessage() ([]byte, error) {
+	_, data, err := c.conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	return data, c.conn.SetReadDeadline(time.Now().Add(messagetypes.PongWait))
 }
 
-const (
-	// RetryCount is count of retrying to connecting to global manager
-	RetryCount = 5
-	// RetryConnectIntervalSeconds is interval time of retrying to connecting to global manager
-	RetryConnectIntervalSeconds = 5
-	// MessageChannelCacheSize is size of channel cache
-	MessageChannelCacheSize = 100
-)
-
-// NewWebSocketClient creates client
-func NewWebSocketClient(options *options.LocalControllerOptions) ClientI {
-	c := wsClient{
-		Options:             options,
-		SubscribeMessageMap: make(map[string]MessageResourceHandler),
-		SendMessageChannel:  make(chan Message, MessageChannelCacheSize),
+func (c *wsConn) WriteMessage(data []byte) error {
+	// the messages not in JSON are binary
+	messageType := websocket.TextMessage
+	if !codec.IsJSON(data) {
+		messageType = websocket.BinaryMessage
 	}
+	return c.conn.WriteMessage(messageType, data)
+}
 
-	return &c
+func (c *wsConn) Close() error {
+	return c.conn.Close()
 }
 
-// Subscribe registers in client
-func (c *wsClient) Subscribe(m MessageResourceHandler) error {
-	name := m.GetName()
-	if c.SubscribeMessageMap[name] == nil {
-		c.SubscribeMessageMap[name] = m
-	} else {
-		klog.Warningf("%s had been registered in websocket client", name)
-	}
+var (
+	// errUpgradeFailed is the error of the websocket upgrade failed, e.g. by a proxy
+	errUpgradeFailed = errors.New("websocket upgrade failed")
+	// errWebSocketRetry is the error of the long-poll session closed to try the websocket again
+	errWebSocketRetry = errors.New("long-poll session closed to try the websocket again")
+)
 
-	return nil
+func newWebSocketClient(options *options.LocalControllerOptions, store MessageStore) *gmClient {
+	c := newBaseClient(options, store)
+	c.dial = c.dialWebSocketOrLongPoll
+	return c
 }
 
-// handleReceivedMessage handles received message
-func (c *wsClient) handleReceivedMessage(stop chan struct{}) {
-	defer func() {
-		stop <- struct{}{}
-	}()
-
-	ws := c.WSConnection.WSConn
+// dialWebSocketOrLongPoll connects to the websocket server of GM,
+// and falls back to the long-poll after the upgrades fail repeatedly.
+// The websocket is tried again on the reconnect after a backoff, the long-poll session
+// is closed then to reconnect.
+func (c *gmClient) dialWebSocketOrLongPoll() (Conn, error) {
+	if c.upgradeFailures >= LongPollFallbackCount && time.Now().Before(c.webSocketRetryAt) {
+		return c.dialLongPollUntilRetry()
+	}
 
-	for {
-		message := Message{}
-		if err := ws.ReadJSON(&message); err != nil {
-			klog.Errorf("client received message from global manager(address: %s) failed, error: %v",
-				c.Options.GMAddr, err)
-			return
+	conn, err := c.dialWebSocket()
+	if !errors.Is(err, errUpgradeFailed) {
+		if err == nil && c.upgradeFailures >= LongPollFallbackCount {
+			klog.Infof("client switches back to the websocket of global manager(address: %s)", c.Options.GMAddr)
 		}
+		c.upgradeFailures = 0
+		c.webSocketRetryBackoff = 0
+		return conn, err
+	}
 
-		klog.V(2).Infof("client received message header: %+v from global manager(address: %s)",
-			message.Header, c.Options.GMAddr)
-		klog.V(4).Infof("client received message content: %s from global manager(address: %s)",
-			message.Content, c.Options.GMAddr)
-
-		m := c.SubscribeMessageMap[message.Header.ResourceKind]
-		if m != nil {
-			go func() {
-				var err error
-				switch message.Header.Operation {
-				case InsertOperation:
-					err = m.Insert(&message)
-
-				case DeleteOperation:
-					err = m.Delete(&message)
-				default:
-					err = fmt.Errorf("unknown operation: %s", message.Header.Operation)
-				}
-				if err != nil {
-					klog.Errorf("failed to handle message(%+v): %v", message.Header, err)
-				}
-			}()
-		} else {
-			klog.Errorf("%s hadn't registered in websocket client", message.Header.ResourceKind)
+	c.upgradeFailures++
+	if c.upgradeFailures < LongPollFallbackCount {
+		return nil, err
+	}
+
+	switch {
+	case c.webSocketRetryBackoff == 0:
+		c.webSocketRetryBackoff = WebSocketRetryInitialBackoff
+	case c.webSocketRetryBackoff < WebSocketRetryMaxBackoff:
+		c.webSocketRetryBackoff *= 2
+		if c.webSocketRetryBackoff > WebSocketRetryMaxBackoff {
+			c.webSocketRetryBackoff = WebSocketRetryMaxBackoff
 		}
 	}
+	c.webSocketRetryAt = time.Now().Add(c.webSocketRetryBackoff)
+	klog.Warningf("client falls back to the long-poll after %d websocket upgrades to global manager(address: %s) failed, "+
+		"and tries the websocket again in %s: %v", c.upgradeFailures, c.Options.GMAddr, c.webSocketRetryBackoff, err)
+	return c.dialLongPollUntilRetry()
 }
 
-// WriteMessage saves message in a queue
-func (c *wsClient) WriteMessage(messageBody interface{}, messageHeader MessageHeader) error {
-	content, err := json.Marshal(&messageBody)
+// dialLongPollUntilRetry creates the long-poll session closed when the websocket is tried again
+func (c *gmClient) dialLongPollUntilRetry() (Conn, error) {
+	conn, err := c.dialLongPoll()
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	message := Message{
-		Content: content,
-		Header:  messageHeader,
-	}
-
-	c.SendMessageChannel <- message
-
-	return nil
+	conn.(*longPollConn).expireAt(c.webSocketRetryAt)
+	return conn, nil
 }
 
-// sendMessage sends the message through the connection
-func (c *wsClient) sendMessage(stop chan struct{}) {
-	defer func() {
-		stop <- struct{}{}
-	}()
-
-	ws := c.WSConnection.WSConn
+// newTLSConfig creates the TLS config of the connection to GM from the options,
+// which is nil if TLS is disabled
+func (c *gmClient) newTLSConfig() (*tls.Config, error) {
+	if !c.Options.GMTLS {
+		return nil, nil
+	}
 
-	for {
-		message, ok := <-c.SendMessageChannel
-		if !ok {
-			return
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.Options.GMCAFile != "" {
+		data, err := ioutil.ReadFile(c.Options.GMCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the CA file %s: %w", c.Options.GMCAFile, err)
 		}
-
-		if err := ws.WriteJSON(&message); err != nil {
-			klog.Errorf("client sent message to global manager(address: %s) failed, error: %v",
-				c.Options.GMAddr, err)
-
-			c.SendMessageChannel <- message
-
-			return
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificate in the CA file %s", c.Options.GMCAFile)
 		}
+		tlsConfig.RootCAs = pool
+	}
 
-		klog.V(2).Infof("client sent message header: %+v to global manager(address: %s)",
-			message.Header, c.Options.GMAddr)
-		klog.V(4).Infof("client sent message content: %s to global manager(address: %s)",
-			message.Content, c.Options.GMAddr)
+	if c.Options.GMCertFile != "" {
+		// the certificate is loaded on each connection, so the renewed certificate is used
+		cert, err := tls.LoadX509KeyPair(c.Options.GMCertFile, c.Options.GMKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
+
+	return tlsConfig, nil
 }
 
-// connect tries to connect remote server
-func (c *wsClient) connect() error {
+// newHeader creates the header of the connection request with the node name and the token
+func (c *gmClient) newHeader() (http.Header, error) {
 	header := http.Header{}
 	header.Add(constants.WSHeaderNodeName, c.Options.NodeName)
-	u := url.URL{Scheme: constants.WSScheme, Host: c.Options.GMAddr, Path: "/"}
-
-	klog.Infof("client starts to connect global manager(address: %s)", c.Options.GMAddr)
 
-	for i := 0; i < RetryCount; i++ {
-		wsConn, _, err := websocket.DefaultDialer.Dial(u.String(), header)
-
-		if err == nil {
-			if errW := wsConn.WriteJSON(&MessageHeader{}); errW != nil {
-				return errW
-			}
-
-			c.WSConnection = &WSConnection{WSConn: wsConn}
-			klog.Infof("websocket connects global manager(address: %s) successful", c.Options.GMAddr)
-
-			return nil
-		}
-
-		klog.Errorf("client tries to connect global manager(address: %s) failed, error: %v",
-			c.Options.GMAddr, err)
-
-		time.Sleep(time.Duration(RetryConnectIntervalSeconds) * time.Second)
+	token, err := c.readToken()
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		header.Add("Authorization", "Bearer "+token)
 	}
 
-	errorMsg := fmt.Errorf("max retry count reached when connecting global manager(address: %s)",
-		c.Options.GMAddr)
-	klog.Errorf("%v", errorMsg)
-
-	return errorMsg
+	return header, nil
 }
 
-// Start starts websocket client
-func (c *wsClient) Start() error {
-	go c.reconnect()
-
-	return nil
-}
+// dialWebSocket connects to the websocket server of GM
+func (c *gmClient) dialWebSocket() (Conn, error) {
+	scheme := constants.WSScheme
+	if c.Options.GMTLS {
+		scheme = constants.WSSScheme
+	}
+	u := url.URL{Scheme: scheme, Host: c.Options.GMAddr, Path: "/"}
 
-// reconnect reconnects global manager
-func (c *wsClient) reconnect() {
-	for {
-		if err := c.connect(); err != nil {
-			continue
-		}
-		ws := c.WSConnection.WSConn
+	tlsConfig, err := c.newTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = tlsConfig
 
-		stop := make(chan struct{}, 2)
-		go c.handleReceivedMessage(stop)
-		go c.sendMessage(stop)
-		<-stop
+	header, err := c.newHeader()
+	if err != nil {
+		return nil, err
+	}
 
-		_ = ws.Close()
+	conn, resp, err := dialer.Dial(u.String(), header)
+	if err != nil {
+		if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+			if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+				err = fmt.Errorf("%v, status: %s", err, resp.Status)
+			} else {
+				// the upgrade request is answered but not upgraded
+				err = fmt.Errorf("%w: %v, status: %s", errUpgradeFailed, err, resp.Status)
+			}
+		}
+		return nil, err
 	}
+
+	// GM pings periodically, the connection without any message or ping is half-open
+	_ = conn.SetReadDeadline(time.Now().Add(messagetypes.PongWait))
+	conn.SetPingHandler(func(data string) error {
+		_ = conn.SetReadDeadline(time.Now().Add(messagetypes.PongWait))
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+	return &wsConn{conn: conn}, nil
 }
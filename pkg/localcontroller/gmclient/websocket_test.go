@@ -0,0 +1,376 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gmclient
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/kubeedge/sedna/cmd/sedna-lc/app/options"
+)
+
+// memoryMessageStore is an in-memory message store, which is kept across the clients
+// to simulate the restart of LC
+type memoryMessageStore struct {
+	lock     sync.Mutex
+	nextID   uint64
+	messages []QueuedMessage
+}
+
+func (s *memoryMessageStore) Append(data []byte) (uint64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.nextID++
+	s.messages = append(s.messages, QueuedMessage{ID: s.nextID, Data: data})
+	return s.nextID, nil
+}
+
+func (s *memoryMessageStore) List(afterID uint64, limit int) ([]QueuedMessage, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var messages []QueuedMessage
+	for _, m := range s.messages {
+		if m.ID > afterID && len(messages) < limit {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+func (s *memoryMessageStore) Delete(id uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i, m := range s.messages {
+		if m.ID == id {
+			s.messages = append(s.messages[:i], s.messages[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *memoryMessageStore) Trim(max int) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	n := len(s.messages) - max
+	if n <= 0 {
+		return 0, nil
+	}
+	s.messages = s.messages[n:]
+	return n, nil
+}
+
+func (s *memoryMessageStore) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return len(s.messages)
+}
+
+// fakeGM is a websocket server receiving and acknowledging the upstream messages like GM
+type fakeGM struct {
+	lock  sync.Mutex
+	conns int
+	// received is the names of the received messages of each connection
+	received [][]string
+
+	// tokens are the bearer tokens of the connections
+	tokens []string
+	// inventories are the inventories sent when connected
+	inventories []Inventory
+
+	// killAfter kills the first connection without the ack when it receives the number of messages
+	killAfter int
+	// downstream are the messages sent to the first connection after the inventory
+	downstream []Message
+}
+
+func (gm *fakeGM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	gm.lock.Lock()
+	index := gm.conns
+	gm.conns++
+	gm.received = append(gm.received, nil)
+	gm.tokens = append(gm.tokens, r.Header.Get("Authorization"))
+	gm.lock.Unlock()
+
+	// the first message is the inventory sent when connected
+	var sync Message
+	if err := conn.ReadJSON(&sync); err != nil || sync.Header.Operation != SyncOperation {
+		return
+	}
+	var inventory Inventory
+	_ = json.Unmarshal(sync.Content, &inventory)
+	gm.lock.Lock()
+	gm.inventories = append(gm.inventories, inventory)
+	gm.lock.Unlock()
+
+	if index == 0 {
+		for i := range gm.downstream {
+			if err := conn.WriteJSON(&gm.downstream[i]); err != nil {
+				return
+			}
+		}
+	}
+
+	for {
+		var message Message
+		if err := conn.ReadJSON(&message); err != nil {
+			return
+		}
+
+		gm.lock.Lock()
+		gm.received[index] = append(gm.received[index], message.Header.ResourceName)
+		kill := index == 0 && len(gm.received[index]) == gm.killAfter
+		gm.lock.Unlock()
+
+		if kill {
+			return
+		}
+
+		ack := Message{Header: MessageHeader{Operation: AckOperation, MessageID: message.Header.MessageID}}
+		if err := conn.WriteJSON(&ack); err != nil {
+			return
+		}
+	}
+}
+
+func (gm *fakeGM) Received() [][]string {
+	gm.lock.Lock()
+	defer gm.lock.Unlock()
+
+	var received [][]string
+	for _, names := range gm.received {
+		received = append(received, append([]string(nil), names...))
+	}
+	return received
+}
+
+func newTestClient(t *testing.T, gm *fakeGM, store MessageStore) *gmClient {
+	srv := httptest.NewServer(gm)
+	t.Cleanup(srv.Close)
+
+	c := newWebSocketClient(&options.LocalControllerOptions{
+		GMAddr:   strings.TrimPrefix(srv.URL, "http://"),
+		NodeName: "edge",
+	}, store)
+	c.inventory = testInventory
+	return c
+}
+
+// testInventory has a dataset, whose version is known after the first connection
+func testInventory(versioned bool) (*Inventory, error) {
+	ref := ResourceRef{Kind: "dataset", Namespace: "default", Name: "ds"}
+	if versioned {
+		ref.ResourceVersion = "7"
+	}
+	return &Inventory{Resources: []ResourceRef{ref}}, nil
+}
+
+func writeMessages(t *testing.T, c *gmClient, from, to int) {
+	for i := from; i < to; i++ {
+		header := MessageHeader{
+			ResourceKind: "dataset",
+			ResourceName: fmt.Sprintf("message-%d", i),
+			Operation:    StatusOperation,
+		}
+		if err := c.WriteMessage(map[string]int{"index": i}, header); err != nil {
+			t.Fatalf("failed to write message %d: %v", i, err)
+		}
+	}
+}
+
+func waitForAcked(t *testing.T, store *memoryMessageStore) {
+	deadline := time.Now().Add(10 * time.Second)
+	for store.Len() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d messages are not acknowledged", store.Len())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// names returns the distinct names in order of their first appearance
+func names(received [][]string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, conn := range received {
+		for _, name := range conn {
+			if !seen[name] {
+				seen[name] = true
+				result = append(result, name)
+			}
+		}
+	}
+	return result
+}
+
+func TestReplayAfterConnectionKilled(t *testing.T) {
+	const total = 20
+	gm := &fakeGM{killAfter: 5}
+	store := &memoryMessageStore{}
+	c := newTestClient(t, gm, store)
+
+	writeMessages(t, c, 0, total/2)
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	writeMessages(t, c, total/2, total)
+
+	waitForAcked(t, store)
+
+	received := gm.Received()
+	if len(received) < 2 {
+		t.Fatalf("expected the client to reconnect, got %d connections", len(received))
+	}
+
+	// the versions of the resources are unknown only on the first connection
+	gm.lock.Lock()
+	if v := gm.inventories[0].Resources[0].ResourceVersion; v != "" {
+		t.Errorf("expected no version in the first inventory, got %s", v)
+	}
+	if v := gm.inventories[1].Resources[0].ResourceVersion; v != "7" {
+		t.Errorf("expected version 7 in the inventory of reconnection, got %q", v)
+	}
+	gm.lock.Unlock()
+
+	// the messages before the killed one are acknowledged, the rest are replayed in order
+	if got, want := received[1][0], fmt.Sprintf("message-%d", gm.killAfter-1); got != want {
+		t.Errorf("expected the replay starts from %s, got %s", want, got)
+	}
+
+	all := names(received)
+	if len(all) != total {
+		t.Fatalf("expected %d messages received, got %d: %v", total, len(all), all)
+	}
+	for i, name := range all {
+		if want := fmt.Sprintf("message-%d", i); name != want {
+			t.Errorf("expected message %d is %s, got %s", i, want, name)
+		}
+	}
+}
+
+func TestReplayAfterRestart(t *testing.T) {
+	const total = 10
+	store := &memoryMessageStore{}
+
+	// the messages are written while GM is not connected, and LC restarts
+	stopped := newWebSocketClient(&options.LocalControllerOptions{NodeName: "edge"}, store)
+	writeMessages(t, stopped, 0, total)
+
+	gm := &fakeGM{}
+	c := newTestClient(t, gm, store)
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	waitForAcked(t, store)
+
+	all := names(gm.Received())
+	if len(all) != total {
+		t.Fatalf("expected %d messages received, got %d: %v", total, len(all), all)
+	}
+	for i, name := range all {
+		if want := fmt.Sprintf("message-%d", i); name != want {
+			t.Errorf("expected message %d is %s, got %s", i, want, name)
+		}
+	}
+}
+
+func TestQueueDropsOldestMessages(t *testing.T) {
+	store := &memoryMessageStore{}
+	q := newUpstreamQueue(store)
+
+	for i := 0; i < MaxQueuedMessages+3; i++ {
+		if err := q.Push(Message{Header: MessageHeader{ResourceName: fmt.Sprint(i)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	messages, err := q.Next(0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.Len() != MaxQueuedMessages {
+		t.Errorf("expected %d messages kept, got %d", MaxQueuedMessages, store.Len())
+	}
+	if got := messages[0].Header.ResourceName; got != "3" {
+		t.Errorf("expected the oldest message kept is 3, got %s", got)
+	}
+	if got := messages[0].Header.MessageID; got != 4 {
+		t.Errorf("expected the id of the oldest message kept is 4, got %d", got)
+	}
+}
+
+func TestConnectWithTLSAndToken(t *testing.T) {
+	gm := &fakeGM{}
+	srv := httptest.NewTLSServer(gm)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatal(err)
+	}
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("edge-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	store := &memoryMessageStore{}
+	c := newWebSocketClient(&options.LocalControllerOptions{
+		GMAddr:      strings.TrimPrefix(srv.URL, "https://"),
+		NodeName:    "edge",
+		GMTLS:       true,
+		GMCAFile:    caFile,
+		GMTokenFile: tokenFile,
+	}, store)
+	c.inventory = testInventory
+
+	writeMessages(t, c, 0, 1)
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	waitForAcked(t, store)
+
+	gm.lock.Lock()
+	defer gm.lock.Unlock()
+	if got := gm.tokens[0]; got != "Bearer edge-token" {
+		t.Errorf("expected the bearer token sent, got %q", got)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"k8s.io/klog/v2"
@@ -30,8 +31,10 @@ import (
 	"github.com/kubeedge/sedna/cmd/sedna-lc/app/options"
 	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
 	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
+	"github.com/kubeedge/sedna/pkg/localcontroller/common/constants"
 	"github.com/kubeedge/sedna/pkg/localcontroller/db"
 	clienttypes "github.com/kubeedge/sedna/pkg/localcontroller/gmclient"
+	"github.com/kubeedge/sedna/pkg/localcontroller/managers"
 	"github.com/kubeedge/sedna/pkg/localcontroller/storage"
 	"github.com/kubeedge/sedna/pkg/localcontroller/util"
 	workertypes "github.com/kubeedge/sedna/pkg/localcontroller/worker"
@@ -43,11 +46,15 @@ const (
 	// KindName is kind of dataset resource
 	KindName = "dataset"
 	// CSVFormat is commas separated value format with a extra header.
-	// It can be used in structured data scenarios.
-	CSVFormat = "csv"
+	CSVFormat = sednav1.DatasetFormatCSV
 	// TXTFormat is line separated format.
-	// It can be used in unstructured data scenarios.
-	TXTFormat = "txt"
+	TXTFormat = sednav1.DatasetFormatTXT
+	// JSONLFormat is JSON Lines format.
+	JSONLFormat = sednav1.DatasetFormatJSONL
+	// ParquetFormat is columnar parquet format.
+	ParquetFormat = sednav1.DatasetFormatParquet
+	// ImageFolderFormat is a directory of images.
+	ImageFolderFormat = sednav1.DatasetFormatImageFolder
 )
 
 // DatasetManager defines dataset manager
@@ -55,6 +62,17 @@ type Manager struct {
 	Client            clienttypes.ClientI
 	DatasetMap        map[string]*Dataset
 	VolumeMountPrefix string
+	// MaxVersions is the max number of the versions kept by each dataset, no limit if not positive
+	MaxVersions int
+
+	// mapLock guards DatasetMap, which is also read by the LC API
+	mapLock sync.RWMutex
+
+	// pinLock guards pins, and serializes the snapshots with the pruning of the versions
+	pinLock sync.Mutex
+	// pins are the versions pinned by the live jobs, which are also saved in the dir of each dataset:
+	// dataset name -> job name -> version
+	pins map[string]map[string]string
 }
 
 // Dataset defines config for dataset
@@ -64,13 +82,32 @@ type Dataset struct {
 	Done       chan struct{}
 	URLPrefix  string
 	Storage    storage.Storage
+
+	// specLock guards the spec and the local storage flag, which are replaced by the updates
+	// while the monitor reads them
+	specLock sync.RWMutex
+
+	// samples are kept in the segment files instead of the memory
+	samples *segmentStore
+	tail    *tailState
+	stats   *qualityStats
+
+	versionsLock sync.Mutex
+	versions     []sednav1.DatasetVersion
 }
 
 // DataSource defines config for data source
 type DataSource struct {
-	TrainSamples    []string
 	NumberOfSamples int
 	Header          string
+	// Schema is the columns of the samples, captured by the structured formats
+	Schema []Column
+}
+
+// Column defines the name and type of a column of the samples
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
 }
 
 // New creates a dataset manager
@@ -79,6 +116,8 @@ func New(client clienttypes.ClientI, options *options.LocalControllerOptions) *M
 		Client:            client,
 		DatasetMap:        make(map[string]*Dataset),
 		VolumeMountPrefix: options.VolumeMountPrefix,
+		MaxVersions:       options.DatasetMaxVersions,
+		pins:              make(map[string]map[string]string),
 	}
 
 	return &dm
@@ -91,6 +130,9 @@ func (dm *Manager) Start() error {
 
 // GetDatasetChannel gets dataset
 func (dm *Manager) GetDataset(name string) (*Dataset, bool) {
+	dm.mapLock.RLock()
+	defer dm.mapLock.RUnlock()
+
 	d, ok := dm.DatasetMap[name]
 	return d, ok
 }
@@ -98,55 +140,94 @@ func (dm *Manager) GetDataset(name string) (*Dataset, bool) {
 // Insert inserts dataset to db
 func (dm *Manager) Insert(message *clienttypes.Message) error {
 	name := util.GetUniqueIdentifier(message.Header.Namespace, message.Header.ResourceName, message.Header.ResourceKind)
+
+	// the message is decoded and validated before applied to the dataset read by the monitor
+	object := &sednav1.Dataset{}
+	if err := json.Unmarshal(message.Content, object); err != nil {
+		return err
+	}
+	if _, err := storage.ParseChecksum(object.Spec.Checksum); err != nil {
+		return fmt.Errorf("dataset(name=%s)'s checksum is invalid, error: %+v", name, err)
+	}
+
 	first := false
-	dataset, ok := dm.DatasetMap[name]
+	dataset, ok := dm.GetDataset(name)
 	if !ok {
-		dataset = &Dataset{}
+		dir := util.AddPrefixPath(dm.VolumeMountPrefix, filepath.Join(constants.DatasetDir, name))
+		samples, err := openSegmentStore(dir)
+		if err != nil {
+			return fmt.Errorf("failed to open the samples of dataset(name=%s), error: %+v", name, err)
+		}
+
+		dataset = &Dataset{Dataset: object}
 		dataset.Storage = storage.Storage{IsLocalStorage: false}
 		dataset.Done = make(chan struct{})
+		dataset.samples = samples
+		dataset.tail = loadTailState(dir)
+		dataset.versions = loadVersions(dir)
+		dataset.stats = newQualityStats(dataset.resolveEntry(dm.VolumeMountPrefix))
+		dm.mapLock.Lock()
 		dm.DatasetMap[name] = dataset
+		dm.mapLock.Unlock()
+		dm.restorePins(name, dir)
 		first = true
 	}
 
-	if err := json.Unmarshal(message.Content, dataset); err != nil {
-		return err
-	}
-
-	credential := dataset.ObjectMeta.Annotations[runtime.SecretAnnotationKey]
+	credential := object.Annotations[runtime.SecretAnnotationKey]
 	if credential != "" {
 		if err := dataset.Storage.SetCredential(credential); err != nil {
 			return fmt.Errorf("failed to set dataset(name=%s)'s storage credential, error: %+v", name, err)
 		}
 	}
 
-	isLocalURL, err := dataset.Storage.IsLocalURL(dataset.Spec.URL)
+	isLocalURL, err := dataset.Storage.IsLocalURL(object.Spec.URL)
 	if err != nil {
 		return fmt.Errorf("dataset(name=%s)'s url is invalid, error: %+v", name, err)
 	}
-	if isLocalURL {
-		dataset.Storage.IsLocalStorage = true
-	}
+
+	dataset.specLock.Lock()
+	dataset.Dataset = object
+	dataset.Storage.IsLocalStorage = isLocalURL
+	dataset.specLock.Unlock()
 
 	if first {
 		go dm.monitorDataSources(name)
 	}
 
-	if err := db.SaveResource(name, dataset.TypeMeta, dataset.ObjectMeta, dataset.Spec); err != nil {
+	if err := db.SaveResource(name, object.TypeMeta, object.ObjectMeta, object.Spec); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Update applies the spec changes of the dataset, e.g. the new credential is used at once,
+// and the samples are read again by the monitor when the url or format is changed
+func (dm *Manager) Update(message *clienttypes.Message) error {
+	name := util.GetUniqueIdentifier(message.Header.Namespace, message.Header.ResourceName, message.Header.ResourceKind)
+	klog.Infof("dataset(name=%s) is updated: %s", name, message.Patch)
+
+	patched, err := managers.PatchMessage(name, message)
+	if err != nil {
+		return err
+	}
+	return dm.Insert(patched)
+}
+
 // Delete deletes dataset config in db
 func (dm *Manager) Delete(message *clienttypes.Message) error {
 	name := util.GetUniqueIdentifier(message.Header.Namespace, message.Header.ResourceName, message.Header.ResourceKind)
 
-	if ds, ok := dm.DatasetMap[name]; ok && ds.Done != nil {
+	if ds, ok := dm.GetDataset(name); ok && ds.Done != nil {
 		close(ds.Done)
+		if err := ds.samples.Remove(); err != nil {
+			klog.Errorf("failed to remove the samples of dataset(name=%s), error: %+v", name, err)
+		}
 	}
 
+	dm.mapLock.Lock()
 	delete(dm.DatasetMap, name)
+	dm.mapLock.Unlock()
 
 	if err := db.DeleteResource(name); err != nil {
 		return err
@@ -157,17 +238,16 @@ func (dm *Manager) Delete(message *clienttypes.Message) error {
 
 // monitorDataSources monitors the data url of specified dataset
 func (dm *Manager) monitorDataSources(name string) {
-	ds, ok := dm.DatasetMap[name]
+	ds, ok := dm.GetDataset(name)
 	if !ok || ds == nil {
 		return
 	}
 
-	dataURL := ds.Spec.URL
-	if ds.Storage.IsLocalStorage {
-		dataURL = util.AddPrefixPath(dm.VolumeMountPrefix, dataURL)
+	dm.resolveDataURL(ds)
+	if err := ds.rebuildStats(); err != nil {
+		klog.Errorf("dataset(name=%s) failed to compute the quality of samples, error: %+v", name, err)
 	}
 
-	ds.URLPrefix = strings.TrimRight(dataURL, filepath.Base(dataURL))
 	samplesNumber := 0
 	for {
 		select {
@@ -176,85 +256,74 @@ func (dm *Manager) monitorDataSources(name string) {
 		default:
 		}
 
-		dataSource, err := ds.getDataSource(dataURL, ds.Spec.Format)
+		// the url may be changed by the update of the dataset
+		dataURL := dm.resolveDataURL(ds)
+		spec, _ := ds.spec()
+		dataSource, err := ds.syncSamples(dataURL, spec.Format)
 		if err != nil {
 			klog.Errorf("dataset(name=%s) get samples from %s failed, error: %+v", name, dataURL, err)
-		} else {
+		}
+		// the samples parsed before the error are available
+		if dataSource != nil {
 			ds.DataSource = dataSource
 			if samplesNumber != dataSource.NumberOfSamples {
 				samplesNumber = dataSource.NumberOfSamples
 				klog.Infof("dataset(name=%s) get samples from data source(url=%s) successfully. number of samples: %d",
 					name, dataURL, dataSource.NumberOfSamples)
 
-				header := clienttypes.MessageHeader{
-					Namespace:    ds.Namespace,
-					ResourceKind: ds.Kind,
-					ResourceName: ds.Name,
-					Operation:    clienttypes.StatusOperation,
-				}
-
-				if err := dm.Client.WriteMessage(struct {
-					NumberOfSamples int `json:"numberOfSamples"`
-				}{
-					dataSource.NumberOfSamples,
-				}, header); err != nil {
-					klog.Errorf("dataset(name=%s) publish samples info failed, error: %+v", name, err)
-				}
+				dm.publishStatus(ds)
 			}
 		}
 		<-time.After(MonitorDataSourceIntervalSeconds * time.Second)
 	}
 }
 
-// getDataSource gets data source info
-func (ds *Dataset) getDataSource(dataURL string, format string) (*DataSource, error) {
-	if err := ds.validFormat(format); err != nil {
-		return nil, err
+// resolveDataURL returns the data url of the dataset with the volume mount prefix if local,
+// and sets the url prefix of the samples
+func (dm *Manager) resolveDataURL(ds *Dataset) string {
+	spec, isLocal := ds.spec()
+	dataURL := spec.URL
+	if isLocal {
+		dataURL = util.AddPrefixPath(dm.VolumeMountPrefix, dataURL)
 	}
 
-	localURL, err := ds.Storage.Download(dataURL, "")
+	ds.URLPrefix = strings.TrimRight(dataURL, filepath.Base(dataURL))
+	return dataURL
+}
 
-	if !ds.Storage.IsLocalStorage {
-		defer os.RemoveAll(localURL)
+// publishStatus sends the number of samples and the versions of the dataset to GM
+func (dm *Manager) publishStatus(ds *Dataset) {
+	status := sednav1.DatasetStatus{
+		Versions: ds.Versions(),
+		Quality:  ds.stats.status(),
 	}
-
-	if err != nil {
-		return nil, err
+	if dataSource := ds.DataSource; dataSource != nil {
+		status.NumberOfSamples = dataSource.NumberOfSamples
 	}
 
-	return ds.readByLine(localURL, format)
-}
-
-// readByLine reads file by line
-func (ds *Dataset) readByLine(url string, format string) (*DataSource, error) {
-	samples, err := GetSamples(url)
-	if err != nil {
-		klog.Errorf("read file %s failed, error: %v", url, err)
-		return nil, err
+	header := clienttypes.MessageHeader{
+		Namespace:    ds.Namespace,
+		ResourceKind: ds.Kind,
+		ResourceName: ds.Name,
+		Operation:    clienttypes.StatusOperation,
 	}
 
-	numberOfSamples := 0
-	dataSource := DataSource{}
-	switch strings.ToLower(format) {
-	case TXTFormat:
-		numberOfSamples += len(samples)
-	case CSVFormat:
-		// the first row of csv file is header
-		if len(samples) == 0 {
-			return nil, fmt.Errorf("file %s is empty", url)
-		}
-		dataSource.Header = samples[0]
-		samples = samples[1:]
-		numberOfSamples += len(samples)
-
-	default:
-		return nil, fmt.Errorf("invaild file format")
+	if err := dm.Client.WriteMessage(status, header); err != nil {
+		klog.Errorf("dataset(name=%s/%s) publish samples info failed, error: %+v", ds.Namespace, ds.Name, err)
 	}
+}
+
+// spec returns the spec of the dataset and whether its url is local
+func (ds *Dataset) spec() (sednav1.DatasetSpec, bool) {
+	ds.specLock.RLock()
+	defer ds.specLock.RUnlock()
 
-	dataSource.TrainSamples = samples
-	dataSource.NumberOfSamples = numberOfSamples
+	return ds.Spec, ds.Storage.IsLocalStorage
+}
 
-	return &dataSource, nil
+// NewCursor creates the cursor reading the samples from the position
+func (ds *Dataset) NewCursor(position int) *Cursor {
+	return &Cursor{store: ds.samples, position: position}
 }
 
 func (dm *Manager) GetName() string {
@@ -294,14 +363,3 @@ func GetSamples(url string) ([]string, error) {
 
 	return samples, nil
 }
-
-// validFormat checks data format is valid
-func (ds *Dataset) validFormat(format string) error {
-	for _, v := range []string{TXTFormat, CSVFormat} {
-		if strings.ToLower(format) == v {
-			return nil
-		}
-	}
-
-	return fmt.Errorf("dataset format(%s) is invalid", format)
-}
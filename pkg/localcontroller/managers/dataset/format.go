@@ -0,0 +1,189 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataset
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kubeedge/sedna/pkg/localcontroller/util"
+)
+
+// maxLineSize is the max size of a line of the line formats
+const maxLineSize = 16 * 1024 * 1024
+
+// Format parses the samples of the dataset in a format
+type Format interface {
+	// Parse parses the samples from the local url of the dataset, and passes each sample to add.
+	// The returned data source has the header and schema of the samples.
+	Parse(url string, add func(sample string) error) (*DataSource, error)
+}
+
+// FormatFunc is an adapter to use the function as a format
+type FormatFunc func(url string, add func(sample string) error) (*DataSource, error)
+
+// Parse calls f(url, add)
+func (f FormatFunc) Parse(url string, add func(sample string) error) (*DataSource, error) {
+	return f(url, add)
+}
+
+// LineFormat is implemented by the formats whose samples are the lines of the file,
+// so the lines appended to the file can be parsed without parsing the whole file.
+type LineFormat interface {
+	Format
+	// NewLineParser creates the parser of the lines following the lines parsed into the data source
+	NewLineParser(dataSource *DataSource) LineParser
+}
+
+// LineParser parses the lines of the file in order, and updates the header and schema
+// of the data source it's created with
+type LineParser interface {
+	// ParseLine parses the line whose number starts from 1, and returns the sample,
+	// or false if the line is not a sample (e.g. the header)
+	ParseLine(lineNo int, line string) (string, bool, error)
+}
+
+// lineFormat is the line format of the parser
+type lineFormat func(dataSource *DataSource) LineParser
+
+func (f lineFormat) NewLineParser(dataSource *DataSource) LineParser {
+	return f(dataSource)
+}
+
+// Parse parses all lines of the file
+func (f lineFormat) Parse(url string, add func(sample string) error) (*DataSource, error) {
+	if !util.IsExists(url) {
+		return nil, fmt.Errorf("url(%s) does not exist", url)
+	}
+
+	if !util.IsFile(url) {
+		return nil, fmt.Errorf("url(%s) is not a file, not vaild", url)
+	}
+
+	file, err := os.Open(url)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	dataSource := DataSource{}
+	parser := f(&dataSource)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		sample, ok, err := parser.ParseLine(lineNo, scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d of file %s is invalid: %v", lineNo, url, err)
+		}
+		if !ok {
+			continue
+		}
+		if err := add(sample); err != nil {
+			return nil, err
+		}
+		dataSource.NumberOfSamples++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read file %s failed, error: %v", url, err)
+	}
+
+	return &dataSource, nil
+}
+
+var (
+	formatsLock sync.RWMutex
+	formats     = make(map[string]Format)
+)
+
+// RegisterFormat registers the format by its name, which is case insensitive
+func RegisterFormat(name string, f Format) {
+	formatsLock.Lock()
+	defer formatsLock.Unlock()
+
+	formats[strings.ToLower(name)] = f
+}
+
+// GetFormat gets the format by its name
+func GetFormat(name string) (Format, error) {
+	formatsLock.RLock()
+	defer formatsLock.RUnlock()
+
+	f, ok := formats[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("dataset format(%s) is invalid, support format: %+v", name, supportedFormats())
+	}
+	return f, nil
+}
+
+// SupportedFormats returns the names of the registered formats
+func SupportedFormats() []string {
+	formatsLock.RLock()
+	defer formatsLock.RUnlock()
+
+	return supportedFormats()
+}
+
+func supportedFormats() []string {
+	var names []string
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// txtParser parses the lines of txt format, each line is a sample
+type txtParser struct{}
+
+func (p txtParser) ParseLine(lineNo int, line string) (string, bool, error) {
+	return line, true, nil
+}
+
+// csvParser parses the lines of csv format, the first row is header
+type csvParser struct {
+	dataSource *DataSource
+}
+
+func (p *csvParser) ParseLine(lineNo int, line string) (string, bool, error) {
+	if lineNo > 1 {
+		return line, true, nil
+	}
+
+	p.dataSource.Header = line
+	p.dataSource.Schema = nil
+	for _, name := range strings.Split(line, ",") {
+		p.dataSource.Schema = append(p.dataSource.Schema, Column{Name: strings.TrimSpace(name), Type: "string"})
+	}
+	return "", false, nil
+}
+
+func init() {
+	RegisterFormat(TXTFormat, lineFormat(func(*DataSource) LineParser {
+		return txtParser{}
+	}))
+	RegisterFormat(CSVFormat, lineFormat(func(ds *DataSource) LineParser {
+		return &csvParser{dataSource: ds}
+	}))
+	RegisterFormat(JSONLFormat, lineFormat(newJSONLParser))
+	RegisterFormat(ParquetFormat, FormatFunc(parseParquet))
+	RegisterFormat(ImageFolderFormat, FormatFunc(parseImageFolder))
+}
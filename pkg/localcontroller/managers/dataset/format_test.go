@@ -0,0 +1,34 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataset
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
+)
+
+// TestRegisteredFormats checks the formats accepted by GM are the registered ones
+func TestRegisteredFormats(t *testing.T) {
+	expected := append([]string(nil), sednav1.DatasetFormats...)
+	sort.Strings(expected)
+	if formats := SupportedFormats(); !reflect.DeepEqual(formats, expected) {
+		t.Errorf("expected the registered formats %v, got %v", expected, formats)
+	}
+}
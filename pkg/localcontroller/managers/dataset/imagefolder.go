@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataset
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kubeedge/sedna/pkg/localcontroller/util"
+)
+
+// labelsSidecars are the names of the labels sidecar file in the image folder,
+// each line is "<relative path of image> <label>" or "<relative path of image>,<label>".
+var labelsSidecars = []string{"labels.txt", "labels.csv"}
+
+var imageExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".bmp": true,
+	".gif": true, ".webp": true, ".tif": true, ".tiff": true,
+}
+
+// imageFolderSchema is the schema of the samples of imagefolder format
+var imageFolderSchema = []Column{{Name: "path", Type: "string"}, {Name: "label", Type: "string"}}
+
+// parseImageFolder parses the samples of imagefolder format.
+// The url is the image folder on the host, whose sub directories are the labels if
+// there is no labels sidecar file, or the url is the labels sidecar file (e.g. in s3).
+// Each sample is "<path> <label>" like txt format, and the path is relative to the parent of
+// the url of the image folder, or relative to the dir of the labels sidecar file.
+func parseImageFolder(url string, add func(sample string) error) (*DataSource, error) {
+	if !util.IsExists(url) {
+		return nil, fmt.Errorf("url(%s) does not exist", url)
+	}
+
+	var samples []string
+	var err error
+	if util.IsDir(url) {
+		samples, err = readImageFolder(filepath.Clean(url))
+	} else {
+		samples, err = readLabelsSidecar(url, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sample := range samples {
+		if err := add(sample); err != nil {
+			return nil, err
+		}
+	}
+
+	return &DataSource{
+		NumberOfSamples: len(samples),
+		Header:          "path,label",
+		Schema:          imageFolderSchema,
+	}, nil
+}
+
+// readImageFolder reads the samples of the image folder on the host
+func readImageFolder(root string) ([]string, error) {
+	base := filepath.Base(root)
+
+	for _, name := range labelsSidecars {
+		sidecar := filepath.Join(root, name)
+		if util.IsExists(sidecar) && util.IsFile(sidecar) {
+			return readLabelsSidecar(sidecar, base)
+		}
+	}
+
+	entries, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var samples []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			// the unlabeled image in the root
+			if imageExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+				samples = append(samples, path.Join(base, entry.Name()))
+			}
+			continue
+		}
+
+		label := entry.Name()
+		var images []string
+		err := filepath.Walk(filepath.Join(root, label), func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && imageExtensions[strings.ToLower(filepath.Ext(p))] {
+				rel, err := filepath.Rel(filepath.Dir(root), p)
+				if err != nil {
+					return err
+				}
+				images = append(images, filepath.ToSlash(rel))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		sort.Strings(images)
+		for _, image := range images {
+			if strings.ContainsAny(image, " \t") {
+				return nil, fmt.Errorf("image path(%s) with spaces is not supported", image)
+			}
+			samples = append(samples, image+" "+label)
+		}
+	}
+
+	return samples, nil
+}
+
+// readLabelsSidecar reads the samples of the labels sidecar, the image paths are joined with the prefix
+func readLabelsSidecar(sidecar string, prefix string) ([]string, error) {
+	lines, err := GetSamples(sidecar)
+	if err != nil {
+		return nil, err
+	}
+
+	var samples []string
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(strings.Replace(line, ",", " ", 1))
+		if i == 0 && len(fields) == 2 && fields[0] == "path" && fields[1] == "label" {
+			// header of csv sidecar
+			continue
+		}
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, fmt.Errorf("line %d of labels file %s is invalid, expected \"<path> <label>\"", i+1, sidecar)
+		}
+
+		sample := path.Join(prefix, filepath.ToSlash(fields[0]))
+		if len(fields) == 2 {
+			sample += " " + fields[1]
+		}
+		samples = append(samples, sample)
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataset
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles writes the files of the relative paths in the dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestImageFolderFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		url      string
+		samples  []string
+		hasError bool
+	}{
+		{
+			name: "labeled by sub directories",
+			files: map[string]string{
+				"images/cat/2.jpg":     "",
+				"images/cat/1.jpg":     "",
+				"images/cat/sub/3.PNG": "",
+				"images/dog/1.jpeg":    "",
+				"images/dog/notes.txt": "",
+				"images/bird.webp":     "",
+				"images/readme.md":     "",
+				"images/empty/.keep":   "",
+				"other/ignored/1.jpg":  "",
+			},
+			url: "images",
+			samples: []string{
+				"images/bird.webp",
+				"images/cat/1.jpg cat",
+				"images/cat/2.jpg cat",
+				"images/cat/sub/3.PNG cat",
+				"images/dog/1.jpeg dog",
+			},
+		},
+		{
+			name: "labels csv in the folder",
+			files: map[string]string{
+				"images/labels.csv": "path,label\na.jpg,cat\n# comment\n\nsub/b.jpg,dog\nc.jpg\n",
+				"images/cat/x.jpg":  "",
+			},
+			url:     "images/",
+			samples: []string{"images/a.jpg cat", "images/sub/b.jpg dog", "images/c.jpg"},
+		},
+		{
+			name: "labels txt in the folder",
+			files: map[string]string{
+				"images/labels.txt": "a.jpg cat\nb.jpg\tdog\n",
+			},
+			url:     "images",
+			samples: []string{"images/a.jpg cat", "images/b.jpg dog"},
+		},
+		{
+			name: "labels sidecar url",
+			files: map[string]string{
+				"labels.txt": "images/a.jpg cat\nimages/b.jpg dog\n",
+			},
+			url:     "labels.txt",
+			samples: []string{"images/a.jpg cat", "images/b.jpg dog"},
+		},
+		{
+			name: "invalid labels line",
+			files: map[string]string{
+				"images/labels.txt": "a.jpg cat\na b c\n",
+			},
+			url:      "images",
+			hasError: true,
+		},
+		{
+			name: "image path with spaces",
+			files: map[string]string{
+				"images/cat/my cat.jpg": "",
+			},
+			url:      "images",
+			hasError: true,
+		},
+		{
+			name:     "not exists",
+			url:      "missing",
+			hasError: true,
+		},
+	}
+
+	f, err := GetFormat("ImageFolder")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			var samples []string
+			dataSource, err := f.Parse(filepath.Join(dir, tt.url), func(sample string) error {
+				samples = append(samples, sample)
+				return nil
+			})
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("expected error, got %v", samples)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+
+			if !reflect.DeepEqual(samples, tt.samples) {
+				t.Errorf("expected samples %v, got %v", tt.samples, samples)
+			}
+			if dataSource.NumberOfSamples != len(tt.samples) || dataSource.Header != "path,label" ||
+				!reflect.DeepEqual(dataSource.Schema, imageFolderSchema) {
+				t.Errorf("unexpected data source %+v", dataSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// jsonKind returns the JSON type name of the decoded value
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// schemaBuilder captures the columns of the samples in the order of appearance
+type schemaBuilder struct {
+	columns []Column
+	index   map[string]int
+}
+
+// newSchemaBuilder creates the builder continuing the columns
+func newSchemaBuilder(columns []Column) *schemaBuilder {
+	b := &schemaBuilder{index: make(map[string]int)}
+	for _, c := range columns {
+		b.index[c.Name] = len(b.columns)
+		b.columns = append(b.columns, c)
+	}
+	return b
+}
+
+// add adds the column, the type becomes "mixed" if conflicting.
+// It returns true if the columns are changed.
+func (b *schemaBuilder) add(name string, typ string) bool {
+	i, ok := b.index[name]
+	if !ok {
+		b.index[name] = len(b.columns)
+		b.columns = append(b.columns, Column{Name: name, Type: typ})
+		return true
+	}
+
+	c := &b.columns[i]
+	switch {
+	case typ == "null" || c.Type == typ:
+		return false
+	case c.Type == "null":
+		c.Type = typ
+	default:
+		c.Type = "mixed"
+	}
+	return true
+}
+
+func (b *schemaBuilder) header() string {
+	var names []string
+	for _, c := range b.columns {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+// jsonlParser parses the lines of jsonl format, each non-empty line is a JSON object
+type jsonlParser struct {
+	dataSource *DataSource
+	schema     *schemaBuilder
+}
+
+func newJSONLParser(dataSource *DataSource) LineParser {
+	return &jsonlParser{
+		dataSource: dataSource,
+		schema:     newSchemaBuilder(dataSource.Schema),
+	}
+}
+
+func (p *jsonlParser) ParseLine(lineNo int, line string) (string, bool, error) {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 {
+		return "", false, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+	var obj map[string]interface{}
+	if err := decoder.Decode(&obj); err != nil || obj == nil {
+		return "", false, fmt.Errorf("not a JSON object")
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	changed := false
+	for _, name := range names {
+		if p.schema.add(name, jsonKind(obj[name])) {
+			changed = true
+		}
+	}
+	if changed {
+		p.dataSource.Schema = append([]Column(nil), p.schema.columns...)
+		p.dataSource.Header = p.schema.header()
+	}
+
+	return line, true, nil
+}
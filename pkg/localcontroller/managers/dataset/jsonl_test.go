@@ -0,0 +1,177 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataset
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// parseSamples parses the samples of the content written to a temp file in the format
+func parseSamples(t *testing.T, format string, content string) ([]string, *DataSource, error) {
+	path := filepath.Join(t.TempDir(), "samples")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := GetFormat(format)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var samples []string
+	dataSource, err := f.Parse(path, func(sample string) error {
+		samples = append(samples, sample)
+		return nil
+	})
+	return samples, dataSource, err
+}
+
+func TestJSONLFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		samples  []string
+		header   string
+		schema   []Column
+		hasError bool
+	}{
+		{
+			name:    "objects",
+			content: "{\"text\":\"a\",\"label\":1}\n\n  {\"text\":\"b\",\"label\":2}  \n",
+			samples: []string{`{"text":"a","label":1}`, `{"text":"b","label":2}`},
+			header:  "label,text",
+			schema:  []Column{{Name: "label", Type: "number"}, {Name: "text", Type: "string"}},
+		},
+		{
+			name: "changed columns",
+			content: `{"id":1,"label":null}
+{"id":2,"label":"cat","extra":[1]}
+{"id":"3","label":"dog","meta":{"a":true}}
+`,
+			samples: []string{
+				`{"id":1,"label":null}`,
+				`{"id":2,"label":"cat","extra":[1]}`,
+				`{"id":"3","label":"dog","meta":{"a":true}}`,
+			},
+			header: "id,label,extra,meta",
+			schema: []Column{
+				{Name: "id", Type: "mixed"},
+				{Name: "label", Type: "string"},
+				{Name: "extra", Type: "array"},
+				{Name: "meta", Type: "object"},
+			},
+		},
+		{
+			name:     "not object",
+			content:  "{\"id\":1}\n[1,2]\n",
+			hasError: true,
+		},
+		{
+			name:     "invalid json",
+			content:  "{\"id\":1\n",
+			hasError: true,
+		},
+		{
+			name:     "null",
+			content:  "null\n",
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples, dataSource, err := parseSamples(t, "JSONL", tt.content)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("expected error, got %v", samples)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+
+			if !reflect.DeepEqual(samples, tt.samples) {
+				t.Errorf("expected samples %v, got %v", tt.samples, samples)
+			}
+			if dataSource.NumberOfSamples != len(tt.samples) || dataSource.Header != tt.header ||
+				!reflect.DeepEqual(dataSource.Schema, tt.schema) {
+				t.Errorf("expected %d samples with header %q and schema %v, got %+v",
+					len(tt.samples), tt.header, tt.schema, dataSource)
+			}
+		})
+	}
+}
+
+func TestJSONLLineParserContinues(t *testing.T) {
+	f, err := GetFormat(JSONLFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lf, ok := f.(LineFormat)
+	if !ok {
+		t.Fatalf("expected jsonl is a line format")
+	}
+
+	// the lines appended are parsed with the schema of the lines parsed before
+	dataSource := &DataSource{
+		Header: "id,label",
+		Schema: []Column{{Name: "id", Type: "number"}, {Name: "label", Type: "null"}},
+	}
+	parser := lf.NewLineParser(dataSource)
+
+	for i, line := range []string{`{"id":3}`, `{"id":4,"label":"cat"}`, `{"score":0.5}`} {
+		sample, ok, err := parser.ParseLine(i+3, line)
+		if err != nil || !ok || sample != line {
+			t.Fatalf("line %d: expected sample %s, got %s, %v, %v", i+3, line, sample, ok, err)
+		}
+	}
+
+	expected := []Column{{Name: "id", Type: "number"}, {Name: "label", Type: "string"}, {Name: "score", Type: "number"}}
+	if !reflect.DeepEqual(dataSource.Schema, expected) || dataSource.Header != "id,label,score" {
+		t.Errorf("expected schema %v, got %v with header %q", expected, dataSource.Schema, dataSource.Header)
+	}
+
+	if _, ok, err := parser.ParseLine(6, "   "); ok || err != nil {
+		t.Errorf("expected the blank line skipped, got %v, %v", ok, err)
+	}
+}
+
+func TestJSONKind(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{value: nil, expected: "null"},
+		{value: true, expected: "boolean"},
+		{value: json.Number("1"), expected: "number"},
+		{value: 1.5, expected: "number"},
+		{value: "a", expected: "string"},
+		{value: []interface{}{1}, expected: "array"},
+		{value: map[string]interface{}{}, expected: "object"},
+	}
+
+	for _, tt := range tests {
+		if kind := jsonKind(tt.value); kind != tt.expected {
+			t.Errorf("expected kind %s of %v, got %s", tt.expected, tt.value, kind)
+		}
+	}
+}
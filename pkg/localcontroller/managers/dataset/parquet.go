@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/kubeedge/sedna/pkg/localcontroller/managers/dataset/parquet"
+)
+
+// parseParquet parses the samples of parquet format, each row is converted to a JSON object line
+func parseParquet(url string, add func(sample string) error) (*DataSource, error) {
+	file, err := os.Open(url)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := parquet.Open(file, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("read parquet file %s failed, error: %v", url, err)
+	}
+
+	dataSource := DataSource{}
+	var names []string
+	for _, c := range f.Columns {
+		dataSource.Schema = append(dataSource.Schema, Column{Name: c.Name, Type: c.Type})
+		names = append(names, c.Name)
+	}
+	dataSource.Header = strings.Join(names, ",")
+
+	// the keys of JSON object are in the order of the columns
+	keys := make([][]byte, len(names))
+	for i, name := range names {
+		if keys[i], err = json.Marshal(name); err != nil {
+			return nil, err
+		}
+	}
+
+	err = f.ReadRows(func(row []interface{}) error {
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		for i, v := range row {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(keys[i])
+			buf.WriteByte(':')
+
+			// NaN and Inf are not valid JSON numbers
+			switch f := v.(type) {
+			case float32:
+				if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+					v = nil
+				}
+			case float64:
+				if math.IsNaN(f) || math.IsInf(f, 0) {
+					v = nil
+				}
+			}
+
+			value, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			buf.Write(value)
+		}
+		buf.WriteByte('}')
+
+		dataSource.NumberOfSamples++
+		return add(buf.String())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read parquet file %s failed, error: %v", url, err)
+	}
+
+	return &dataSource, nil
+}
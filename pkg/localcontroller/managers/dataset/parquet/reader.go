@@ -0,0 +1,434 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package parquet reads the rows of the flat parquet files, which is enough to index the datasets.
+// The pages are decoded by github.com/xitongsys/parquet-go, while the metadata and page headers
+// of the untrusted file are validated first, so that a corrupted count is rejected before it sizes
+// any allocation.
+package parquet
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/schema"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/types"
+)
+
+const (
+	magic = "PAR1"
+
+	// maxPageSize is the max size of a page, to avoid huge allocations of the corrupted file
+	maxPageSize = 256 * 1024 * 1024
+
+	// maxPageValues is the max number of values of a page, the writers usually limit it to 20000
+	maxPageValues = 4 * 1024 * 1024
+
+	// batchRows is the number of rows read from each column at a time
+	batchRows = 1000
+)
+
+// Column is the column of the flat schema
+type Column struct {
+	Name string
+	// Type is the physical type, with "(STRING)" if annotated as string, e.g. "BYTE_ARRAY(STRING)"
+	Type     string
+	Optional bool
+}
+
+// File is the opened parquet file
+type File struct {
+	Columns []Column
+	NumRows int64
+
+	r        io.ReaderAt
+	size     int64
+	metaData []byte
+	leaves   []*parquet.SchemaElement
+}
+
+// Open reads and validates the metadata of the parquet file
+func Open(r io.ReaderAt, size int64) (f *File, err error) {
+	defer recoverError(&err)
+
+	if size < int64(2*len(magic)+4) {
+		return nil, fmt.Errorf("not a parquet file, size %d is too small", size)
+	}
+
+	head := make([]byte, len(magic))
+	if _, err := r.ReadAt(head, 0); err != nil {
+		return nil, err
+	}
+	tail := make([]byte, 4+len(magic))
+	if _, err := r.ReadAt(tail, size-int64(len(tail))); err != nil {
+		return nil, err
+	}
+	if string(head) != magic || string(tail[4:]) != magic {
+		return nil, fmt.Errorf("not a parquet file, magic number mismatch")
+	}
+
+	metaSize := int64(binary.LittleEndian.Uint32(tail))
+	if metaSize > size-int64(len(head)+len(tail)) {
+		return nil, fmt.Errorf("invalid metadata size %d", metaSize)
+	}
+	metaData := make([]byte, metaSize)
+	if _, err := r.ReadAt(metaData, size-int64(len(tail))-metaSize); err != nil {
+		return nil, err
+	}
+
+	meta, err := readFileMetaData(metaData)
+	if err != nil {
+		return nil, err
+	}
+
+	f = &File{
+		NumRows:  meta.NumRows,
+		r:        r,
+		size:     size,
+		metaData: metaData,
+	}
+
+	if len(meta.Schema) == 0 || int(meta.Schema[0].GetNumChildren()) != len(meta.Schema)-1 {
+		return nil, fmt.Errorf("nested schema is not supported")
+	}
+	for _, e := range meta.Schema[1:] {
+		if e.GetNumChildren() > 0 || e.Type == nil {
+			return nil, fmt.Errorf("nested column %s is not supported", e.Name)
+		}
+		if e.GetRepetitionType() == parquet.FieldRepetitionType_REPEATED {
+			return nil, fmt.Errorf("repeated column %s is not supported", e.Name)
+		}
+
+		typ := e.Type.String()
+		if isString(e) {
+			typ += "(STRING)"
+		}
+		f.leaves = append(f.leaves, e)
+		f.Columns = append(f.Columns, Column{
+			Name:     e.Name,
+			Type:     typ,
+			Optional: e.GetRepetitionType() == parquet.FieldRepetitionType_OPTIONAL,
+		})
+	}
+
+	if err := f.validate(meta, size-int64(len(tail))-metaSize); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// ReadRows calls fn with each row, whose values are in the order of the columns.
+// The value is nil if null, string for the string column, and []byte for other binary columns.
+func (f *File) ReadRows(fn func(row []interface{}) error) (err error) {
+	defer recoverError(&err)
+
+	// the reader renames the schema of the metadata, so each read decodes its own copy
+	meta, err := readFileMetaData(f.metaData)
+	if err != nil {
+		return err
+	}
+	pr := &reader.ParquetReader{
+		NP:            1,
+		PFile:         newReaderAtFile(f.r, f.size),
+		Footer:        meta,
+		SchemaHandler: schema.NewSchemaHandlerFromSchemaList(meta.Schema),
+		ColumnBuffers: make(map[string]*reader.ColumnBufferType),
+	}
+	pr.RenameSchema()
+
+	columns := make([][]interface{}, len(f.leaves))
+	for read := int64(0); read < f.NumRows; {
+		n := f.NumRows - read
+		if n > batchRows {
+			n = batchRows
+		}
+
+		for i := range f.leaves {
+			values, _, _, err := pr.ReadColumnByIndex(int64(i), n)
+			if err != nil {
+				return fmt.Errorf("failed to read column %s: %w", f.leaves[i].Name, err)
+			}
+			// the reader stops silently at the broken page, so the count is checked
+			if int64(len(values)) != n {
+				return fmt.Errorf("column %s has %d values at row %d, expected %d", f.leaves[i].Name, len(values), read, n)
+			}
+			columns[i] = values
+		}
+
+		for r := int64(0); r < n; r++ {
+			row := make([]interface{}, len(columns))
+			for i := range columns {
+				row[i] = convert(f.leaves[i], columns[i][r])
+			}
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		read += n
+	}
+
+	return nil
+}
+
+// validate checks the counts and sizes of the row groups and page headers against the file,
+// since the decoder allocates by them. dataEnd is the offset where the metadata starts.
+func (f *File) validate(meta *parquet.FileMetaData, dataEnd int64) error {
+	if f.NumRows < 0 {
+		return fmt.Errorf("invalid number of rows %d", f.NumRows)
+	}
+
+	var rows int64
+	for i, rg := range meta.RowGroups {
+		if rg.NumRows < 0 || rg.NumRows > f.NumRows-rows {
+			return fmt.Errorf("row group %d has invalid number of rows %d", i, rg.NumRows)
+		}
+		rows += rg.NumRows
+
+		if len(rg.Columns) != len(f.leaves) {
+			return fmt.Errorf("row group %d has %d columns, expected %d", i, len(rg.Columns), len(f.leaves))
+		}
+		for j, c := range rg.Columns {
+			e := f.leaves[j]
+			m := c.MetaData
+			if m == nil || c.FilePath != nil {
+				return fmt.Errorf("column %s of row group %d is not in the file", e.Name, i)
+			}
+			if len(m.PathInSchema) != 1 || m.PathInSchema[0] != e.Name || m.Type != *e.Type {
+				return fmt.Errorf("column chunk %v mismatches the schema column %s", m.PathInSchema, e.Name)
+			}
+			// each row of the flat schema has exactly one value, including the null
+			if m.NumValues != rg.NumRows {
+				return fmt.Errorf("column %s of row group %d has %d values, expected %d", e.Name, i, m.NumValues, rg.NumRows)
+			}
+			if err := f.validatePages(m, e, dataEnd); err != nil {
+				return fmt.Errorf("column %s of row group %d: %w", e.Name, i, err)
+			}
+		}
+	}
+
+	if rows != f.NumRows {
+		return fmt.Errorf("row groups have %d rows, expected %d", rows, f.NumRows)
+	}
+	return nil
+}
+
+// validatePages checks the page headers of the column chunk
+func (f *File) validatePages(m *parquet.ColumnMetaData, e *parquet.SchemaElement, dataEnd int64) error {
+	// the same start as the decoder reads from
+	start := m.DataPageOffset
+	if m.DictionaryPageOffset != nil {
+		start = *m.DictionaryPageOffset
+	}
+	if start < int64(len(magic)) || start > dataEnd || m.TotalCompressedSize < 0 || m.TotalCompressedSize > dataEnd-start {
+		return fmt.Errorf("invalid chunk of offset %d and size %d", start, m.TotalCompressedSize)
+	}
+
+	sr := io.NewSectionReader(f.r, start, m.TotalCompressedSize)
+	br := bufio.NewReader(sr)
+	// bufio.NewReader returns br itself, so br keeps the position after the header
+	protocol := thrift.NewTCompactProtocolConf(thrift.NewStreamTransportR(br), &thrift.TConfiguration{
+		MaxMessageSize: maxPageSize,
+	})
+	required := e.GetRepetitionType() == parquet.FieldRepetitionType_REQUIRED
+
+	var values int64
+	for values < m.NumValues {
+		h := parquet.NewPageHeader()
+		if err := h.Read(context.TODO(), protocol); err != nil {
+			return fmt.Errorf("invalid page header: %w", err)
+		}
+		read, _ := sr.Seek(0, io.SeekCurrent)
+		remaining := m.TotalCompressedSize - read + int64(br.Buffered())
+		if h.CompressedPageSize < 0 || int64(h.CompressedPageSize) > remaining ||
+			h.UncompressedPageSize < 0 || h.UncompressedPageSize > maxPageSize {
+			return fmt.Errorf("invalid page size %d, uncompressed %d, with %d bytes left in the chunk",
+				h.CompressedPageSize, h.UncompressedPageSize, remaining)
+		}
+
+		left := m.NumValues - values
+		if left > maxPageValues {
+			left = maxPageValues
+		}
+		count := func(n int32) error {
+			if n < 0 || int64(n) > left {
+				return fmt.Errorf("invalid number of values %d of page, expected at most %d", n, left)
+			}
+			return nil
+		}
+		// the plain values take at least minBits each, while the levels and RLE runs may be smaller
+		plain := func(n int32, encoding parquet.Encoding, size int64) error {
+			if encoding == parquet.Encoding_PLAIN && int64(n)*minBits(e) > 8*size {
+				return fmt.Errorf("%d plain values of %s mismatch the page size %d", n, e.Type, size)
+			}
+			return nil
+		}
+
+		switch h.Type {
+		case parquet.PageType_DICTIONARY_PAGE:
+			dh := h.DictionaryPageHeader
+			if dh == nil {
+				return fmt.Errorf("dictionary page without header")
+			}
+			if dh.NumValues < 0 || int64(dh.NumValues) > m.NumValues {
+				return fmt.Errorf("invalid number of values %d of dictionary page", dh.NumValues)
+			}
+			if err := plain(dh.NumValues, parquet.Encoding_PLAIN, int64(h.UncompressedPageSize)); err != nil {
+				return err
+			}
+
+		case parquet.PageType_DATA_PAGE:
+			dh := h.DataPageHeader
+			if dh == nil {
+				return fmt.Errorf("data page without header")
+			}
+			if err := count(dh.NumValues); err != nil {
+				return err
+			}
+			if required {
+				if err := plain(dh.NumValues, dh.Encoding, int64(h.UncompressedPageSize)); err != nil {
+					return err
+				}
+			}
+			values += int64(dh.NumValues)
+
+		case parquet.PageType_DATA_PAGE_V2:
+			dh := h.DataPageHeaderV2
+			if dh == nil {
+				return fmt.Errorf("data page v2 without header")
+			}
+			if err := count(dh.NumValues); err != nil {
+				return err
+			}
+			if dh.NumNulls < 0 || dh.NumNulls > dh.NumValues || dh.NumRows < 0 || dh.NumRows > dh.NumValues {
+				return fmt.Errorf("invalid number of nulls %d or rows %d of page", dh.NumNulls, dh.NumRows)
+			}
+			levels := int64(dh.DefinitionLevelsByteLength) + int64(dh.RepetitionLevelsByteLength)
+			if dh.DefinitionLevelsByteLength < 0 || dh.RepetitionLevelsByteLength < 0 ||
+				levels > int64(h.CompressedPageSize) || levels > int64(h.UncompressedPageSize) {
+				return fmt.Errorf("invalid levels size %d of page", levels)
+			}
+			if err := plain(dh.NumValues-dh.NumNulls, dh.Encoding, int64(h.UncompressedPageSize)-levels); err != nil {
+				return err
+			}
+			values += int64(dh.NumValues)
+		}
+		// other pages (e.g. index page) are skipped
+
+		if _, err := br.Discard(int(h.CompressedPageSize)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readFileMetaData decodes the metadata, whose lists and strings can't be larger than itself
+func readFileMetaData(data []byte) (*parquet.FileMetaData, error) {
+	buf := thrift.NewTMemoryBufferLen(len(data))
+	buf.Write(data)
+	protocol := thrift.NewTCompactProtocolConf(buf, &thrift.TConfiguration{
+		MaxMessageSize: int32(len(data)),
+	})
+
+	meta := parquet.NewFileMetaData()
+	if err := meta.Read(context.TODO(), protocol); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	return meta, nil
+}
+
+// minBits returns the min bits of a plain encoded value of the column
+func minBits(e *parquet.SchemaElement) int64 {
+	switch *e.Type {
+	case parquet.Type_BOOLEAN:
+		return 1
+	case parquet.Type_INT32, parquet.Type_FLOAT, parquet.Type_BYTE_ARRAY:
+		// the byte array has the length of 4 bytes
+		return 32
+	case parquet.Type_INT64, parquet.Type_DOUBLE:
+		return 64
+	case parquet.Type_INT96:
+		return 96
+	case parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		return 8 * int64(e.GetTypeLength())
+	}
+	return 0
+}
+
+func isString(e *parquet.SchemaElement) bool {
+	return e.GetConvertedType() == parquet.ConvertedType_UTF8 && e.IsSetConvertedType() ||
+		e.LogicalType != nil && e.LogicalType.IsSetSTRING()
+}
+
+// convert converts the decoded value to the documented type of ReadRows
+func convert(e *parquet.SchemaElement, v interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	switch {
+	case *e.Type == parquet.Type_INT96:
+		return types.INT96ToTime(s).UTC()
+	case isString(e):
+		return s
+	default:
+		return []byte(s)
+	}
+}
+
+// recoverError turns the panic of decoding the corrupted file into the error
+func recoverError(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("invalid parquet file: %v", r)
+	}
+}
+
+// readerAtFile adapts io.ReaderAt to the read-only source.ParquetFile of the decoder
+type readerAtFile struct {
+	*io.SectionReader
+}
+
+func newReaderAtFile(r io.ReaderAt, size int64) *readerAtFile {
+	return &readerAtFile{io.NewSectionReader(r, 0, size)}
+}
+
+// Open opens another reader of the same file for each column
+func (f *readerAtFile) Open(name string) (source.ParquetFile, error) {
+	if name != "" {
+		return nil, fmt.Errorf("external file %s is not supported", name)
+	}
+	return newReaderAtFile(f.SectionReader, f.Size()), nil
+}
+
+func (f *readerAtFile) Create(name string) (source.ParquetFile, error) {
+	return nil, fmt.Errorf("parquet file is read-only")
+}
+
+func (f *readerAtFile) Write(p []byte) (int, error) {
+	return 0, fmt.Errorf("parquet file is read-only")
+}
+
+func (f *readerAtFile) Close() error {
+	return nil
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parquet
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+func TestReadRows(t *testing.T) {
+	expectedColumns := []Column{
+		{Name: "id", Type: "INT64"},
+		{Name: "name", Type: "BYTE_ARRAY(STRING)", Optional: true},
+		{Name: "score", Type: "DOUBLE"},
+		{Name: "ok", Type: "BOOLEAN"},
+		{Name: "label", Type: "INT32"},
+	}
+
+	for _, name := range []string{"uncompressed", "snappy", "gzip"} {
+		file, err := os.Open("testdata/" + name + ".parquet")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		info, _ := file.Stat()
+
+		f, err := Open(file, info.Size())
+		if err != nil {
+			t.Fatalf("%s: failed to open: %v", name, err)
+		}
+		if !reflect.DeepEqual(f.Columns, expectedColumns) {
+			t.Errorf("%s: columns mismatch: %+v", name, f.Columns)
+		}
+		if f.NumRows != 100 {
+			t.Errorf("%s: expected 100 rows, got %d", name, f.NumRows)
+		}
+
+		i := 0
+		err = f.ReadRows(func(row []interface{}) error {
+			var rowName interface{}
+			if i%3 != 0 {
+				rowName = fmt.Sprintf("name-%d", i%5)
+			}
+			expected := []interface{}{int64(i), rowName, float64(i) / 2, i%2 == 0, int32(i % 7)}
+			if !reflect.DeepEqual(row, expected) {
+				return fmt.Errorf("row %d mismatch, expected %v, got %v", i, expected, row)
+			}
+			i++
+			return nil
+		})
+		if err != nil {
+			t.Errorf("%s: failed to read rows: %v", name, err)
+		}
+		if i != 100 {
+			t.Errorf("%s: expected 100 rows, read %d", name, i)
+		}
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	data, err := os.ReadFile("testdata/snappy.parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, b := range map[string][]byte{
+		"empty":     {},
+		"magic":     append([]byte("PAR0"), data[4:]...),
+		"truncated": data[:len(data)/2],
+	} {
+		if _, err := Open(bytesReaderAt(b), int64(len(b))); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+type bytesReaderAt []byte
+
+func (b bytesReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > int64(len(b)) {
+		return 0, fmt.Errorf("out of range")
+	}
+	return copy(p, b[off:]), nil
+}
+
+// writeInt32File writes a file of a required INT32 column, with the pages of the headers,
+// whose data size is the compressed page size clamped to [0, 64]
+func writeInt32File(t *testing.T, numRows int64, headers ...*parquet.PageHeader) []byte {
+	ctx := context.TODO()
+	data := thrift.NewTMemoryBuffer()
+	data.WriteString(magic)
+
+	for _, h := range headers {
+		if err := h.Write(ctx, thrift.NewTCompactProtocolConf(data, nil)); err != nil {
+			t.Fatal(err)
+		}
+		n := int(h.CompressedPageSize)
+		if n < 0 {
+			n = 0
+		} else if n > 64 {
+			n = 64
+		}
+		page := make([]byte, n)
+		for i := 0; i+4 <= len(page); i += 4 {
+			binary.LittleEndian.PutUint32(page[i:], uint32(i/4))
+		}
+		data.Write(page)
+	}
+	size := int64(data.Len() - len(magic))
+
+	typ := parquet.Type_INT32
+	repetition := parquet.FieldRepetitionType_REQUIRED
+	numChildren := int32(1)
+	meta := &parquet.FileMetaData{
+		NumRows: numRows,
+		Schema: []*parquet.SchemaElement{
+			{Name: "schema", NumChildren: &numChildren},
+			{Name: "v", Type: &typ, RepetitionType: &repetition},
+		},
+		RowGroups: []*parquet.RowGroup{{
+			NumRows:       numRows,
+			TotalByteSize: size,
+			Columns: []*parquet.ColumnChunk{{
+				FileOffset: int64(len(magic)),
+				MetaData: &parquet.ColumnMetaData{
+					Type:                  typ,
+					Encodings:             []parquet.Encoding{parquet.Encoding_PLAIN},
+					PathInSchema:          []string{"v"},
+					Codec:                 parquet.CompressionCodec_UNCOMPRESSED,
+					NumValues:             numRows,
+					TotalUncompressedSize: size,
+					TotalCompressedSize:   size,
+					DataPageOffset:        int64(len(magic)),
+				},
+			}},
+		}},
+	}
+
+	metaData := thrift.NewTMemoryBuffer()
+	if err := meta.Write(ctx, thrift.NewTCompactProtocolConf(metaData, nil)); err != nil {
+		t.Fatal(err)
+	}
+	data.Write(metaData.Bytes())
+	tail := make([]byte, 4)
+	binary.LittleEndian.PutUint32(tail, uint32(metaData.Len()))
+	data.Write(tail)
+	data.WriteString(magic)
+	return data.Bytes()
+}
+
+func dataPage(numValues, size int32) *parquet.PageHeader {
+	return &parquet.PageHeader{
+		Type:                 parquet.PageType_DATA_PAGE,
+		UncompressedPageSize: size,
+		CompressedPageSize:   size,
+		DataPageHeader: &parquet.DataPageHeader{
+			NumValues:               numValues,
+			Encoding:                parquet.Encoding_PLAIN,
+			DefinitionLevelEncoding: parquet.Encoding_RLE,
+			RepetitionLevelEncoding: parquet.Encoding_RLE,
+		},
+	}
+}
+
+func TestCorruptedPageHeader(t *testing.T) {
+	negative := dataPage(3, 12)
+	negative.CompressedPageSize = -1
+	beyond := dataPage(3, 12)
+	beyond.CompressedPageSize = 100
+	uncompressed := dataPage(3, 12)
+	uncompressed.UncompressedPageSize = maxPageSize + 1
+
+	for _, tc := range []struct {
+		name    string
+		numRows int64
+		headers []*parquet.PageHeader
+		valid   bool
+	}{
+		{name: "valid", numRows: 3, headers: []*parquet.PageHeader{dataPage(3, 12)}, valid: true},
+		{name: "two pages", numRows: 5, headers: []*parquet.PageHeader{dataPage(3, 12), dataPage(2, 8)}, valid: true},
+		{name: "negative values", numRows: 3, headers: []*parquet.PageHeader{dataPage(-1, 12)}},
+		{name: "huge values", numRows: 3, headers: []*parquet.PageHeader{dataPage(math.MaxInt32, 12)}},
+		{name: "values beyond rows", numRows: 3, headers: []*parquet.PageHeader{dataPage(4, 16)}},
+		{name: "values beyond page size", numRows: 3, headers: []*parquet.PageHeader{dataPage(3, 8)}},
+		{name: "negative page size", numRows: 3, headers: []*parquet.PageHeader{negative}},
+		{name: "page beyond chunk", numRows: 3, headers: []*parquet.PageHeader{beyond}},
+		{name: "huge uncompressed size", numRows: 3, headers: []*parquet.PageHeader{uncompressed}},
+		{name: "missing page", numRows: 5, headers: []*parquet.PageHeader{dataPage(3, 12)}},
+		{name: "negative rows", numRows: -1, headers: []*parquet.PageHeader{dataPage(3, 12)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := writeInt32File(t, tc.numRows, tc.headers...)
+			f, err := Open(bytesReaderAt(b), int64(len(b)))
+			if !tc.valid {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to open: %v", err)
+			}
+
+			var values []interface{}
+			err = f.ReadRows(func(row []interface{}) error {
+				values = append(values, row[0])
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("failed to read rows: %v", err)
+			}
+			if int64(len(values)) != tc.numRows || values[0] != int32(0) {
+				t.Errorf("unexpected values %v", values)
+			}
+		})
+	}
+}
@@ -0,0 +1,395 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataset
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kubeedge/sedna/pkg/localcontroller/util"
+)
+
+const (
+	// SegmentSamples is the max number of samples in a segment file
+	SegmentSamples = 10000
+
+	segmentSuffix = ".seg"
+)
+
+// segmentStore keeps the samples of a dataset in the segment files on the disk,
+// each line of the segment file is a sample.
+type segmentStore struct {
+	dir string
+
+	lock    sync.RWMutex
+	count   int
+	removed bool
+}
+
+// openSegmentStore opens the segment files in the dir, and counts the samples
+func openSegmentStore(dir string) (*segmentStore, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	s := &segmentStore{dir: dir}
+
+	segments, err := s.segments()
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range segments {
+		if name != s.segmentPath(i) {
+			// the segments are not continuous, drop them
+			return s, s.reset()
+		}
+	}
+
+	if n := len(segments); n > 0 {
+		lines, err := countLines(segments[n-1])
+		if err != nil {
+			return nil, err
+		}
+		s.count = (n-1)*SegmentSamples + lines
+	}
+
+	return s, nil
+}
+
+func (s *segmentStore) segmentPath(i int) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%08d%s", i, segmentSuffix))
+}
+
+// segments returns the paths of the segment files in order
+func (s *segmentStore) segments() ([]string, error) {
+	entries, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), segmentSuffix) {
+			segments = append(segments, filepath.Join(s.dir, entry.Name()))
+		}
+	}
+	sort.Strings(segments)
+	return segments, nil
+}
+
+// Count returns the number of the samples
+func (s *segmentStore) Count() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.count
+}
+
+// Append appends the samples to the segment files
+func (s *segmentStore) Append(samples []string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.removed {
+		return fmt.Errorf("samples of dir %s are removed", s.dir)
+	}
+
+	for len(samples) > 0 {
+		i := s.count / SegmentSamples
+		n := SegmentSamples - s.count%SegmentSamples
+		if n > len(samples) {
+			n = len(samples)
+		}
+
+		if err := appendLines(s.segmentPath(i), samples[:n]); err != nil {
+			return err
+		}
+		s.count += n
+		samples = samples[n:]
+	}
+
+	return nil
+}
+
+// Read reads at most max samples from the position, max <= 0 means all samples after the position
+func (s *segmentStore) Read(position int, max int) ([]string, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	end := s.count
+	if max > 0 && position+max < end {
+		end = position + max
+	}
+
+	var samples []string
+	for position < end {
+		i := position / SegmentSamples
+		skip := position % SegmentSamples
+		n := SegmentSamples - skip
+		if n > end-position {
+			n = end - position
+		}
+
+		lines, err := readLines(s.segmentPath(i), skip, n)
+		if err != nil {
+			return nil, err
+		}
+		if len(lines) < n {
+			return nil, fmt.Errorf("segment %s is truncated", s.segmentPath(i))
+		}
+		samples = append(samples, lines...)
+		position += n
+	}
+
+	return samples, nil
+}
+
+// Reset drops all samples
+func (s *segmentStore) Reset() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.reset()
+}
+
+func (s *segmentStore) reset() error {
+	segments, err := s.segments()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range segments {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	s.count = 0
+	return nil
+}
+
+// Remove removes the dir of the segment files, the samples can't be appended after removed
+func (s *segmentStore) Remove() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.removed = true
+	s.count = 0
+	return os.RemoveAll(s.dir)
+}
+
+// Snapshot links the segments of the first count samples to the dir, and writes the samples to the hash.
+// The full segments are never changed, so they are shared by the hard links.
+func (s *segmentStore) Snapshot(dir string, count int, h io.Writer) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if count > s.count {
+		return fmt.Errorf("only %d samples, can't snapshot %d samples", s.count, count)
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	for i := 0; i < count/SegmentSamples; i++ {
+		src := s.segmentPath(i)
+		dst := filepath.Join(dir, filepath.Base(src))
+		if err := os.Link(src, dst); err != nil {
+			if _, err := util.CopyFile(src, dst); err != nil {
+				return err
+			}
+		}
+
+		f, err := os.Open(dst)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if rest := count % SegmentSamples; rest > 0 {
+		src := s.segmentPath(count / SegmentSamples)
+		lines, err := readLines(src, 0, rest)
+		if err != nil {
+			return err
+		}
+		if err := appendLines(filepath.Join(dir, filepath.Base(src)), lines); err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if _, err := io.WriteString(h, line+"\n"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// appendLines appends the lines to the file
+func appendLines(path string, lines []string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	for _, line := range lines {
+		if _, err := w.WriteString(line); err != nil {
+			f.Close()
+			return err
+		}
+		if err := w.WriteByte('\n'); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readLines reads at most n lines after skipping the lines of the file
+func readLines(path string, skip int, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for i := 0; len(lines) < n && scanner.Scan(); i++ {
+		if i >= skip {
+			lines = append(lines, scanner.Text())
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// countLines counts the lines of the file
+func countLines(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		count++
+	}
+
+	return count, scanner.Err()
+}
+
+// Cursor reads the samples of the dataset in order from a position,
+// the samples are read from the segment files instead of the memory.
+type Cursor struct {
+	store    *segmentStore
+	position int
+}
+
+// Position returns the position of the cursor, which is the number of the samples before it
+func (c *Cursor) Position() int {
+	return c.position
+}
+
+// Remaining returns the number of the samples after the position
+func (c *Cursor) Remaining() int {
+	if n := c.store.Count() - c.position; n > 0 {
+		return n
+	}
+	return 0
+}
+
+// Next reads at most max samples after the position, and moves the cursor after them.
+// The max <= 0 means all samples after the position.
+func (c *Cursor) Next(max int) ([]string, error) {
+	samples, err := c.store.Read(c.position, max)
+	if err != nil {
+		return nil, err
+	}
+
+	c.position += len(samples)
+	return samples, nil
+}
+
+// SampleRange is the range [Start, End) of the sample positions
+type SampleRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Len returns the number of the samples in the range
+func (r SampleRange) Len() int {
+	if r.End > r.Start {
+		return r.End - r.Start
+	}
+	return 0
+}
+
+// At returns a new cursor at the position of the same samples
+func (c *Cursor) At(position int) *Cursor {
+	return &Cursor{store: c.store, position: position}
+}
+
+// ReadRanges calls fn with the samples of the ranges in order, at most SegmentSamples at a time,
+// so the samples are never loaded into the memory at once. The cursor is not moved.
+func (c *Cursor) ReadRanges(ranges []SampleRange, fn func(samples []string) error) error {
+	for _, r := range ranges {
+		for position := r.Start; position < r.End; {
+			n := r.End - position
+			if n > SegmentSamples {
+				n = SegmentSamples
+			}
+
+			samples, err := c.store.Read(position, n)
+			if err != nil {
+				return err
+			}
+			if len(samples) == 0 {
+				return fmt.Errorf("samples of range [%d, %d) are missing from %d", r.Start, r.End, position)
+			}
+			if err := fn(samples); err != nil {
+				return err
+			}
+			position += len(samples)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataset
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func makeSamples(from, to int) []string {
+	var samples []string
+	for i := from; i < to; i++ {
+		samples = append(samples, fmt.Sprintf("sample-%d", i))
+	}
+	return samples
+}
+
+func TestSegmentRollover(t *testing.T) {
+	dir := t.TempDir()
+	s, err := openSegmentStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the second batch fills the first segment and rolls over to the second
+	if err := s.Append(makeSamples(0, SegmentSamples-3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Append(makeSamples(SegmentSamples-3, SegmentSamples+5)); err != nil {
+		t.Fatal(err)
+	}
+
+	segments, err := s.segments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(segments, []string{s.segmentPath(0), s.segmentPath(1)}) {
+		t.Fatalf("expected 2 segments, got %v", segments)
+	}
+	for i, expected := range []int{SegmentSamples, 5} {
+		if n, _ := countLines(segments[i]); n != expected {
+			t.Errorf("expected %d samples in segment %d, got %d", expected, i, n)
+		}
+	}
+	if s.Count() != SegmentSamples+5 {
+		t.Errorf("expected %d samples, got %d", SegmentSamples+5, s.Count())
+	}
+
+	// read across the segments
+	samples, err := s.Read(SegmentSamples-2, 4)
+	if err != nil || !reflect.DeepEqual(samples, makeSamples(SegmentSamples-2, SegmentSamples+2)) {
+		t.Errorf("unexpected samples %v across the segments, err=%v", samples, err)
+	}
+	samples, err = s.Read(SegmentSamples+3, 0)
+	if err != nil || !reflect.DeepEqual(samples, makeSamples(SegmentSamples+3, SegmentSamples+5)) {
+		t.Errorf("unexpected samples %v to the end, err=%v", samples, err)
+	}
+	if samples, err := s.Read(SegmentSamples+5, 10); err != nil || len(samples) != 0 {
+		t.Errorf("expected no samples at the end, got %v, %v", samples, err)
+	}
+
+	// the samples are counted again after restart
+	reopened, err := openSegmentStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Count() != SegmentSamples+5 {
+		t.Errorf("expected %d samples after reopen, got %d", SegmentSamples+5, reopened.Count())
+	}
+
+	// the segments are dropped if not continuous
+	if err := os.Remove(s.segmentPath(0)); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err = openSegmentStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if segments, _ := reopened.segments(); reopened.Count() != 0 || len(segments) != 0 {
+		t.Errorf("expected the segments dropped, got %d samples in %v", reopened.Count(), segments)
+	}
+}
+
+func TestSegmentTruncated(t *testing.T) {
+	s, err := openSegmentStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Append(makeSamples(0, 10)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(s.segmentPath(0), []byte("sample-0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Read(0, 0); err == nil {
+		t.Errorf("expected error of the truncated segment")
+	}
+}
+
+func TestSegmentResetAndRemove(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "samples")
+	s, err := openSegmentStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Append(makeSamples(0, 3)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Reset(); err != nil || s.Count() != 0 {
+		t.Fatalf("expected no samples after reset, got %d, %v", s.Count(), err)
+	}
+	if err := s.Append(makeSamples(3, 4)); err != nil {
+		t.Fatal(err)
+	}
+	if samples, _ := s.Read(0, 0); !reflect.DeepEqual(samples, []string{"sample-3"}) {
+		t.Errorf("expected the samples appended after reset, got %v", samples)
+	}
+
+	if err := s.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected the dir removed, got %v", err)
+	}
+	if err := s.Append(makeSamples(0, 1)); err == nil {
+		t.Errorf("expected error of appending to the removed samples")
+	}
+}
+
+func TestSegmentSnapshot(t *testing.T) {
+	s, err := openSegmentStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := makeSamples(0, SegmentSamples+3)
+	if err := s.Append(samples); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "v1")
+	h := sha256.New()
+	if err := s.Snapshot(dir, SegmentSamples+2, h); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := sha256.Sum256([]byte(strings.Join(samples[:SegmentSamples+2], "\n") + "\n"))
+	if !reflect.DeepEqual(h.Sum(nil), expected[:]) {
+		t.Errorf("expected the hash of the samples")
+	}
+
+	snapshot, err := openSegmentStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read, _ := snapshot.Read(0, 0); !reflect.DeepEqual(read, samples[:SegmentSamples+2]) {
+		t.Errorf("expected %d samples in the snapshot, got %d", SegmentSamples+2, len(read))
+	}
+
+	if err := s.Snapshot(filepath.Join(t.TempDir(), "v2"), SegmentSamples+4, sha256.New()); err == nil {
+		t.Errorf("expected error of snapshot beyond the samples")
+	}
+}
+
+func TestCursor(t *testing.T) {
+	ds := newTestDataset(t, makeSamples(0, 5)...)
+
+	cursor := ds.NewCursor(1)
+	if cursor.Position() != 1 || cursor.Remaining() != 4 {
+		t.Errorf("expected position 1 and 4 remaining, got %d and %d", cursor.Position(), cursor.Remaining())
+	}
+
+	samples, err := cursor.Next(3)
+	if err != nil || !reflect.DeepEqual(samples, makeSamples(1, 4)) {
+		t.Errorf("unexpected samples %v, err=%v", samples, err)
+	}
+	if cursor.Position() != 4 || cursor.Remaining() != 1 {
+		t.Errorf("expected position 4 and 1 remaining, got %d and %d", cursor.Position(), cursor.Remaining())
+	}
+
+	// the cursor reads the samples appended
+	if err := ds.samples.Append(makeSamples(5, 7)); err != nil {
+		t.Fatal(err)
+	}
+	samples, err = cursor.Next(0)
+	if err != nil || !reflect.DeepEqual(samples, makeSamples(4, 7)) {
+		t.Errorf("unexpected samples %v, err=%v", samples, err)
+	}
+	if samples, _ := cursor.Next(0); len(samples) != 0 || cursor.Position() != 7 || cursor.Remaining() != 0 {
+		t.Errorf("expected no samples at position 7, got %v at %d", samples, cursor.Position())
+	}
+
+	// the cursor is restored from its position, e.g. after restart
+	restored := ds.NewCursor(6)
+	if samples, _ := restored.Next(0); !reflect.DeepEqual(samples, []string{"sample-6"}) {
+		t.Errorf("unexpected samples %v of restored cursor", samples)
+	}
+
+	// the position beyond the samples after reset has no remaining samples
+	if err := ds.samples.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if cursor.Remaining() != 0 {
+		t.Errorf("expected no remaining samples after reset, got %d", cursor.Remaining())
+	}
+}
+
+func TestCursorReadRanges(t *testing.T) {
+	ds := newTestDataset(t, makeSamples(0, SegmentSamples+10)...)
+	cursor := ds.NewCursor(3)
+
+	var batches int
+	var samples []string
+	ranges := []SampleRange{{Start: 2, End: 4}, {Start: 8, End: SegmentSamples + 9}, {Start: 5, End: 5}}
+	err := cursor.ReadRanges(ranges, func(batch []string) error {
+		if len(batch) > SegmentSamples {
+			t.Errorf("expected at most %d samples of a batch, got %d", SegmentSamples, len(batch))
+		}
+		batches++
+		samples = append(samples, batch...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := append(makeSamples(2, 4), makeSamples(8, SegmentSamples+9)...)
+	if batches != 3 || !reflect.DeepEqual(samples, expected) {
+		t.Errorf("expected %d samples in 3 batches, got %d in %d", len(expected), len(samples), batches)
+	}
+	if cursor.Position() != 3 {
+		t.Errorf("expected the cursor not moved, got position %d", cursor.Position())
+	}
+	if at := cursor.At(SegmentSamples + 8); at.Position() != SegmentSamples+8 || at.Remaining() != 2 {
+		t.Errorf("expected 2 remaining at position %d, got %d", at.Position(), at.Remaining())
+	}
+
+	missing := []SampleRange{{Start: SegmentSamples + 8, End: SegmentSamples + 12}}
+	if err := cursor.ReadRanges(missing, func([]string) error { return nil }); err == nil {
+		t.Errorf("expected error of the missing samples")
+	}
+}
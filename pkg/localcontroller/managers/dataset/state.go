@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataset
+
+import (
+	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
+	"github.com/kubeedge/sedna/pkg/localcontroller/managers"
+)
+
+// State defines the state of dataset shown by the LC API
+type State struct {
+	URL    string `json:"url"`
+	Format string `json:"format"`
+	// NumberOfSamples is the number of the samples in the data source at the last check,
+	// and StoredSamples is the number of the samples read and kept by LC
+	NumberOfSamples int                      `json:"numberOfSamples"`
+	StoredSamples   int                      `json:"storedSamples"`
+	Schema          []Column                 `json:"schema,omitempty"`
+	Versions        []sednav1.DatasetVersion `json:"versions,omitempty"`
+	// Pins are the versions pinned by the jobs, which are not pruned: job name -> version
+	Pins    map[string]string       `json:"pins,omitempty"`
+	Quality *sednav1.DatasetQuality `json:"quality,omitempty"`
+}
+
+// ListStates lists the states of the datasets
+func (dm *Manager) ListStates() []managers.ResourceState {
+	dm.mapLock.RLock()
+	datasets := make(map[string]*Dataset, len(dm.DatasetMap))
+	for name, ds := range dm.DatasetMap {
+		// the dataset is not decoded yet
+		if ds.Dataset != nil {
+			datasets[name] = ds
+		}
+	}
+	dm.mapLock.RUnlock()
+
+	states := make([]managers.ResourceState, 0, len(datasets))
+	for name, ds := range datasets {
+		states = append(states, ds.state(dm.Pins(name)))
+	}
+	return states
+}
+
+// GetState gets the state of the dataset
+func (dm *Manager) GetState(namespace, name string) (managers.ResourceState, bool) {
+	for _, state := range dm.ListStates() {
+		if state.Namespace == namespace && state.Name == name {
+			return state, true
+		}
+	}
+	return managers.ResourceState{}, false
+}
+
+func (ds *Dataset) state(pins map[string]string) managers.ResourceState {
+	spec, _ := ds.spec()
+	state := State{
+		URL:           spec.URL,
+		Format:        spec.Format,
+		StoredSamples: ds.samples.Count(),
+		Versions:      ds.Versions(),
+		Pins:          pins,
+		Quality:       ds.stats.status(),
+	}
+	if dataSource := ds.DataSource; dataSource != nil {
+		state.NumberOfSamples = dataSource.NumberOfSamples
+		state.Schema = dataSource.Schema
+	}
+
+	return managers.ResourceState{Namespace: ds.Namespace, Name: ds.Name, State: state}
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataset
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
+)
+
+const (
+	// maxTrackedSamples is the max number of the sample hashes kept to find the duplicates
+	maxTrackedSamples = 1 << 20
+	// maxLabels is the max number of the labels counted, the others are counted as otherLabel
+	maxLabels  = 256
+	otherLabel = "<other>"
+	// maxDistinctValues is the max number of the distinct values counted of a column
+	maxDistinctValues = 1000
+	// labelColumn is the name of the label column of the structured samples
+	labelColumn = "label"
+)
+
+// columnStats accumulates the statistics of a column
+type columnStats struct {
+	name     string
+	missing  int
+	values   int
+	numbers  int
+	min, max float64
+	sum      float64
+	distinct map[string]struct{}
+}
+
+func (c *columnStats) add(value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		c.missing++
+		return
+	}
+
+	c.values++
+	if len(c.distinct) < maxDistinctValues {
+		c.distinct[value] = struct{}{}
+	}
+
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		if c.numbers == 0 || f < c.min {
+			c.min = f
+		}
+		if c.numbers == 0 || f > c.max {
+			c.max = f
+		}
+		c.numbers++
+		c.sum += f
+	}
+}
+
+func (c *columnStats) status() sednav1.ColumnStats {
+	s := sednav1.ColumnStats{
+		Name:     c.name,
+		Type:     "string",
+		Missing:  c.missing,
+		Distinct: len(c.distinct),
+	}
+
+	if c.values > 0 && c.numbers == c.values {
+		s.Type = "number"
+		s.Min = strconv.FormatFloat(c.min, 'g', -1, 64)
+		s.Max = strconv.FormatFloat(c.max, 'g', -1, 64)
+		s.Mean = strconv.FormatFloat(c.sum/float64(c.numbers), 'g', 6, 64)
+	}
+	return s
+}
+
+// qualityStats accumulates the quality statistics of the samples as they are appended
+type qualityStats struct {
+	lock sync.Mutex
+
+	format string
+	// resolve returns the path on the host of the file of the index entry,
+	// or false if the file can't be checked on the host
+	resolve func(entry string) (string, bool)
+
+	samples      int
+	duplicates   int
+	missingFiles int
+	hashes       map[uint64]struct{}
+	labels       map[string]int
+
+	header  string
+	columns []*columnStats
+	label   int
+}
+
+func newQualityStats(resolve func(string) (string, bool)) *qualityStats {
+	q := &qualityStats{resolve: resolve}
+	q.reset("")
+	return q
+}
+
+// reset drops the statistics of the samples added
+func (q *qualityStats) reset(format string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.format = strings.ToLower(format)
+	q.samples = 0
+	q.duplicates = 0
+	q.missingFiles = 0
+	q.hashes = make(map[uint64]struct{})
+	q.labels = make(map[string]int)
+	q.header = ""
+	q.columns = nil
+	q.label = -1
+}
+
+// setHeader sets the columns of csv samples
+func (q *qualityStats) setHeader(header string) {
+	if header == q.header {
+		return
+	}
+
+	q.header = header
+	q.columns = nil
+	q.label = -1
+	for i, name := range strings.Split(header, ",") {
+		name = strings.TrimSpace(name)
+		q.columns = append(q.columns, &columnStats{name: name, distinct: make(map[string]struct{})})
+		if strings.EqualFold(name, labelColumn) {
+			q.label = i
+		}
+	}
+}
+
+// add adds the samples with the header of the data source
+func (q *qualityStats) add(samples []string, header string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.format == CSVFormat {
+		q.setHeader(header)
+	}
+
+	for _, sample := range samples {
+		q.samples++
+
+		h := fnv.New64a()
+		h.Write([]byte(sample))
+		sum := h.Sum64()
+		if _, ok := q.hashes[sum]; ok {
+			q.duplicates++
+		} else if len(q.hashes) < maxTrackedSamples {
+			q.hashes[sum] = struct{}{}
+		}
+
+		switch q.format {
+		case TXTFormat, ImageFolderFormat:
+			q.addEntry(sample)
+		case CSVFormat:
+			q.addRecord(sample)
+		case JSONLFormat, ParquetFormat:
+			q.addObject(sample)
+		}
+	}
+}
+
+// addEntry adds the index entry "<path> [label]"
+func (q *qualityStats) addEntry(sample string) {
+	fields := strings.Fields(sample)
+	if len(fields) == 0 {
+		return
+	}
+
+	if len(fields) > 1 {
+		q.addLabel(fields[1])
+	}
+
+	if path, ok := q.resolve(fields[0]); ok {
+		if _, err := os.Stat(path); err != nil {
+			q.missingFiles++
+		}
+	}
+}
+
+// addRecord adds the csv record to the column statistics
+func (q *qualityStats) addRecord(sample string) {
+	record, err := csv.NewReader(strings.NewReader(sample)).Read()
+	if err != nil {
+		return
+	}
+
+	for i, value := range record {
+		if i < len(q.columns) {
+			q.columns[i].add(value)
+		}
+	}
+	if q.label >= 0 && q.label < len(record) {
+		q.addLabel(strings.TrimSpace(record[q.label]))
+	}
+}
+
+// addObject adds the label of the JSON object
+func (q *qualityStats) addObject(sample string) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(sample), &obj); err != nil {
+		return
+	}
+
+	if v, ok := obj[labelColumn]; ok && v != nil {
+		q.addLabel(fmt.Sprint(v))
+	}
+}
+
+func (q *qualityStats) addLabel(label string) {
+	if label == "" {
+		return
+	}
+
+	if _, ok := q.labels[label]; !ok && len(q.labels) >= maxLabels {
+		label = otherLabel
+	}
+	q.labels[label]++
+}
+
+// status returns the quality of the samples added
+func (q *qualityStats) status() *sednav1.DatasetQuality {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	quality := &sednav1.DatasetQuality{
+		DuplicateSamples: q.duplicates,
+		MissingFiles:     q.missingFiles,
+	}
+
+	if q.samples > 0 {
+		quality.DuplicateRate = strconv.FormatFloat(float64(q.duplicates)/float64(q.samples), 'f', 4, 64)
+	}
+
+	if len(q.labels) > 0 {
+		quality.LabelDistribution = make(map[string]int, len(q.labels))
+		for label, n := range q.labels {
+			quality.LabelDistribution[label] = n
+		}
+	}
+
+	for _, c := range q.columns {
+		quality.Columns = append(quality.Columns, c.status())
+	}
+
+	return quality
+}
+
+// resolveEntry returns the path of the index entry on the host,
+// the entries of the dataset not on the host are not resolved
+func (ds *Dataset) resolveEntry(volumeMountPrefix string) func(string) (string, bool) {
+	return func(entry string) (string, bool) {
+		if _, isLocal := ds.spec(); !isLocal {
+			return "", false
+		}
+		if filepath.IsAbs(entry) {
+			return filepath.Join(volumeMountPrefix, entry), true
+		}
+		return filepath.Join(ds.URLPrefix, entry), true
+	}
+}
+
+// rebuildStats computes the quality statistics of all samples again
+func (ds *Dataset) rebuildStats() error {
+	ds.stats.reset(ds.tail.Format)
+
+	cursor := ds.NewCursor(0)
+	for {
+		samples, err := cursor.Next(SegmentSamples)
+		if err != nil {
+			return err
+		}
+		if len(samples) == 0 {
+			return nil
+		}
+		ds.stats.add(samples, ds.tail.Header)
+	}
+}
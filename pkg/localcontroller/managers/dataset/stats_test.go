@@ -0,0 +1,231 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataset
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
+)
+
+const statsTestdata = "testdata/stats"
+
+// readFixture reads the lines of the fixture, the first line is returned as the header if csv
+func readFixture(t *testing.T, name string, format string) ([]string, string) {
+	data, err := ioutil.ReadFile(filepath.Join(statsTestdata, name))
+	if err != nil {
+		t.Fatalf("failed to read fixture %s: %v", name, err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if format == CSVFormat {
+		return lines[1:], lines[0]
+	}
+	return lines, ""
+}
+
+func TestQualityStats(t *testing.T) {
+	local := func(entry string) (string, bool) {
+		return filepath.Join(statsTestdata, entry), true
+	}
+	remote := func(string) (string, bool) {
+		return "", false
+	}
+
+	tests := []struct {
+		name     string
+		fixture  string
+		format   string
+		resolve  func(string) (string, bool)
+		expected *sednav1.DatasetQuality
+	}{
+		{
+			name:    "local index",
+			fixture: "index.txt",
+			format:  TXTFormat,
+			resolve: local,
+			expected: &sednav1.DatasetQuality{
+				LabelDistribution: map[string]int{"cat": 2, "dog": 3},
+				DuplicateSamples:  1,
+				DuplicateRate:     "0.1667",
+				MissingFiles:      1,
+			},
+		},
+		{
+			name:    "remote index",
+			fixture: "index.txt",
+			format:  ImageFolderFormat,
+			resolve: remote,
+			expected: &sednav1.DatasetQuality{
+				LabelDistribution: map[string]int{"cat": 2, "dog": 3},
+				DuplicateSamples:  1,
+				DuplicateRate:     "0.1667",
+			},
+		},
+		{
+			name:    "csv",
+			fixture: "samples.csv",
+			format:  "CSV",
+			resolve: local,
+			expected: &sednav1.DatasetQuality{
+				LabelDistribution: map[string]int{"a": 4, "b": 2},
+				DuplicateSamples:  1,
+				DuplicateRate:     "0.1667",
+				Columns: []sednav1.ColumnStats{
+					{Name: "id", Type: "number", Distinct: 5, Min: "1", Max: "5", Mean: "3.16667"},
+					{Name: "feature", Type: "string", Missing: 1, Distinct: 4},
+					{Name: "label", Type: "string", Distinct: 2},
+				},
+			},
+		},
+		{
+			name:    "jsonl",
+			fixture: "samples.jsonl",
+			format:  JSONLFormat,
+			resolve: local,
+			expected: &sednav1.DatasetQuality{
+				LabelDistribution: map[string]int{"pos": 2, "1": 1},
+				DuplicateSamples:  1,
+				DuplicateRate:     "0.2000",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := strings.ToLower(tt.format)
+			samples, header := readFixture(t, tt.fixture, format)
+
+			q := newQualityStats(tt.resolve)
+			q.reset(tt.format)
+			// the samples are added in batches as they are appended
+			q.add(samples[:2], header)
+			q.add(samples[2:], header)
+
+			if quality := q.status(); !reflect.DeepEqual(quality, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, quality)
+			}
+		})
+	}
+}
+
+func TestQualityStatsReset(t *testing.T) {
+	q := newQualityStats(func(string) (string, bool) { return "", false })
+	if quality := q.status(); !reflect.DeepEqual(quality, &sednav1.DatasetQuality{}) {
+		t.Errorf("expected empty quality, got %+v", quality)
+	}
+
+	q.reset(CSVFormat)
+	q.add([]string{"1,a", "1,a"}, "id,label")
+	q.reset(TXTFormat)
+	q.add([]string{"1.jpg a"}, "")
+
+	expected := &sednav1.DatasetQuality{
+		LabelDistribution: map[string]int{"a": 1},
+		DuplicateRate:     "0.0000",
+	}
+	if quality := q.status(); !reflect.DeepEqual(quality, expected) {
+		t.Errorf("expected %+v after reset, got %+v", expected, quality)
+	}
+}
+
+func TestQualityStatsLabelLimit(t *testing.T) {
+	q := newQualityStats(func(string) (string, bool) { return "", false })
+	q.reset(TXTFormat)
+
+	var samples []string
+	for i := 0; i < maxLabels+10; i++ {
+		samples = append(samples, fmt.Sprintf("%d.jpg label%d", i, i))
+	}
+	q.add(samples, "")
+
+	quality := q.status()
+	if len(quality.LabelDistribution) != maxLabels+1 || quality.LabelDistribution[otherLabel] != 10 {
+		t.Errorf("expected %d labels with 10 others, got %d labels with %d others",
+			maxLabels+1, len(quality.LabelDistribution), quality.LabelDistribution[otherLabel])
+	}
+}
+
+func TestQualityStatsHeaderChanged(t *testing.T) {
+	q := newQualityStats(func(string) (string, bool) { return "", false })
+	q.reset(CSVFormat)
+	q.add([]string{"1,a"}, "id,label")
+	q.add([]string{"b,2"}, "Label,value")
+
+	expected := []sednav1.ColumnStats{
+		{Name: "Label", Type: "string", Distinct: 1},
+		{Name: "value", Type: "number", Distinct: 1, Min: "2", Max: "2", Mean: "2"},
+	}
+	quality := q.status()
+	if !reflect.DeepEqual(quality.Columns, expected) {
+		t.Errorf("expected columns %+v of the new header, got %+v", expected, quality.Columns)
+	}
+	if !reflect.DeepEqual(quality.LabelDistribution, map[string]int{"a": 1, "b": 1}) {
+		t.Errorf("unexpected labels %v", quality.LabelDistribution)
+	}
+}
+
+func TestResolveEntry(t *testing.T) {
+	ds := newTestDataset(t)
+	ds.URLPrefix = "/rootfs/data/"
+	resolve := ds.resolveEntry("/rootfs")
+
+	if _, ok := resolve("a.jpg"); ok {
+		t.Errorf("expected the entry of remote dataset not resolved")
+	}
+
+	ds.Storage.IsLocalStorage = true
+	for entry, expected := range map[string]string{
+		"a.jpg":         "/rootfs/data/a.jpg",
+		"images/b.jpg":  "/rootfs/data/images/b.jpg",
+		"/images/c.jpg": "/rootfs/images/c.jpg",
+	} {
+		if path, ok := resolve(entry); !ok || path != expected {
+			t.Errorf("expected %s resolved to %s, got %s", entry, expected, path)
+		}
+	}
+}
+
+func TestRebuildStats(t *testing.T) {
+	samples, header := readFixture(t, "samples.csv", CSVFormat)
+	ds := newTestDataset(t, samples...)
+	ds.tail.Format = CSVFormat
+	ds.tail.Header = header
+
+	if err := ds.rebuildStats(); err != nil {
+		t.Fatalf("failed to rebuild stats: %v", err)
+	}
+
+	quality := ds.stats.status()
+	if quality.DuplicateSamples != 1 || len(quality.Columns) != 3 ||
+		!reflect.DeepEqual(quality.LabelDistribution, map[string]int{"a": 4, "b": 2}) {
+		t.Errorf("unexpected quality %+v", quality)
+	}
+
+	// the stats are computed again, not added twice
+	if err := ds.rebuildStats(); err != nil {
+		t.Fatalf("failed to rebuild stats: %v", err)
+	}
+	if again := ds.stats.status(); !reflect.DeepEqual(again, quality) {
+		t.Errorf("expected the same quality after rebuilding again, got %+v", again)
+	}
+}
@@ -0,0 +1,324 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubeedge/sedna/pkg/localcontroller/storage"
+)
+
+const (
+	// fenceSize is the max size of the content before the offset kept to detect the rewritten file
+	fenceSize = 256
+	// tailBatchSize is the number of the samples appended to the segments at once
+	tailBatchSize = 1000
+
+	tailStateFile = "state.json"
+)
+
+// tailState is the progress of reading the data source, persisted with the segments
+type tailState struct {
+	URL     string `json:"url"`
+	Format  string `json:"format"`
+	Version string `json:"version,omitempty"`
+	// Offset is the size of the content whose lines are parsed
+	Offset int64 `json:"offset"`
+	// Lines is the number of the parsed lines
+	Lines int `json:"lines"`
+	// Fence is the content before the offset, the file is rewritten if it's changed
+	Fence []byte `json:"fence,omitempty"`
+	// Partial is true if the last line is not ended, which may be being written.
+	// It's parsed when the file is not changed since the last read.
+	Partial bool `json:"partial,omitempty"`
+
+	NumberOfSamples int      `json:"numberOfSamples"`
+	Header          string   `json:"header,omitempty"`
+	Schema          []Column `json:"schema,omitempty"`
+}
+
+// dataSource returns the data source of the parsed samples
+func (s *tailState) dataSource() *DataSource {
+	return &DataSource{
+		NumberOfSamples: s.NumberOfSamples,
+		Header:          s.Header,
+		Schema:          s.Schema,
+	}
+}
+
+// loadTailState loads the state in the dir, it's empty if not exists or invalid
+func loadTailState(dir string) *tailState {
+	state := &tailState{}
+	data, err := ioutil.ReadFile(filepath.Join(dir, tailStateFile))
+	if err != nil {
+		return state
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		klog.Warningf("invalid state of samples in %s, error: %v", dir, err)
+		return &tailState{}
+	}
+	return state
+}
+
+// save writes the state to the dir atomically
+func (s *tailState) save(dir string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, tailStateFile)
+	if err := ioutil.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// syncSamples reads the samples of the data url to the segments.
+// The lines appended to the file of the line format are read from the offset of the
+// last read, the file of other formats or with checksum is parsed wholly when its version changes.
+func (ds *Dataset) syncSamples(dataURL string, format string) (*DataSource, error) {
+	f, err := GetFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	state := ds.tail
+	if state.URL != dataURL || state.Format != format || state.NumberOfSamples != ds.samples.Count() {
+		klog.Infof("dataset(name=%s) reads samples of url(%s) from the beginning", ds.Name, dataURL)
+		if state, err = ds.resetSamples(dataURL, format); err != nil {
+			return nil, err
+		}
+	}
+
+	version, err := ds.Storage.Version(dataURL)
+	if err != nil {
+		return nil, err
+	}
+	unchanged := version != "" && version == state.Version
+	if unchanged && !state.Partial {
+		return state.dataSource(), nil
+	}
+
+	spec, _ := ds.spec()
+	if lf, ok := f.(LineFormat); ok && spec.Checksum == "" {
+		err = ds.tailLines(dataURL, lf, unchanged)
+	} else {
+		err = ds.reloadSamples(dataURL, f)
+	}
+
+	// the state may be reset
+	state = ds.tail
+	if err == nil {
+		state.Version = version
+	}
+
+	// save the progress even if failed, the parsed samples are kept
+	if saveErr := state.save(ds.samples.dir); saveErr != nil {
+		klog.Errorf("dataset(name=%s) failed to save the state of samples, error: %v", ds.Name, saveErr)
+	}
+
+	return state.dataSource(), err
+}
+
+// resetSamples drops the samples and the state
+func (ds *Dataset) resetSamples(dataURL string, format string) (*tailState, error) {
+	if err := ds.samples.Reset(); err != nil {
+		return nil, err
+	}
+
+	ds.tail = &tailState{URL: dataURL, Format: format}
+	ds.stats.reset(format)
+	return ds.tail, nil
+}
+
+// tailLines parses the lines appended after the offset of the state.
+// The last line not ended is parsed only if the file is stable.
+func (ds *Dataset) tailLines(dataURL string, lf LineFormat, stable bool) error {
+	state := ds.tail
+	reader, err := ds.openTail(dataURL, state)
+	if err != nil {
+		return err
+	}
+	if reader == nil {
+		// the file is rewritten
+		klog.Infof("dataset(name=%s) url(%s) is rewritten, reads samples from the beginning", ds.Name, dataURL)
+		if state, err = ds.resetSamples(state.URL, state.Format); err != nil {
+			return err
+		}
+		if reader, err = ds.Storage.OpenRange(dataURL, 0); err != nil {
+			return err
+		}
+	}
+	defer reader.Close()
+
+	state.Partial = false
+	dataSource := state.dataSource()
+	parser := lf.NewLineParser(dataSource)
+	fence := state.Fence
+
+	var batch []string
+	flush := func() error {
+		if err := ds.samples.Append(batch); err != nil {
+			return err
+		}
+		ds.stats.add(batch, dataSource.Header)
+		state.NumberOfSamples += len(batch)
+		state.Header = dataSource.Header
+		state.Schema = dataSource.Schema
+		state.Fence = fence
+		batch = batch[:0]
+		return nil
+	}
+
+	br := bufio.NewReaderSize(reader, 64*1024)
+	for {
+		line, err := br.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			// the line is longer than the buffer
+			long := append([]byte(nil), line...)
+			for err == bufio.ErrBufferFull {
+				if len(long) > maxLineSize {
+					return fmt.Errorf("line %d of url(%s) is too long", state.Lines+1, dataURL)
+				}
+				line, err = br.ReadSlice('\n')
+				long = append(long, line...)
+			}
+			line = long
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(line) == 0 {
+			break
+		}
+
+		if line[len(line)-1] != '\n' && !stable {
+			// the last line may be being written, it's parsed next time
+			state.Partial = true
+			break
+		}
+
+		sample, ok, parseErr := parser.ParseLine(state.Lines+1, string(bytes.TrimRight(line, "\r\n")))
+		if parseErr != nil {
+			if flushErr := flush(); flushErr != nil {
+				return flushErr
+			}
+			return fmt.Errorf("line %d of url(%s) is invalid: %v", state.Lines+1, dataURL, parseErr)
+		}
+		if ok {
+			batch = append(batch, sample)
+		}
+
+		state.Lines++
+		state.Offset += int64(len(line))
+		fence = append(fence, line...)
+		if len(fence) > fenceSize {
+			fence = append([]byte(nil), fence[len(fence)-fenceSize:]...)
+		}
+
+		if len(batch) >= tailBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return flush()
+}
+
+// openTail opens the data url from the offset of the state,
+// and returns nil if the content before the offset is changed.
+func (ds *Dataset) openTail(dataURL string, state *tailState) (io.ReadCloser, error) {
+	start := state.Offset - int64(len(state.Fence))
+	reader, err := ds.Storage.OpenRange(dataURL, start)
+	if errors.Is(err, storage.ErrInvalidRange) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(state.Fence) > 0 {
+		fence := make([]byte, len(state.Fence))
+		if _, err := io.ReadFull(reader, fence); err != nil || !bytes.Equal(fence, state.Fence) {
+			reader.Close()
+			if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+				return nil, err
+			}
+			return nil, nil
+		}
+	}
+
+	return reader, nil
+}
+
+// reloadSamples parses all samples of the data url again
+func (ds *Dataset) reloadSamples(dataURL string, f Format) error {
+	spec, isLocal := ds.spec()
+	localURL, err := ds.Storage.DownloadWithChecksum(dataURL, "", spec.Checksum)
+
+	if !isLocal {
+		defer os.RemoveAll(localURL)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	state, err := ds.resetSamples(ds.tail.URL, ds.tail.Format)
+	if err != nil {
+		return err
+	}
+
+	var batch []string
+	dataSource, err := f.Parse(localURL, func(sample string) error {
+		batch = append(batch, sample)
+		if len(batch) < tailBatchSize {
+			return nil
+		}
+		err := ds.samples.Append(batch)
+		batch = batch[:0]
+		return err
+	})
+	if err == nil {
+		err = ds.samples.Append(batch)
+	}
+	if err != nil {
+		// the partial samples are dropped
+		_, _ = ds.resetSamples(state.URL, state.Format)
+		return err
+	}
+
+	state.NumberOfSamples = ds.samples.Count()
+	state.Header = dataSource.Header
+	state.Schema = dataSource.Schema
+
+	// the header is known after all samples are parsed
+	return ds.rebuildStats()
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataset
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kubeedge/sedna/pkg/localcontroller/storage"
+)
+
+// newTailDataset creates the dataset of the local data file, keeping the samples in the dir
+func newTailDataset(t *testing.T, dir string) *Dataset {
+	store, err := openSegmentStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ds := newTestDataset(t)
+	ds.samples = store
+	ds.tail = loadTailState(dir)
+	ds.Storage = storage.Storage{IsLocalStorage: true}
+	return ds
+}
+
+func appendFile(t *testing.T, path string, content string) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readAll(t *testing.T, ds *Dataset) []string {
+	samples, err := ds.NewCursor(0).Next(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return samples
+}
+
+func syncSamples(t *testing.T, ds *Dataset, url string, format string) *DataSource {
+	dataSource, err := ds.syncSamples(url, format)
+	if err != nil {
+		t.Fatalf("failed to sync samples: %v", err)
+	}
+	return dataSource
+}
+
+func TestTailAppendedLines(t *testing.T) {
+	url := filepath.Join(t.TempDir(), "index.txt")
+	ds := newTailDataset(t, t.TempDir())
+
+	writeFile(t, url, "a\nb\n")
+	if dataSource := syncSamples(t, ds, url, TXTFormat); dataSource.NumberOfSamples != 2 {
+		t.Errorf("expected 2 samples, got %d", dataSource.NumberOfSamples)
+	}
+
+	// the last line not ended may be being written
+	appendFile(t, url, "c\nd")
+	syncSamples(t, ds, url, TXTFormat)
+	if samples := readAll(t, ds); !reflect.DeepEqual(samples, []string{"a", "b", "c"}) || !ds.tail.Partial {
+		t.Errorf("expected the partial line not parsed, got %v partial=%v", samples, ds.tail.Partial)
+	}
+
+	appendFile(t, url, "\ne\n")
+	dataSource := syncSamples(t, ds, url, TXTFormat)
+	if samples := readAll(t, ds); !reflect.DeepEqual(samples, []string{"a", "b", "c", "d", "e"}) {
+		t.Errorf("expected the appended lines parsed, got %v", samples)
+	}
+	info, _ := os.Stat(url)
+	if dataSource.NumberOfSamples != 5 || ds.tail.Lines != 5 || ds.tail.Offset != info.Size() || ds.tail.Partial {
+		t.Errorf("unexpected state %+v", ds.tail)
+	}
+
+	// the partial line is parsed if the file is not changed since the last read
+	appendFile(t, url, "f")
+	syncSamples(t, ds, url, TXTFormat)
+	syncSamples(t, ds, url, TXTFormat)
+	if samples := readAll(t, ds); len(samples) != 6 || samples[5] != "f" || ds.tail.Partial {
+		t.Errorf("expected the stable partial line parsed, got %v", samples)
+	}
+}
+
+func TestTailResumeAfterRestart(t *testing.T) {
+	url := filepath.Join(t.TempDir(), "samples.csv")
+	dir := t.TempDir()
+
+	var content strings.Builder
+	content.WriteString("id,label\n")
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&content, "%03d,cat\n", i)
+	}
+	writeFile(t, url, content.String())
+
+	ds := newTailDataset(t, dir)
+	syncSamples(t, ds, url, CSVFormat)
+
+	// the content before the fence is not read again after restart,
+	// so the changed first sample proves the samples are read from the offset
+	data := []byte(content.String())
+	copy(data[len("id,label\n"):], "XXX")
+	writeFile(t, url, string(data)+"100,dog\n")
+
+	restarted := newTailDataset(t, dir)
+	if restarted.samples.Count() != 100 || restarted.tail.Header != "id,label" {
+		t.Fatalf("expected the samples and state restored, got %d samples, state %+v", restarted.samples.Count(), restarted.tail)
+	}
+
+	dataSource := syncSamples(t, restarted, url, CSVFormat)
+	samples := readAll(t, restarted)
+	if len(samples) != 101 || samples[0] != "000,cat" || samples[100] != "100,dog" {
+		t.Errorf("expected the appended sample read from the offset, got %d samples: %v ... %v",
+			len(samples), samples[0], samples[len(samples)-1])
+	}
+	if dataSource.NumberOfSamples != 101 || dataSource.Header != "id,label" {
+		t.Errorf("unexpected data source %+v", dataSource)
+	}
+
+	// the cursor of the job continues from its position
+	cursor := restarted.NewCursor(100)
+	if next, _ := cursor.Next(0); !reflect.DeepEqual(next, []string{"100,dog"}) {
+		t.Errorf("unexpected samples %v after the position", next)
+	}
+}
+
+func TestTailRewrittenFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		rewritten string
+		expected  []string
+	}{
+		{name: "changed", rewritten: "x\ny\nz\n", expected: []string{"x", "y", "z"}},
+		{name: "truncated", rewritten: "a\n", expected: []string{"a"}},
+		{name: "empty", rewritten: "", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := filepath.Join(t.TempDir(), "index.txt")
+			ds := newTailDataset(t, t.TempDir())
+
+			writeFile(t, url, "a\nb\nc\n")
+			syncSamples(t, ds, url, TXTFormat)
+
+			writeFile(t, url, tt.rewritten)
+			dataSource := syncSamples(t, ds, url, TXTFormat)
+			if samples := readAll(t, ds); !reflect.DeepEqual(samples, tt.expected) ||
+				dataSource.NumberOfSamples != len(tt.expected) {
+				t.Errorf("expected samples %v read again, got %v", tt.expected, samples)
+			}
+		})
+	}
+}
+
+func TestTailChangedURL(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	writeFile(t, first, "a\nb\n")
+	writeFile(t, second, "c\n")
+
+	ds := newTailDataset(t, t.TempDir())
+	syncSamples(t, ds, first, TXTFormat)
+	syncSamples(t, ds, second, TXTFormat)
+	if samples := readAll(t, ds); !reflect.DeepEqual(samples, []string{"c"}) {
+		t.Errorf("expected the samples of the new url, got %v", samples)
+	}
+
+}
+
+func TestTailInvalidLine(t *testing.T) {
+	url := filepath.Join(t.TempDir(), "samples.jsonl")
+	writeFile(t, url, "{\"id\":1}\n{\"id\":2}\nbad\n{\"id\":3}\n")
+
+	// the invalid line stops the tail, and the samples before it are kept
+	ds := newTailDataset(t, t.TempDir())
+	if _, err := ds.syncSamples(url, JSONLFormat); err == nil {
+		t.Errorf("expected error of the invalid line")
+	}
+	if samples := readAll(t, ds); !reflect.DeepEqual(samples, []string{`{"id":1}`, `{"id":2}`}) || ds.tail.Lines != 2 {
+		t.Errorf("expected the samples before the invalid line kept, got %v at line %d", samples, ds.tail.Lines)
+	}
+
+	// the tail continues from the invalid line once it's fixed
+	writeFile(t, url, "{\"id\":1}\n{\"id\":2}\n{\"id\":9}\n{\"id\":3}\n")
+	syncSamples(t, ds, url, JSONLFormat)
+	if samples := readAll(t, ds); len(samples) != 4 || samples[2] != `{"id":9}` {
+		t.Errorf("expected the fixed line parsed, got %v", samples)
+	}
+}
@@ -0,0 +1,373 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
+	"github.com/kubeedge/sedna/pkg/localcontroller/util"
+)
+
+const (
+	versionsDir  = "versions"
+	versionsFile = "versions.json"
+	// pinsFile keeps the versions pinned by the jobs, so they are not pruned after the LC restarts
+	pinsFile = "pins.json"
+	// versionPrefix is the prefix of the version name, e.g. "v1"
+	versionPrefix = "v"
+
+	// DefaultMaxVersions is the default max number of the versions kept by each dataset
+	DefaultMaxVersions = 10
+)
+
+// loadVersions loads the versions of the dataset in the dir
+func loadVersions(dir string) []sednav1.DatasetVersion {
+	data, err := ioutil.ReadFile(filepath.Join(dir, versionsFile))
+	if err != nil {
+		return nil
+	}
+
+	var versions []sednav1.DatasetVersion
+	if err := json.Unmarshal(data, &versions); err != nil {
+		klog.Warningf("invalid versions of dataset in %s, error: %v", dir, err)
+		return nil
+	}
+	return versions
+}
+
+// saveVersions writes the versions of the dataset to the dir atomically
+func saveVersions(dir string, versions []sednav1.DatasetVersion) error {
+	data, err := json.Marshal(versions)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, versionsFile)
+	if err := ioutil.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// loadPins loads the versions of the dataset pinned by the jobs in the dir: job name -> version
+func loadPins(dir string) map[string]string {
+	data, err := ioutil.ReadFile(filepath.Join(dir, pinsFile))
+	if err != nil {
+		return nil
+	}
+
+	var pins map[string]string
+	if err := json.Unmarshal(data, &pins); err != nil {
+		klog.Warningf("invalid pins of dataset in %s, error: %v", dir, err)
+		return nil
+	}
+	return pins
+}
+
+// savePins writes the versions of the dataset pinned by the jobs to the dir atomically
+func savePins(dir string, pins map[string]string) error {
+	data, err := json.Marshal(pins)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, pinsFile)
+	if err := ioutil.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Versions returns the versions of the dataset
+func (ds *Dataset) Versions() []sednav1.DatasetVersion {
+	ds.versionsLock.Lock()
+	defer ds.versionsLock.Unlock()
+
+	return append([]sednav1.DatasetVersion(nil), ds.versions...)
+}
+
+// GetVersion gets the version of the dataset by name
+func (ds *Dataset) GetVersion(name string) (*sednav1.DatasetVersion, bool) {
+	ds.versionsLock.Lock()
+	defer ds.versionsLock.Unlock()
+
+	for i := range ds.versions {
+		if ds.versions[i].Name == name {
+			v := ds.versions[i]
+			return &v, true
+		}
+	}
+	return nil, false
+}
+
+// NewVersionCursor creates the cursor reading the samples of the version from the position
+func (ds *Dataset) NewVersionCursor(name string, position int) (*Cursor, error) {
+	if _, ok := ds.GetVersion(name); !ok {
+		return nil, fmt.Errorf("dataset(name=%s) has no version %s", ds.Name, name)
+	}
+
+	store, err := openSegmentStore(filepath.Join(ds.samples.dir, versionsDir, name))
+	if err != nil {
+		return nil, err
+	}
+	return &Cursor{store: store, position: position}, nil
+}
+
+// snapshot creates the version of the first count samples. If the samples are the same as
+// an existing version, the existing version is returned and false is returned.
+func (ds *Dataset) snapshot(count int, volumeMountPrefix string) (*sednav1.DatasetVersion, bool, error) {
+	ds.versionsLock.Lock()
+	defer ds.versionsLock.Unlock()
+
+	root := filepath.Join(ds.samples.dir, versionsDir)
+	if err := os.MkdirAll(root, os.ModePerm); err != nil {
+		return nil, false, err
+	}
+
+	tmp, err := ioutil.TempDir(root, ".snapshot")
+	if err != nil {
+		return nil, false, err
+	}
+	defer os.RemoveAll(tmp)
+
+	h := sha256.New()
+	if err := ds.samples.Snapshot(tmp, count, h); err != nil {
+		return nil, false, err
+	}
+	hash := "sha256:" + hex.EncodeToString(h.Sum(nil))
+
+	for i := range ds.versions {
+		if ds.versions[i].Hash == hash {
+			v := ds.versions[i]
+			return &v, false, nil
+		}
+	}
+
+	name := versionPrefix + strconv.Itoa(nextVersion(ds.versions))
+	dir := filepath.Join(root, name)
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, false, err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return nil, false, err
+	}
+
+	now := metav1.Now()
+	version := sednav1.DatasetVersion{
+		Name:            name,
+		Hash:            hash,
+		NumberOfSamples: count,
+		URL:             util.TrimPrefixPath(volumeMountPrefix, dir),
+		CreateTime:      &now,
+	}
+
+	versions := append(append([]sednav1.DatasetVersion(nil), ds.versions...), version)
+	if err := saveVersions(ds.samples.dir, versions); err != nil {
+		os.RemoveAll(dir)
+		return nil, false, err
+	}
+	ds.versions = versions
+
+	return &version, true, nil
+}
+
+// prune removes the oldest versions beyond max except the pinned ones, and returns the names of the removed.
+// The latest version is always kept, so the version numbers are never reused.
+func (ds *Dataset) prune(max int, pinned map[string]bool) ([]string, error) {
+	ds.versionsLock.Lock()
+	defer ds.versionsLock.Unlock()
+
+	excess := len(ds.versions) - max
+	if max <= 0 || excess <= 0 {
+		return nil, nil
+	}
+
+	var kept []sednav1.DatasetVersion
+	var removed []string
+	for i, v := range ds.versions {
+		if excess > 0 && i < len(ds.versions)-1 && !pinned[v.Name] {
+			removed = append(removed, v.Name)
+			excess--
+			continue
+		}
+		kept = append(kept, v)
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	// the versions are saved before the dirs are removed, so no version refers to a removed dir
+	if err := saveVersions(ds.samples.dir, kept); err != nil {
+		return nil, err
+	}
+	ds.versions = kept
+
+	for _, name := range removed {
+		if err := os.RemoveAll(filepath.Join(ds.samples.dir, versionsDir, name)); err != nil {
+			klog.Warningf("failed to remove version %s of dataset(name=%s), error: %v", name, ds.Name, err)
+		}
+	}
+	return removed, nil
+}
+
+// nextVersion returns the number of the next version, which is never reused
+func nextVersion(versions []sednav1.DatasetVersion) int {
+	next := 1
+	for _, v := range versions {
+		if n, err := strconv.Atoi(strings.TrimPrefix(v.Name, versionPrefix)); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return next
+}
+
+// Snapshot creates the immutable version of the first count samples of the dataset,
+// which is pinned to the job as the samples of its round. The existing version is returned
+// if its samples are the same. The oldest versions not pinned are pruned beyond MaxVersions.
+func (dm *Manager) Snapshot(name string, jobName string, count int) (*sednav1.DatasetVersion, error) {
+	ds, ok := dm.GetDataset(name)
+	if !ok || ds == nil {
+		return nil, fmt.Errorf("not exists dataset(name=%s)", name)
+	}
+
+	dm.pinLock.Lock()
+	defer dm.pinLock.Unlock()
+
+	version, created, err := ds.snapshot(count, dm.VolumeMountPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot dataset(name=%s), error: %w", name, err)
+	}
+	if created {
+		klog.Infof("dataset(name=%s) created version %s of %d samples(hash=%s)",
+			name, version.Name, version.NumberOfSamples, version.Hash)
+	}
+
+	dm.pin(name, jobName, version.Name)
+
+	pinned := make(map[string]bool)
+	for _, v := range dm.pins[name] {
+		pinned[v] = true
+	}
+	removed, err := ds.prune(dm.MaxVersions, pinned)
+	if err != nil {
+		klog.Errorf("dataset(name=%s) failed to prune versions, error: %v", name, err)
+	}
+	if len(removed) > 0 {
+		klog.Infof("dataset(name=%s) pruned versions %v", name, removed)
+	}
+
+	if created || len(removed) > 0 {
+		dm.publishStatus(ds)
+	}
+
+	return version, nil
+}
+
+// Pin pins the version of the dataset to the job, which replaces the version pinned before.
+// The pinned versions are not pruned until the job unpins them.
+func (dm *Manager) Pin(name string, jobName string, version string) {
+	dm.pinLock.Lock()
+	defer dm.pinLock.Unlock()
+
+	dm.pin(name, jobName, version)
+}
+
+func (dm *Manager) pin(name string, jobName string, version string) {
+	if dm.pins[name] == nil {
+		dm.pins[name] = make(map[string]string)
+	}
+	if dm.pins[name][jobName] == version {
+		return
+	}
+	dm.pins[name][jobName] = version
+	dm.savePins(name)
+}
+
+// savePins saves the pins of the dataset, which are saved when the dataset is inserted if not exists yet
+func (dm *Manager) savePins(name string) {
+	ds, ok := dm.GetDataset(name)
+	if !ok || ds == nil {
+		return
+	}
+
+	if err := savePins(ds.samples.dir, dm.pins[name]); err != nil {
+		klog.Warningf("failed to save the pins of dataset(name=%s), error: %v", name, err)
+	}
+}
+
+// restorePins restores the pins of the dataset saved in the dir, e.g. the LC restarts.
+// The versions pinned since the LC started are kept.
+func (dm *Manager) restorePins(name string, dir string) {
+	dm.pinLock.Lock()
+	defer dm.pinLock.Unlock()
+
+	pins := loadPins(dir)
+	if len(pins) == 0 && len(dm.pins[name]) == 0 {
+		return
+	}
+
+	if dm.pins[name] == nil {
+		dm.pins[name] = make(map[string]string)
+	}
+	for jobName, version := range pins {
+		if _, ok := dm.pins[name][jobName]; !ok {
+			dm.pins[name][jobName] = version
+		}
+	}
+	dm.savePins(name)
+}
+
+// Unpin unpins the versions of the datasets pinned to the job, e.g. the job is deleted
+func (dm *Manager) Unpin(jobName string) {
+	dm.pinLock.Lock()
+	defer dm.pinLock.Unlock()
+
+	for name, jobs := range dm.pins {
+		if _, ok := jobs[jobName]; !ok {
+			continue
+		}
+		delete(jobs, jobName)
+		if len(jobs) == 0 {
+			delete(dm.pins, name)
+		}
+		dm.savePins(name)
+	}
+}
+
+// Pins returns the versions of the dataset pinned by the jobs: job name -> version
+func (dm *Manager) Pins(name string) map[string]string {
+	dm.pinLock.Lock()
+	defer dm.pinLock.Unlock()
+
+	pins := make(map[string]string, len(dm.pins[name]))
+	for jobName, version := range dm.pins[name] {
+		pins[jobName] = version
+	}
+	return pins
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeedge/sedna/cmd/sedna-lc/app/options"
+	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
+	clienttypes "github.com/kubeedge/sedna/pkg/localcontroller/gmclient"
+)
+
+// fakeClient records the statuses written to GM
+type fakeClient struct {
+	clienttypes.ClientI
+	statuses []interface{}
+}
+
+func (c *fakeClient) WriteMessage(messageBody interface{}, messageHeader clienttypes.MessageHeader) error {
+	c.statuses = append(c.statuses, messageBody)
+	return nil
+}
+
+// newTestDataset creates the dataset keeping the samples in a temp dir
+func newTestDataset(t *testing.T, samples ...string) *Dataset {
+	store, err := openSegmentStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to open segment store: %v", err)
+	}
+	if err := store.Append(samples); err != nil {
+		t.Fatalf("failed to append samples: %v", err)
+	}
+
+	ds := &Dataset{
+		Dataset: &sednav1.Dataset{
+			TypeMeta:   metav1.TypeMeta{Kind: "Dataset"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ds"},
+		},
+		Done:    make(chan struct{}),
+		samples: store,
+		tail:    loadTailState(store.dir),
+	}
+	ds.stats = newQualityStats(ds.resolveEntry(""))
+	return ds
+}
+
+func versionNames(versions []sednav1.DatasetVersion) []string {
+	var names []string
+	for _, v := range versions {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []string
+		expected int
+	}{
+		{name: "no versions", expected: 1},
+		{name: "continuous", versions: []string{"v1", "v2"}, expected: 3},
+		{name: "pruned", versions: []string{"v3", "v7"}, expected: 8},
+		{name: "unordered", versions: []string{"v5", "v2"}, expected: 6},
+		{name: "invalid names", versions: []string{"latest", "v2", "vx"}, expected: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var versions []sednav1.DatasetVersion
+			for _, name := range tt.versions {
+				versions = append(versions, sednav1.DatasetVersion{Name: name})
+			}
+			if n := nextVersion(versions); n != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, n)
+			}
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	ds := newTestDataset(t, "a.jpg", "b.jpg", "c.jpg", "d.jpg", "e.jpg")
+
+	v1, created, err := ds.snapshot(3, "")
+	if err != nil || !created {
+		t.Fatalf("expected version created, got created=%v err=%v", created, err)
+	}
+	if v1.Name != "v1" || v1.NumberOfSamples != 3 || v1.URL != filepath.Join(ds.samples.dir, versionsDir, "v1") {
+		t.Errorf("unexpected version %+v", v1)
+	}
+
+	// the same samples are the same version
+	same, created, err := ds.snapshot(3, "")
+	if err != nil || created || same.Name != "v1" || same.Hash != v1.Hash {
+		t.Errorf("expected version v1 reused, got %+v created=%v err=%v", same, created, err)
+	}
+
+	v2, created, err := ds.snapshot(5, "")
+	if err != nil || !created || v2.Name != "v2" || v2.Hash == v1.Hash {
+		t.Fatalf("expected version v2 created, got %+v created=%v err=%v", v2, created, err)
+	}
+
+	if _, _, err := ds.snapshot(6, ""); err == nil {
+		t.Errorf("expected error of snapshot beyond the samples")
+	}
+
+	// the version is immutable after the samples are appended
+	if err := ds.samples.Append([]string{"f.jpg"}); err != nil {
+		t.Fatalf("failed to append samples: %v", err)
+	}
+	cursor, err := ds.NewVersionCursor("v1", 0)
+	if err != nil {
+		t.Fatalf("failed to create version cursor: %v", err)
+	}
+	samples, err := cursor.Next(0)
+	if err != nil || !reflect.DeepEqual(samples, []string{"a.jpg", "b.jpg", "c.jpg"}) {
+		t.Errorf("unexpected samples %v of v1, err=%v", samples, err)
+	}
+	if _, err := ds.NewVersionCursor("v3", 0); err == nil {
+		t.Errorf("expected error of unknown version")
+	}
+
+	// the versions are kept across restarts
+	if names := versionNames(loadVersions(ds.samples.dir)); !reflect.DeepEqual(names, []string{"v1", "v2"}) {
+		t.Errorf("expected versions [v1 v2] loaded, got %v", names)
+	}
+}
+
+func TestSnapshotPrunesVersions(t *testing.T) {
+	client := &fakeClient{}
+	dm := New(client, &options.LocalControllerOptions{DatasetMaxVersions: 2})
+	ds := newTestDataset(t, "1", "2", "3", "4", "5")
+	dm.DatasetMap["default/dataset/ds"] = ds
+
+	dm.Pin("default/dataset/ds", "job-b", "v1")
+	for count := 1; count <= 4; count++ {
+		if _, err := dm.Snapshot("default/dataset/ds", "job-a", count); err != nil {
+			t.Fatalf("failed to snapshot %d samples: %v", count, err)
+		}
+	}
+
+	// v1 is pinned by job-b, v4 by job-a, and v2 and v3 are pruned
+	if names := versionNames(ds.Versions()); !reflect.DeepEqual(names, []string{"v1", "v4"}) {
+		t.Errorf("expected versions [v1 v4], got %v", names)
+	}
+	for name, exists := range map[string]bool{"v1": true, "v2": false, "v3": false, "v4": true} {
+		_, err := os.Stat(filepath.Join(ds.samples.dir, versionsDir, name))
+		if exists != (err == nil) {
+			t.Errorf("expected dir of %s exists=%v, got err=%v", name, exists, err)
+		}
+	}
+	if names := versionNames(loadVersions(ds.samples.dir)); !reflect.DeepEqual(names, []string{"v1", "v4"}) {
+		t.Errorf("expected versions [v1 v4] saved, got %v", names)
+	}
+
+	status, ok := client.statuses[len(client.statuses)-1].(sednav1.DatasetStatus)
+	if !ok || !reflect.DeepEqual(versionNames(status.Versions), []string{"v1", "v4"}) {
+		t.Errorf("expected the pruned versions published, got %+v", client.statuses[len(client.statuses)-1])
+	}
+
+	// v1 is pruned once unpinned, and the numbers are not reused
+	dm.Unpin("job-b")
+	if pins := dm.Pins("default/dataset/ds"); !reflect.DeepEqual(pins, map[string]string{"job-a": "v4"}) {
+		t.Errorf("unexpected pins %v", pins)
+	}
+	if _, err := dm.Snapshot("default/dataset/ds", "job-a", 5); err != nil {
+		t.Fatalf("failed to snapshot: %v", err)
+	}
+	if names := versionNames(ds.Versions()); !reflect.DeepEqual(names, []string{"v4", "v5"}) {
+		t.Errorf("expected versions [v4 v5], got %v", names)
+	}
+}
+
+func TestPinsRestoredAfterRestart(t *testing.T) {
+	dm := New(&fakeClient{}, &options.LocalControllerOptions{DatasetMaxVersions: 1})
+	ds := newTestDataset(t, "1", "2", "3", "4")
+	dm.DatasetMap["default/dataset/ds"] = ds
+
+	if _, err := dm.Snapshot("default/dataset/ds", "job-a", 1); err != nil {
+		t.Fatalf("failed to snapshot: %v", err)
+	}
+
+	// the LC restarts, the job recovered later is still training on v1
+	restarted := New(&fakeClient{}, &options.LocalControllerOptions{DatasetMaxVersions: 1})
+	reopened := newTestDataset(t)
+	reopened.samples = ds.samples
+	reopened.versions = loadVersions(ds.samples.dir)
+	restarted.DatasetMap["default/dataset/ds"] = reopened
+	restarted.restorePins("default/dataset/ds", ds.samples.dir)
+
+	if pins := restarted.Pins("default/dataset/ds"); !reflect.DeepEqual(pins, map[string]string{"job-a": "v1"}) {
+		t.Fatalf("expected the pins restored, got %v", pins)
+	}
+	for count := 2; count <= 3; count++ {
+		if _, err := restarted.Snapshot("default/dataset/ds", "job-b", count); err != nil {
+			t.Fatalf("failed to snapshot %d samples: %v", count, err)
+		}
+	}
+	if names := versionNames(reopened.Versions()); !reflect.DeepEqual(names, []string{"v1", "v3"}) {
+		t.Errorf("expected the pinned v1 kept, got %v", names)
+	}
+
+	// the unpin is saved too
+	restarted.Unpin("job-a")
+	if pins := loadPins(ds.samples.dir); !reflect.DeepEqual(pins, map[string]string{"job-b": "v3"}) {
+		t.Errorf("expected the pins of job-b saved, got %v", pins)
+	}
+}
+
+func TestPruneKeepsLatestVersion(t *testing.T) {
+	ds := newTestDataset(t, "1", "2", "3")
+	for count := 1; count <= 3; count++ {
+		if _, _, err := ds.snapshot(count, ""); err != nil {
+			t.Fatalf("failed to snapshot %d samples: %v", count, err)
+		}
+	}
+
+	pinned := map[string]bool{"v1": true, "v2": true}
+	removed, err := ds.prune(1, pinned)
+	if err != nil || len(removed) != 0 {
+		t.Errorf("expected no version removed, got %v err=%v", removed, err)
+	}
+
+	removed, err = ds.prune(0, nil)
+	if err != nil || len(removed) != 0 {
+		t.Errorf("expected no limit of max 0, got %v err=%v", removed, err)
+	}
+
+	removed, err = ds.prune(1, nil)
+	if err != nil || fmt.Sprint(removed) != "[v1 v2]" {
+		t.Errorf("expected [v1 v2] removed, got %v err=%v", removed, err)
+	}
+}
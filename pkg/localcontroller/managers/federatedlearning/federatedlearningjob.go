@@ -112,6 +112,17 @@ func (fm *Manager) Insert(message *clienttypes.Message) error {
 	return nil
 }
 
+// Update applies the spec patch to federated-learning-job config in db, which is read by the workers when started
+func (fm *Manager) Update(message *clienttypes.Message) error {
+	name := util.GetUniqueIdentifier(message.Header.Namespace, message.Header.ResourceName, message.Header.ResourceKind)
+
+	patched, err := types.PatchMessage(name, message)
+	if err != nil {
+		return err
+	}
+	return fm.Insert(patched)
+}
+
 // Delete deletes federated-learning-job config in db
 func (fm *Manager) Delete(message *clienttypes.Message) error {
 	name := util.GetUniqueIdentifier(message.Header.Namespace, message.Header.ResourceName, message.Header.ResourceKind)
@@ -24,6 +24,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
@@ -37,12 +38,13 @@ import (
 	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
 	"github.com/kubeedge/sedna/pkg/localcontroller/db"
 	clienttypes "github.com/kubeedge/sedna/pkg/localcontroller/gmclient"
+	"github.com/kubeedge/sedna/pkg/localcontroller/managers"
 	"github.com/kubeedge/sedna/pkg/localcontroller/managers/dataset"
 	"github.com/kubeedge/sedna/pkg/localcontroller/managers/model"
 	"github.com/kubeedge/sedna/pkg/localcontroller/storage"
-	"github.com/kubeedge/sedna/pkg/localcontroller/trigger"
 	"github.com/kubeedge/sedna/pkg/localcontroller/util"
 	workertypes "github.com/kubeedge/sedna/pkg/localcontroller/worker"
+	"github.com/kubeedge/sedna/pkg/trigger"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -56,8 +58,8 @@ type Job struct {
 type JobConfig struct {
 	UniqueIdentifier                  string
 	Rounds                            int
-	TrainTrigger                      trigger.Base
-	DeployTrigger                     trigger.Base
+	TrainTrigger                      *trigger.Checker
+	DeployTrigger                     *trigger.Checker
 	TriggerTime                       time.Time
 	TrainTriggerStatus                string
 	EvalTriggerStatus                 string
@@ -87,12 +89,18 @@ type OutputConfig struct {
 	EvalOutput    string            `json:"evalOutput"`
 }
 
-// DataSamples defines samples information
+// DataSamples defines samples information, which only keeps the positions of the samples in the dataset,
+// the samples are read from the dataset segments in batches when written for the workers
 type DataSamples struct {
-	PreviousNumbers    int
-	TrainSamples       []string
-	EvalVersionSamples [][]string
-	EvalSamples        []string
+	// PreviousNumbers is the position of the samples not trained yet
+	PreviousNumbers int
+	// Cursor reads the samples from PreviousNumbers, it's nil until the dataset is loaded
+	Cursor *dataset.Cursor
+	// TrainSamples and EvalSamples are the ranges of the samples of the triggered train round
+	TrainSamples dataset.SampleRange
+	EvalSamples  dataset.SampleRange
+	// EvalVersionSamples are the ranges of the eval samples of the last rounds
+	EvalVersionSamples []dataset.SampleRange
 }
 
 // IncrementalLearningJob defines incremental-learning-job manager
@@ -103,13 +111,17 @@ type Manager struct {
 	ModelManager         *model.Manager
 	IncrementalJobMap    map[string]*Job
 	VolumeMountPrefix    string
+
+	// mapLock guards IncrementalJobMap, which is also read by the LC API
+	mapLock sync.RWMutex
+
+	// workerChannel sends the commands to the workers connected by the streams
+	workerChannel managers.WorkerChannel
 }
 
 const (
 	// JobIterationIntervalSeconds is interval time of each iteration of job
 	JobIterationIntervalSeconds = 10
-	// DatasetHandlerIntervalSeconds is interval time of handling dataset
-	DatasetHandlerIntervalSeconds = 10
 	// EvalSamplesCapacity is capacity of eval samples
 	EvalSamplesCapacity = 5
 	//KindName is kind of incremental-learning-job resource
@@ -120,9 +132,14 @@ const (
 	// TriggerCompletedStatus is the completed status about trigger
 	TriggerCompletedStatus = "completed"
 
+	// TrainTriggerName is the name of train trigger whose state is saved in db
+	TrainTriggerName = "train"
+	// DeployTriggerName is the name of deploy trigger whose state is saved in db
+	DeployTriggerName = "deploy"
+
 	AnnotationsRoundsKey          = "sedna.io/rounds"
 	AnnotationsNumberOfSamplesKey = "sedna.io/number-of-samples"
-	AnnotationsDataFileOfEvalKey  = "sedna.io/data-file-of-eval"
+	AnnotationsEvalSamplesKey     = "sedna.io/eval-samples"
 )
 
 // New creates a incremental-learning-job manager
@@ -320,11 +337,12 @@ func (im *Manager) hotModelUpdateDeployTask(job *Job) error {
 			}
 		}
 
+		modelPath := strings.Replace(localHostModelFile, localHostDir, runtime.ModelHotUpdateContainerPrefix, 1)
+		modelUpdateTime := time.Now().String()
 		config := map[string]map[string]string{
 			"model_config": {
-				"model_path": strings.Replace(localHostModelFile, localHostDir,
-					runtime.ModelHotUpdateContainerPrefix, 1),
-				"model_update_time": time.Now().String(),
+				"model_path":        modelPath,
+				"model_update_time": modelUpdateTime,
 			},
 		}
 
@@ -345,6 +363,9 @@ func (im *Manager) hotModelUpdateDeployTask(job *Job) error {
 		job.JobConfig.HotModelUpdateDeployTriggerStatus = TriggerCompletedStatus
 		klog.V(4).Infof("job(%s) write model config file(url=%s) successfully in deploy phase",
 			job.JobConfig.UniqueIdentifier, modelConfigFile)
+
+		// the connected workers load the model at once instead of polling the config file
+		im.notifyModelUpdate(job, modelPath, modelUpdateTime)
 		klog.Infof("job(%s) completed the %s task successfully", job.JobConfig.UniqueIdentifier, sednav1.ILJobDeploy)
 	}
 
@@ -424,10 +445,40 @@ func (im *Manager) deployTask(job *Job) error {
 	return nil
 }
 
+// SetWorkerChannel sets the channel sending the commands to the workers
+func (im *Manager) SetWorkerChannel(channel managers.WorkerChannel) {
+	im.workerChannel = channel
+}
+
+// notifyModelUpdate tells the workers of the job the new model is available
+func (im *Manager) notifyModelUpdate(job *Job, modelPath string, modelUpdateTime string) {
+	if im.workerChannel == nil {
+		return
+	}
+
+	count := im.workerChannel.Broadcast(job.Namespace, KindName, job.Name, workertypes.Command{
+		Name: workertypes.UpdateModelCommand,
+		Parameters: map[string]interface{}{
+			"model_path":        modelPath,
+			"model_update_time": modelUpdateTime,
+		},
+	})
+	klog.V(4).Infof("job(%s) told %d workers the new model(path=%s)", job.JobConfig.UniqueIdentifier, count, modelPath)
+}
+
+// getJob gets the job by its unique identifier
+func (im *Manager) getJob(name string) (*Job, bool) {
+	im.mapLock.RLock()
+	defer im.mapLock.RUnlock()
+
+	job, ok := im.IncrementalJobMap[name]
+	return job, ok
+}
+
 // startJob starts a job
 func (im *Manager) startJob(name string) {
 	var err error
-	job := im.IncrementalJobMap[name]
+	job, _ := im.getJob(name)
 
 	err = im.initJob(job, name)
 	if err != nil {
@@ -438,10 +489,6 @@ func (im *Manager) startJob(name string) {
 	klog.Infof("incremental job(%s) was started", name)
 	defer klog.Infof("incremental learning job(%s) was stopped", name)
 
-	// handle data from dataset
-	go im.handleData(job)
-
-	tick := time.NewTicker(JobIterationIntervalSeconds * time.Second)
 	for {
 		select {
 		case <-job.JobConfig.Done:
@@ -451,6 +498,7 @@ func (im *Manager) startJob(name string) {
 
 		cond := im.getLatestCondition(job)
 		jobStage := cond.Stage
+		wait := JobIterationIntervalSeconds * time.Second
 
 		switch jobStage {
 		case sednav1.ILJobTrain:
@@ -472,7 +520,10 @@ func (im *Manager) startJob(name string) {
 			klog.Errorf("job(%s) failed to complete the %s task: %v", name, jobStage, err)
 		}
 
-		<-tick.C
+		if jobStage == sednav1.ILJobTrain {
+			wait = managers.NextTriggerCheck(wait, job.JobConfig.trainTrigger())
+		}
+		<-time.After(wait)
 	}
 }
 
@@ -481,12 +532,14 @@ func (im *Manager) Insert(message *clienttypes.Message) error {
 	name := util.GetUniqueIdentifier(message.Header.Namespace, message.Header.ResourceName, message.Header.ResourceKind)
 
 	first := false
+	im.mapLock.Lock()
 	job, ok := im.IncrementalJobMap[name]
 	if !ok {
 		job = &Job{}
 		im.IncrementalJobMap[name] = job
 		first = true
 	}
+	im.mapLock.Unlock()
 
 	if err := json.Unmarshal(message.Content, &job); err != nil {
 		return err
@@ -503,6 +556,72 @@ func (im *Manager) Insert(message *clienttypes.Message) error {
 	return nil
 }
 
+// Update applies the spec changes of the running incremental-learning-job without restarting it,
+// e.g. the updated triggers keep their state and the rotated credential is used at once.
+// The changes of the worker templates are applied by GM when creating the workers.
+func (im *Manager) Update(message *clienttypes.Message) error {
+	name := util.GetUniqueIdentifier(message.Header.Namespace, message.Header.ResourceName, message.Header.ResourceKind)
+
+	job, ok := im.getJob(name)
+	if !ok || job.JobConfig == nil {
+		return im.Insert(message)
+	}
+
+	newJob := sednav1.IncrementalLearningJob{}
+	patched, err := managers.PatchMessage(name, message)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(patched.Content, &newJob); err != nil {
+		return err
+	}
+
+	jobConfig := job.JobConfig
+	jobConfig.Lock.Lock()
+	defer jobConfig.Lock.Unlock()
+
+	if !reflect.DeepEqual(job.Spec.TrainSpec.Trigger, newJob.Spec.TrainSpec.Trigger) {
+		trainTrigger, err := updateTrigger(jobConfig.TrainTrigger, newJob.Spec.TrainSpec.Trigger)
+		if err != nil {
+			return fmt.Errorf("failed to update train trigger: %w", err)
+		}
+		if err := trigger.ValidateMetrics(trainTrigger, trigger.NumOfSamplesMetric); err != nil {
+			return fmt.Errorf("invalid train trigger: %w", err)
+		}
+		jobConfig.TrainTrigger = trainTrigger
+	}
+
+	if !reflect.DeepEqual(job.Spec.DeploySpec.Trigger, newJob.Spec.DeploySpec.Trigger) {
+		deployTrigger, err := updateTrigger(jobConfig.DeployTrigger, newJob.Spec.DeploySpec.Trigger)
+		if err != nil {
+			return fmt.Errorf("failed to update deploy trigger: %w", err)
+		}
+		jobConfig.DeployTrigger = deployTrigger
+	}
+
+	credential := newJob.Annotations[runtime.SecretAnnotationKey]
+	if credential != "" && credential != job.Annotations[runtime.SecretAnnotationKey] {
+		if err := jobConfig.Storage.SetCredential(credential); err != nil {
+			return fmt.Errorf("failed to update storage credential: %w", err)
+		}
+	}
+
+	// the annotations of the job progress are written by LC
+	for _, key := range []string{AnnotationsRoundsKey, AnnotationsNumberOfSamplesKey, AnnotationsEvalSamplesKey} {
+		if value, ok := job.Annotations[key]; ok {
+			if newJob.Annotations == nil {
+				newJob.Annotations = make(map[string]string)
+			}
+			newJob.Annotations[key] = value
+		}
+	}
+	job.IncrementalLearningJob = newJob
+
+	klog.Infof("incremental learning job(%s) was updated: %s", name, message.Patch)
+
+	return db.SaveResource(name, job.TypeMeta, job.ObjectMeta, job.Spec)
+}
+
 // deleteModelHotUpdateData deletes the local data of model hot update
 func (im *Manager) deleteModelHotUpdateData(job *Job) error {
 	if configFile, ok := job.ObjectMeta.Annotations[runtime.ModelHotUpdateAnnotationsKey]; ok {
@@ -520,7 +639,7 @@ func (im *Manager) deleteModelHotUpdateData(job *Job) error {
 func (im *Manager) Delete(message *clienttypes.Message) error {
 	name := util.GetUniqueIdentifier(message.Header.Namespace, message.Header.ResourceName, message.Header.ResourceKind)
 
-	if job, ok := im.IncrementalJobMap[name]; ok && job.JobConfig.Done != nil {
+	if job, ok := im.getJob(name); ok && job.JobConfig.Done != nil {
 		close(job.JobConfig.Done)
 
 		if err := im.deleteModelHotUpdateData(job); err != nil {
@@ -529,13 +648,16 @@ func (im *Manager) Delete(message *clienttypes.Message) error {
 		}
 	}
 
+	im.mapLock.Lock()
 	delete(im.IncrementalJobMap, name)
+	im.mapLock.Unlock()
+	im.DatasetManager.Unpin(name)
 
 	if err := db.DeleteResource(name); err != nil {
 		return err
 	}
 
-	return nil
+	return managers.DeleteTriggerStates(name, TrainTriggerName, DeployTriggerName)
 }
 
 // updateJobFromDB updates job from db
@@ -570,30 +692,12 @@ func (im *Manager) updateJobFromDB(job *Job) error {
 		return err
 	}
 
-	dataFileOfEval, ok := m.Annotations[AnnotationsDataFileOfEvalKey]
+	evalSamples, ok := m.Annotations[AnnotationsEvalSamplesKey]
 	if !ok {
 		return nil
 	}
 
-	localURL, err := job.JobConfig.Storage.Download(dataFileOfEval, "")
-
-	if !job.JobConfig.Storage.IsLocalStorage {
-		defer os.RemoveAll(localURL)
-	}
-
-	if err != nil {
-		return err
-	}
-
-	samples, err := dataset.GetSamples(dataFileOfEval)
-	if err != nil {
-		klog.Errorf("read file %s failed: %v", dataFileOfEval, err)
-		return err
-	}
-
-	job.JobConfig.DataSamples.EvalVersionSamples = append(job.JobConfig.DataSamples.EvalVersionSamples, samples)
-
-	return nil
+	return json.Unmarshal([]byte(evalSamples), &job.JobConfig.DataSamples.EvalVersionSamples)
 }
 
 // saveJobToDB saves job info to db
@@ -605,7 +709,11 @@ func (im *Manager) saveJobToDB(job *Job) error {
 
 	ann[AnnotationsRoundsKey] = strconv.Itoa(job.JobConfig.Rounds)
 	ann[AnnotationsNumberOfSamplesKey] = strconv.Itoa(job.JobConfig.DataSamples.PreviousNumbers)
-	ann[AnnotationsDataFileOfEvalKey] = job.JobConfig.EvalDataURL
+	evalSamples, err := json.Marshal(job.JobConfig.DataSamples.EvalVersionSamples)
+	if err != nil {
+		return err
+	}
+	ann[AnnotationsEvalSamplesKey] = string(evalSamples)
 
 	return db.SaveResource(job.JobConfig.UniqueIdentifier, job.TypeMeta, job.ObjectMeta, job.Spec)
 }
@@ -631,19 +739,23 @@ func (im *Manager) initJob(job *Job, name string) error {
 
 	jobConfig.DataSamples = &DataSamples{
 		PreviousNumbers:    0,
-		TrainSamples:       make([]string, 0),
-		EvalVersionSamples: make([][]string, 0),
-		EvalSamples:        make([]string, 0),
+		EvalVersionSamples: make([]dataset.SampleRange, 0),
 	}
 
 	trainTrigger, err := newTrigger(job.Spec.TrainSpec.Trigger)
 	if err != nil {
 		return fmt.Errorf("failed to init train trigger: %+w", err)
 	}
+	if err := trigger.ValidateMetrics(trainTrigger, trigger.NumOfSamplesMetric); err != nil {
+		return fmt.Errorf("invalid train trigger: %w", err)
+	}
 	deployTrigger, err := newTrigger(job.Spec.DeploySpec.Trigger)
 	if err != nil {
 		return fmt.Errorf("failed to init deploy trigger: %+w", err)
 	}
+	managers.LoadTriggerState(name, TrainTriggerName, trainTrigger)
+	managers.LoadTriggerState(name, DeployTriggerName, deployTrigger)
+	im.restoreDatasetVersionPin(job, name)
 	jobConfig.TrainTrigger = trainTrigger
 	jobConfig.DeployTrigger = deployTrigger
 
@@ -681,7 +793,7 @@ func initTriggerStatus(jobConfig *JobConfig) {
 	jobConfig.HotModelUpdateDeployTriggerStatus = TriggerReadyStatus
 }
 
-func newTrigger(t sednav1.Trigger) (trigger.Base, error) {
+func newTrigger(t sednav1.Trigger) (*trigger.Checker, error) {
 	// convert trigger to map
 	triggerMap := make(map[string]interface{})
 	c, err := json.Marshal(t)
@@ -696,6 +808,29 @@ func newTrigger(t sednav1.Trigger) (trigger.Base, error) {
 	return trigger.NewTrigger(triggerMap)
 }
 
+// trainTrigger returns the train trigger, which is replaced by Update under the lock
+func (jc *JobConfig) trainTrigger() *trigger.Checker {
+	jc.Lock.Lock()
+	defer jc.Lock.Unlock()
+
+	return jc.TrainTrigger
+}
+
+// updateTrigger creates the trigger of the updated spec, which keeps the state of the current one
+func updateTrigger(current *trigger.Checker, t sednav1.Trigger) (*trigger.Checker, error) {
+	checker, err := newTrigger(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if current != nil {
+		if err := checker.Inherit(current); err != nil {
+			return nil, err
+		}
+	}
+	return checker, nil
+}
+
 // getModelsFromJobConditions gets models from job condition
 func (im *Manager) getModelsFromJobConditions(jobConditions []sednav1.ILJobCondition, stage sednav1.ILJobStage, currentType sednav1.ILJobStageConditionType, dataType string) []Model {
 	// TODO: runtime.type changes to common.type for gm and lc
@@ -798,12 +933,27 @@ func (im *Manager) triggerTrainTask(job *Job) (interface{}, bool, error) {
 	var err error
 	jobConfig := job.JobConfig
 
-	const numOfSamples = "num_of_samples"
+	trainTrigger := jobConfig.trainTrigger()
+	if !trainTrigger.Due() {
+		return nil, false, nil
+	}
+
+	cursor, err := im.samplesCursor(job)
+	if err != nil {
+		klog.Errorf("job(%s) failed to read samples of dataset, error: %v", jobConfig.UniqueIdentifier, err)
+		return nil, false, nil
+	}
+
+	// the samples after the cursor are split into train and eval samples when the round is triggered
+	remaining := cursor.Remaining()
+	numOfSamples := int(job.Spec.Dataset.TrainProb * float64(remaining))
+
 	samples := map[string]interface{}{
-		numOfSamples: len(jobConfig.DataSamples.TrainSamples),
+		trigger.NumOfSamplesMetric: numOfSamples,
 	}
 
-	isTrigger := jobConfig.TrainTrigger.Trigger(samples)
+	isTrigger := trainTrigger.Trigger(samples)
+	managers.SaveTriggerState(jobConfig.UniqueIdentifier, TrainTriggerName, trainTrigger)
 
 	if !isTrigger {
 		return nil, false, nil
@@ -819,8 +969,16 @@ func (im *Manager) triggerTrainTask(job *Job) (interface{}, bool, error) {
 		m = im.getModelFromJobConditions(job, sednav1.ILJobTrain)
 	}
 
+	position := cursor.Position()
+	jobConfig.Lock.Lock()
+	jobConfig.DataSamples.TrainSamples = dataset.SampleRange{Start: position, End: position + numOfSamples}
+	jobConfig.DataSamples.EvalSamples = dataset.SampleRange{Start: position + numOfSamples, End: position + remaining}
+	jobConfig.Lock.Unlock()
+	klog.Infof("job(%s)'s current train samples nums is %d, eval samples nums is %d",
+		jobConfig.UniqueIdentifier, numOfSamples, remaining-numOfSamples)
+
 	var dataIndexURL string
-	jobConfig.TrainDataURL, dataIndexURL, err = im.writeSamples(job, jobConfig.DataSamples.TrainSamples,
+	jobConfig.TrainDataURL, dataIndexURL, err = im.writeSamples(job, []dataset.SampleRange{jobConfig.DataSamples.TrainSamples},
 		jobConfig.OutputConfig.SamplesOutput["train"], rounds, jobConfig.Dataset.Spec.Format, jobConfig.Dataset.URLPrefix)
 	if err != nil {
 		job.JobConfig.Rounds--
@@ -838,10 +996,11 @@ func (im *Manager) triggerTrainTask(job *Job) (interface{}, bool, error) {
 	}
 
 	input := clienttypes.Input{
-		Models:       []Model{*m},
-		DataURL:      dataURL,
-		DataIndexURL: dataIndexURL,
-		OutputDir:    outputDir,
+		Models:         []Model{*m},
+		DataURL:        dataURL,
+		DataIndexURL:   dataIndexURL,
+		OutputDir:      outputDir,
+		DatasetVersion: im.pinDatasetVersion(job),
 	}
 	msg := clienttypes.UpstreamMessage{
 		Phase:  string(sednav1.ILJobTrain),
@@ -864,14 +1023,13 @@ func (im *Manager) triggerEvalTask(job *Job) (*clienttypes.UpstreamMessage, erro
 	models = append(models, *m, *jobConfig.EvalModel)
 
 	var dataIndexURL string
-	jobConfig.EvalDataURL, dataIndexURL, err = im.writeSamples(job, jobConfig.DataSamples.EvalSamples, jobConfig.OutputConfig.SamplesOutput["eval"],
+	jobConfig.EvalDataURL, dataIndexURL, err = im.writeSamples(job, jobConfig.DataSamples.EvalVersionSamples, jobConfig.OutputConfig.SamplesOutput["eval"],
 		job.JobConfig.Rounds, jobConfig.Dataset.Spec.Format, jobConfig.Dataset.URLPrefix)
 	if err != nil {
 		klog.Errorf("job(%s) eval phase: write samples to the file(%s) is failed: %v",
 			jobConfig.UniqueIdentifier, jobConfig.EvalDataURL, err)
 		return nil, err
 	}
-	jobConfig.DataSamples.EvalSamples = []string{}
 
 	dataURL := jobConfig.EvalDataURL
 	if jobConfig.Storage.IsLocalStorage {
@@ -898,6 +1056,9 @@ func (im *Manager) triggerDeployTask(job *Job) (bool, error) {
 	jobConfig := job.JobConfig
 
 	evalResult, err := im.getEvalResult(job)
+	if err != nil {
+		return false, err
+	}
 	// EvalResult must has two models info, first is trained model, second is deployed model.
 	if len(evalResult) != 2 {
 		return false, fmt.Errorf("expected 2 evaluation results, actual: %d", len(jobConfig.EvalResult))
@@ -916,21 +1077,14 @@ func (im *Manager) triggerDeployTask(job *Job) (bool, error) {
 		}
 		metricDelta[metric+"_delta"] = l
 	}
-	tt := job.Spec.DeploySpec.Trigger
-
-	// convert tt to map
-	triggerMap := make(map[string]interface{})
-	c, err := json.Marshal(tt)
-	if err != nil {
-		return false, err
-	}
 
-	err = json.Unmarshal(c, &triggerMap)
-	if err != nil {
-		return false, err
-	}
+	jobConfig.Lock.Lock()
+	deployTrigger := jobConfig.DeployTrigger
+	jobConfig.Lock.Unlock()
 
-	return jobConfig.DeployTrigger.Trigger(metricDelta), nil
+	isTrigger, err := trigger.Evaluate(deployTrigger, metricDelta)
+	managers.SaveTriggerState(jobConfig.UniqueIdentifier, DeployTriggerName, deployTrigger)
+	return isTrigger, err
 }
 
 // updateDeployModelFile updates deploy model file
@@ -1073,79 +1227,111 @@ func (im *Manager) loadDataset(job *Job) error {
 	return nil
 }
 
-// handleData updates samples information
-func (im *Manager) handleData(job *Job) {
-	tick := time.NewTicker(DatasetHandlerIntervalSeconds * time.Second)
-
+// pinDatasetVersion returns the dataset version of the samples of the train round, which is pinned to the job,
+// the version of the samples read by the job is created if not specified
+func (im *Manager) pinDatasetVersion(job *Job) string {
 	jobConfig := job.JobConfig
-	iterCount := 0
-	for {
-		select {
-		case <-jobConfig.Done:
-			return
-		default:
-		}
+	datasetName := util.GetUniqueIdentifier(job.Namespace, job.Spec.Dataset.Name, dataset.KindName)
+	if job.Spec.Dataset.Version != "" {
+		im.DatasetManager.Pin(datasetName, jobConfig.UniqueIdentifier, job.Spec.Dataset.Version)
+		return job.Spec.Dataset.Version
+	}
 
-		if iterCount%100 == 0 {
-			klog.V(4).Infof("job(%s) is handling dataset", jobConfig.UniqueIdentifier)
-		}
-		iterCount++
+	version, err := im.DatasetManager.Snapshot(datasetName, jobConfig.UniqueIdentifier, jobConfig.DataSamples.PreviousNumbers)
+	if err != nil {
+		klog.Warningf("job(%s) failed to pin the dataset version of round %d: %v",
+			jobConfig.UniqueIdentifier, jobConfig.Rounds, err)
+		return ""
+	}
 
-		if jobConfig.Dataset == nil || jobConfig.Dataset.DataSource == nil {
-			// already loaded dataset
-			<-tick.C
+	return version.Name
+}
+
+// restoreDatasetVersionPin pins the dataset version of the last train round to the job again,
+// e.g. after LC restarts, so the version is not pruned before the next round
+func (im *Manager) restoreDatasetVersionPin(job *Job, name string) {
+	version := job.Spec.Dataset.Version
+	if version == "" {
+		version = lastDatasetVersion(job.Status.Conditions)
+	}
+
+	if version != "" {
+		datasetName := util.GetUniqueIdentifier(job.Namespace, job.Spec.Dataset.Name, dataset.KindName)
+		im.DatasetManager.Pin(datasetName, name, version)
+	}
+}
+
+// lastDatasetVersion returns the dataset version of the last train round in the job conditions
+func lastDatasetVersion(jobConditions []sednav1.ILJobCondition) string {
+	for i := len(jobConditions) - 1; i >= 0; i-- {
+		var cond gmtypes.IncrementalCondData
+		jobCond := jobConditions[i]
+		if jobCond.Stage != sednav1.ILJobTrain {
 			continue
 		}
 
-		dataset := jobConfig.Dataset
-		currentNumberOfSamples := dataset.DataSource.NumberOfSamples
-		previousNumberOfSamples := jobConfig.DataSamples.PreviousNumbers
+		if err := (&cond).Unmarshal([]byte(jobCond.Data)); err != nil || cond.Input == nil {
+			continue
+		}
 
-		if dataset.DataSource != nil && currentNumberOfSamples > previousNumberOfSamples {
-			samples := dataset.DataSource.TrainSamples
-			newNumberOfSamples := currentNumberOfSamples - previousNumberOfSamples
-			trainNum := int(job.Spec.Dataset.TrainProb * float64(newNumberOfSamples))
+		if cond.Input.DatasetVersion != "" {
+			return cond.Input.DatasetVersion
+		}
+	}
+	return ""
+}
 
-			jobConfig.Lock.Lock()
-			jobConfig.DataSamples.TrainSamples = append(jobConfig.DataSamples.TrainSamples,
-				samples[previousNumberOfSamples:previousNumberOfSamples+trainNum]...)
-			klog.Infof("job(%s)'s current train samples nums is %d", jobConfig.UniqueIdentifier, trainNum)
+// samplesCursor returns the cursor of the samples not trained yet, which is opened once the dataset is loaded
+func (im *Manager) samplesCursor(job *Job) (*dataset.Cursor, error) {
+	jobConfig := job.JobConfig
+	jobConfig.Lock.Lock()
+	defer jobConfig.Lock.Unlock()
 
-			jobConfig.DataSamples.EvalVersionSamples = append(jobConfig.DataSamples.EvalVersionSamples,
-				samples[previousNumberOfSamples+trainNum:])
-			jobConfig.Lock.Unlock()
+	if jobConfig.DataSamples.Cursor != nil {
+		return jobConfig.DataSamples.Cursor, nil
+	}
 
-			for _, v := range jobConfig.DataSamples.EvalVersionSamples {
-				jobConfig.DataSamples.EvalSamples = append(jobConfig.DataSamples.EvalSamples, v...)
-			}
-			klog.Infof("job(%s)'s current eval samples nums is %d", jobConfig.UniqueIdentifier, len(jobConfig.DataSamples.EvalSamples))
+	if jobConfig.Dataset == nil || jobConfig.Dataset.DataSource == nil {
+		return nil, fmt.Errorf("dataset is not loaded")
+	}
 
-			jobConfig.DataSamples.PreviousNumbers = currentNumberOfSamples
+	position := jobConfig.DataSamples.PreviousNumbers
+	if version := job.Spec.Dataset.Version; version != "" {
+		cursor, err := jobConfig.Dataset.NewVersionCursor(version, position)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dataset version %s: %w", version, err)
 		}
-
-		<-tick.C
+		jobConfig.DataSamples.Cursor = cursor
+	} else {
+		jobConfig.DataSamples.Cursor = jobConfig.Dataset.NewCursor(position)
 	}
+
+	return jobConfig.DataSamples.Cursor, nil
 }
 
 // createFile creates data file and data index file
 func createFile(dir string, format string, isLocalStorage bool) (string, string) {
-	switch format {
-	case dataset.TXTFormat:
+	switch strings.ToLower(format) {
+	case dataset.TXTFormat, dataset.ImageFolderFormat:
 		if isLocalStorage {
 			return path.Join(dir, "data.txt"), ""
 		}
 		return strings.Join([]string{dir, "data.txt"}, "/"), strings.Join([]string{dir, "dataIndex.txt"}, "/")
+	case dataset.JSONLFormat, dataset.ParquetFormat:
+		// the samples of parquet are converted to JSON object lines
+		return strings.Join([]string{dir, "data.jsonl"}, "/"), ""
 	}
 	return "", ""
 }
 
-// writeSamples writes samples information to a file
-func (im *Manager) writeSamples(job *Job, samples []string, dir string, rounds int, format string, urlPrefix string) (string, string, error) {
-	if samples == nil {
-		return "", "", fmt.Errorf("not samples")
+// writeSamples writes samples information of the ranges to a file
+func (im *Manager) writeSamples(job *Job, samples []dataset.SampleRange, dir string, rounds int, format string, urlPrefix string) (string, string, error) {
+	jobConfig := job.JobConfig
+	cursor, err := im.samplesCursor(job)
+	if err != nil {
+		return "", "", err
 	}
 
-	jobConfig := job.JobConfig
 	subDir := strings.Join([]string{dir, strconv.Itoa(rounds)}, "/")
 	fileURL, absURLFile := createFile(subDir, format, jobConfig.Dataset.Storage.IsLocalStorage)
 
@@ -1153,13 +1339,12 @@ func (im *Manager) writeSamples(job *Job, samples []string, dir string, rounds i
 		if err := util.CreateFolder(subDir); err != nil {
 			return "", "", err
 		}
-		if err := im.writeByLine(samples, fileURL); err != nil {
+		if err := im.writeByLine(cursor, samples, "", fileURL); err != nil {
 			return "", "", err
 		}
 
-		if !jobConfig.Dataset.Storage.IsLocalStorage {
-			tempSamples := util.ParsingDatasetIndex(samples, urlPrefix)
-			if err := im.writeByLine(tempSamples, absURLFile); err != nil {
+		if !jobConfig.Dataset.Storage.IsLocalStorage && absURLFile != "" {
+			if err := im.writeByLine(cursor, samples, urlPrefix, absURLFile); err != nil {
 				return "", "", err
 			}
 		}
@@ -1174,7 +1359,7 @@ func (im *Manager) writeSamples(job *Job, samples []string, dir string, rounds i
 
 	localFileURL, localAbsURLFile := createFile(temporaryDir, format, jobConfig.Dataset.Storage.IsLocalStorage)
 
-	if err := im.writeByLine(samples, localFileURL); err != nil {
+	if err := im.writeByLine(cursor, samples, "", localFileURL); err != nil {
 		return "", "", err
 	}
 
@@ -1182,33 +1367,50 @@ func (im *Manager) writeSamples(job *Job, samples []string, dir string, rounds i
 		return "", "", err
 	}
 
-	tempSamples := util.ParsingDatasetIndex(samples, urlPrefix)
+	defer os.RemoveAll(localFileURL)
 
-	if err := im.writeByLine(tempSamples, localAbsURLFile); err != nil {
-		return "", "", err
-	}
+	if absURLFile != "" {
+		if err := im.writeByLine(cursor, samples, urlPrefix, localAbsURLFile); err != nil {
+			return "", "", err
+		}
 
-	if err := jobConfig.Storage.Upload(localAbsURLFile, absURLFile); err != nil {
-		return "", "", err
-	}
+		if err := jobConfig.Storage.Upload(localAbsURLFile, absURLFile); err != nil {
+			return "", "", err
+		}
 
-	defer os.RemoveAll(localFileURL)
-	defer os.RemoveAll(localAbsURLFile)
+		defer os.RemoveAll(localAbsURLFile)
+	}
 
 	return fileURL, absURLFile, nil
 }
 
-// writeByLine writes file by line
-func (im *Manager) writeByLine(samples []string, fileURL string) error {
+// writeByLine writes the samples of the ranges to file by line, which are read from the cursor in batches.
+// The index of the samples with the prefix is written instead if indexPrefix is not empty.
+func (im *Manager) writeByLine(cursor *dataset.Cursor, samples []dataset.SampleRange, indexPrefix string, fileURL string) error {
 	file, err := os.Create(fileURL)
 	if err != nil {
 		klog.Errorf("create file(%s) failed", fileURL)
 		return err
 	}
+	defer file.Close()
 
 	w := bufio.NewWriter(file)
-	for _, line := range samples {
-		_, _ = fmt.Fprintln(w, line)
+	if indexPrefix != "" {
+		_, _ = fmt.Fprintln(w, indexPrefix)
+	}
+	err = cursor.ReadRanges(samples, func(lines []string) error {
+		if indexPrefix != "" {
+			// the first line of the index is the prefix
+			lines = util.ParsingDatasetIndex(lines, indexPrefix)[1:]
+		}
+		for _, line := range lines {
+			_, _ = fmt.Fprintln(w, line)
+		}
+		return nil
+	})
+	if err != nil {
+		klog.Errorf("failed to read samples of file(%s): %v", fileURL, err)
+		return err
 	}
 	if err := w.Flush(); err != nil {
 		klog.Errorf("failed to write file(%s): %v", fileURL, err)
@@ -1235,7 +1437,7 @@ func (im *Manager) monitorWorker() {
 
 		name := util.GetUniqueIdentifier(workerMessage.Namespace, workerMessage.OwnerName, workerMessage.OwnerKind)
 
-		job, ok := im.IncrementalJobMap[name]
+		job, ok := im.getJob(name)
 		if !ok {
 			continue
 		}
@@ -1258,12 +1460,17 @@ func (im *Manager) monitorWorker() {
 	}
 }
 
-// forwardSamples deletes the samples information in the memory
+// forwardSamples moves the positions of the samples after the triggered task
 func forwardSamples(jobConfig *JobConfig, jobStage sednav1.ILJobStage) {
 	switch jobStage {
 	case sednav1.ILJobTrain:
 		jobConfig.Lock.Lock()
-		jobConfig.DataSamples.TrainSamples = jobConfig.DataSamples.TrainSamples[:0]
+		samples := jobConfig.DataSamples
+		samples.EvalVersionSamples = append(samples.EvalVersionSamples, samples.EvalSamples)
+		samples.PreviousNumbers = samples.EvalSamples.End
+		samples.Cursor = samples.Cursor.At(samples.PreviousNumbers)
+		samples.TrainSamples = dataset.SampleRange{}
+		samples.EvalSamples = dataset.SampleRange{}
 		jobConfig.Lock.Unlock()
 	case sednav1.ILJobEval:
 		if len(jobConfig.DataSamples.EvalVersionSamples) > EvalSamplesCapacity {
@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package incrementallearning
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeedge/sedna/cmd/sedna-lc/app/options"
+	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
+	"github.com/kubeedge/sedna/pkg/localcontroller/managers/dataset"
+)
+
+func newTestJob(version string, conditions ...sednav1.ILJobCondition) *Job {
+	job := &Job{JobConfig: &JobConfig{UniqueIdentifier: "default/job", DataSamples: &DataSamples{}}}
+	job.ObjectMeta = metav1.ObjectMeta{Namespace: "default", Name: "job"}
+	job.Spec.Dataset = sednav1.ILDataset{Name: "ds", Version: version}
+	job.Status.Conditions = conditions
+	return job
+}
+
+func TestPinDatasetVersion(t *testing.T) {
+	dm := dataset.New(nil, &options.LocalControllerOptions{})
+	m := &Manager{DatasetManager: dm}
+
+	// the version of the spec is pinned as is
+	if version := m.pinDatasetVersion(newTestJob("v3")); version != "v3" {
+		t.Errorf("expected version v3, got %s", version)
+	}
+	if pins := dm.Pins("default/dataset/ds"); !reflect.DeepEqual(pins, map[string]string{"default/job": "v3"}) {
+		t.Errorf("unexpected pins %v", pins)
+	}
+
+	// no version is pinned if the dataset can't be snapshotted
+	if version := m.pinDatasetVersion(newTestJob("")); version != "" {
+		t.Errorf("expected no version without the dataset, got %s", version)
+	}
+	if pins := dm.Pins("default/dataset/ds"); pins["default/job"] != "v3" {
+		t.Errorf("expected the pin kept, got %v", pins)
+	}
+}
+
+func TestRestoreDatasetVersionPin(t *testing.T) {
+	train := func(data string) sednav1.ILJobCondition {
+		return sednav1.ILJobCondition{Stage: sednav1.ILJobTrain, Data: data}
+	}
+	conditions := []sednav1.ILJobCondition{
+		train(`{"input":{"datasetVersion":"v1"}}`),
+		train(`{"input":{"datasetVersion":"v2"}}`),
+		{Stage: sednav1.ILJobEval, Data: `{"input":{"datasetVersion":"v9"}}`},
+		train(`{"output":{}}`),
+		train(`{invalid`),
+	}
+
+	tests := []struct {
+		name     string
+		job      *Job
+		expected map[string]string
+	}{
+		{
+			name:     "last train round",
+			job:      newTestJob("", conditions...),
+			expected: map[string]string{"default/job": "v2"},
+		},
+		{
+			name:     "version of spec",
+			job:      newTestJob("v5", conditions...),
+			expected: map[string]string{"default/job": "v5"},
+		},
+		{
+			name:     "no train round",
+			job:      newTestJob(""),
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := dataset.New(nil, &options.LocalControllerOptions{})
+			m := &Manager{DatasetManager: dm}
+			m.restoreDatasetVersionPin(tt.job, "default/job")
+			if pins := dm.Pins("default/dataset/ds"); !reflect.DeepEqual(pins, tt.expected) {
+				t.Errorf("expected pins %v, got %v", tt.expected, pins)
+			}
+		})
+	}
+}
+
+func TestForwardSamples(t *testing.T) {
+	jobConfig := &JobConfig{DataSamples: &DataSamples{
+		PreviousNumbers:    10,
+		Cursor:             new(dataset.Cursor).At(10),
+		TrainSamples:       dataset.SampleRange{Start: 10, End: 18},
+		EvalSamples:        dataset.SampleRange{Start: 18, End: 20},
+		EvalVersionSamples: []dataset.SampleRange{{Start: 8, End: 10}},
+	}}
+
+	// the train round moves the cursor after its samples, and keeps the positions of its eval samples
+	forwardSamples(jobConfig, sednav1.ILJobTrain)
+	samples := jobConfig.DataSamples
+	if samples.PreviousNumbers != 20 || samples.Cursor.Position() != 20 {
+		t.Errorf("expected position 20, got %d and cursor %d", samples.PreviousNumbers, samples.Cursor.Position())
+	}
+	expected := []dataset.SampleRange{{Start: 8, End: 10}, {Start: 18, End: 20}}
+	if !reflect.DeepEqual(samples.EvalVersionSamples, expected) {
+		t.Errorf("expected eval samples %v, got %v", expected, samples.EvalVersionSamples)
+	}
+	if samples.TrainSamples.Len() != 0 || samples.EvalSamples.Len() != 0 {
+		t.Errorf("expected no samples of the round, got %v and %v", samples.TrainSamples, samples.EvalSamples)
+	}
+
+	// the eval samples of the oldest round are dropped beyond the capacity
+	for i := 0; i < EvalSamplesCapacity; i++ {
+		samples.EvalVersionSamples = append(samples.EvalVersionSamples, dataset.SampleRange{Start: 20 + i, End: 21 + i})
+	}
+	forwardSamples(jobConfig, sednav1.ILJobEval)
+	if len(samples.EvalVersionSamples) != EvalSamplesCapacity+1 || samples.EvalVersionSamples[0] != expected[1] {
+		t.Errorf("expected the oldest eval samples dropped, got %v", samples.EvalVersionSamples)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package incrementallearning
+
+import (
+	"time"
+
+	"github.com/kubeedge/sedna/pkg/localcontroller/managers"
+	"github.com/kubeedge/sedna/pkg/trigger"
+)
+
+// JobState defines the state of incremental-learning-job shown by the LC API
+type JobState struct {
+	Rounds      int       `json:"rounds"`
+	TriggerTime time.Time `json:"triggerTime,omitempty"`
+
+	TrainTriggerStatus                string `json:"trainTriggerStatus,omitempty"`
+	EvalTriggerStatus                 string `json:"evalTriggerStatus,omitempty"`
+	DeployTriggerStatus               string `json:"deployTriggerStatus,omitempty"`
+	HotModelUpdateDeployTriggerStatus string `json:"hotModelUpdateDeployTriggerStatus,omitempty"`
+
+	// TrainTrigger and DeployTrigger are the results of the last evaluations of the triggers
+	TrainTrigger  *trigger.State `json:"trainTrigger,omitempty"`
+	DeployTrigger *trigger.State `json:"deployTrigger,omitempty"`
+
+	Dataset string `json:"dataset,omitempty"`
+	// SamplesRead is the number of the samples of the dataset read by the job
+	SamplesRead int `json:"samplesRead"`
+	// TrainSamples is the number of the train samples pending for the next round,
+	// EvalSamples is the number of the eval samples of the last rounds
+	TrainSamples int `json:"trainSamples"`
+	EvalSamples  int `json:"evalSamples"`
+
+	OutputDir   string  `json:"outputDir,omitempty"`
+	TrainModel  *Model  `json:"trainModel,omitempty"`
+	EvalModel   *Model  `json:"evalModel,omitempty"`
+	DeployModel *Model  `json:"deployModel,omitempty"`
+	EvalResult  []Model `json:"evalResult,omitempty"`
+}
+
+// ListStates lists the states of the jobs
+func (im *Manager) ListStates() []managers.ResourceState {
+	im.mapLock.RLock()
+	jobs := make([]*Job, 0, len(im.IncrementalJobMap))
+	for _, job := range im.IncrementalJobMap {
+		jobs = append(jobs, job)
+	}
+	im.mapLock.RUnlock()
+
+	states := make([]managers.ResourceState, 0, len(jobs))
+	for _, job := range jobs {
+		states = append(states, job.state())
+	}
+	return states
+}
+
+// GetState gets the state of the job
+func (im *Manager) GetState(namespace, name string) (managers.ResourceState, bool) {
+	for _, state := range im.ListStates() {
+		if state.Namespace == namespace && state.Name == name {
+			return state, true
+		}
+	}
+	return managers.ResourceState{}, false
+}
+
+// state returns the state of the job, which is empty until the job is initialized
+func (job *Job) state() managers.ResourceState {
+	rs := managers.ResourceState{Namespace: job.Namespace, Name: job.Name}
+	jobConfig := job.JobConfig
+	if jobConfig == nil {
+		return rs
+	}
+
+	jobConfig.Lock.Lock()
+	defer jobConfig.Lock.Unlock()
+
+	state := JobState{
+		Rounds:                            jobConfig.Rounds,
+		TriggerTime:                       jobConfig.TriggerTime,
+		TrainTriggerStatus:                jobConfig.TrainTriggerStatus,
+		EvalTriggerStatus:                 jobConfig.EvalTriggerStatus,
+		DeployTriggerStatus:               jobConfig.DeployTriggerStatus,
+		HotModelUpdateDeployTriggerStatus: jobConfig.HotModelUpdateDeployTriggerStatus,
+		OutputDir:                         jobConfig.OutputDir,
+		TrainModel:                        jobConfig.TrainModel,
+		EvalModel:                         jobConfig.EvalModel,
+		DeployModel:                       jobConfig.DeployModel,
+		EvalResult:                        jobConfig.EvalResult,
+	}
+	if t := jobConfig.TrainTrigger; t != nil {
+		s := t.Status()
+		state.TrainTrigger = &s
+	}
+	if t := jobConfig.DeployTrigger; t != nil {
+		s := t.Status()
+		state.DeployTrigger = &s
+	}
+	if ds := jobConfig.Dataset; ds != nil && ds.Dataset != nil {
+		state.Dataset = ds.Name
+	}
+	if samples := jobConfig.DataSamples; samples != nil {
+		state.SamplesRead = samples.PreviousNumbers
+		if samples.Cursor != nil {
+			state.TrainSamples = int(job.Spec.Dataset.TrainProb * float64(samples.Cursor.Remaining()))
+		}
+		for _, r := range samples.EvalVersionSamples {
+			state.EvalSamples += r.Len()
+		}
+	}
+
+	rs.State = state
+	return rs
+}
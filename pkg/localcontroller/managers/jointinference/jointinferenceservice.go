@@ -106,6 +106,17 @@ func (jm *Manager) Insert(message *clienttypes.Message) error {
 	return nil
 }
 
+// Update applies the spec patch to joint-inference-service config in db, which is read by the workers when started
+func (jm *Manager) Update(message *clienttypes.Message) error {
+	name := util.GetUniqueIdentifier(message.Header.Namespace, message.Header.ResourceName, message.Header.ResourceKind)
+
+	patched, err := types.PatchMessage(name, message)
+	if err != nil {
+		return err
+	}
+	return jm.Insert(patched)
+}
+
 // Delete deletes joint-inference-service config in db
 func (jm *Manager) Delete(message *clienttypes.Message) error {
 	name := util.GetUniqueIdentifier(message.Header.Namespace, message.Header.ResourceName, message.Header.ResourceKind)
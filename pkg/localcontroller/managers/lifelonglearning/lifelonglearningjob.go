@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
@@ -36,19 +37,18 @@ import (
 	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
 	"github.com/kubeedge/sedna/pkg/localcontroller/db"
 	clienttypes "github.com/kubeedge/sedna/pkg/localcontroller/gmclient"
+	"github.com/kubeedge/sedna/pkg/localcontroller/managers"
 	"github.com/kubeedge/sedna/pkg/localcontroller/managers/dataset"
 	"github.com/kubeedge/sedna/pkg/localcontroller/storage"
-	"github.com/kubeedge/sedna/pkg/localcontroller/trigger"
 	"github.com/kubeedge/sedna/pkg/localcontroller/util"
 	workertypes "github.com/kubeedge/sedna/pkg/localcontroller/worker"
+	"github.com/kubeedge/sedna/pkg/trigger"
 	"github.com/microcosm-cc/bluemonday"
 )
 
 const (
 	// JobIterationIntervalSeconds is interval time of each iteration of job
 	JobIterationIntervalSeconds = 10
-	// DatasetHandlerIntervalSeconds is interval time of handling dataset
-	DatasetHandlerIntervalSeconds = 10
 	// EvalSamplesCapacity is capacity of eval samples
 	EvalSamplesCapacity = 5
 	//KindName is kind of lifelong-learning-job resource
@@ -59,9 +59,12 @@ const (
 	// TriggerCompletedStatus is the completed status about trigger
 	TriggerCompletedStatus = "completed"
 
+	// TrainTriggerName is the name of train trigger whose state is saved in db
+	TrainTriggerName = "train"
+
 	AnnotationsRoundsKey          = "sedna.io/rounds"
 	AnnotationsNumberOfSamplesKey = "sedna.io/number-of-samples"
-	AnnotationsDataFileOfEvalKey  = "sedna.io/data-file-of-eval"
+	AnnotationsEvalSamplesKey     = "sedna.io/eval-samples"
 )
 
 // LifelongLearningJobManager defines lifelong-learning-job Manager
@@ -71,6 +74,9 @@ type Manager struct {
 	DatasetManager         *dataset.Manager
 	LifelongLearningJobMap map[string]*Job
 	VolumeMountPrefix      string
+
+	// mapLock guards LifelongLearningJobMap, which is also read by the LC API
+	mapLock sync.RWMutex
 }
 
 // LifelongLearningJob defines config for lifelong-learning-job
@@ -83,7 +89,7 @@ type Job struct {
 type JobConfig struct {
 	UniqueIdentifier    string
 	Rounds              int
-	TrainTrigger        trigger.Base
+	TrainTrigger        *trigger.Checker
 	TriggerTime         time.Time
 	TrainTriggerStatus  string
 	EvalTriggerStatus   string
@@ -109,12 +115,18 @@ type OutputConfig struct {
 	EvalOutput    string            `json:"evalOutput"`
 }
 
-// DataSamples defines samples information
+// DataSamples defines samples information, which only keeps the positions of the samples in the dataset,
+// the samples are read from the dataset segments in batches when written for the workers
 type DataSamples struct {
-	PreviousNumbers    int
-	TrainSamples       []string
-	EvalVersionSamples [][]string
-	EvalSamples        []string
+	// PreviousNumbers is the position of the samples not trained yet
+	PreviousNumbers int
+	// Cursor reads the samples from PreviousNumbers, it's nil until the dataset is loaded
+	Cursor *dataset.Cursor
+	// TrainSamples and EvalSamples are the ranges of the samples of the triggered train round
+	TrainSamples dataset.SampleRange
+	EvalSamples  dataset.SampleRange
+	// EvalVersionSamples are the ranges of the eval samples of the last rounds
+	EvalVersionSamples []dataset.SampleRange
 }
 
 // New creates a lifelong-learning-job manager
@@ -137,12 +149,14 @@ func (lm *Manager) Insert(message *clienttypes.Message) error {
 		message.Header.ResourceName, message.Header.ResourceKind))
 
 	first := false
+	lm.mapLock.Lock()
 	job, ok := lm.LifelongLearningJobMap[name]
 	if !ok {
 		job = &Job{}
 		lm.LifelongLearningJobMap[name] = job
 		first = true
 	}
+	lm.mapLock.Unlock()
 
 	if err := json.Unmarshal(message.Content, &job); err != nil {
 		return err
@@ -159,10 +173,79 @@ func (lm *Manager) Insert(message *clienttypes.Message) error {
 	return nil
 }
 
+// Update applies the spec changes of the running lifelong-learning-job without restarting it,
+// e.g. the updated trigger keeps its state and the rotated credential is used at once.
+// The changes of the worker templates are applied by GM when creating the workers.
+func (lm *Manager) Update(message *clienttypes.Message) error {
+	p := bluemonday.NewPolicy()
+	name := p.Sanitize(util.GetUniqueIdentifier(message.Header.Namespace,
+		message.Header.ResourceName, message.Header.ResourceKind))
+
+	job, ok := lm.getJob(name)
+	if !ok || job.JobConfig == nil {
+		return lm.Insert(message)
+	}
+
+	newJob := sednav1.LifelongLearningJob{}
+	patched, err := managers.PatchMessage(name, message)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(patched.Content, &newJob); err != nil {
+		return err
+	}
+
+	jobConfig := job.JobConfig
+	jobConfig.Lock.Lock()
+	defer jobConfig.Lock.Unlock()
+
+	if !reflect.DeepEqual(job.Spec.TrainSpec.Trigger, newJob.Spec.TrainSpec.Trigger) {
+		trainTrigger, err := updateTrigger(jobConfig.TrainTrigger, newJob.Spec.TrainSpec.Trigger)
+		if err != nil {
+			return fmt.Errorf("failed to update train trigger: %w", err)
+		}
+		if err := trigger.ValidateMetrics(trainTrigger, trigger.NumOfSamplesMetric); err != nil {
+			return fmt.Errorf("invalid train trigger: %w", err)
+		}
+		jobConfig.TrainTrigger = trainTrigger
+	}
+
+	credential := newJob.Annotations[runtime.SecretAnnotationKey]
+	if credential != "" && credential != job.Annotations[runtime.SecretAnnotationKey] {
+		if err := jobConfig.Storage.SetCredential(credential); err != nil {
+			return fmt.Errorf("failed to update storage credential: %w", err)
+		}
+	}
+
+	// the annotations of the job progress are written by LC
+	for _, key := range []string{AnnotationsRoundsKey, AnnotationsNumberOfSamplesKey, AnnotationsEvalSamplesKey} {
+		if value, ok := job.Annotations[key]; ok {
+			if newJob.Annotations == nil {
+				newJob.Annotations = make(map[string]string)
+			}
+			newJob.Annotations[key] = value
+		}
+	}
+	job.LifelongLearningJob = newJob
+
+	klog.Infof("lifelong learning job(%s) was updated: %s", name, message.Patch)
+
+	return db.SaveResource(name, job.TypeMeta, job.ObjectMeta, job.Spec)
+}
+
+// getJob gets the job by its unique identifier
+func (lm *Manager) getJob(name string) (*Job, bool) {
+	lm.mapLock.RLock()
+	defer lm.mapLock.RUnlock()
+
+	job, ok := lm.LifelongLearningJobMap[name]
+	return job, ok
+}
+
 // startJob starts a job
 func (lm *Manager) startJob(name string) {
 	var err error
-	job, ok := lm.LifelongLearningJobMap[name]
+	job, ok := lm.getJob(name)
 	if !ok {
 		return
 	}
@@ -175,17 +258,15 @@ func (lm *Manager) startJob(name string) {
 	klog.Infof("lifelong learning job(%s) is started", name)
 	defer klog.Infof("lifelong learning job(%s) is stopped", name)
 
-	// handle data from dataset
-	go lm.handleData(job)
-
-	tick := time.NewTicker(JobIterationIntervalSeconds * time.Second)
+	wait := JobIterationIntervalSeconds * time.Second
 	for {
 		select {
 		case <-job.JobConfig.Done:
 			return
-		case <-tick.C:
+		case <-time.After(wait):
 			cond := lm.getLatestCondition(job)
 			jobStage := cond.Stage
+			wait = JobIterationIntervalSeconds * time.Second
 
 			switch jobStage {
 			case sednav1.LLJobTrain:
@@ -203,6 +284,10 @@ func (lm *Manager) startJob(name string) {
 			if err != nil {
 				klog.Errorf("job(%s) failed to complete the %s task: %v", name, jobStage, err)
 			}
+
+			if jobStage == sednav1.LLJobTrain {
+				wait = managers.NextTriggerCheck(wait, job.JobConfig.trainTrigger())
+			}
 		}
 	}
 }
@@ -351,13 +436,28 @@ func (lm *Manager) triggerTrainTask(job *Job) (interface{}, bool, error) {
 	var err error
 	jobConfig := job.JobConfig
 
-	const numOfSamples = "num_of_samples"
+	trainTrigger := jobConfig.trainTrigger()
+	if !trainTrigger.Due() {
+		return nil, false, nil
+	}
+
+	cursor, err := lm.samplesCursor(job)
+	if err != nil {
+		klog.Errorf("job(%s) failed to read samples of dataset, error: %v", jobConfig.UniqueIdentifier, err)
+		return nil, false, nil
+	}
+
+	// the samples after the cursor are split into train and eval samples when the round is triggered
+	remaining := cursor.Remaining()
+	numOfSamples := int(job.Spec.Dataset.TrainProb * float64(remaining))
+
 	samples := map[string]interface{}{
-		numOfSamples: len(jobConfig.DataSamples.TrainSamples),
+		trigger.NumOfSamplesMetric: numOfSamples,
 	}
 
 	// the condition to trigger training worker.
-	isTrigger := jobConfig.TrainTrigger.Trigger(samples)
+	isTrigger := trainTrigger.Trigger(samples)
+	managers.SaveTriggerState(jobConfig.UniqueIdentifier, TrainTriggerName, trainTrigger)
 
 	if !isTrigger {
 		return nil, false, nil
@@ -366,8 +466,16 @@ func (lm *Manager) triggerTrainTask(job *Job) (interface{}, bool, error) {
 	job.JobConfig.Rounds++
 	rounds := jobConfig.Rounds
 
+	position := cursor.Position()
+	jobConfig.Lock.Lock()
+	jobConfig.DataSamples.TrainSamples = dataset.SampleRange{Start: position, End: position + numOfSamples}
+	jobConfig.DataSamples.EvalSamples = dataset.SampleRange{Start: position + numOfSamples, End: position + remaining}
+	jobConfig.Lock.Unlock()
+	klog.Infof("job(%s)'s current train samples nums is %d, eval samples nums is %d",
+		jobConfig.UniqueIdentifier, numOfSamples, remaining-numOfSamples)
+
 	var dataIndexURL string
-	jobConfig.TrainDataURL, dataIndexURL, err = lm.writeSamples(job, jobConfig.DataSamples.TrainSamples,
+	jobConfig.TrainDataURL, dataIndexURL, err = lm.writeSamples(job, []dataset.SampleRange{jobConfig.DataSamples.TrainSamples},
 		jobConfig.OutputConfig.SamplesOutput["train"], rounds, jobConfig.Dataset.Spec.Format, jobConfig.Dataset.URLPrefix)
 	if err != nil {
 		job.JobConfig.Rounds--
@@ -385,9 +493,10 @@ func (lm *Manager) triggerTrainTask(job *Job) (interface{}, bool, error) {
 	}
 
 	input := clienttypes.Input{
-		DataURL:      dataURL,
-		DataIndexURL: dataIndexURL,
-		OutputDir:    outputDir,
+		DataURL:        dataURL,
+		DataIndexURL:   dataIndexURL,
+		OutputDir:      outputDir,
+		DatasetVersion: lm.pinDatasetVersion(job),
 	}
 
 	// the message sent to GM, then create training worker.
@@ -415,7 +524,7 @@ func (lm *Manager) triggerEvalTask(job *Job) (*clienttypes.UpstreamMessage, erro
 	}
 
 	var dataIndexURL string
-	jobConfig.EvalDataURL, dataIndexURL, err = lm.writeSamples(job, jobConfig.DataSamples.EvalSamples, jobConfig.OutputConfig.SamplesOutput["eval"],
+	jobConfig.EvalDataURL, dataIndexURL, err = lm.writeSamples(job, jobConfig.DataSamples.EvalVersionSamples, jobConfig.OutputConfig.SamplesOutput["eval"],
 		job.JobConfig.Rounds, jobConfig.Dataset.Spec.Format, jobConfig.Dataset.URLPrefix)
 	if err != nil {
 		klog.Errorf("job(%s) eval phase: write samples to the file(%s) is failed: %v",
@@ -507,25 +616,29 @@ func (lm *Manager) getLatestCondition(job *Job) sednav1.LLJobCondition {
 // createFile creates data file and data index file
 func createFile(dir string, format string, isLocalStorage bool) (string, string) {
 	switch strings.ToLower(format) {
-	case dataset.TXTFormat:
+	case dataset.TXTFormat, dataset.ImageFolderFormat:
 		if isLocalStorage {
 			return path.Join(dir, "data.txt"), ""
 		}
 		return strings.Join([]string{dir, "data.txt"}, "/"), strings.Join([]string{dir, "dataIndex.txt"}, "/")
 	case dataset.CSVFormat:
 		return strings.Join([]string{dir, "data.csv"}, "/"), ""
+	case dataset.JSONLFormat, dataset.ParquetFormat:
+		// the samples of parquet are converted to JSON object lines
+		return strings.Join([]string{dir, "data.jsonl"}, "/"), ""
 	}
 
 	return "", ""
 }
 
-// writeSamples writes samples information to a file
-func (lm *Manager) writeSamples(job *Job, samples []string, dir string, rounds int, format string, urlPrefix string) (string, string, error) {
-	if samples == nil {
-		return "", "", fmt.Errorf("not samples")
+// writeSamples writes samples information of the ranges to a file
+func (lm *Manager) writeSamples(job *Job, samples []dataset.SampleRange, dir string, rounds int, format string, urlPrefix string) (string, string, error) {
+	jobConfig := job.JobConfig
+	cursor, err := lm.samplesCursor(job)
+	if err != nil {
+		return "", "", err
 	}
 
-	jobConfig := job.JobConfig
 	subDir := strings.Join([]string{dir, strconv.Itoa(rounds)}, "/")
 	fileURL, absURLFile := createFile(subDir, format, jobConfig.Dataset.Storage.IsLocalStorage)
 
@@ -533,13 +646,12 @@ func (lm *Manager) writeSamples(job *Job, samples []string, dir string, rounds i
 		if err := util.CreateFolder(subDir); err != nil {
 			return "", "", err
 		}
-		if err := job.writeByLine(samples, fileURL, format); err != nil {
+		if err := job.writeByLine(cursor, samples, "", fileURL, format); err != nil {
 			return "", "", err
 		}
 
 		if !jobConfig.Dataset.Storage.IsLocalStorage && absURLFile != "" {
-			tempSamples := util.ParsingDatasetIndex(samples, urlPrefix)
-			if err := job.writeByLine(tempSamples, absURLFile, format); err != nil {
+			if err := job.writeByLine(cursor, samples, urlPrefix, absURLFile, format); err != nil {
 				return "", "", err
 			}
 		}
@@ -554,7 +666,7 @@ func (lm *Manager) writeSamples(job *Job, samples []string, dir string, rounds i
 
 	localFileURL, localAbsURLFile := createFile(temporaryDir, format, jobConfig.Dataset.Storage.IsLocalStorage)
 
-	if err := job.writeByLine(samples, localFileURL, format); err != nil {
+	if err := job.writeByLine(cursor, samples, "", localFileURL, format); err != nil {
 		return "", "", err
 	}
 
@@ -563,9 +675,7 @@ func (lm *Manager) writeSamples(job *Job, samples []string, dir string, rounds i
 	}
 
 	if absURLFile != "" {
-		tempSamples := util.ParsingDatasetIndex(samples, urlPrefix)
-
-		if err := job.writeByLine(tempSamples, localAbsURLFile, format); err != nil {
+		if err := job.writeByLine(cursor, samples, urlPrefix, localAbsURLFile, format); err != nil {
 			return "", "", err
 		}
 
@@ -581,22 +691,37 @@ func (lm *Manager) writeSamples(job *Job, samples []string, dir string, rounds i
 	return fileURL, absURLFile, nil
 }
 
-// writeByLine writes file by line
-func (job *Job) writeByLine(samples []string, fileURL string, format string) error {
+// writeByLine writes the samples of the ranges to file by line, which are read from the cursor in batches.
+// The index of the samples with the prefix is written instead if indexPrefix is not empty.
+func (job *Job) writeByLine(cursor *dataset.Cursor, samples []dataset.SampleRange, indexPrefix string, fileURL string, format string) error {
 	file, err := os.Create(fileURL)
 	if err != nil {
 		klog.Errorf("create file(%s) failed", fileURL)
 		return err
 	}
+	defer file.Close()
 
 	w := bufio.NewWriter(file)
 
-	if format == "csv" {
+	if indexPrefix != "" {
+		_, _ = fmt.Fprintln(w, indexPrefix)
+	} else if format == "csv" {
 		_, _ = fmt.Fprintln(w, job.JobConfig.Dataset.DataSource.Header)
 	}
 
-	for _, line := range samples {
-		_, _ = fmt.Fprintln(w, line)
+	err = cursor.ReadRanges(samples, func(lines []string) error {
+		if indexPrefix != "" {
+			// the first line of the index is the prefix
+			lines = util.ParsingDatasetIndex(lines, indexPrefix)[1:]
+		}
+		for _, line := range lines {
+			_, _ = fmt.Fprintln(w, line)
+		}
+		return nil
+	})
+	if err != nil {
+		klog.Errorf("failed to read samples of file(%s): %v", fileURL, err)
+		return err
 	}
 	if err := w.Flush(); err != nil {
 		klog.Errorf("write file(%s) failed", fileURL)
@@ -611,58 +736,86 @@ func (job *Job) writeByLine(samples []string, fileURL string, format string) err
 	return nil
 }
 
-// handleData updates samples information
-func (lm *Manager) handleData(job *Job) {
-	tick := time.NewTicker(DatasetHandlerIntervalSeconds * time.Second)
-
+// pinDatasetVersion returns the dataset version of the samples of the train round, which is pinned to the job,
+// the version of the samples read by the job is created if not specified
+func (lm *Manager) pinDatasetVersion(job *Job) string {
 	jobConfig := job.JobConfig
-	iterCount := 0
-	for {
-		select {
-		case <-jobConfig.Done:
-			return
-		default:
-		}
+	datasetName := util.GetUniqueIdentifier(job.Namespace, job.Spec.Dataset.Name, dataset.KindName)
+	if job.Spec.Dataset.Version != "" {
+		lm.DatasetManager.Pin(datasetName, jobConfig.UniqueIdentifier, job.Spec.Dataset.Version)
+		return job.Spec.Dataset.Version
+	}
 
-		if iterCount%100 == 0 {
-			klog.V(4).Infof("job(%s) is handling dataset", jobConfig.UniqueIdentifier)
-		}
-		iterCount++
+	version, err := lm.DatasetManager.Snapshot(datasetName, jobConfig.UniqueIdentifier, jobConfig.DataSamples.PreviousNumbers)
+	if err != nil {
+		klog.Warningf("job(%s) failed to pin the dataset version of round %d: %v",
+			jobConfig.UniqueIdentifier, jobConfig.Rounds, err)
+		return ""
+	}
 
-		if jobConfig.Dataset == nil || jobConfig.Dataset.DataSource == nil {
-			// already loaded dataset
-			<-tick.C
+	return version.Name
+}
+
+// restoreDatasetVersionPin pins the dataset version of the last train round to the job again,
+// e.g. after LC restarts, so the version is not pruned before the next round
+func (lm *Manager) restoreDatasetVersionPin(job *Job, name string) {
+	version := job.Spec.Dataset.Version
+	if version == "" {
+		version = lastDatasetVersion(job.Status.Conditions)
+	}
+
+	if version != "" {
+		datasetName := util.GetUniqueIdentifier(job.Namespace, job.Spec.Dataset.Name, dataset.KindName)
+		lm.DatasetManager.Pin(datasetName, name, version)
+	}
+}
+
+// lastDatasetVersion returns the dataset version of the last train round in the job conditions
+func lastDatasetVersion(jobConditions []sednav1.LLJobCondition) string {
+	for i := len(jobConditions) - 1; i >= 0; i-- {
+		var cond gmtypes.ConditionData
+		jobCond := jobConditions[i]
+		if jobCond.Stage != sednav1.LLJobTrain {
 			continue
 		}
 
-		dataset := jobConfig.Dataset
-		currentNumberOfSamples := dataset.DataSource.NumberOfSamples
-		previousNumberOfSamples := jobConfig.DataSamples.PreviousNumbers
+		if err := (&cond).Unmarshal([]byte(jobCond.Data)); err != nil || cond.Input == nil {
+			continue
+		}
 
-		if dataset.DataSource != nil && currentNumberOfSamples > previousNumberOfSamples {
-			samples := dataset.DataSource.TrainSamples
-			newNumberOfSamples := currentNumberOfSamples - previousNumberOfSamples
-			trainNum := int(job.Spec.Dataset.TrainProb * float64(newNumberOfSamples))
+		if cond.Input.DatasetVersion != "" {
+			return cond.Input.DatasetVersion
+		}
+	}
+	return ""
+}
 
-			jobConfig.Lock.Lock()
-			jobConfig.DataSamples.TrainSamples = append(jobConfig.DataSamples.TrainSamples,
-				samples[previousNumberOfSamples:previousNumberOfSamples+trainNum]...)
-			klog.Infof("job(%s)'s current train samples nums is %d", jobConfig.UniqueIdentifier, trainNum)
+// samplesCursor returns the cursor of the samples not trained yet, which is opened once the dataset is loaded
+func (lm *Manager) samplesCursor(job *Job) (*dataset.Cursor, error) {
+	jobConfig := job.JobConfig
+	jobConfig.Lock.Lock()
+	defer jobConfig.Lock.Unlock()
 
-			jobConfig.DataSamples.EvalVersionSamples = append(jobConfig.DataSamples.EvalVersionSamples,
-				samples[previousNumberOfSamples+trainNum:])
-			jobConfig.Lock.Unlock()
+	if jobConfig.DataSamples.Cursor != nil {
+		return jobConfig.DataSamples.Cursor, nil
+	}
 
-			for _, v := range jobConfig.DataSamples.EvalVersionSamples {
-				jobConfig.DataSamples.EvalSamples = append(jobConfig.DataSamples.EvalSamples, v...)
-			}
-			klog.Infof("job(%s)'s current eval samples nums is %d", jobConfig.UniqueIdentifier, len(jobConfig.DataSamples.EvalSamples))
+	if jobConfig.Dataset == nil || jobConfig.Dataset.DataSource == nil {
+		return nil, fmt.Errorf("dataset is not loaded")
+	}
 
-			jobConfig.DataSamples.PreviousNumbers = currentNumberOfSamples
+	position := jobConfig.DataSamples.PreviousNumbers
+	if version := job.Spec.Dataset.Version; version != "" {
+		cursor, err := jobConfig.Dataset.NewVersionCursor(version, position)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dataset version %s: %w", version, err)
 		}
-
-		<-tick.C
+		jobConfig.DataSamples.Cursor = cursor
+	} else {
+		jobConfig.DataSamples.Cursor = jobConfig.Dataset.NewCursor(position)
 	}
+
+	return jobConfig.DataSamples.Cursor, nil
 }
 
 // loadDataset loads dataset information
@@ -703,15 +856,18 @@ func (lm *Manager) initJob(job *Job, name string) error {
 
 	jobConfig.DataSamples = &DataSamples{
 		PreviousNumbers:    0,
-		TrainSamples:       make([]string, 0),
-		EvalVersionSamples: make([][]string, 0),
-		EvalSamples:        make([]string, 0),
+		EvalVersionSamples: make([]dataset.SampleRange, 0),
 	}
 
 	trainTrigger, err := newTrigger(job.Spec.TrainSpec.Trigger)
 	if err != nil {
 		return fmt.Errorf("failed to init train trigger: %+w", err)
 	}
+	if err := trigger.ValidateMetrics(trainTrigger, trigger.NumOfSamplesMetric); err != nil {
+		return fmt.Errorf("invalid train trigger: %w", err)
+	}
+	managers.LoadTriggerState(name, TrainTriggerName, trainTrigger)
+	lm.restoreDatasetVersionPin(job, name)
 	jobConfig.TrainTrigger = trainTrigger
 
 	outputDir := job.Spec.OutputDir
@@ -754,7 +910,7 @@ func initTriggerStatus(jobConfig *JobConfig) {
 	jobConfig.DeployTriggerStatus = TriggerReadyStatus
 }
 
-func newTrigger(t sednav1.LLTrigger) (trigger.Base, error) {
+func newTrigger(t sednav1.LLTrigger) (*trigger.Checker, error) {
 	// convert trigger to map
 	triggerMap := make(map[string]interface{})
 	c, err := json.Marshal(t)
@@ -769,6 +925,29 @@ func newTrigger(t sednav1.LLTrigger) (trigger.Base, error) {
 	return trigger.NewTrigger(triggerMap)
 }
 
+// trainTrigger returns the train trigger, which is replaced by Update under the lock
+func (jc *JobConfig) trainTrigger() *trigger.Checker {
+	jc.Lock.Lock()
+	defer jc.Lock.Unlock()
+
+	return jc.TrainTrigger
+}
+
+// updateTrigger creates the trigger of the updated spec, which keeps the state of the current one
+func updateTrigger(current *trigger.Checker, t sednav1.LLTrigger) (*trigger.Checker, error) {
+	checker, err := newTrigger(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if current != nil {
+		if err := checker.Inherit(current); err != nil {
+			return nil, err
+		}
+	}
+	return checker, nil
+}
+
 // getModelsFromJobConditions gets models from job condition
 func (lm *Manager) getModelsFromJobConditions(jobConditions []sednav1.LLJobCondition, stage sednav1.LLJobStage, currentType sednav1.LLJobStageConditionType, dataType string) []Model {
 	// TODO: runtime.type changes to common.type for gm and lc
@@ -836,12 +1015,17 @@ func (lm *Manager) getJobStageModel(job *Job, jobStage sednav1.LLJobStage) (mode
 	return models
 }
 
-// forwardSamples deletes the samples information in the memory
+// forwardSamples moves the positions of the samples after the triggered task
 func forwardSamples(jobConfig *JobConfig, jobStage sednav1.LLJobStage) {
 	switch jobStage {
 	case sednav1.LLJobTrain:
 		jobConfig.Lock.Lock()
-		jobConfig.DataSamples.TrainSamples = jobConfig.DataSamples.TrainSamples[:0]
+		samples := jobConfig.DataSamples
+		samples.EvalVersionSamples = append(samples.EvalVersionSamples, samples.EvalSamples)
+		samples.PreviousNumbers = samples.EvalSamples.End
+		samples.Cursor = samples.Cursor.At(samples.PreviousNumbers)
+		samples.TrainSamples = dataset.SampleRange{}
+		samples.EvalSamples = dataset.SampleRange{}
 		jobConfig.Lock.Unlock()
 	case sednav1.LLJobEval:
 		if len(jobConfig.DataSamples.EvalVersionSamples) > EvalSamplesCapacity {
@@ -854,17 +1038,20 @@ func forwardSamples(jobConfig *JobConfig, jobStage sednav1.LLJobStage) {
 func (lm *Manager) Delete(message *clienttypes.Message) error {
 	name := util.GetUniqueIdentifier(message.Header.Namespace, message.Header.ResourceName, message.Header.ResourceKind)
 
-	if job, ok := lm.LifelongLearningJobMap[name]; ok && job.JobConfig.Done != nil {
+	if job, ok := lm.getJob(name); ok && job.JobConfig.Done != nil {
 		close(job.JobConfig.Done)
 	}
 
+	lm.mapLock.Lock()
 	delete(lm.LifelongLearningJobMap, name)
+	lm.mapLock.Unlock()
+	lm.DatasetManager.Unpin(name)
 
 	if err := db.DeleteResource(name); err != nil {
 		return err
 	}
 
-	return nil
+	return managers.DeleteTriggerStates(name, TrainTriggerName)
 }
 
 // updateJobFromDB updates job from db
@@ -899,30 +1086,12 @@ func (lm *Manager) updateJobFromDB(job *Job) error {
 		return err
 	}
 
-	dataFileOfEval, ok := m.Annotations[AnnotationsDataFileOfEvalKey]
+	evalSamples, ok := m.Annotations[AnnotationsEvalSamplesKey]
 	if !ok {
 		return nil
 	}
 
-	localURL, err := job.JobConfig.Storage.Download(dataFileOfEval, "")
-
-	if !job.JobConfig.Storage.IsLocalStorage {
-		defer os.RemoveAll(localURL)
-	}
-
-	if err != nil {
-		return err
-	}
-
-	samples, err := dataset.GetSamples(dataFileOfEval)
-	if err != nil {
-		klog.Errorf("read file %s failed: %v", dataFileOfEval, err)
-		return err
-	}
-
-	job.JobConfig.DataSamples.EvalVersionSamples = append(job.JobConfig.DataSamples.EvalVersionSamples, samples)
-
-	return nil
+	return json.Unmarshal([]byte(evalSamples), &job.JobConfig.DataSamples.EvalVersionSamples)
 }
 
 // saveJobToDB saves job info to db
@@ -934,7 +1103,11 @@ func (lm *Manager) saveJobToDB(job *Job) error {
 
 	ann[AnnotationsRoundsKey] = strconv.Itoa(job.JobConfig.Rounds)
 	ann[AnnotationsNumberOfSamplesKey] = strconv.Itoa(job.JobConfig.DataSamples.PreviousNumbers)
-	ann[AnnotationsDataFileOfEvalKey] = job.JobConfig.EvalDataURL
+	evalSamples, err := json.Marshal(job.JobConfig.DataSamples.EvalVersionSamples)
+	if err != nil {
+		return err
+	}
+	ann[AnnotationsEvalSamplesKey] = string(evalSamples)
 
 	return db.SaveResource(job.JobConfig.UniqueIdentifier, job.TypeMeta, job.ObjectMeta, job.Spec)
 }
@@ -958,7 +1131,7 @@ func (lm *Manager) monitorWorker() {
 
 		name := util.GetUniqueIdentifier(workerMessage.Namespace, workerMessage.OwnerName, workerMessage.OwnerKind)
 
-		job, ok := lm.LifelongLearningJobMap[name]
+		job, ok := lm.getJob(name)
 		if !ok {
 			continue
 		}
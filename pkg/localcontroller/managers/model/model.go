@@ -18,10 +18,14 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
+	"sync"
 
 	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
 	"github.com/kubeedge/sedna/pkg/localcontroller/db"
 	clienttypes "github.com/kubeedge/sedna/pkg/localcontroller/gmclient"
+	"github.com/kubeedge/sedna/pkg/localcontroller/managers"
+	"github.com/kubeedge/sedna/pkg/localcontroller/storage"
 	"github.com/kubeedge/sedna/pkg/localcontroller/util"
 	workertypes "github.com/kubeedge/sedna/pkg/localcontroller/worker"
 )
@@ -30,6 +34,9 @@ import (
 type Manager struct {
 	Client   clienttypes.ClientI
 	ModelMap map[string]sednav1.Model
+
+	// mapLock guards ModelMap, which is also read by the LC API
+	mapLock sync.RWMutex
 }
 
 const (
@@ -54,12 +61,18 @@ func (mm *Manager) Start() error {
 
 // GetModel gets model
 func (mm *Manager) GetModel(name string) (sednav1.Model, bool) {
+	mm.mapLock.RLock()
+	defer mm.mapLock.RUnlock()
+
 	model, ok := mm.ModelMap[name]
 	return model, ok
 }
 
 // addNewModel adds model
 func (mm *Manager) addNewModel(name string, model sednav1.Model) {
+	mm.mapLock.Lock()
+	defer mm.mapLock.Unlock()
+
 	mm.ModelMap[name] = model
 }
 
@@ -72,6 +85,10 @@ func (mm *Manager) Insert(message *clienttypes.Message) error {
 		return err
 	}
 
+	if _, err := storage.ParseChecksum(model.Spec.Checksum); err != nil {
+		return fmt.Errorf("model(name=%s)'s checksum is invalid, error: %+v", name, err)
+	}
+
 	if err := db.SaveResource(name, model.TypeMeta, model.ObjectMeta, model.Spec); err != nil {
 		return err
 	}
@@ -81,11 +98,24 @@ func (mm *Manager) Insert(message *clienttypes.Message) error {
 	return nil
 }
 
+// Update applies the spec patch to model in db, which is kept only
+func (mm *Manager) Update(message *clienttypes.Message) error {
+	name := util.GetUniqueIdentifier(message.Header.Namespace, message.Header.ResourceName, message.Header.ResourceKind)
+
+	patched, err := managers.PatchMessage(name, message)
+	if err != nil {
+		return err
+	}
+	return mm.Insert(patched)
+}
+
 // Delete deletes model in db
 func (mm *Manager) Delete(message *clienttypes.Message) error {
 	name := util.GetUniqueIdentifier(message.Header.Namespace, message.Header.ResourceName, message.Header.ResourceKind)
 
+	mm.mapLock.Lock()
 	delete(mm.ModelMap, name)
+	mm.mapLock.Unlock()
 
 	if err := db.DeleteResource(name); err != nil {
 		return err
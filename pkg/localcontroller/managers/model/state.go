@@ -0,0 +1,54 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
+	"github.com/kubeedge/sedna/pkg/localcontroller/managers"
+)
+
+// State defines the state of model cached by LC shown by the LC API
+type State struct {
+	Spec   sednav1.ModelSpec   `json:"spec"`
+	Status sednav1.ModelStatus `json:"status"`
+}
+
+// ListStates lists the states of the models cached
+func (mm *Manager) ListStates() []managers.ResourceState {
+	mm.mapLock.RLock()
+	defer mm.mapLock.RUnlock()
+
+	states := make([]managers.ResourceState, 0, len(mm.ModelMap))
+	for _, model := range mm.ModelMap {
+		states = append(states, managers.ResourceState{
+			Namespace: model.Namespace,
+			Name:      model.Name,
+			State:     State{Spec: model.Spec, Status: model.Status},
+		})
+	}
+	return states
+}
+
+// GetState gets the state of the model cached
+func (mm *Manager) GetState(namespace, name string) (managers.ResourceState, bool) {
+	for _, state := range mm.ListStates() {
+		if state.Namespace == namespace && state.Name == name {
+			return state, true
+		}
+	}
+	return managers.ResourceState{}, false
+}
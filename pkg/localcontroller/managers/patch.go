@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+
+	"github.com/kubeedge/sedna/pkg/localcontroller/db"
+	clienttype "github.com/kubeedge/sedna/pkg/localcontroller/gmclient"
+)
+
+// PatchMessage returns the update message whose spec is the spec patch applied to the spec
+// of the resource saved in db, so the update applies the changes onto the resource LC has.
+// The message is returned as is if it carries no patch or the resource isn't saved.
+func PatchMessage(name string, message *clienttype.Message) (*clienttype.Message, error) {
+	if len(message.Patch) == 0 {
+		return message, nil
+	}
+
+	resource, err := db.GetResource(name)
+	if err != nil {
+		return message, nil
+	}
+
+	content, err := applySpecPatch(message.Content, []byte(resource.Spec), message.Patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply the spec patch of resource(name=%s): %w", name, err)
+	}
+
+	patched := *message
+	patched.Content = content
+	return &patched, nil
+}
+
+// applySpecPatch replaces the spec of the content with the spec merge patch applied to the spec
+func applySpecPatch(content, spec, patch []byte) ([]byte, error) {
+	var p struct {
+		Spec json.RawMessage `json:"spec"`
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, err
+	}
+	if len(p.Spec) == 0 {
+		return content, nil
+	}
+
+	patchedSpec, err := jsonpatch.MergePatch(spec, p.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(content, &object); err != nil {
+		return nil, err
+	}
+	object["spec"] = patchedSpec
+	return json.Marshal(object)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestApplySpecPatch(t *testing.T) {
+	content := `{"metadata":{"name":"job1","resourceVersion":"2"},"spec":{"url":"new","nodeName":"edge1"},"status":{"phase":"Running"}}`
+	spec := `{"url":"old","nodeName":"edge1","format":"txt","trigger":{"threshold":100,"metric":"num_of_samples"}}`
+
+	tests := []struct {
+		name     string
+		patch    string
+		expected string
+	}{
+		{
+			name:     "changed and removed fields",
+			patch:    `{"spec":{"url":"new","format":null}}`,
+			expected: `{"url":"new","nodeName":"edge1","trigger":{"threshold":100,"metric":"num_of_samples"}}`,
+		},
+		{
+			name:     "nested field",
+			patch:    `{"spec":{"trigger":{"threshold":200}}}`,
+			expected: `{"url":"old","nodeName":"edge1","format":"txt","trigger":{"threshold":200,"metric":"num_of_samples"}}`,
+		},
+		{
+			name:     "no spec patch",
+			patch:    `{}`,
+			expected: `{"url":"new","nodeName":"edge1"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patched, err := applySpecPatch([]byte(content), []byte(spec), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("failed to apply the patch: %v", err)
+			}
+
+			var object, expected map[string]interface{}
+			if err := json.Unmarshal(patched, &object); err != nil {
+				t.Fatalf("invalid patched content %s: %v", patched, err)
+			}
+			if err := json.Unmarshal([]byte(tt.expected), &expected); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(object["spec"], expected) {
+				t.Errorf("expected spec %v, got %v", expected, object["spec"])
+			}
+			if object["status"] == nil || object["metadata"] == nil {
+				t.Errorf("expected the metadata and status of the content kept, got %s", patched)
+			}
+		})
+	}
+}
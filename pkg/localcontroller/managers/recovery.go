@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubeedge/sedna/pkg/localcontroller/db"
+	clienttype "github.com/kubeedge/sedna/pkg/localcontroller/gmclient"
+)
+
+// Recover replays the resources saved in the db into the Insert of their feature managers,
+// so that LC keeps running them after restarted even if GM is unreachable.
+// The managers are recovered in order, e.g. the datasets and models before the jobs using them.
+func Recover(ms []FeatureManager) error {
+	resources, err := db.ListResources()
+	if err != nil {
+		return fmt.Errorf("failed to list the resources in db: %w", err)
+	}
+
+	recoverResources(ms, resources)
+	return nil
+}
+
+// recoverResources replays the resources into their feature managers, and returns the number recovered
+func recoverResources(ms []FeatureManager, resources []db.Resource) int {
+	messages := make(map[string][]*clienttype.Message)
+	for i := range resources {
+		message, err := newRecoveryMessage(&resources[i])
+		if err != nil {
+			klog.Errorf("failed to recover resource(name=%s): %v", resources[i].Name, err)
+			continue
+		}
+		kind := message.Header.ResourceKind
+		messages[kind] = append(messages[kind], message)
+	}
+
+	count := 0
+	for _, m := range ms {
+		for _, message := range messages[m.GetName()] {
+			if err := m.Insert(message); err != nil {
+				klog.Errorf("failed to recover %s(name=%s/%s): %v", m.GetName(),
+					message.Header.Namespace, message.Header.ResourceName, err)
+				continue
+			}
+			count++
+		}
+		delete(messages, m.GetName())
+	}
+
+	for kind, list := range messages {
+		klog.Warningf("skipped recovering %d resources of %s which has no manager", len(list), kind)
+	}
+	klog.Infof("recovered %d resources from db", count)
+	return count
+}
+
+// newRecoveryMessage builds the insert message of the resource, as if it's sent by GM
+func newRecoveryMessage(r *db.Resource) (*clienttype.Message, error) {
+	// the name of resource is "namespace/kind/name"
+	parts := strings.SplitN(r.Name, "/", 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("unknown resource name")
+	}
+
+	object := make(map[string]json.RawMessage)
+	if r.TypeMeta != "" {
+		if err := json.Unmarshal([]byte(r.TypeMeta), &object); err != nil {
+			return nil, fmt.Errorf("invalid type meta: %w", err)
+		}
+	}
+	for key, value := range map[string]string{"metadata": r.ObjectMeta, "spec": r.Spec} {
+		if !json.Valid([]byte(value)) {
+			return nil, fmt.Errorf("invalid %s", key)
+		}
+		object[key] = json.RawMessage(value)
+	}
+
+	content, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	return &clienttype.Message{
+		Header: clienttype.MessageHeader{
+			Namespace:    parts[0],
+			ResourceKind: parts[1],
+			ResourceName: parts[2],
+			Operation:    clienttype.InsertOperation,
+		},
+		Content: content,
+	}, nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managers
+
+import (
+	"encoding/json"
+	"testing"
+
+	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
+	"github.com/kubeedge/sedna/pkg/localcontroller/db"
+	clienttype "github.com/kubeedge/sedna/pkg/localcontroller/gmclient"
+	workertypes "github.com/kubeedge/sedna/pkg/localcontroller/worker"
+)
+
+// recordingManager is a fake manager recording the inserted resources in order
+type recordingManager struct {
+	name     string
+	inserted *[]string
+	datasets map[string]sednav1.Dataset
+}
+
+func (m *recordingManager) Start() error                                { return nil }
+func (m *recordingManager) GetName() string                             { return m.name }
+func (m *recordingManager) AddWorkerMessage(workertypes.MessageContent) {}
+func (m *recordingManager) Update(*clienttype.Message) error            { return nil }
+func (m *recordingManager) Delete(*clienttype.Message) error            { return nil }
+
+func (m *recordingManager) Insert(message *clienttype.Message) error {
+	var ds sednav1.Dataset
+	if err := json.Unmarshal(message.Content, &ds); err != nil {
+		return err
+	}
+	m.datasets[message.Header.ResourceName] = ds
+	*m.inserted = append(*m.inserted, message.Header.ResourceKind+"/"+message.Header.ResourceName)
+	return nil
+}
+
+func TestRecoverResources(t *testing.T) {
+	var inserted []string
+	newManager := func(name string) *recordingManager {
+		return &recordingManager{name: name, inserted: &inserted, datasets: make(map[string]sednav1.Dataset)}
+	}
+	dm := newManager("dataset")
+	jm := newManager("incrementallearningjob")
+
+	resources := []db.Resource{
+		{
+			Name:       "default/incrementallearningjob/job",
+			TypeMeta:   `{"kind":"IncrementalLearningJob","apiVersion":"sedna.io/v1alpha1"}`,
+			ObjectMeta: `{"name":"job","namespace":"default"}`,
+			Spec:       `{}`,
+		},
+		{
+			Name:       "default/dataset/ds",
+			TypeMeta:   `{"kind":"Dataset","apiVersion":"sedna.io/v1alpha1"}`,
+			ObjectMeta: `{"name":"ds","namespace":"default","uid":"uid"}`,
+			Spec:       `{"url":"/data/index.txt","format":"txt"}`,
+		},
+		{Name: "default/model/no-manager", ObjectMeta: `{}`, Spec: `{}`},
+		{Name: "invalid", ObjectMeta: `{}`, Spec: `{}`},
+		{Name: "default/dataset/broken", ObjectMeta: `{`, Spec: `{}`},
+	}
+
+	if count := recoverResources([]FeatureManager{dm, jm}, resources); count != 2 {
+		t.Errorf("expected 2 resources recovered, got %d", count)
+	}
+	// the managers are recovered in order
+	if len(inserted) != 2 || inserted[0] != "dataset/ds" || inserted[1] != "incrementallearningjob/job" {
+		t.Errorf("unexpected recovered resources %v", inserted)
+	}
+
+	ds := dm.datasets["ds"]
+	if ds.Kind != "Dataset" || ds.UID != "uid" || ds.Spec.URL != "/data/index.txt" || ds.Spec.Format != "txt" {
+		t.Errorf("unexpected recovered dataset %+v", ds)
+	}
+}
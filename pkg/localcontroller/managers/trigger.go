@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managers
+
+import (
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubeedge/sedna/pkg/localcontroller/db"
+	"github.com/kubeedge/sedna/pkg/trigger"
+)
+
+// minTriggerCheckInterval is the min interval of the job loops waiting for the trigger checks
+const minTriggerCheckInterval = time.Second
+
+// triggerStateName is the name of the trigger state of the job in db
+func triggerStateName(jobName, triggerName string) string {
+	return jobName + "/" + triggerName
+}
+
+// LoadTriggerState restores the state of the trigger of the job from db
+func LoadTriggerState(jobName string, triggerName string, t *trigger.Checker) {
+	state, err := db.GetTriggerState(triggerStateName(jobName, triggerName))
+	if err != nil {
+		// no state saved
+		return
+	}
+
+	if err := t.Restore(state); err != nil {
+		klog.Errorf("job(%s) failed to restore %s trigger state: %v", jobName, triggerName, err)
+	}
+}
+
+// SaveTriggerState saves the state of the trigger of the job to db
+func SaveTriggerState(jobName string, triggerName string, t *trigger.Checker) {
+	state, err := t.State()
+	if err == nil {
+		err = db.SaveTriggerState(triggerStateName(jobName, triggerName), state)
+	}
+
+	if err != nil {
+		klog.Errorf("job(%s) failed to save %s trigger state: %v", jobName, triggerName, err)
+	}
+}
+
+// DeleteTriggerStates deletes the states of the triggers of the job in db
+func DeleteTriggerStates(jobName string, triggerNames ...string) error {
+	for _, triggerName := range triggerNames {
+		if err := db.DeleteTriggerState(triggerStateName(jobName, triggerName)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NextTriggerCheck returns how long the job loop waits for the next iteration, which is the interval
+// unless the check of the trigger is due earlier, so the check periods shorter than the interval are honored
+func NextTriggerCheck(interval time.Duration, t *trigger.Checker) time.Duration {
+	if t == nil {
+		return interval
+	}
+
+	wait := t.Until()
+	if wait <= 0 {
+		// not checked in this iteration, e.g. no samples yet
+		wait = t.Period
+	}
+	if wait < minTriggerCheckInterval {
+		wait = minTriggerCheckInterval
+	}
+	if wait > interval {
+		return interval
+	}
+	return wait
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubeedge/sedna/pkg/trigger"
+)
+
+func TestNextTriggerCheck(t *testing.T) {
+	interval := 10 * time.Second
+	newChecker := func(periodSeconds int) *trigger.Checker {
+		c, err := trigger.NewTrigger(map[string]interface{}{
+			"checkPeriodSeconds": float64(periodSeconds),
+			"expression":         "num_of_samples > 10",
+		})
+		if err != nil {
+			t.Fatalf("failed to create trigger: %v", err)
+		}
+		return c
+	}
+	stats := map[string]interface{}{trigger.NumOfSamplesMetric: 20}
+
+	if wait := NextTriggerCheck(interval, nil); wait != interval {
+		t.Errorf("expected %v without trigger, got %v", interval, wait)
+	}
+
+	long := newChecker(60)
+	long.Trigger(stats)
+	if wait := NextTriggerCheck(interval, long); wait != interval {
+		t.Errorf("expected %v for a period longer than the interval, got %v", interval, wait)
+	}
+
+	short := newChecker(3)
+	if wait := NextTriggerCheck(interval, short); wait != 3*time.Second {
+		t.Errorf("expected the period before the first check, got %v", wait)
+	}
+	short.Trigger(stats)
+	if wait := NextTriggerCheck(interval, short); wait <= 2*time.Second || wait > 3*time.Second {
+		t.Errorf("expected the rest of the period after a check, got %v", wait)
+	}
+}
@@ -35,5 +35,45 @@ type FeatureManager interface {
 	// Insert includes gm message creation/updation
 	Insert(*clienttype.Message) error
 
+	// Update applies the spec changes of the resource without restarting it,
+	// it inserts the resource if not existing
+	Update(*clienttype.Message) error
+
 	Delete(*clienttype.Message) error
 }
+
+// Inspector is implemented by the feature managers whose state can be inspected by the LC API
+type Inspector interface {
+	// ListStates returns the states of the resources held by the manager
+	ListStates() []ResourceState
+
+	// GetState returns the state of the resource, false if not found
+	GetState(namespace, name string) (ResourceState, bool)
+}
+
+// ResourceState defines the state of a resource held by a feature manager
+type ResourceState struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// State is the details of the resource, which are specific to the feature
+	State interface{} `json:"state"`
+}
+
+// WorkerChannel sends the commands to the workers connected to LC by the streams
+type WorkerChannel interface {
+	// SendCommand queues the command of the worker of the owner, which is sent again
+	// when the worker reconnects until it's acknowledged
+	SendCommand(namespace, ownerKind, ownerName, workerName string, command workertypes.Command)
+
+	// Broadcast queues the command of all the workers of the owner known by LC,
+	// and returns the number of the workers
+	Broadcast(namespace, ownerKind, ownerName string, command workertypes.Command) int
+
+	// ListWorkers returns the names of the workers of the owner connected
+	ListWorkers(namespace, ownerKind, ownerName string) []string
+}
+
+// WorkerChannelUser is implemented by the feature managers sending the commands to the workers
+type WorkerChannelUser interface {
+	SetWorkerChannel(channel WorkerChannel)
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
+	"github.com/kubeedge/sedna/pkg/localcontroller/db"
+	"github.com/kubeedge/sedna/pkg/localcontroller/util"
+	workertypes "github.com/kubeedge/sedna/pkg/localcontroller/worker"
+)
+
+// workerAuthenticator verifies the tokens of the worker messages by the public key told by GM.
+// The messages are accepted without the token if GM doesn't issue the tokens.
+type workerAuthenticator struct {
+	// keyFile keeps the key, so the workers are authenticated when GM is unreachable after LC restarts
+	keyFile string
+
+	lock sync.RWMutex
+	key  ed25519.PublicKey
+
+	now func() time.Time
+	// ownerUID returns the uid of the owner known by LC, false if unknown
+	ownerUID func(namespace, name, kind string) (string, bool)
+}
+
+func newWorkerAuthenticator(keyFile string) *workerAuthenticator {
+	a := &workerAuthenticator{
+		keyFile:  keyFile,
+		now:      time.Now,
+		ownerUID: dbOwnerUID,
+	}
+
+	if data, err := ioutil.ReadFile(keyFile); err == nil {
+		if len(data) == ed25519.PublicKeySize {
+			a.key = data
+		} else {
+			klog.Errorf("ignored the invalid worker token key in %s", keyFile)
+		}
+	}
+	return a
+}
+
+// dbOwnerUID returns the uid of the owner in the db
+func dbOwnerUID(namespace, name, kind string) (string, bool) {
+	r, err := db.GetResource(util.GetUniqueIdentifier(namespace, name, kind))
+	if err != nil {
+		return "", false
+	}
+
+	var objectMeta metav1.ObjectMeta
+	if err := json.Unmarshal([]byte(r.ObjectMeta), &objectMeta); err != nil {
+		return "", false
+	}
+	return string(objectMeta.UID), true
+}
+
+// SetKey sets the key told by GM, empty if GM doesn't issue the tokens
+func (a *workerAuthenticator) SetKey(key []byte) {
+	if len(key) != 0 && len(key) != ed25519.PublicKeySize {
+		klog.Errorf("ignored the invalid worker token key of %d bytes told by global manager", len(key))
+		return
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if string(a.key) == string(key) {
+		return
+	}
+	a.key = key
+
+	var err error
+	if len(key) == 0 {
+		klog.Warningf("global manager doesn't issue the worker tokens, the worker messages are not authenticated")
+		err = os.Remove(a.keyFile)
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	} else {
+		klog.Infof("the worker messages are authenticated by the tokens issued by global manager")
+		if err = os.MkdirAll(filepath.Dir(a.keyFile), os.ModePerm); err == nil {
+			err = ioutil.WriteFile(a.keyFile, key, 0644)
+		}
+	}
+	if err != nil {
+		klog.Errorf("failed to save the worker token key in %s: %v", a.keyFile, err)
+	}
+}
+
+func (a *workerAuthenticator) getKey() ed25519.PublicKey {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return a.key
+}
+
+// bearerToken returns the bearer token of the request
+func bearerToken(request *restful.Request) string {
+	auth := strings.TrimSpace(request.HeaderParameter("Authorization"))
+	const prefix = "bearer "
+	if len(auth) > len(prefix) && strings.EqualFold(auth[:len(prefix)], prefix) {
+		return strings.TrimSpace(auth[len(prefix):])
+	}
+	return ""
+}
+
+// Authenticate verifies the token of the request is issued to the worker of the owner of the message
+func (a *workerAuthenticator) Authenticate(request *restful.Request, message *workertypes.MessageContent) error {
+	key := a.getKey()
+	if key == nil {
+		return nil
+	}
+
+	token := bearerToken(request)
+	if token == "" {
+		return fmt.Errorf("no worker token")
+	}
+	claims, err := runtime.VerifyWorkerToken(key, token, a.now())
+	if err != nil {
+		return err
+	}
+
+	if claims.Kind != message.OwnerKind || claims.Namespace != message.Namespace || claims.Name != message.OwnerName {
+		return fmt.Errorf("the token of %s %s/%s is not for %s %s/%s", claims.Kind, claims.Namespace, claims.Name,
+			message.OwnerKind, message.Namespace, message.OwnerName)
+	}
+	if claims.Worker != "" && claims.Worker != message.Name {
+		return fmt.Errorf("the token of worker %s is not for worker %s", claims.Worker, message.Name)
+	}
+	// the owner of the worker may not be sent to this node, e.g. the cloud worker of joint inference
+	if uid, ok := a.ownerUID(message.Namespace, message.OwnerName, message.OwnerKind); ok && uid != claims.UID {
+		return fmt.Errorf("the token is of the deleted %s %s/%s", claims.Kind, claims.Namespace, claims.Name)
+	}
+	return nil
+}
@@ -17,15 +17,19 @@ limitations under the License.
 package server
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/http"
+	"sort"
 
 	"github.com/emicklei/go-restful/v3"
 	"k8s.io/klog/v2"
 
 	"github.com/kubeedge/sedna/cmd/sedna-lc/app/options"
 	"github.com/kubeedge/sedna/pkg/localcontroller/common/constants"
+	"github.com/kubeedge/sedna/pkg/localcontroller/gmclient"
 	"github.com/kubeedge/sedna/pkg/localcontroller/managers"
+	"github.com/kubeedge/sedna/pkg/localcontroller/util"
 	workertypes "github.com/kubeedge/sedna/pkg/localcontroller/worker"
 )
 
@@ -34,6 +38,13 @@ type Server struct {
 	Port     string
 	Resource *Resource
 	fmm      featureManagerMap
+	client   gmclient.ClientI
+	auth     *workerAuthenticator
+	workers  *workerRegistry
+
+	// certFile and keyFile serve the port in TLS if set
+	certFile string
+	keyFile  string
 }
 
 // Resource defines resource
@@ -47,21 +58,42 @@ type ResponseMessage struct {
 	Message string
 }
 
+// Feature defines the feature manager listed by the API
+type Feature struct {
+	Name string `json:"name"`
+	// Inspectable is true if the state of the feature manager can be inspected
+	Inspectable bool `json:"inspectable"`
+}
+
 type featureManagerMap map[string]managers.FeatureManager
 
 // New creates a new LC server
-func New(options *options.LocalControllerOptions) *Server {
+func New(options *options.LocalControllerOptions, client gmclient.ClientI) *Server {
 	s := Server{
-		Port: options.BindPort,
+		Port:     options.BindPort,
+		client:   client,
+		auth:     newWorkerAuthenticator(util.AddPrefixPath(options.VolumeMountPrefix, constants.WorkerTokenKeyFile)),
+		workers:  newWorkerRegistry(),
+		certFile: options.BindCertFile,
+		keyFile:  options.BindKeyFile,
 	}
 
 	s.fmm = featureManagerMap{}
 
+	if client != nil {
+		// the key is told by global manager when connected
+		client.OnWorkerTokenKey(s.auth.SetKey)
+	}
+
 	return &s
 }
 
 func (s *Server) AddFeatureManager(m managers.FeatureManager) {
 	s.fmm[m.GetName()] = m
+
+	if u, ok := m.(managers.WorkerChannelUser); ok {
+		u.SetWorkerChannel(s.workers)
+	}
 }
 
 // register registers api
@@ -74,6 +106,26 @@ func (s *Server) register(container *restful.Container) {
 	ws.Route(ws.POST("/workers/{worker-name}/info").
 		To(s.messageHandler).
 		Doc("receive worker message"))
+	ws.Route(ws.GET("/workers/{worker-name}/stream").
+		To(s.streamHandler).
+		Doc("serve the stream of the worker messages and the LC commands"))
+
+	// the read-only api inspecting the state of LC
+	ws.Route(ws.GET("/features").
+		To(s.listFeatures).
+		Doc("list the feature managers"))
+	ws.Route(ws.GET("/features/{feature-name}/resources").
+		To(s.listResources).
+		Doc("list the states of the resources of the feature manager"))
+	ws.Route(ws.GET("/features/{feature-name}/resources/{namespace}/{name}").
+		To(s.getResource).
+		Doc("get the state of the resource of the feature manager"))
+	ws.Route(ws.GET("/workers").
+		To(s.listWorkerStreams).
+		Doc("list the workers connected by the streams"))
+	ws.Route(ws.GET("/connection").
+		To(s.getConnection).
+		Doc("get the state of the connection to global manager"))
 	container.Add(ws)
 }
 
@@ -117,10 +169,19 @@ func (s *Server) messageHandler(request *restful.Request, response *restful.Resp
 		return
 	}
 
-	if m, ok := s.fmm[workerMessage.OwnerKind]; ok {
-		m.AddWorkerMessage(workerMessage)
+	if err = s.auth.Authenticate(request, &workerMessage); err != nil {
+		msg := fmt.Sprintf("failed to authenticate worker(name=%s) message, error: %v", workerName, err)
+		klog.Errorf(msg)
+		err = s.reply(response, http.StatusUnauthorized, msg)
+		if err != nil {
+			klog.Errorf("reply message to worker(name=%s) failed, error: %v", workerName, err)
+		}
+
+		return
 	}
 
+	s.dispatch(workerMessage)
+
 	err = s.reply(response, http.StatusOK, "OK")
 	if err != nil {
 		klog.Errorf("reply message to worker(name=%s) failed, error: %v", workerName, err)
@@ -128,6 +189,98 @@ func (s *Server) messageHandler(request *restful.Request, response *restful.Resp
 	}
 }
 
+// dispatch dispatches the worker message to the feature manager of its owner
+func (s *Server) dispatch(message workertypes.MessageContent) {
+	if m, ok := s.fmm[message.OwnerKind]; ok {
+		m.AddWorkerMessage(message)
+	}
+}
+
+// getInspector gets the inspector of the feature manager, and replies the error if not found
+func (s *Server) getInspector(request *restful.Request, response *restful.Response) (managers.Inspector, bool) {
+	name := request.PathParameter("feature-name")
+	m, ok := s.fmm[name]
+	if !ok {
+		_ = s.reply(response, http.StatusNotFound, fmt.Sprintf("feature(name=%s) not found", name))
+		return nil, false
+	}
+
+	inspector, ok := m.(managers.Inspector)
+	if !ok {
+		_ = s.reply(response, http.StatusNotFound, fmt.Sprintf("feature(name=%s) can't be inspected", name))
+		return nil, false
+	}
+	return inspector, true
+}
+
+// writeEntity writes the entity in JSON
+func (s *Server) writeEntity(response *restful.Response, entity interface{}) {
+	if err := response.WriteHeaderAndJson(http.StatusOK, entity, restful.MIME_JSON); err != nil {
+		klog.Errorf("the value could not be written on the response, error: %v", err)
+	}
+}
+
+// listFeatures lists the feature managers
+func (s *Server) listFeatures(request *restful.Request, response *restful.Response) {
+	features := make([]Feature, 0, len(s.fmm))
+	for name, m := range s.fmm {
+		_, inspectable := m.(managers.Inspector)
+		features = append(features, Feature{Name: name, Inspectable: inspectable})
+	}
+	sort.Slice(features, func(i, j int) bool {
+		return features[i].Name < features[j].Name
+	})
+
+	s.writeEntity(response, features)
+}
+
+// listResources lists the states of the resources of the feature manager
+func (s *Server) listResources(request *restful.Request, response *restful.Response) {
+	inspector, ok := s.getInspector(request, response)
+	if !ok {
+		return
+	}
+
+	states := inspector.ListStates()
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Namespace != states[j].Namespace {
+			return states[i].Namespace < states[j].Namespace
+		}
+		return states[i].Name < states[j].Name
+	})
+
+	s.writeEntity(response, states)
+}
+
+// getResource gets the state of the resource of the feature manager
+func (s *Server) getResource(request *restful.Request, response *restful.Response) {
+	inspector, ok := s.getInspector(request, response)
+	if !ok {
+		return
+	}
+
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+	state, ok := inspector.GetState(namespace, name)
+	if !ok {
+		_ = s.reply(response, http.StatusNotFound, fmt.Sprintf("resource(name=%s/%s) of feature(name=%s) not found",
+			namespace, name, request.PathParameter("feature-name")))
+		return
+	}
+
+	s.writeEntity(response, state)
+}
+
+// getConnection gets the state of the connection to global manager
+func (s *Server) getConnection(request *restful.Request, response *restful.Response) {
+	if s.client == nil {
+		_ = s.reply(response, http.StatusNotFound, "no client of global manager")
+		return
+	}
+
+	s.writeEntity(response, s.client.Status())
+}
+
 // ListenAndServe starts server
 func (s *Server) ListenAndServe() {
 	wsContainer := restful.NewContainer()
@@ -137,6 +290,15 @@ func (s *Server) ListenAndServe() {
 
 	server := &http.Server{Addr: fmt.Sprintf(":%s", s.Port), Handler: wsContainer}
 
-	klog.Infof("server binds port %s successfully", s.Port)
-	klog.Fatal(server.ListenAndServe())
+	if s.certFile == "" && s.keyFile == "" {
+		klog.Infof("server binds port %s successfully", s.Port)
+		klog.Fatal(server.ListenAndServe())
+	}
+
+	if s.certFile == "" || s.keyFile == "" {
+		klog.Fatalf("both the cert file and the key file are required to serve TLS")
+	}
+	server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	klog.Infof("server binds port %s in TLS successfully", s.Port)
+	klog.Fatal(server.ListenAndServeTLS(s.certFile, s.keyFile))
 }
@@ -0,0 +1,365 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/gorilla/websocket"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeedge/sedna/cmd/sedna-lc/app/options"
+	sednav1 "github.com/kubeedge/sedna/pkg/apis/sedna/v1alpha1"
+	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
+	"github.com/kubeedge/sedna/pkg/localcontroller/gmclient"
+	"github.com/kubeedge/sedna/pkg/localcontroller/managers"
+	workertypes "github.com/kubeedge/sedna/pkg/localcontroller/worker"
+)
+
+type fakeManager struct {
+	name   string
+	states []managers.ResourceState
+}
+
+func (m *fakeManager) Start() error                                { return nil }
+func (m *fakeManager) GetName() string                             { return m.name }
+func (m *fakeManager) AddWorkerMessage(workertypes.MessageContent) {}
+func (m *fakeManager) Insert(*gmclient.Message) error              { return nil }
+func (m *fakeManager) Update(*gmclient.Message) error              { return nil }
+func (m *fakeManager) Delete(*gmclient.Message) error              { return nil }
+
+// recordingManager is a fake manager recording the worker messages
+type recordingManager struct {
+	fakeManager
+	messages chan workertypes.MessageContent
+}
+
+func (m *recordingManager) AddWorkerMessage(message workertypes.MessageContent) {
+	m.messages <- message
+}
+
+// inspectableManager is a fake manager whose state can be inspected
+type inspectableManager struct {
+	fakeManager
+}
+
+func (m *inspectableManager) ListStates() []managers.ResourceState {
+	return m.states
+}
+
+func (m *inspectableManager) GetState(namespace, name string) (managers.ResourceState, bool) {
+	for _, state := range m.states {
+		if state.Namespace == namespace && state.Name == name {
+			return state, true
+		}
+	}
+	return managers.ResourceState{}, false
+}
+
+type fakeClient struct {
+	gmclient.ClientI
+}
+
+func (fakeClient) OnWorkerTokenKey(func(key []byte)) {}
+
+func (fakeClient) Status() gmclient.ConnectionStatus {
+	return gmclient.ConnectionStatus{Address: "gm:9000", Transport: "websocket", Connected: true}
+}
+
+func TestInspectionAPI(t *testing.T) {
+	s := New(&options.LocalControllerOptions{}, fakeClient{})
+	s.AddFeatureManager(&fakeManager{name: "jointinferenceservice"})
+	s.AddFeatureManager(&inspectableManager{fakeManager{
+		name: "dataset",
+		states: []managers.ResourceState{
+			{Namespace: "default", Name: "b", State: map[string]int{"numberOfSamples": 2}},
+			{Namespace: "default", Name: "a", State: map[string]int{"numberOfSamples": 1}},
+		},
+	}})
+
+	container := restful.NewContainer()
+	s.register(container)
+	server := httptest.NewServer(container)
+	defer server.Close()
+
+	get := func(path string, code int, v interface{}) {
+		t.Helper()
+		resp, err := http.Get(server.URL + "/sedna" + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != code {
+			t.Fatalf("GET %s: expected status %d, got %d", path, code, resp.StatusCode)
+		}
+		if v != nil {
+			if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+				t.Fatalf("GET %s: %v", path, err)
+			}
+		}
+	}
+
+	var features []Feature
+	get("/features", http.StatusOK, &features)
+	if len(features) != 2 || features[0] != (Feature{Name: "dataset", Inspectable: true}) ||
+		features[1] != (Feature{Name: "jointinferenceservice"}) {
+		t.Errorf("unexpected features %+v", features)
+	}
+
+	var states []managers.ResourceState
+	get("/features/dataset/resources", http.StatusOK, &states)
+	if len(states) != 2 || states[0].Name != "a" || states[1].Name != "b" {
+		t.Errorf("expected the states sorted by name, got %+v", states)
+	}
+
+	var state struct {
+		Name  string `json:"name"`
+		State struct {
+			NumberOfSamples int `json:"numberOfSamples"`
+		} `json:"state"`
+	}
+	get("/features/dataset/resources/default/b", http.StatusOK, &state)
+	if state.Name != "b" || state.State.NumberOfSamples != 2 {
+		t.Errorf("unexpected state %+v", state)
+	}
+
+	get("/features/dataset/resources/default/c", http.StatusNotFound, nil)
+	get("/features/jointinferenceservice/resources", http.StatusNotFound, nil)
+	get("/features/unknown/resources", http.StatusNotFound, nil)
+
+	var status gmclient.ConnectionStatus
+	get("/connection", http.StatusOK, &status)
+	if !status.Connected || status.Address != "gm:9000" {
+		t.Errorf("unexpected connection status %+v", status)
+	}
+}
+
+func TestWorkerAuthentication(t *testing.T) {
+	s := New(&options.LocalControllerOptions{VolumeMountPrefix: t.TempDir()}, fakeClient{})
+	s.auth.keyFile = filepath.Join(t.TempDir(), "worker-token.pub")
+	uids := map[string]string{"default/incrementallearningjob/job": "uid"}
+	s.auth.ownerUID = func(namespace, name, kind string) (string, bool) {
+		uid, ok := uids[namespace+"/"+kind+"/"+name]
+		return uid, ok
+	}
+
+	container := restful.NewContainer()
+	s.register(container)
+	server := httptest.NewServer(container)
+	defer server.Close()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := runtime.NewWorkerTokenIssuer(key, time.Hour)
+	job := &sednav1.IncrementalLearningJob{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "job", UID: "uid"}}
+	job.SetGroupVersionKind(sednav1.SchemeGroupVersion.WithKind("IncrementalLearningJob"))
+	token, err := issuer.Issue(job, "job-train")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(token, worker, owner string) int {
+		t.Helper()
+		body, _ := json.Marshal(workertypes.MessageContent{
+			Name: worker, Namespace: "default", OwnerName: owner, OwnerKind: "incrementallearningjob",
+		})
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/sedna/workers/"+worker+"/info", bytes.NewReader(body))
+		req.Header.Set("Content-Type", restful.MIME_JSON)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// not authenticated until the key is told by GM
+	if code := post("", "job-train", "job"); code != http.StatusOK {
+		t.Errorf("expected the message accepted without the key, got %d", code)
+	}
+
+	s.auth.SetKey(key.Public().(ed25519.PublicKey))
+	for _, c := range []struct {
+		name   string
+		token  string
+		worker string
+		owner  string
+		code   int
+	}{
+		{"valid", token, "job-train", "job", http.StatusOK},
+		{"missing", "", "job-train", "job", http.StatusUnauthorized},
+		{"malformed", "token", "job-train", "job", http.StatusUnauthorized},
+		{"other worker", token, "job-eval", "job", http.StatusUnauthorized},
+		{"other owner", token, "job-train", "other", http.StatusUnauthorized},
+	} {
+		if code := post(c.token, c.worker, c.owner); code != c.code {
+			t.Errorf("%s token: expected status %d, got %d", c.name, c.code, code)
+		}
+	}
+
+	// the owner is recreated with the same name
+	uids["default/incrementallearningjob/job"] = "new-uid"
+	if code := post(token, "job-train", "job"); code != http.StatusUnauthorized {
+		t.Errorf("expected the token of the deleted owner rejected, got %d", code)
+	}
+	delete(uids, "default/incrementallearningjob/job")
+
+	s.auth.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if code := post(token, "job-train", "job"); code != http.StatusUnauthorized {
+		t.Errorf("expected the expired token rejected, got %d", code)
+	}
+	s.auth.now = time.Now
+
+	// the key is kept after restarts
+	if a := newWorkerAuthenticator(s.auth.keyFile); !a.getKey().Equal(key.Public()) {
+		t.Errorf("expected the key loaded from %s", s.auth.keyFile)
+	}
+}
+
+func TestWorkerStream(t *testing.T) {
+	s := New(&options.LocalControllerOptions{VolumeMountPrefix: t.TempDir()}, fakeClient{})
+	m := &recordingManager{
+		fakeManager: fakeManager{name: "incrementallearningjob"},
+		messages:    make(chan workertypes.MessageContent, 1),
+	}
+	s.AddFeatureManager(m)
+
+	container := restful.NewContainer()
+	s.register(container)
+	server := httptest.NewServer(container)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") +
+		"/sedna/workers/job-deploy/stream?namespace=default&ownerKind=incrementallearningjob&ownerName=job"
+	dial := func() *websocket.Conn {
+		t.Helper()
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conn
+	}
+	read := func(conn *websocket.Conn) workertypes.StreamFrame {
+		t.Helper()
+		var frame workertypes.StreamFrame
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if err := conn.ReadJSON(&frame); err != nil {
+			t.Fatal(err)
+		}
+		return frame
+	}
+	waitWorkers := func(want string) {
+		t.Helper()
+		for i := 0; i < 100; i++ {
+			if got := strings.Join(s.workers.ListWorkers("default", "incrementallearningjob", "job"), ","); got == want {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("expected the connected workers %q", want)
+	}
+
+	conn := dial()
+	waitWorkers("job-deploy")
+
+	// the worker messages are dispatched and acknowledged
+	message := workertypes.MessageContent{
+		Name: "job-deploy", Namespace: "default", OwnerName: "job", OwnerKind: "incrementallearningjob", Kind: "deploy",
+	}
+	if err := conn.WriteJSON(workertypes.StreamFrame{Type: workertypes.MessageFrame, ID: 1, Message: &message}); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-m.messages; got.Kind != "deploy" {
+		t.Errorf("unexpected message %+v", got)
+	}
+	if ack := read(conn); ack.Type != workertypes.AckFrame || ack.ID != 1 || ack.Error != "" {
+		t.Errorf("unexpected ack %+v", ack)
+	}
+
+	other := message
+	other.OwnerName = "other"
+	_ = conn.WriteJSON(workertypes.StreamFrame{Type: workertypes.MessageFrame, ID: 2, Message: &other})
+	if ack := read(conn); ack.ID != 2 || ack.Error == "" {
+		t.Errorf("expected the message of the other owner rejected, got %+v", ack)
+	}
+
+	// the unacknowledged command is sent again when the worker reconnects
+	command := workertypes.Command{Name: workertypes.UpdateModelCommand}
+	if n := s.workers.Broadcast("default", "incrementallearningjob", "job", command); n != 1 {
+		t.Fatalf("expected the command broadcast to 1 worker, got %d", n)
+	}
+	if frame := read(conn); frame.Type != workertypes.CommandFrame || frame.Command.Name != command.Name {
+		t.Fatalf("unexpected frame %+v", frame)
+	}
+	conn.Close()
+	waitWorkers("")
+
+	conn = dial()
+	defer conn.Close()
+	frame := read(conn)
+	if frame.Type != workertypes.CommandFrame || frame.Command.Name != command.Name {
+		t.Fatalf("expected the command sent again, got %+v", frame)
+	}
+	_ = conn.WriteJSON(workertypes.StreamFrame{Type: workertypes.AckFrame, ID: frame.ID})
+	for i := 0; i < 100 && s.workers.states()[0].PendingCommands != 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	s.workers.SendCommand("default", "incrementallearningjob", "job", "job-deploy", workertypes.Command{Name: workertypes.StopCommand})
+	if frame := read(conn); frame.Command == nil || frame.Command.Name != workertypes.StopCommand {
+		t.Fatalf("expected the stop command, got %+v", frame)
+	}
+
+	var states []WorkerStreamState
+	resp, err := http.Get(server.URL + "/sedna/workers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&states); err != nil {
+		t.Fatal(err)
+	}
+	// the acknowledged command isn't pending
+	if len(states) != 1 || !states[0].Connected || states[0].PendingCommands != 1 {
+		t.Errorf("unexpected worker states %+v", states)
+	}
+
+	// the owner is required
+	resp, err = http.Get(server.URL + "/sedna/workers/job-deploy/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d without the owner, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
@@ -0,0 +1,438 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/gorilla/websocket"
+	"k8s.io/klog/v2"
+
+	workertypes "github.com/kubeedge/sedna/pkg/localcontroller/worker"
+)
+
+const (
+	// streamPingPeriod is the period of the pings to the workers
+	streamPingPeriod = 30 * time.Second
+	// streamPongWait is how long a worker is disconnected without any frame received
+	streamPongWait = 2 * streamPingPeriod
+	// streamWriteWait is the timeout of writing a frame
+	streamWriteWait = 10 * time.Second
+
+	// maxPendingCommands is the max number of the unacknowledged commands of a worker
+	maxPendingCommands = 100
+	// streamExpiry is how long a disconnected worker is kept with its pending commands
+	streamExpiry = 10 * time.Minute
+)
+
+// workerKey identifies the worker of an owner
+type workerKey struct {
+	namespace string
+	ownerKind string
+	ownerName string
+	name      string
+}
+
+// workerStream is the stream of a worker, which is kept across the reconnections
+// so the unacknowledged commands are sent again
+type workerStream struct {
+	key workerKey
+
+	// conn is the current connection, nil if disconnected
+	conn *websocket.Conn
+	// session increments on each connection, so a replaced connection doesn't touch the current one
+	session uint64
+	// wake is signaled when a command is queued for the current connection
+	wake chan struct{}
+	// since is when the worker is connected or disconnected
+	since time.Time
+
+	nextID  uint64
+	pending []workertypes.StreamFrame
+	// sentID is the ID of the last command sent in the current connection
+	sentID uint64
+}
+
+// WorkerStreamState defines the state of the stream of a worker listed by the API
+type WorkerStreamState struct {
+	Namespace string    `json:"namespace"`
+	OwnerKind string    `json:"ownerKind"`
+	OwnerName string    `json:"ownerName"`
+	Name      string    `json:"name"`
+	Connected bool      `json:"connected"`
+	Since     time.Time `json:"since"`
+	// PendingCommands is the number of the unacknowledged commands
+	PendingCommands int `json:"pendingCommands"`
+}
+
+// workerRegistry is the registry of the worker streams, which implements managers.WorkerChannel
+type workerRegistry struct {
+	lock    sync.Mutex
+	workers map[workerKey]*workerStream
+	now     func() time.Time
+}
+
+func newWorkerRegistry() *workerRegistry {
+	return &workerRegistry{
+		workers: make(map[workerKey]*workerStream),
+		now:     time.Now,
+	}
+}
+
+// expire removes the workers disconnected for long, which must be called with the lock held
+func (r *workerRegistry) expire() {
+	now := r.now()
+	for key, w := range r.workers {
+		if w.conn == nil && now.Sub(w.since) > streamExpiry {
+			if len(w.pending) > 0 {
+				klog.Warningf("dropped %d commands of the worker(name=%s) of %s %s/%s disconnected since %s",
+					len(w.pending), key.name, key.ownerKind, key.namespace, key.ownerName, w.since.Format(time.RFC3339))
+			}
+			delete(r.workers, key)
+		}
+	}
+}
+
+// getWorker gets the worker, which is created if not existing, and must be called with the lock held
+func (r *workerRegistry) getWorker(key workerKey) *workerStream {
+	w, ok := r.workers[key]
+	if !ok {
+		w = &workerStream{key: key, since: r.now()}
+		r.workers[key] = w
+	}
+	return w
+}
+
+// connect sets the current connection of the worker, the previous one is closed
+func (r *workerRegistry) connect(key workerKey, conn *websocket.Conn) (*workerStream, uint64, chan struct{}) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.expire()
+	w := r.getWorker(key)
+	if w.conn != nil {
+		klog.Infof("the stream of worker(name=%s) is replaced by the new connection", key.name)
+		_ = w.conn.Close()
+	}
+	w.conn = conn
+	w.session++
+	w.wake = make(chan struct{}, 1)
+	w.since = r.now()
+	// the unacknowledged commands are sent again
+	w.sentID = 0
+	return w, w.session, w.wake
+}
+
+// disconnect clears the connection of the worker if it's still the current one
+func (r *workerRegistry) disconnect(w *workerStream, session uint64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if w.session != session {
+		return
+	}
+	w.conn = nil
+	w.wake = nil
+	w.since = r.now()
+	if len(w.pending) == 0 {
+		delete(r.workers, w.key)
+	}
+}
+
+// unsent returns the commands not sent in the session, false if the session is replaced
+func (r *workerRegistry) unsent(w *workerStream, session uint64) ([]workertypes.StreamFrame, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if w.session != session {
+		return nil, false
+	}
+	var frames []workertypes.StreamFrame
+	for _, f := range w.pending {
+		if f.ID > w.sentID {
+			frames = append(frames, f)
+		}
+	}
+	if len(frames) > 0 {
+		w.sentID = frames[len(frames)-1].ID
+	}
+	return frames, true
+}
+
+// ack removes the command acknowledged by the worker
+func (r *workerRegistry) ack(w *workerStream, id uint64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i, f := range w.pending {
+		if f.ID == id {
+			w.pending = append(w.pending[:i], w.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// enqueue queues the command of the worker, which must be called with the lock held
+func (r *workerRegistry) enqueue(w *workerStream, command workertypes.Command) {
+	if len(w.pending) >= maxPendingCommands {
+		klog.Warningf("dropped the oldest command %s of the worker(name=%s) since it has %d commands pending",
+			w.pending[0].Command.Name, w.key.name, len(w.pending))
+		w.pending = w.pending[1:]
+	}
+
+	w.nextID++
+	w.pending = append(w.pending, workertypes.StreamFrame{
+		Type:    workertypes.CommandFrame,
+		ID:      w.nextID,
+		Command: &command,
+	})
+	if w.wake != nil {
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// SendCommand queues the command of the worker of the owner
+func (r *workerRegistry) SendCommand(namespace, ownerKind, ownerName, workerName string, command workertypes.Command) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.expire()
+	r.enqueue(r.getWorker(workerKey{namespace, ownerKind, ownerName, workerName}), command)
+}
+
+// Broadcast queues the command of the workers of the owner
+func (r *workerRegistry) Broadcast(namespace, ownerKind, ownerName string, command workertypes.Command) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.expire()
+	count := 0
+	for key, w := range r.workers {
+		if key.namespace == namespace && key.ownerKind == ownerKind && key.ownerName == ownerName {
+			r.enqueue(w, command)
+			count++
+		}
+	}
+	return count
+}
+
+// ListWorkers returns the names of the connected workers of the owner
+func (r *workerRegistry) ListWorkers(namespace, ownerKind, ownerName string) []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var names []string
+	for key, w := range r.workers {
+		if key.namespace == namespace && key.ownerKind == ownerKind && key.ownerName == ownerName && w.conn != nil {
+			names = append(names, key.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// states returns the states of the worker streams, sorted by the owner and the name
+func (r *workerRegistry) states() []WorkerStreamState {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.expire()
+	states := make([]WorkerStreamState, 0, len(r.workers))
+	for key, w := range r.workers {
+		states = append(states, WorkerStreamState{
+			Namespace:       key.namespace,
+			OwnerKind:       key.ownerKind,
+			OwnerName:       key.ownerName,
+			Name:            key.name,
+			Connected:       w.conn != nil,
+			Since:           w.since,
+			PendingCommands: len(w.pending),
+		})
+	}
+	sort.Slice(states, func(i, j int) bool {
+		a, b := states[i], states[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.OwnerKind != b.OwnerKind {
+			return a.OwnerKind < b.OwnerKind
+		}
+		if a.OwnerName != b.OwnerName {
+			return a.OwnerName < b.OwnerName
+		}
+		return a.Name < b.Name
+	})
+	return states
+}
+
+var streamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// streamHandler serves the stream of the worker
+func (s *Server) streamHandler(request *restful.Request, response *restful.Response) {
+	key := workerKey{
+		namespace: request.QueryParameter("namespace"),
+		ownerKind: request.QueryParameter("ownerKind"),
+		ownerName: request.QueryParameter("ownerName"),
+		name:      request.PathParameter("worker-name"),
+	}
+	if key.namespace == "" || key.ownerKind == "" || key.ownerName == "" {
+		msg := fmt.Sprintf("the owner of worker(name=%s) is required by the namespace, ownerKind and ownerName parameters", key.name)
+		klog.Errorf(msg)
+		_ = s.reply(response, http.StatusBadRequest, msg)
+		return
+	}
+
+	if err := s.auth.Authenticate(request, &workertypes.MessageContent{
+		Name:      key.name,
+		Namespace: key.namespace,
+		OwnerKind: key.ownerKind,
+		OwnerName: key.ownerName,
+	}); err != nil {
+		msg := fmt.Sprintf("failed to authenticate worker(name=%s) stream, error: %v", key.name, err)
+		klog.Errorf(msg)
+		_ = s.reply(response, http.StatusUnauthorized, msg)
+		return
+	}
+
+	conn, err := streamUpgrader.Upgrade(response.ResponseWriter, request.Request, nil)
+	if err != nil {
+		klog.Errorf("failed to upgrade the stream of worker(name=%s), error: %v", key.name, err)
+		return
+	}
+
+	w, session, wake := s.workers.connect(key, conn)
+	klog.Infof("worker(name=%s) of %s %s/%s is connected by the stream", key.name, key.ownerKind, key.namespace, key.ownerName)
+
+	acks := make(chan workertypes.StreamFrame, workertypes.MessageChannelCacheSize)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		s.writeStream(conn, w, session, wake, acks, done)
+	}()
+
+	err = s.readStream(conn, w, key, acks, stopped)
+	close(done)
+	_ = conn.Close()
+	s.workers.disconnect(w, session)
+	klog.Infof("worker(name=%s) of %s %s/%s is disconnected: %v", key.name, key.ownerKind, key.namespace, key.ownerName, err)
+}
+
+// readStream reads the frames of the worker until the connection fails
+func (s *Server) readStream(conn *websocket.Conn, w *workerStream, key workerKey,
+	acks chan<- workertypes.StreamFrame, stopped <-chan struct{}) error {
+	_ = conn.SetReadDeadline(time.Now().Add(streamPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(streamPongWait))
+	})
+
+	for {
+		var frame workertypes.StreamFrame
+		if err := conn.ReadJSON(&frame); err != nil {
+			return err
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(streamPongWait))
+
+		switch frame.Type {
+		case workertypes.AckFrame:
+			s.workers.ack(w, frame.ID)
+		case workertypes.MessageFrame:
+			ack := workertypes.StreamFrame{Type: workertypes.AckFrame, ID: frame.ID}
+			message := frame.Message
+			if message == nil {
+				ack.Error = "no message in the frame"
+			} else if message.Name != key.name || message.Namespace != key.namespace ||
+				message.OwnerKind != key.ownerKind || message.OwnerName != key.ownerName {
+				ack.Error = fmt.Sprintf("the message of worker(name=%s) of %s %s/%s is not for the stream",
+					message.Name, message.OwnerKind, message.Namespace, message.OwnerName)
+			} else {
+				s.dispatch(*message)
+			}
+			if ack.Error != "" {
+				klog.Errorf("rejected the stream message of worker(name=%s): %s", key.name, ack.Error)
+			}
+			select {
+			case acks <- ack:
+			case <-stopped:
+				return fmt.Errorf("the stream writer is stopped")
+			}
+		default:
+			klog.Warningf("ignored the stream frame of type %q from worker(name=%s)", frame.Type, key.name)
+		}
+	}
+}
+
+// writeStream writes the commands and the acks to the worker until the connection is closed
+func (s *Server) writeStream(conn *websocket.Conn, w *workerStream, session uint64, wake <-chan struct{},
+	acks <-chan workertypes.StreamFrame, done <-chan struct{}) {
+	ticker := time.NewTicker(streamPingPeriod)
+	defer ticker.Stop()
+
+	write := func(frame workertypes.StreamFrame) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+		if err := conn.WriteJSON(frame); err != nil {
+			klog.Errorf("failed to write the stream frame to worker(name=%s), error: %v", w.key.name, err)
+			_ = conn.Close()
+			return false
+		}
+		return true
+	}
+
+	for {
+		frames, ok := s.workers.unsent(w, session)
+		if !ok {
+			return
+		}
+		for _, frame := range frames {
+			if !write(frame) {
+				return
+			}
+		}
+
+		select {
+		case <-wake:
+		case ack := <-acks:
+			if !write(ack) {
+				return
+			}
+		case <-ticker.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				_ = conn.Close()
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// listWorkerStreams lists the states of the worker streams
+func (s *Server) listWorkerStreams(request *restful.Request, response *restful.Response) {
+	s.writeEntity(response, s.workers.states())
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Backend defines the storage service of the url scheme
+type Backend interface {
+	// Download downloads the object to the local path
+	Download(objectURL string, localPath string) error
+	// Upload uploads the local file to the object url
+	Upload(localPath string, objectURL string) error
+	// Copy copies the object to another object in the same storage service
+	Copy(srcURL string, objectURL string) error
+}
+
+// Versioner is implemented by the backend which knows the version (e.g. etag) of the object,
+// the version is used to find the cached file of the object without downloading.
+type Versioner interface {
+	Version(objectURL string) (string, error)
+}
+
+// RangeReader is implemented by the backend which can read the object from an offset,
+// so the content appended to the object can be read without downloading the whole object.
+type RangeReader interface {
+	// OpenRange opens the object from the offset, which reads nothing if the offset is the size of
+	// the object, and returns ErrInvalidRange if the offset is beyond the size.
+	OpenRange(objectURL string, offset int64) (io.ReadCloser, error)
+}
+
+// ErrInvalidRange is returned when the offset is beyond the size of the object
+var ErrInvalidRange = errors.New("offset is beyond the size of the object")
+
+// BackendFactory creates the backend with the credential,
+// which parses the keys it needs from the credential.
+type BackendFactory func(credential map[string]string) (Backend, error)
+
+var (
+	backendsLock sync.RWMutex
+	backends     = make(map[string]BackendFactory)
+)
+
+// RegisterBackend registers the backend factory of the url schemes
+func RegisterBackend(factory BackendFactory, schemes ...string) {
+	backendsLock.Lock()
+	defer backendsLock.Unlock()
+
+	for _, scheme := range schemes {
+		backends[scheme] = factory
+	}
+}
+
+// getBackendFactory gets the backend factory of the url scheme
+func getBackendFactory(scheme string) (BackendFactory, bool) {
+	backendsLock.RLock()
+	defer backendsLock.RUnlock()
+
+	f, ok := backends[scheme]
+	return f, ok
+}
+
+// SupportedSchemes returns the url schemes of all registered backends
+func SupportedSchemes() []string {
+	backendsLock.RLock()
+	defer backendsLock.RUnlock()
+
+	var schemes []string
+	for scheme := range backends {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// errReadOnly returns the error that the backend does not support writing
+func errReadOnly(scheme string, operation string) error {
+	return fmt.Errorf("%s is not supported by the read-only storage of scheme(%s)", operation, scheme)
+}
+
+func init() {
+	RegisterBackend(newLocalBackend, LocalPrefix)
+	RegisterBackend(newS3Backend, S3Prefix)
+	RegisterBackend(newHTTPBackend, HTTPPrefix, HTTPSPrefix)
+	RegisterBackend(newWebDAVBackend, WebDAVPrefix, WebDAVSPrefix)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
+)
+
+// fakeBackend records the credential it's created with
+type fakeBackend struct {
+	credential map[string]string
+}
+
+func (b *fakeBackend) Download(objectURL string, localPath string) error { return nil }
+func (b *fakeBackend) Upload(localPath string, objectURL string) error   { return nil }
+func (b *fakeBackend) Copy(srcURL string, objectURL string) error        { return nil }
+
+// registerFakeBackend registers the fake backend of the scheme until the test ends
+func registerFakeBackend(t *testing.T, scheme string, factory BackendFactory) {
+	RegisterBackend(factory, scheme)
+	t.Cleanup(func() {
+		backendsLock.Lock()
+		defer backendsLock.Unlock()
+		delete(backends, scheme)
+	})
+}
+
+func TestBackendRegistry(t *testing.T) {
+	created := 0
+	registerFakeBackend(t, "fake", func(credential map[string]string) (Backend, error) {
+		created++
+		return &fakeBackend{credential: credential}, nil
+	})
+
+	schemes := SupportedSchemes()
+	if !sort.StringsAreSorted(schemes) {
+		t.Errorf("expected sorted schemes, got %v", schemes)
+	}
+	for _, scheme := range []string{LocalPrefix, S3Prefix, HTTPPrefix, HTTPSPrefix, WebDAVPrefix, WebDAVSPrefix, "fake"} {
+		if _, ok := getBackendFactory(scheme); !ok {
+			t.Errorf("expected scheme(%s) registered in %v", scheme, schemes)
+		}
+	}
+
+	s := &Storage{}
+	tests := []struct {
+		url      string
+		expected string
+		invalid  bool
+	}{
+		{url: "/data/index.txt", expected: LocalPrefix},
+		{url: "s3://bucket/index.txt", expected: S3Prefix},
+		{url: "webdavs://host/index.txt", expected: WebDAVSPrefix},
+		{url: "fake://host/index.txt", expected: "fake"},
+		{url: "ftp://host/index.txt", invalid: true},
+		{url: "", invalid: true},
+	}
+	for _, tt := range tests {
+		prefix, err := s.CheckURL(tt.url)
+		if tt.invalid != (err != nil) || prefix != tt.expected {
+			t.Errorf("url(%s): expected prefix %q invalid=%v, got %q, %v", tt.url, tt.expected, tt.invalid, prefix, err)
+		}
+	}
+
+	if isLocal, err := s.IsLocalURL("/data/index.txt"); err != nil || !isLocal {
+		t.Errorf("expected local url, got %v, %v", isLocal, err)
+	}
+
+	// the backend is created once, and created again with the new credential
+	b1, err := s.backend("fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2, _ := s.backend("fake")
+	if b1 != b2 || created != 1 {
+		t.Errorf("expected the backend created once, created %d", created)
+	}
+	if len(b1.(*fakeBackend).credential) != 0 {
+		t.Errorf("expected empty credential, got %v", b1.(*fakeBackend).credential)
+	}
+
+	if err := s.SetCredential(`{"token":"secret"}`); err != nil {
+		t.Fatal(err)
+	}
+	b3, _ := s.backend("fake")
+	if b3 == b1 || created != 2 || !reflect.DeepEqual(b3.(*fakeBackend).credential, map[string]string{"token": "secret"}) {
+		t.Errorf("expected the backend recreated with the new credential, got %+v", b3)
+	}
+
+	if _, err := s.backend("ftp"); err == nil {
+		t.Errorf("expected error of unknown scheme")
+	}
+}
+
+func TestBackendCredential(t *testing.T) {
+	failure := errors.New("invalid key")
+	registerFakeBackend(t, "failed", func(map[string]string) (Backend, error) {
+		return nil, failure
+	})
+
+	s := &Storage{}
+	if err := s.SetCredential(`{"token":`); err == nil {
+		t.Errorf("expected error of invalid credential")
+	}
+	if _, err := s.backend("failed"); !errors.Is(err, failure) {
+		t.Errorf("expected the error of the factory, got %v", err)
+	}
+
+	// the s3 backend requires the endpoint and the keys
+	if _, err := newS3Backend(map[string]string{runtime.S3EndpointKey: "s3.local"}); err == nil {
+		t.Errorf("expected error of s3 credential without keys")
+	}
+
+	b, err := newWebDAVBackend(map[string]string{
+		WebDAVUsernameKey:   "user",
+		WebDAVPasswordKey:   "password",
+		runtime.AccessKeyID: "ignored",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wb := b.(*webdavBackend); wb.username != "user" || wb.password != "password" {
+		t.Errorf("unexpected webdav credential %+v", wb)
+	}
+
+	// the webdav credential is optional
+	b, err = newWebDAVBackend(map[string]string{})
+	if err != nil || b.(*webdavBackend).username != "" {
+		t.Errorf("expected webdav backend without auth, got %+v, %v", b, err)
+	}
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubeedge/sedna/pkg/localcontroller/common/constants"
+	"github.com/kubeedge/sedna/pkg/localcontroller/util"
+)
+
+// DefaultCacheMaxSizeMB is the default max size in MB of the cached files on the node
+const DefaultCacheMaxSizeMB = 2048
+
+// Cache is the content-addressed cache of the downloaded files on the node,
+// the files are stored by the sha256 digest, so the same file is not downloaded twice.
+//
+// The layout of the dir:
+//
+//	sha256/<digest>  the cached files, which are read-only
+//	index/<key>      the digest of the versioned url, e.g. s3 url with its etag
+//	partial/<key>/   the partial files of the unfinished downloads
+//	transfer/        the states of the unfinished uploads
+type Cache struct {
+	Dir string
+	// MaxSize is the max size in bytes of the cached files, no limit if not positive
+	MaxSize int64
+
+	lock sync.Mutex
+	keys map[string]*sync.Mutex
+}
+
+var defaultCache = newDefaultCache()
+
+// newDefaultCache creates the cache in the rootfs of the host, like the database
+func newDefaultCache() *Cache {
+	prefix, ok := os.LookupEnv(constants.RootFSMountDirENV)
+	if !ok {
+		prefix = "/rootfs"
+	}
+
+	maxSizeMB := int64(DefaultCacheMaxSizeMB)
+	if v := os.Getenv(constants.CacheMaxSizeENV); v != "" {
+		size, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			klog.Errorf("invalid %s(%s), use the default %d", constants.CacheMaxSizeENV, v, maxSizeMB)
+		} else {
+			maxSizeMB = size
+		}
+	}
+
+	return NewCache(util.AddPrefixPath(prefix, constants.CacheDir), maxSizeMB*1024*1024)
+}
+
+// NewCache creates the cache in the dir
+func NewCache(dir string, maxSize int64) *Cache {
+	return &Cache{
+		Dir:     dir,
+		MaxSize: maxSize,
+		keys:    make(map[string]*sync.Mutex),
+	}
+}
+
+// hashKey converts the key (e.g. url) to the file name
+func hashKey(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
+
+// Lock locks the key, to avoid downloading the same url concurrently
+func (c *Cache) Lock(key string) func() {
+	c.lock.Lock()
+	l, ok := c.keys[key]
+	if !ok {
+		l = &sync.Mutex{}
+		c.keys[key] = l
+	}
+	c.lock.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+func (c *Cache) blobPath(digest string) string {
+	return filepath.Join(c.Dir, ChecksumSHA256, digest)
+}
+
+// PartialPath returns the stable path to download the url, so the download can be resumed
+func (c *Cache) PartialPath(key string, name string) string {
+	return filepath.Join(c.Dir, "partial", hashKey(key), name)
+}
+
+// TransferDir returns the dir of the states of the unfinished uploads
+func (c *Cache) TransferDir() string {
+	return filepath.Join(c.Dir, "transfer")
+}
+
+// Get links (or copies) the cached file of the digest to the local path,
+// and returns false if not cached.
+func (c *Cache) Get(digest string, localPath string) bool {
+	if digest == "" {
+		return false
+	}
+
+	blob := c.blobPath(digest)
+	if !util.IsExists(blob) {
+		return false
+	}
+
+	if err := linkOrCopy(blob, localPath); err != nil {
+		klog.Warningf("failed to get the cached file(digest=%s), error: %v", digest, err)
+		return false
+	}
+
+	// the modification time is used as the last access time when pruning
+	now := time.Now()
+	if err := os.Chtimes(blob, now, now); err != nil {
+		klog.V(4).Infof("failed to touch the cached file(digest=%s): %v", digest, err)
+	}
+
+	return true
+}
+
+// Put moves the verified file into the cache, and links it back to the path
+func (c *Cache) Put(path string, digest string) error {
+	blob := c.blobPath(digest)
+	if err := os.MkdirAll(filepath.Dir(blob), os.ModePerm); err != nil {
+		return err
+	}
+
+	if !util.IsExists(blob) {
+		if err := linkOrCopy(path, blob); err != nil {
+			return err
+		}
+		// the cached file is read-only, avoid to be modified by the linked path
+		if err := os.Chmod(blob, 0444); err != nil {
+			return err
+		}
+	}
+
+	c.prune()
+	return nil
+}
+
+// Resolve returns the digest of the key if remembered
+func (c *Cache) Resolve(key string) string {
+	data, err := ioutil.ReadFile(filepath.Join(c.Dir, "index", hashKey(key)))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// Remember remembers the digest of the key
+func (c *Cache) Remember(key string, digest string) error {
+	dir := filepath.Join(c.Dir, "index")
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, hashKey(key)), []byte(digest), 0644)
+}
+
+// prune removes the least recently used files until the cache is within the max size
+func (c *Cache) prune() {
+	if c.MaxSize <= 0 {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	infos, err := ioutil.ReadDir(filepath.Join(c.Dir, ChecksumSHA256))
+	if err != nil {
+		return
+	}
+
+	var total int64
+	for _, info := range infos {
+		total += info.Size()
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ModTime().Before(infos[j].ModTime())
+	})
+
+	for _, info := range infos {
+		if total <= c.MaxSize {
+			break
+		}
+		if err := os.Remove(c.blobPath(info.Name())); err != nil {
+			klog.Warningf("failed to remove the cached file(digest=%s): %v", info.Name(), err)
+			continue
+		}
+		total -= info.Size()
+		klog.V(2).Infof("removed the cached file(digest=%s, size=%d)", info.Name(), info.Size())
+	}
+}
+
+// linkOrCopy hard links the src to the dst, and copies if failed (e.g. cross devices)
+func linkOrCopy(src string, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	_ = os.Remove(dst)
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+
+	_, err := util.CopyFile(src, dst)
+	return err
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	// ChecksumSHA256 is the algorithm of sha256 checksum, e.g. "sha256:<hex>"
+	ChecksumSHA256 = "sha256"
+	// ChecksumMD5 is the algorithm of md5 checksum, e.g. "md5:<hex>"
+	ChecksumMD5 = "md5"
+)
+
+var hexPattern = regexp.MustCompile("^[0-9a-f]+$")
+
+// Checksum is the expected checksum of a file
+type Checksum struct {
+	Algorithm string
+	// Value is the lower-case hex digest
+	Value string
+}
+
+// ParseChecksum parses the checksum in format "<algorithm>:<hex>",
+// the algorithm can be omitted for sha256. It returns nil if the checksum is empty.
+func ParseChecksum(checksum string) (*Checksum, error) {
+	checksum = strings.TrimSpace(checksum)
+	if checksum == "" {
+		return nil, nil
+	}
+
+	c := &Checksum{Algorithm: ChecksumSHA256, Value: checksum}
+	if i := strings.Index(checksum, ":"); i != -1 {
+		c.Algorithm = strings.ToLower(checksum[:i])
+		c.Value = checksum[i+1:]
+	}
+	c.Value = strings.ToLower(c.Value)
+
+	var size int
+	switch c.Algorithm {
+	case ChecksumSHA256:
+		size = sha256.Size
+	case ChecksumMD5:
+		size = md5.Size
+	default:
+		return nil, fmt.Errorf("invalid checksum(%s), not support algorithm(%s), support algorithm: %+v",
+			checksum, c.Algorithm, []string{ChecksumSHA256, ChecksumMD5})
+	}
+
+	if len(c.Value) != 2*size || !hexPattern.MatchString(c.Value) {
+		return nil, fmt.Errorf("invalid checksum(%s), expected %d hex digits of %s", checksum, 2*size, c.Algorithm)
+	}
+
+	return c, nil
+}
+
+func (c *Checksum) String() string {
+	return c.Algorithm + ":" + c.Value
+}
+
+// digests is the digests of a file
+type digests struct {
+	SHA256 string
+	MD5    string
+}
+
+// fileDigests computes the sha256 and md5 digests of the file in one pass
+func fileDigests(path string) (*digests, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s := sha256.New()
+	m := md5.New()
+	if _, err := io.Copy(io.MultiWriter(s, m), f); err != nil {
+		return nil, err
+	}
+
+	return &digests{
+		SHA256: hex.EncodeToString(s.Sum(nil)),
+		MD5:    hex.EncodeToString(m.Sum(nil)),
+	}, nil
+}
+
+// verifyFile verifies the file against the checksum if not nil, and returns its digests
+func verifyFile(path string, checksum *Checksum) (*digests, error) {
+	d, err := fileDigests(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if checksum == nil {
+		return d, nil
+	}
+
+	actual := d.SHA256
+	if checksum.Algorithm == ChecksumMD5 {
+		actual = d.MD5
+	}
+
+	if actual != checksum.Value {
+		return nil, fmt.Errorf("checksum of file(%s) mismatch, expected %s, actual %s:%s",
+			path, checksum, checksum.Algorithm, actual)
+	}
+
+	return d, nil
+}
+
+// isMD5ETag checks whether the etag is the md5 of the object,
+// the etag of the multipart uploaded object is not, e.g. "<hex>-<parts>".
+func isMD5ETag(etag string) bool {
+	etag = strings.Trim(etag, "\"")
+	return len(etag) == 2*md5.Size && hexPattern.MatchString(etag)
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// HTTPUsernameKey is the credential key of the basic auth username of http server
+	HTTPUsernameKey = "http-username"
+	// HTTPPasswordKey is the credential key of the basic auth password of http server
+	HTTPPasswordKey = "http-password"
+	// HTTPTokenKey is the credential key of the bearer token of http server
+	HTTPTokenKey = "http-token"
+)
+
+const (
+	// httpDialTimeout is the timeout of connecting to the http server
+	httpDialTimeout = 30 * time.Second
+	// httpTLSHandshakeTimeout is the timeout of the tls handshake with the https server
+	httpTLSHandshakeTimeout = 30 * time.Second
+	// httpResponseHeaderTimeout is the timeout of waiting for the response headers after the request is sent
+	httpResponseHeaderTimeout = MaxTimeOut
+	// httpIdleReadTimeout is the timeout of the connection receiving nothing,
+	// so the download of the large file isn't limited by a total timeout but the stalled one fails
+	httpIdleReadTimeout = MaxTimeOut
+)
+
+// newHTTPClient creates the http client without the total timeout of the request
+func newHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   httpDialTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				conn, err := dialer.DialContext(ctx, network, addr)
+				if err != nil {
+					return nil, err
+				}
+				return &idleTimeoutConn{Conn: conn, timeout: httpIdleReadTimeout}, nil
+			},
+			TLSHandshakeTimeout:   httpTLSHandshakeTimeout,
+			ResponseHeaderTimeout: httpResponseHeaderTimeout,
+			IdleConnTimeout:       90 * time.Second,
+			MaxIdleConns:          100,
+		},
+	}
+}
+
+// idleTimeoutConn fails the read of the connection which receives nothing in the timeout
+type idleTimeoutConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *idleTimeoutConn) Read(b []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(b)
+}
+
+// httpBackend downloads the files from the http server, it's read-only
+type httpBackend struct {
+	client   *http.Client
+	username string
+	password string
+	token    string
+}
+
+// newHTTPBackend parses the optional http keys of the credential
+func newHTTPBackend(credential map[string]string) (Backend, error) {
+	b := &httpBackend{
+		client:   newHTTPClient(),
+		username: credential[HTTPUsernameKey],
+		password: credential[HTTPPasswordKey],
+		token:    credential[HTTPTokenKey],
+	}
+
+	if b.token != "" && b.username != "" {
+		return nil, fmt.Errorf("only one of %s and %s can be set", HTTPUsernameKey, HTTPTokenKey)
+	}
+
+	return b, nil
+}
+
+// authorize sets the authorization header of the request
+func (b *httpBackend) authorize(req *http.Request) {
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	} else if b.username != "" {
+		req.SetBasicAuth(b.username, b.password)
+	}
+}
+
+func (b *httpBackend) Download(objectURL string, localPath string) error {
+	return httpDownload(b.client, b.authorize, objectURL, objectURL, localPath)
+}
+
+func (b *httpBackend) Upload(localPath string, objectURL string) error {
+	return errReadOnly(HTTPPrefix, "upload")
+}
+
+func (b *httpBackend) Copy(srcURL string, objectURL string) error {
+	return errReadOnly(HTTPPrefix, "copy")
+}
+
+func (b *httpBackend) OpenRange(objectURL string, offset int64) (io.ReadCloser, error) {
+	return httpOpenRange(b.client, b.authorize, objectURL, objectURL, offset)
+}
+
+func (b *httpBackend) Version(objectURL string) (string, error) {
+	return httpVersion(b.client, b.authorize, objectURL, objectURL)
+}
+
+// httpDownload gets the http url and writes the body to the local path.
+// The body is written to the partial file first, which is resumed by the range request,
+// and the range is only accepted if the modification time of the server is unchanged.
+func httpDownload(client *http.Client, authorize func(*http.Request), objectURL string, httpURL string, localPath string) error {
+	partialPath := localPath + partialSuffix
+
+	req, err := http.NewRequest(http.MethodGet, httpURL, nil)
+	if err != nil {
+		return fmt.Errorf("invalid url(%s): %w", objectURL, err)
+	}
+	authorize(req)
+
+	var offset int64
+	if info, err := os.Stat(partialPath); err == nil && info.Size() > 0 {
+		offset = info.Size()
+		// the modification time of the partial file is the one of the server,
+		// otherwise the range isn't accepted and the whole file is downloaded
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", info.ModTime().UTC().Format(http.TimeFormat))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("download file from file url(%s) failed, error: %+v", objectURL, err)
+	}
+	defer resp.Body.Close()
+
+	flag := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0 &&
+		strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)):
+		flag |= os.O_APPEND
+		klog.Infof("resume downloading file url(%s) from offset %d", objectURL, offset)
+	case resp.StatusCode == http.StatusOK:
+		flag |= os.O_TRUNC
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// the partial file isn't the prefix of the file, download it again
+		if err := os.Remove(partialPath); err != nil {
+			return err
+		}
+		return httpDownload(client, authorize, objectURL, httpURL, localPath)
+	default:
+		return fmt.Errorf("download file from file url(%s) failed, status: %s", objectURL, resp.Status)
+	}
+
+	f, err := os.OpenFile(partialPath, flag, 0644)
+	if err != nil {
+		return err
+	}
+
+	modified, modifiedErr := http.ParseTime(resp.Header.Get("Last-Modified"))
+	if _, err = io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		// keep the partial file to resume, which is only possible if the server provides the modification time
+		if modifiedErr != nil || os.Chtimes(partialPath, time.Now(), modified) != nil {
+			os.Remove(partialPath)
+		}
+		return fmt.Errorf("download file from file url(%s) to file path(%s) failed, error: %+v", objectURL, localPath, err)
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(partialPath, localPath); err != nil {
+		return err
+	}
+
+	// keep the modification time of the server if provided
+	if modifiedErr == nil {
+		_ = os.Chtimes(localPath, time.Now(), modified)
+	}
+
+	return nil
+}
+
+// httpOpenRange gets the http url from the offset by the range request,
+// and skips the content before the offset if the server doesn't support range requests
+func httpOpenRange(client *http.Client, authorize func(*http.Request), objectURL string, httpURL string, offset int64) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, httpURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url(%s): %w", objectURL, err)
+	}
+	authorize(req)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("read file url(%s) failed, error: %+v", objectURL, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return resp.Body, nil
+	case http.StatusOK:
+		if _, err := io.CopyN(ioutil.Discard, resp.Body, offset); err != nil {
+			resp.Body.Close()
+			if err == io.EOF {
+				return nil, ErrInvalidRange
+			}
+			return nil, fmt.Errorf("read file url(%s) failed, error: %+v", objectURL, err)
+		}
+		return resp.Body, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		resp.Body.Close()
+		// Content-Range is "bytes */<size>", the offset at the end is a valid empty range
+		size := strings.TrimPrefix(resp.Header.Get("Content-Range"), "bytes */")
+		if n, err := strconv.ParseInt(size, 10, 64); err == nil && n == offset {
+			return ioutil.NopCloser(strings.NewReader("")), nil
+		}
+		return nil, ErrInvalidRange
+	}
+
+	resp.Body.Close()
+	return nil, fmt.Errorf("read file url(%s) failed, status: %s", objectURL, resp.Status)
+}
+
+// httpVersion returns the etag or the modification time of the http url, or empty if neither is provided
+func httpVersion(client *http.Client, authorize func(*http.Request), objectURL string, httpURL string) (string, error) {
+	req, err := http.NewRequest(http.MethodHead, httpURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid url(%s): %w", objectURL, err)
+	}
+	authorize(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("stat file url(%s) failed, error: %+v", objectURL, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("stat file url(%s) failed, status: %s", objectURL, resp.Status)
+	}
+
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		return etag, nil
+	}
+	if modified := resp.Header.Get("Last-Modified"); modified != "" {
+		return modified + "-" + resp.Header.Get("Content-Length"), nil
+	}
+	return "", nil
+}
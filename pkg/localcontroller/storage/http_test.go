@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPOpenRange(t *testing.T) {
+	content := "line1\nline2\nline3\n"
+	modified := time.Now()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "index.txt", modified, strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	b, err := newHTTPBackend(map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := b.(RangeReader)
+
+	for _, offset := range []int64{0, 6, int64(len(content))} {
+		reader, err := rr.OpenRange(server.URL+"/index.txt", offset)
+		if err != nil {
+			t.Fatalf("failed to open from offset %d: %v", offset, err)
+		}
+		data, _ := ioutil.ReadAll(reader)
+		reader.Close()
+		if !bytes.Equal(data, []byte(content[offset:])) {
+			t.Errorf("offset %d: expected %q, got %q", offset, content[offset:], data)
+		}
+	}
+
+	if _, err := rr.OpenRange(server.URL+"/index.txt", int64(len(content))+1); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected invalid range, got %v", err)
+	}
+
+	version, err := b.(Versioner).Version(server.URL + "/index.txt")
+	if err != nil || version == "" {
+		t.Errorf("expected the version from Last-Modified, got %q, %v", version, err)
+	}
+}
+
+func TestHTTPDownloadResume(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+	modified := time.Now().Add(-time.Hour)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		if len(ranges) == 1 {
+			// the connection is broken after half of the file
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(content[:len(content)/2]))
+			w.(http.Flusher).Flush()
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		http.ServeContent(w, r, "index.txt", modified, strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	b, err := newHTTPBackend(map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	localPath := filepath.Join(t.TempDir(), "index.txt")
+	if err := b.Download(server.URL+"/index.txt", localPath); err == nil {
+		t.Fatalf("expected the broken download failed")
+	}
+	if err := b.Download(server.URL+"/index.txt", localPath); err != nil {
+		t.Fatalf("failed to resume the download: %v", err)
+	}
+
+	data, _ := ioutil.ReadFile(localPath)
+	if string(data) != content {
+		t.Errorf("expected the whole file downloaded, got %d bytes", len(data))
+	}
+	expected := fmt.Sprintf("bytes=%d-", len(content)/2)
+	if len(ranges) != 2 || ranges[1] != expected {
+		t.Errorf("expected the download resumed by range %q, got %q", expected, ranges)
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/kubeedge/sedna/pkg/localcontroller/util"
+)
+
+// localBackend is the local file system of the host, including the mounted PVC
+type localBackend struct{}
+
+func newLocalBackend(credential map[string]string) (Backend, error) {
+	return &localBackend{}, nil
+}
+
+func (b *localBackend) Download(objectURL string, localPath string) error {
+	return b.Copy(objectURL, localPath)
+}
+
+func (b *localBackend) Upload(localPath string, objectURL string) error {
+	return b.Copy(localPath, objectURL)
+}
+
+func (b *localBackend) Copy(srcURL string, objectURL string) error {
+	if !util.IsExists(srcURL) {
+		return fmt.Errorf("url(%s) does not exists", srcURL)
+	}
+
+	dir := path.Dir(objectURL)
+	if !util.IsDir(dir) {
+		if err := util.CreateFolder(dir); err != nil {
+			return err
+		}
+	}
+
+	if _, err := util.CopyFile(srcURL, objectURL); err != nil {
+		return fmt.Errorf("copy file from %s to %s failed: %w", srcURL, objectURL, err)
+	}
+
+	return nil
+}
+
+func (b *localBackend) OpenRange(objectURL string, offset int64) (io.ReadCloser, error) {
+	f, err := os.Open(objectURL)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if offset > info.Size() {
+		f.Close()
+		return nil, ErrInvalidRange
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// Version returns the size and modification time of the file, or empty for the directory
+func (b *localBackend) Version(objectURL string) (string, error) {
+	info, err := os.Stat(objectURL)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", nil
+	}
+
+	return fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano()), nil
+}
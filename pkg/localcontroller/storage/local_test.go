@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLocalBackend(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "index.txt")
+	if err := ioutil.WriteFile(src, []byte("line1\nline2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := newLocalBackend(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the dirs of the destination are created
+	dest := filepath.Join(dir, "a", "b", "index.txt")
+	if err := b.Upload(src, dest); err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+	if data, err := ioutil.ReadFile(dest); err != nil || string(data) != "line1\nline2\n" {
+		t.Errorf("unexpected uploaded file %q, %v", data, err)
+	}
+	if err := b.Download(filepath.Join(dir, "missing.txt"), dest); err == nil {
+		t.Errorf("expected error of missing file")
+	}
+
+	rr := b.(RangeReader)
+	reader, err := rr.OpenRange(dest, 6)
+	if err != nil {
+		t.Fatalf("failed to open range: %v", err)
+	}
+	data, _ := ioutil.ReadAll(reader)
+	reader.Close()
+	if string(data) != "line2\n" {
+		t.Errorf("expected the content after the offset, got %q", data)
+	}
+	if _, err := rr.OpenRange(dest, 13); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected invalid range, got %v", err)
+	}
+
+	v := b.(Versioner)
+	version, err := v.Version(dest)
+	if err != nil || version == "" {
+		t.Fatalf("expected the version of the file, got %q, %v", version, err)
+	}
+	if err := ioutil.WriteFile(dest, []byte("line1\nline2\nline3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(dest, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if changed, _ := v.Version(dest); changed == version {
+		t.Errorf("expected the version changed with the file")
+	}
+	if version, err := v.Version(dir); err != nil || version != "" {
+		t.Errorf("expected no version of the dir, got %q, %v", version, err)
+	}
+}
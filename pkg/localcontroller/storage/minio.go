@@ -19,12 +19,15 @@ package storage
 import (
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/url"
 	"strings"
 	"time"
 
 	"k8s.io/klog/v2"
 
+	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
 	"github.com/kubeedge/sedna/pkg/localcontroller/util"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -32,7 +35,10 @@ import (
 
 // MinioClient defines a minio client
 type MinioClient struct {
-	Client *minio.Client
+	Client  *minio.Client
+	Options TransferOptions
+
+	core objectClient
 }
 
 // MaxTimeOut is max deadline time of client working
@@ -55,12 +61,64 @@ func createMinioClient(endpoint string, useHTTPS string, accessKeyID string, sec
 	}
 
 	c := MinioClient{
-		Client: client,
+		Client:  client,
+		Options: TransferOptions{StateDir: defaultCache.TransferDir()},
+		core:    &minio.Core{Client: client},
 	}
 
 	return &c, nil
 }
 
+// newS3Backend parses the s3 keys of the credential, and creates the minio client
+func newS3Backend(credential map[string]string) (Backend, error) {
+	endpoint, err := checkMapKeyExists(credential, runtime.S3EndpointKey)
+	if err != nil {
+		return nil, err
+	}
+
+	useHTTPS, err := checkMapKeyExists(credential, runtime.S3UseHTTPSKey)
+	if err != nil {
+		useHTTPS = "1"
+	}
+
+	ak, err := checkMapKeyExists(credential, runtime.AccessKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	sk, err := checkMapKeyExists(credential, runtime.SecretAccessKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return createMinioClient(endpoint, useHTTPS, ak, sk)
+}
+
+// checkMapKeyExists checks whether key exists in the dict
+func checkMapKeyExists(m map[string]string, key string) (string, error) {
+	v, ok := m[key]
+	if !ok {
+		return "", fmt.Errorf("%s does not exists", key)
+	}
+
+	return v, nil
+}
+
+// Download downloads the object of s3 to the local host
+func (mc *MinioClient) Download(objectURL string, localPath string) error {
+	return mc.downloadFile(objectURL, localPath)
+}
+
+// Upload uploads the local file to the object url of s3
+func (mc *MinioClient) Upload(localPath string, objectURL string) error {
+	return mc.uploadFile(localPath, objectURL)
+}
+
+// Copy copies the object of s3 to another
+func (mc *MinioClient) Copy(srcURL string, objectURL string) error {
+	return mc.copyFile(srcURL, objectURL)
+}
+
 // uploadFile uploads file from local host to storage service
 func (mc *MinioClient) uploadFile(localPath string, objectURL string) error {
 	bucket, absPath, err := mc.parseURL(objectURL)
@@ -72,10 +130,7 @@ func (mc *MinioClient) uploadFile(localPath string, objectURL string) error {
 		return fmt.Errorf("file(%s) in the local host is not exists", localPath)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), MaxTimeOut)
-	defer cancel()
-
-	if _, err = mc.Client.FPutObject(ctx, bucket, absPath, localPath, minio.PutObjectOptions{}); err != nil {
+	if err = mc.resumableUpload(localPath, bucket, absPath); err != nil {
 		return fmt.Errorf("upload file from file path(%s) to file url(%s) failed, error: %+v", localPath, objectURL, err)
 	}
 
@@ -118,14 +173,67 @@ func (mc *MinioClient) downloadFile(objectURL string, localPath string) error {
 		return err
 	}
 
+	if err = mc.resumableDownload(bucket, absPath, localPath); err != nil {
+		return fmt.Errorf("download file from file url(%s) to file path(%s) failed, error: %+v", objectURL, localPath, err)
+	}
+
+	return nil
+}
+
+// Version returns the etag of the object, which changes with the content
+func (mc *MinioClient) Version(objectURL string) (string, error) {
+	bucket, absPath, err := mc.parseURL(objectURL)
+	if err != nil {
+		return "", err
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), MaxTimeOut)
 	defer cancel()
 
-	if err = mc.Client.FGetObject(ctx, bucket, absPath, localPath, minio.GetObjectOptions{}); err != nil {
-		return fmt.Errorf("download file from file url(%s) to file path(%s) failed, error: %+v", objectURL, localPath, err)
+	info, err := mc.core.StatObject(ctx, bucket, absPath, minio.StatObjectOptions{})
+	if err != nil {
+		return "", fmt.Errorf("stat file url(%s) failed, error: %+v", objectURL, err)
 	}
 
-	return nil
+	return info.ETag, nil
+}
+
+// OpenRange reads the object from the offset by the range request
+func (mc *MinioClient) OpenRange(objectURL string, offset int64) (io.ReadCloser, error) {
+	bucket, absPath, err := mc.parseURL(objectURL)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), MaxTimeOut)
+	defer cancel()
+
+	info, err := mc.core.StatObject(ctx, bucket, absPath, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("stat file url(%s) failed, error: %+v", objectURL, err)
+	}
+	if offset > info.Size {
+		return nil, ErrInvalidRange
+	}
+	if offset == info.Size {
+		return ioutil.NopCloser(strings.NewReader("")), nil
+	}
+
+	opts := minio.GetObjectOptions{}
+	if err := opts.SetMatchETag(info.ETag); err != nil {
+		return nil, err
+	}
+	if err := opts.SetRange(offset, 0); err != nil {
+		return nil, err
+	}
+
+	// the reader is not limited by the timeout, which reads the appended content of any size
+	reader, _, _, err := mc.core.GetObject(context.Background(), bucket, absPath, opts)
+	if err != nil {
+		return nil, fmt.Errorf("read file url(%s) failed, error: %+v", objectURL, err)
+	}
+
+	return reader, nil
 }
 
 // parseURL parses url
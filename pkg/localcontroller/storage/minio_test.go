@@ -0,0 +1,369 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+// fakeObjectClient is an in-memory s3 compatible storage service
+type fakeObjectClient struct {
+	lock    sync.Mutex
+	objects map[string][]byte
+	uploads map[string]map[int][]byte
+	nextID  int
+
+	// failRanges fails the get of the ranges once, e.g. "bytes=8-11"
+	failRanges map[string]bool
+	// corruptRanges returns the wrong content of the ranges
+	corruptRanges map[string]bool
+	// failParts fails the upload of the parts once
+	failParts map[int]bool
+
+	gets  []string
+	puts  []int
+	stats int
+}
+
+func newFakeObjectClient() *fakeObjectClient {
+	return &fakeObjectClient{
+		objects:       make(map[string][]byte),
+		uploads:       make(map[string]map[int][]byte),
+		failRanges:    make(map[string]bool),
+		corruptRanges: make(map[string]bool),
+		failParts:     make(map[int]bool),
+	}
+}
+
+func md5Hex(data []byte) string {
+	h := md5.Sum(data)
+	return hex.EncodeToString(h[:])
+}
+
+func (c *fakeObjectClient) StatObject(ctx context.Context, bucket, object string, opts minio.StatObjectOptions) (minio.ObjectInfo, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.stats++
+	data, ok := c.objects[bucket+"/"+object]
+	if !ok {
+		return minio.ObjectInfo{}, fmt.Errorf("no such key %s/%s", bucket, object)
+	}
+	return minio.ObjectInfo{Key: object, Size: int64(len(data)), ETag: md5Hex(data)}, nil
+}
+
+func (c *fakeObjectClient) GetObject(ctx context.Context, bucket, object string, opts minio.GetObjectOptions) (io.ReadCloser, minio.ObjectInfo, http.Header, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	data, ok := c.objects[bucket+"/"+object]
+	if !ok {
+		return nil, minio.ObjectInfo{}, nil, fmt.Errorf("no such key %s/%s", bucket, object)
+	}
+
+	r := opts.Header().Get("Range")
+	c.gets = append(c.gets, r)
+	if c.failRanges[r] {
+		delete(c.failRanges, r)
+		return nil, minio.ObjectInfo{}, nil, fmt.Errorf("connection reset")
+	}
+
+	var start, end int
+	if _, err := fmt.Sscanf(r, "bytes=%d-%d", &start, &end); err != nil {
+		start, end = 0, len(data)-1
+	}
+	part := append([]byte(nil), data[start:end+1]...)
+	if c.corruptRanges[r] {
+		part[0] ^= 0xff
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(part)), minio.ObjectInfo{}, nil, nil
+}
+
+func (c *fakeObjectClient) PutObject(ctx context.Context, bucket, object string, data io.Reader, size int64, md5Base64, sha256Hex string, opts minio.PutObjectOptions) (minio.UploadInfo, error) {
+	content, err := ioutil.ReadAll(data)
+	if err != nil {
+		return minio.UploadInfo{}, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.objects[bucket+"/"+object] = content
+	return minio.UploadInfo{ETag: md5Hex(content)}, nil
+}
+
+func (c *fakeObjectClient) NewMultipartUpload(ctx context.Context, bucket, object string, opts minio.PutObjectOptions) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.nextID++
+	id := fmt.Sprintf("upload-%d", c.nextID)
+	c.uploads[id] = make(map[int][]byte)
+	return id, nil
+}
+
+func (c *fakeObjectClient) PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data io.Reader, size int64, md5Base64, sha256Hex string, sse encrypt.ServerSide) (minio.ObjectPart, error) {
+	content, err := ioutil.ReadAll(data)
+	if err != nil {
+		return minio.ObjectPart{}, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.puts = append(c.puts, partID)
+	if c.failParts[partID] {
+		delete(c.failParts, partID)
+		return minio.ObjectPart{}, fmt.Errorf("connection reset")
+	}
+
+	h := md5.Sum(content)
+	if md5Base64 != base64.StdEncoding.EncodeToString(h[:]) {
+		return minio.ObjectPart{}, fmt.Errorf("bad digest of part %d", partID)
+	}
+
+	c.uploads[uploadID][partID] = content
+	return minio.ObjectPart{PartNumber: partID, ETag: md5Hex(content), Size: size}, nil
+}
+
+func (c *fakeObjectClient) ListObjectParts(ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int) (minio.ListObjectPartsResult, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	parts, ok := c.uploads[uploadID]
+	if !ok {
+		return minio.ListObjectPartsResult{}, fmt.Errorf("no such upload %s", uploadID)
+	}
+
+	result := minio.ListObjectPartsResult{UploadID: uploadID}
+	for id, content := range parts {
+		result.ObjectParts = append(result.ObjectParts, minio.ObjectPart{PartNumber: id, ETag: md5Hex(content)})
+	}
+	return result, nil
+}
+
+func (c *fakeObjectClient) CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, parts []minio.CompletePart) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var content []byte
+	for i, p := range parts {
+		if p.PartNumber != i+1 {
+			return "", fmt.Errorf("invalid part order")
+		}
+		content = append(content, c.uploads[uploadID][p.PartNumber]...)
+	}
+	delete(c.uploads, uploadID)
+	c.objects[bucket+"/"+object] = content
+	return fmt.Sprintf("%s-%d", md5Hex(content), len(parts)), nil
+}
+
+func newFakeMinioClient(t *testing.T, fake *fakeObjectClient) *MinioClient {
+	return &MinioClient{
+		Options: TransferOptions{
+			PartSize:    4,
+			Concurrency: 2,
+			Retries:     0,
+			StateDir:    filepath.Join(t.TempDir(), "transfer"),
+		},
+		core: fake,
+	}
+}
+
+func TestResumableDownload(t *testing.T) {
+	fake := newFakeObjectClient()
+	content := []byte("0123456789abcdefghijklmnopqrstuvwxyz!")
+	fake.objects["bucket/model.pb"] = content
+	fake.failRanges["bytes=8-11"] = true
+
+	mc := newFakeMinioClient(t, fake)
+	localPath := filepath.Join(t.TempDir(), "model.pb")
+
+	if err := mc.downloadFile("s3://bucket/model.pb", localPath); err == nil {
+		t.Fatalf("expected the download to fail")
+	}
+	if _, err := os.Stat(localPath + stateSuffix); err != nil {
+		t.Fatalf("expected the state of the unfinished download: %v", err)
+	}
+
+	fake.gets = nil
+	if err := mc.downloadFile("s3://bucket/model.pb", localPath); err != nil {
+		t.Fatalf("failed to resume the download: %v", err)
+	}
+	if len(fake.gets) != 1 || fake.gets[0] != "bytes=8-11" {
+		t.Errorf("expected to download only the failed part, got %v", fake.gets)
+	}
+
+	data, _ := ioutil.ReadFile(localPath)
+	if !bytes.Equal(data, content) {
+		t.Errorf("downloaded content mismatch: %q", data)
+	}
+	if _, err := os.Stat(localPath + stateSuffix); !os.IsNotExist(err) {
+		t.Errorf("expected the state to be removed")
+	}
+}
+
+func TestDownloadCorrupted(t *testing.T) {
+	fake := newFakeObjectClient()
+	fake.objects["bucket/data.txt"] = []byte("0123456789")
+	fake.corruptRanges["bytes=4-7"] = true
+
+	mc := newFakeMinioClient(t, fake)
+	localPath := filepath.Join(t.TempDir(), "data.txt")
+
+	err := mc.downloadFile("s3://bucket/data.txt", localPath)
+	if err == nil || !strings.Contains(err.Error(), "etag") {
+		t.Fatalf("expected etag mismatch, got %v", err)
+	}
+	if _, err := os.Stat(localPath); !os.IsNotExist(err) {
+		t.Errorf("expected the corrupted file not to be used")
+	}
+}
+
+func TestResumableUpload(t *testing.T) {
+	fake := newFakeObjectClient()
+	fake.failParts[2] = true
+
+	mc := newFakeMinioClient(t, fake)
+	mc.Options.Concurrency = 1
+
+	content := []byte("the trained model in parts")
+	localPath := filepath.Join(t.TempDir(), "model.pb")
+	if err := ioutil.WriteFile(localPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mc.uploadFile(localPath, "s3://bucket/output/model.pb"); err == nil {
+		t.Fatalf("expected the upload to fail")
+	}
+
+	fake.puts = nil
+	if err := mc.uploadFile(localPath, "s3://bucket/output/model.pb"); err != nil {
+		t.Fatalf("failed to resume the upload: %v", err)
+	}
+
+	sort.Ints(fake.puts)
+	for _, part := range fake.puts {
+		if part == 1 {
+			t.Errorf("expected the uploaded part 1 to be skipped, got %v", fake.puts)
+		}
+	}
+	if !bytes.Equal(fake.objects["bucket/output/model.pb"], content) {
+		t.Errorf("uploaded content mismatch: %q", fake.objects["bucket/output/model.pb"])
+	}
+}
+
+func TestDownloadCached(t *testing.T) {
+	fake := newFakeObjectClient()
+	content := []byte("index of the dataset")
+	fake.objects["bucket/index.txt"] = content
+	digest, err := fileDigestsOf(t, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mc := newFakeMinioClient(t, fake)
+	cache := NewCache(t.TempDir(), 0)
+
+	wrong, _ := ParseChecksum("sha256:" + strings.Repeat("0", 64))
+	if err := downloadCached(cache, mc, "s3://bucket/index.txt", filepath.Join(t.TempDir(), "index.txt"), wrong); err == nil {
+		t.Fatalf("expected the checksum mismatch")
+	}
+
+	expected, _ := ParseChecksum("sha256:" + digest)
+	for i := 0; i < 2; i++ {
+		fake.gets = nil
+		localPath := filepath.Join(t.TempDir(), "index.txt")
+		if err := downloadCached(cache, mc, "s3://bucket/index.txt", localPath, expected); err != nil {
+			t.Fatalf("failed to download: %v", err)
+		}
+
+		data, _ := ioutil.ReadFile(localPath)
+		if !bytes.Equal(data, content) {
+			t.Errorf("downloaded content mismatch: %q", data)
+		}
+		if i == 1 && len(fake.gets) != 0 {
+			t.Errorf("expected to get from the cache, got downloads %v", fake.gets)
+		}
+	}
+
+	// the digest of the unchanged object is remembered by its etag
+	fake.gets = nil
+	if err := downloadCached(cache, mc, "s3://bucket/index.txt", filepath.Join(t.TempDir(), "index.txt"), nil); err != nil {
+		t.Fatalf("failed to download: %v", err)
+	}
+	if len(fake.gets) != 0 {
+		t.Errorf("expected to get from the cache, got downloads %v", fake.gets)
+	}
+}
+
+func fileDigestsOf(t *testing.T, content []byte) (string, error) {
+	path := filepath.Join(t.TempDir(), "content")
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		return "", err
+	}
+	d, err := fileDigests(path)
+	if err != nil {
+		return "", err
+	}
+	return d.SHA256, nil
+}
+
+func TestParseChecksum(t *testing.T) {
+	sha := strings.Repeat("ab", 32)
+	var checksumTest = []struct {
+		checksum string
+		expected string
+		valid    bool
+	}{
+		{"", "", true},
+		{sha, "sha256:" + sha, true},
+		{"SHA256:" + strings.ToUpper(sha), "sha256:" + sha, true},
+		{"md5:" + strings.Repeat("0", 32), "md5:" + strings.Repeat("0", 32), true},
+		{"md5:" + sha, "", false},
+		{"crc32:00000000", "", false},
+		{"sha256:xyz", "", false},
+	}
+
+	for _, tt := range checksumTest {
+		c, err := ParseChecksum(tt.checksum)
+		if (err == nil) != tt.valid {
+			t.Errorf("checksum %q: expected valid=%v, got error %v", tt.checksum, tt.valid, err)
+			continue
+		}
+		if c != nil && c.String() != tt.expected {
+			t.Errorf("checksum %q: expected %s, got %s", tt.checksum, tt.expected, c)
+		}
+	}
+}
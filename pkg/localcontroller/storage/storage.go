@@ -19,11 +19,15 @@ package storage
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/url"
+	"os"
 	"path"
 	"path/filepath"
+	"sync"
+
+	"k8s.io/klog/v2"
 
-	"github.com/kubeedge/sedna/pkg/globalmanager/runtime"
 	"github.com/kubeedge/sedna/pkg/localcontroller/util"
 )
 
@@ -32,53 +36,86 @@ const (
 	S3Prefix = "s3"
 	// LocalPrefix defines that prefix of url is local host
 	LocalPrefix = ""
+	// HTTPPrefix defines that prefix of url is http, which is read-only
+	HTTPPrefix = "http"
+	// HTTPSPrefix defines that prefix of url is https, which is read-only
+	HTTPSPrefix = "https"
+	// WebDAVPrefix defines that prefix of url is webdav over http
+	WebDAVPrefix = "webdav"
+	// WebDAVSPrefix defines that prefix of url is webdav over https
+	WebDAVSPrefix = "webdavs"
 )
 
+// Storage dispatches the operations of the url to the backend registered for its scheme
 type Storage struct {
-	MinioClient    *MinioClient
 	IsLocalStorage bool
+
+	lock       sync.Mutex
+	credential map[string]string
+	backends   map[string]Backend
 }
 
-// Download downloads the file to the local host
-func (s *Storage) Download(objectURL string, localPath string) (string, error) {
-	prefix, err := s.CheckURL(objectURL)
-	if err != nil {
-		return "", err
+// backend gets the backend of the url scheme, which is created with the credential at first use
+func (s *Storage) backend(scheme string) (Backend, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if b, ok := s.backends[scheme]; ok {
+		return b, nil
 	}
 
-	switch prefix {
-	case LocalPrefix:
-		return s.localCopy(objectURL, localPath)
-	case S3Prefix:
-		return s.downloadS3(objectURL, localPath)
-	default:
-		return "", fmt.Errorf("invalid url(%s)", objectURL)
+	factory, ok := getBackendFactory(scheme)
+	if !ok {
+		return nil, fmt.Errorf("not support prefix(%s), support prefix: %+v", scheme, SupportedSchemes())
 	}
-}
 
-// downloadLocal copies the local file to another in local host
-func (s *Storage) localCopy(objectURL string, localPath string) (string, error) {
-	if !util.IsExists(objectURL) {
-		return "", fmt.Errorf("url(%s) does not exists", objectURL)
+	credential := s.credential
+	if credential == nil {
+		credential = make(map[string]string)
 	}
 
-	if localPath == "" {
-		return objectURL, nil
+	b, err := factory(credential)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the storage backend of prefix(%s): %w", scheme, err)
 	}
 
-	dir := path.Dir(localPath)
-	if !util.IsDir(dir) {
-		util.CreateFolder(dir)
+	if s.backends == nil {
+		s.backends = make(map[string]Backend)
 	}
+	s.backends[scheme] = b
 
-	util.CopyFile(objectURL, localPath)
+	return b, nil
+}
 
-	return localPath, nil
+// Download downloads the file to the local host
+func (s *Storage) Download(objectURL string, localPath string) (string, error) {
+	return s.DownloadWithChecksum(objectURL, localPath, "")
 }
 
-// downloadS3 downloads the file from url of s3 to the local host
-func (s *Storage) downloadS3(objectURL string, localPath string) (string, error) {
+// DownloadWithChecksum downloads the file to the local host, and verifies the optional checksum.
+// The downloaded files are kept in the node cache by their sha256 digests.
+func (s *Storage) DownloadWithChecksum(objectURL string, localPath string, checksum string) (string, error) {
+	expected, err := ParseChecksum(checksum)
+	if err != nil {
+		return "", err
+	}
+
+	prefix, err := s.CheckURL(objectURL)
+	if err != nil {
+		return "", err
+	}
+
 	if localPath == "" {
+		if prefix == LocalPrefix {
+			if !util.IsExists(objectURL) {
+				return "", fmt.Errorf("url(%s) does not exists", objectURL)
+			}
+			if _, err := verifyFile(objectURL, expected); err != nil {
+				return "", err
+			}
+			return objectURL, nil
+		}
+
 		temporaryDir, err := util.CreateTemporaryDir()
 		if err != nil {
 			return "", err
@@ -89,93 +126,209 @@ func (s *Storage) downloadS3(objectURL string, localPath string) (string, error)
 
 	dir := path.Dir(localPath)
 	if !util.IsDir(dir) {
-		util.CreateFolder(dir)
+		if err := util.CreateFolder(dir); err != nil {
+			return "", err
+		}
 	}
 
-	if err := s.MinioClient.downloadFile(objectURL, localPath); err != nil {
+	b, err := s.backend(prefix)
+	if err != nil {
+		return "", err
+	}
+
+	if prefix == LocalPrefix {
+		if err := b.Download(objectURL, localPath); err != nil {
+			return "", err
+		}
+		if _, err := verifyFile(localPath, expected); err != nil {
+			return "", err
+		}
+		return localPath, nil
+	}
+
+	if err := downloadCached(defaultCache, b, objectURL, localPath, expected); err != nil {
 		return "", err
 	}
 
 	return localPath, nil
 }
 
-// checkMapKeyExists checks whether key exists in the dict
-func checkMapKeyExists(m map[string]string, key string) (string, error) {
-	v, ok := m[key]
-	if !ok {
-		return "", fmt.Errorf("%s does not exists", key)
+// downloadCached gets the file from the cache if the digest is known,
+// otherwise downloads it to the partial path of the cache, and verifies and caches it.
+func downloadCached(cache *Cache, b Backend, objectURL string, localPath string, expected *Checksum) error {
+	key := objectURL
+	versioned := false
+	if v, ok := b.(Versioner); ok {
+		version, err := v.Version(objectURL)
+		if err != nil {
+			return err
+		}
+		// the unknown version can't identify the content
+		if version != "" {
+			key += "#" + version
+			versioned = true
+		}
 	}
 
-	return v, nil
-}
+	unlock := cache.Lock(key)
+	defer unlock()
 
-// SetCredential sets credential of the storage service
-func (s *Storage) SetCredential(credential string) error {
-	c := credential
-	m := make(map[string]string)
-	if err := json.Unmarshal([]byte(c), &m); err != nil {
+	var digest string
+	if expected != nil && expected.Algorithm == ChecksumSHA256 {
+		digest = expected.Value
+	} else if versioned {
+		digest = cache.Resolve(key)
+	}
+
+	if cache.Get(digest, localPath) {
+		klog.V(2).Infof("got the file of url(%s) from the cache(digest=%s)", objectURL, digest)
+		return nil
+	}
+
+	partialPath := cache.PartialPath(key, filepath.Base(localPath))
+	if err := os.MkdirAll(filepath.Dir(partialPath), os.ModePerm); err != nil {
+		return err
+	}
+
+	// the partial files are kept if failed, so the download can be resumed
+	if err := b.Download(objectURL, partialPath); err != nil {
 		return err
 	}
+	defer os.RemoveAll(filepath.Dir(partialPath))
 
-	endpoint, err := checkMapKeyExists(m, runtime.S3EndpointKey)
+	d, err := verifyFile(partialPath, expected)
 	if err != nil {
 		return err
 	}
 
-	useHTTPS, err := checkMapKeyExists(m, runtime.S3UseHTTPSKey)
+	if err := cache.Put(partialPath, d.SHA256); err != nil {
+		klog.Warningf("failed to cache the file of url(%s): %v", objectURL, err)
+		return linkOrCopy(partialPath, localPath)
+	}
+	if versioned {
+		if err := cache.Remember(key, d.SHA256); err != nil {
+			klog.Warningf("failed to remember the digest of url(%s): %v", objectURL, err)
+		}
+	}
+
+	if !cache.Get(d.SHA256, localPath) {
+		return linkOrCopy(partialPath, localPath)
+	}
+	return nil
+}
+
+// Version returns the version of the object which changes with the content,
+// it's empty if the backend of the url doesn't know the version.
+func (s *Storage) Version(objectURL string) (string, error) {
+	prefix, err := s.CheckURL(objectURL)
 	if err != nil {
-		useHTTPS = "1"
+		return "", err
 	}
 
-	ak, err := checkMapKeyExists(m, runtime.AccessKeyID)
+	b, err := s.backend(prefix)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	sk, err := checkMapKeyExists(m, runtime.SecretAccessKey)
+	v, ok := b.(Versioner)
+	if !ok {
+		return "", nil
+	}
+	return v.Version(objectURL)
+}
+
+// OpenRange opens the object from the offset. If the backend of the url
+// can't read from the offset, the object is downloaded to read from the offset.
+func (s *Storage) OpenRange(objectURL string, offset int64) (io.ReadCloser, error) {
+	prefix, err := s.CheckURL(objectURL)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	b, err := s.backend(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if r, ok := b.(RangeReader); ok {
+		return r.OpenRange(objectURL, offset)
 	}
 
-	mc, err := createMinioClient(endpoint, useHTTPS, ak, sk)
+	localPath, err := s.Download(objectURL, "")
 	if err != nil {
+		return nil, err
+	}
+
+	reader, err := (&localBackend{}).OpenRange(localPath, offset)
+	if err != nil {
+		os.RemoveAll(filepath.Dir(localPath))
+		return nil, err
+	}
+
+	return &tempFileReader{ReadCloser: reader, dir: filepath.Dir(localPath)}, nil
+}
+
+// tempFileReader removes the temporary dir of the downloaded file when closed
+type tempFileReader struct {
+	io.ReadCloser
+	dir string
+}
+
+func (r *tempFileReader) Close() error {
+	defer os.RemoveAll(r.dir)
+	return r.ReadCloser.Close()
+}
+
+// SetCredential sets credential of the storage service,
+// the keys are parsed by the backend of each url scheme.
+func (s *Storage) SetCredential(credential string) error {
+	m := make(map[string]string)
+	if err := json.Unmarshal([]byte(credential), &m); err != nil {
 		return err
 	}
 
-	s.MinioClient = mc
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.credential = m
+	// recreate the backends with the new credential
+	s.backends = nil
 
 	return nil
 }
 
 // Upload uploads the src url to the object url (e.g., "s3")
-func (s *Storage) Upload(localPath string, objectURL string) error {
+func (s *Storage) Upload(srcURL string, objectURL string) error {
 	prefix, err := s.CheckURL(objectURL)
 	if err != nil {
 		return err
 	}
 
-	switch prefix {
-	case S3Prefix:
-		return s.uploadS3(localPath, objectURL)
-	default:
-		return fmt.Errorf("invalid url(%s)", objectURL)
+	srcPrefix, err := s.CheckURL(srcURL)
+	if err != nil {
+		return err
 	}
-}
 
-// uploadS3 uploads the src url to the object url of s3
-func (s *Storage) uploadS3(srcURL string, objectURL string) error {
-	prefix, err := s.CheckURL(srcURL)
+	b, err := s.backend(prefix)
 	if err != nil {
 		return err
 	}
-	switch prefix {
+
+	switch srcPrefix {
+	case prefix:
+		return b.Copy(srcURL, objectURL)
 	case LocalPrefix:
-		return s.MinioClient.uploadFile(srcURL, objectURL)
+		return b.Upload(srcURL, objectURL)
+	}
 
-	case S3Prefix:
-		return s.MinioClient.copyFile(srcURL, objectURL)
+	// the src url is in another storage service, transfer by the local host
+	localPath, err := s.Download(srcURL, "")
+	if err != nil {
+		return err
 	}
-	return nil
+	defer os.RemoveAll(filepath.Dir(localPath))
+
+	return b.Upload(localPath, objectURL)
 }
 
 // CheckURL checks prefix of the url
@@ -189,15 +342,12 @@ func (s *Storage) CheckURL(objectURL string) (string, error) {
 		return "", fmt.Errorf("invalid url(%s), error: %+v", objectURL, err)
 	}
 
-	l := []string{LocalPrefix, S3Prefix}
-
-	for _, v := range l {
-		if u.Scheme == v {
-			return u.Scheme, nil
-		}
+	if _, ok := getBackendFactory(u.Scheme); !ok {
+		return "", fmt.Errorf("invalid url(%s), not support prefix(%s), support prefix: %+v",
+			objectURL, u.Scheme, SupportedSchemes())
 	}
 
-	return "", fmt.Errorf("invalid url(%s), not support prefix(%s), support prefix: %+v", objectURL, u.Scheme, l)
+	return u.Scheme, nil
 }
 
 // IsLocalURL checks whether the url is local url
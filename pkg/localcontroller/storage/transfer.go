@@ -0,0 +1,447 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubeedge/sedna/pkg/localcontroller/util"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+const (
+	// DefaultPartSize is the default size of a part of the transfers
+	DefaultPartSize = 16 * 1024 * 1024
+	// DefaultConcurrency is the default number of the parts transferred concurrently
+	DefaultConcurrency = 4
+	// DefaultRetries is the default number of retries of a part
+	DefaultRetries = 3
+
+	// partialSuffix is the suffix of the unfinished download file
+	partialSuffix = ".part"
+	// stateSuffix is the suffix of the state file of the unfinished download
+	stateSuffix = ".part.json"
+)
+
+// TransferOptions defines the options of the transfers
+type TransferOptions struct {
+	PartSize    int64
+	Concurrency int
+	Retries     int
+	// StateDir is the dir of the states of the unfinished uploads
+	StateDir string
+}
+
+// objectClient is the low level api of the s3 compatible storage service,
+// which is implemented by minio.Core.
+type objectClient interface {
+	StatObject(ctx context.Context, bucket, object string, opts minio.StatObjectOptions) (minio.ObjectInfo, error)
+	GetObject(ctx context.Context, bucket, object string, opts minio.GetObjectOptions) (io.ReadCloser, minio.ObjectInfo, http.Header, error)
+	PutObject(ctx context.Context, bucket, object string, data io.Reader, size int64, md5Base64, sha256Hex string, opts minio.PutObjectOptions) (minio.UploadInfo, error)
+	NewMultipartUpload(ctx context.Context, bucket, object string, opts minio.PutObjectOptions) (string, error)
+	PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data io.Reader, size int64, md5Base64, sha256Hex string, sse encrypt.ServerSide) (minio.ObjectPart, error)
+	ListObjectParts(ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int) (minio.ListObjectPartsResult, error)
+	CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, parts []minio.CompletePart) (string, error)
+}
+
+// downloadState is the state of the unfinished download
+type downloadState struct {
+	ETag     string `json:"etag"`
+	Size     int64  `json:"size"`
+	PartSize int64  `json:"partSize"`
+	Done     []bool `json:"done"`
+}
+
+// uploadState is the state of the unfinished multipart upload
+type uploadState struct {
+	UploadID string    `json:"uploadID"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"modTime"`
+	PartSize int64     `json:"partSize"`
+}
+
+func (o *TransferOptions) withDefaults() TransferOptions {
+	opts := *o
+	if opts.PartSize <= 0 {
+		opts.PartSize = DefaultPartSize
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.StateDir == "" {
+		opts.StateDir = filepath.Join(os.TempDir(), "sedna-transfer")
+	}
+	return opts
+}
+
+// parallel runs f for each part in the parts with bounded concurrency,
+// retrying each part, and returns the first error.
+func parallel(parts []int, concurrency int, retries int, f func(part int) error) error {
+	ch := make(chan int)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for part := range ch {
+				var err error
+				for attempt := 0; attempt <= retries; attempt++ {
+					if attempt > 0 {
+						klog.Warningf("retry part %d (attempt %d/%d), error: %v", part, attempt, retries, err)
+						time.Sleep(time.Duration(attempt) * time.Second)
+					}
+					if err = f(part); err == nil {
+						break
+					}
+				}
+				if err != nil {
+					once.Do(func() { firstErr = err })
+				}
+			}
+		}()
+	}
+
+	for _, part := range parts {
+		ch <- part
+	}
+	close(ch)
+	wg.Wait()
+
+	return firstErr
+}
+
+// offsetWriter writes to the file from the offset
+type offsetWriter struct {
+	f      *os.File
+	offset int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.f.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func writeJSON(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// resumableDownload downloads the object by ranged parts concurrently,
+// the finished parts are recorded so the download can be resumed from the local path.
+func (mc *MinioClient) resumableDownload(bucket, object string, localPath string) error {
+	opts := mc.Options.withDefaults()
+
+	ctx, cancel := context.WithTimeout(context.Background(), MaxTimeOut)
+	info, err := mc.core.StatObject(ctx, bucket, object, minio.StatObjectOptions{})
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	partialPath := localPath + partialSuffix
+	statePath := localPath + stateSuffix
+
+	var state downloadState
+	if err := readJSON(statePath, &state); err != nil || state.ETag != info.ETag ||
+		state.Size != info.Size || state.PartSize != opts.PartSize || !util.IsExists(partialPath) {
+		// the object is changed, or there is no resumable download
+		state = downloadState{
+			ETag:     info.ETag,
+			Size:     info.Size,
+			PartSize: opts.PartSize,
+			Done:     make([]bool, (info.Size+opts.PartSize-1)/opts.PartSize),
+		}
+		_ = os.Remove(partialPath)
+	} else {
+		klog.Infof("resume downloading %s/%s to %s", bucket, object, localPath)
+	}
+
+	f, err := os.OpenFile(partialPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := f.Truncate(info.Size); err != nil {
+		return err
+	}
+
+	var parts []int
+	for i, done := range state.Done {
+		if !done {
+			parts = append(parts, i)
+		}
+	}
+
+	var stateLock sync.Mutex
+	err = parallel(parts, opts.Concurrency, opts.Retries, func(part int) error {
+		start := int64(part) * state.PartSize
+		end := start + state.PartSize - 1
+		if end >= state.Size {
+			end = state.Size - 1
+		}
+
+		getOpts := minio.GetObjectOptions{}
+		if err := getOpts.SetRange(start, end); err != nil {
+			return err
+		}
+		// fail if the object is changed during the download
+		if err := getOpts.SetMatchETag(state.ETag); err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), MaxTimeOut)
+		defer cancel()
+
+		reader, _, _, err := mc.core.GetObject(ctx, bucket, object, getOpts)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		n, err := io.Copy(&offsetWriter{f: f, offset: start}, reader)
+		if err != nil {
+			return err
+		}
+		if n != end-start+1 {
+			return fmt.Errorf("part %d is short, expected %d bytes, got %d", part, end-start+1, n)
+		}
+
+		stateLock.Lock()
+		defer stateLock.Unlock()
+		state.Done[part] = true
+		return writeJSON(statePath, &state)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		return err
+	}
+
+	if isMD5ETag(info.ETag) {
+		d, err := fileDigests(partialPath)
+		if err != nil {
+			return err
+		}
+		if d.MD5 != strings.Trim(info.ETag, "\"") {
+			// the corrupted file can't be resumed
+			_ = os.Remove(partialPath)
+			_ = os.Remove(statePath)
+			return fmt.Errorf("etag of the downloaded file mismatch, expected %s, actual %s", info.ETag, d.MD5)
+		}
+	}
+
+	if err := os.Rename(partialPath, localPath); err != nil {
+		return err
+	}
+	_ = os.Remove(statePath)
+
+	return nil
+}
+
+// partMD5 computes the base64 md5 of the section of the file
+func partMD5(f *os.File, offset int64, size int64) (string, error) {
+	h := md5.New()
+	if _, err := io.Copy(h, io.NewSectionReader(f, offset, size)); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// resumableUpload uploads the file by multipart concurrently, the upload id is recorded
+// in the state dir, so the uploaded parts are skipped when retrying the same upload.
+func (mc *MinioClient) resumableUpload(localPath string, bucket, object string) error {
+	opts := mc.Options.withDefaults()
+
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if fi.Size() <= opts.PartSize {
+		md5Base64, err := partMD5(f, 0, fi.Size())
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), MaxTimeOut)
+		defer cancel()
+		_, err = mc.core.PutObject(ctx, bucket, object, io.NewSectionReader(f, 0, fi.Size()), fi.Size(),
+			md5Base64, "", minio.PutObjectOptions{})
+		return err
+	}
+
+	absPath, _ := filepath.Abs(localPath)
+	statePath := filepath.Join(opts.StateDir, hashKey(absPath+"\n"+bucket+"/"+object)+".json")
+
+	uploaded := make(map[int]string)
+	var state uploadState
+	if err := readJSON(statePath, &state); err == nil && state.Size == fi.Size() &&
+		state.ModTime.Equal(fi.ModTime()) && state.PartSize == opts.PartSize {
+		if uploaded, err = mc.listUploadedParts(bucket, object, state.UploadID); err != nil {
+			klog.Warningf("failed to list the uploaded parts of %s/%s, restart the upload: %v", bucket, object, err)
+			state.UploadID = ""
+			uploaded = make(map[int]string)
+		} else {
+			klog.Infof("resume uploading %s to %s/%s", localPath, bucket, object)
+		}
+	} else {
+		state = uploadState{}
+	}
+
+	if state.UploadID == "" {
+		ctx, cancel := context.WithTimeout(context.Background(), MaxTimeOut)
+		uploadID, err := mc.core.NewMultipartUpload(ctx, bucket, object, minio.PutObjectOptions{})
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		state = uploadState{
+			UploadID: uploadID,
+			Size:     fi.Size(),
+			ModTime:  fi.ModTime(),
+			PartSize: opts.PartSize,
+		}
+		if err := os.MkdirAll(opts.StateDir, os.ModePerm); err != nil {
+			return err
+		}
+		if err := writeJSON(statePath, &state); err != nil {
+			return err
+		}
+	}
+
+	numParts := int((fi.Size() + opts.PartSize - 1) / opts.PartSize)
+	var parts []int
+	for i := 1; i <= numParts; i++ {
+		if _, ok := uploaded[i]; !ok {
+			parts = append(parts, i)
+		}
+	}
+
+	var lock sync.Mutex
+	err = parallel(parts, opts.Concurrency, opts.Retries, func(part int) error {
+		offset := int64(part-1) * opts.PartSize
+		size := opts.PartSize
+		if offset+size > fi.Size() {
+			size = fi.Size() - offset
+		}
+
+		md5Base64, err := partMD5(f, offset, size)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), MaxTimeOut)
+		defer cancel()
+		p, err := mc.core.PutObjectPart(ctx, bucket, object, state.UploadID, part,
+			io.NewSectionReader(f, offset, size), size, md5Base64, "", nil)
+		if err != nil {
+			return err
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+		uploaded[part] = p.ETag
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var completeParts []minio.CompletePart
+	for part, etag := range uploaded {
+		completeParts = append(completeParts, minio.CompletePart{PartNumber: part, ETag: etag})
+	}
+	sort.Slice(completeParts, func(i, j int) bool {
+		return completeParts[i].PartNumber < completeParts[j].PartNumber
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), MaxTimeOut)
+	defer cancel()
+	if _, err := mc.core.CompleteMultipartUpload(ctx, bucket, object, state.UploadID, completeParts); err != nil {
+		return err
+	}
+	_ = os.Remove(statePath)
+
+	return nil
+}
+
+// listUploadedParts lists the uploaded parts and their etags of the multipart upload
+func (mc *MinioClient) listUploadedParts(bucket, object, uploadID string) (map[int]string, error) {
+	parts := make(map[int]string)
+	marker := 0
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), MaxTimeOut)
+		result, err := mc.core.ListObjectParts(ctx, bucket, object, uploadID, marker, 1000)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range result.ObjectParts {
+			parts[p.PartNumber] = p.ETag
+		}
+
+		if !result.IsTruncated {
+			return parts, nil
+		}
+		marker = result.NextPartNumberMarker
+	}
+}
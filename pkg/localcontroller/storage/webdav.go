@@ -0,0 +1,212 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	// WebDAVUsernameKey is the credential key of the basic auth username of webdav server
+	WebDAVUsernameKey = "webdav-username"
+	// WebDAVPasswordKey is the credential key of the basic auth password of webdav server
+	WebDAVPasswordKey = "webdav-password"
+)
+
+// webdavBackend reads and writes the files of the webdav server,
+// which is also the generic blob backend of the servers supporting http GET/PUT,
+// e.g. url "webdavs://host/path" is accessed by "https://host/path".
+type webdavBackend struct {
+	client   *http.Client
+	username string
+	password string
+}
+
+// newWebDAVBackend parses the optional webdav keys of the credential
+func newWebDAVBackend(credential map[string]string) (Backend, error) {
+	return &webdavBackend{
+		client:   newHTTPClient(),
+		username: credential[WebDAVUsernameKey],
+		password: credential[WebDAVPasswordKey],
+	}, nil
+}
+
+// httpURL converts the webdav url to the http url
+func (b *webdavBackend) httpURL(objectURL string) (*url.URL, error) {
+	u, err := url.Parse(objectURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url(%s)", objectURL)
+	}
+
+	switch u.Scheme {
+	case WebDAVPrefix:
+		u.Scheme = HTTPPrefix
+	case WebDAVSPrefix:
+		u.Scheme = HTTPSPrefix
+	default:
+		return nil, fmt.Errorf("invalid url(%s)", objectURL)
+	}
+
+	return u, nil
+}
+
+func (b *webdavBackend) authorize(req *http.Request) {
+	if b.username != "" {
+		req.SetBasicAuth(b.username, b.password)
+	}
+}
+
+// do sends the request, and checks the status is one of the expected
+func (b *webdavBackend) do(req *http.Request, expected ...int) error {
+	b.authorize(req)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	for _, code := range expected {
+		if resp.StatusCode == code {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s %s failed, status: %s", req.Method, req.URL.Redacted(), resp.Status)
+}
+
+// makeCollections creates the parent collections of the url if not exist
+func (b *webdavBackend) makeCollections(u *url.URL) error {
+	dir := path.Dir(strings.TrimSuffix(u.Path, "/"))
+	if dir == "/" || dir == "." {
+		return nil
+	}
+
+	parent := *u
+	parent.Path = dir + "/"
+	if err := b.makeCollections(&parent); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("MKCOL", parent.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	// 405 Method Not Allowed means the collection already exists
+	return b.do(req, http.StatusCreated, http.StatusMethodNotAllowed)
+}
+
+func (b *webdavBackend) Download(objectURL string, localPath string) error {
+	u, err := b.httpURL(objectURL)
+	if err != nil {
+		return err
+	}
+
+	return httpDownload(b.client, b.authorize, objectURL, u.String(), localPath)
+}
+
+func (b *webdavBackend) OpenRange(objectURL string, offset int64) (io.ReadCloser, error) {
+	u, err := b.httpURL(objectURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return httpOpenRange(b.client, b.authorize, objectURL, u.String(), offset)
+}
+
+func (b *webdavBackend) Version(objectURL string) (string, error) {
+	u, err := b.httpURL(objectURL)
+	if err != nil {
+		return "", err
+	}
+
+	return httpVersion(b.client, b.authorize, objectURL, u.String())
+}
+
+func (b *webdavBackend) Upload(localPath string, objectURL string) error {
+	u, err := b.httpURL(objectURL)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("file(%s) in the local host is not exists", localPath)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := b.makeCollections(u); err != nil {
+		return fmt.Errorf("upload file from file path(%s) to file url(%s) failed, error: %+v", localPath, objectURL, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, u.String(), f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+
+	if err := b.do(req, http.StatusOK, http.StatusCreated, http.StatusNoContent); err != nil {
+		return fmt.Errorf("upload file from file path(%s) to file url(%s) failed, error: %+v", localPath, objectURL, err)
+	}
+
+	return nil
+}
+
+func (b *webdavBackend) Copy(srcURL string, objectURL string) error {
+	src, err := b.httpURL(srcURL)
+	if err != nil {
+		return err
+	}
+
+	dest, err := b.httpURL(objectURL)
+	if err != nil {
+		return err
+	}
+
+	if src.Host != dest.Host {
+		return fmt.Errorf("copy file across webdav servers(%s, %s) is not supported", src.Host, dest.Host)
+	}
+
+	if err := b.makeCollections(dest); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("COPY", src.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Destination", dest.String())
+	req.Header.Set("Overwrite", "T")
+
+	if err := b.do(req, http.StatusCreated, http.StatusNoContent); err != nil {
+		return fmt.Errorf("copy file from file url(%s) to file url(%s) failed, error: %+v", srcURL, objectURL, err)
+	}
+
+	return nil
+}
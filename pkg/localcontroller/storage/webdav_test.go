@@ -0,0 +1,214 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeWebDAVServer serves the files of the dir with the webdav methods used by the backend
+type fakeWebDAVServer struct {
+	dir      string
+	username string
+	password string
+
+	lock    sync.Mutex
+	methods []string
+}
+
+func (s *fakeWebDAVServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	s.methods = append(s.methods, r.Method+" "+r.URL.Path)
+	s.lock.Unlock()
+
+	if username, password, _ := r.BasicAuth(); username != s.username || password != s.password {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	name := filepath.Join(s.dir, filepath.FromSlash(path.Clean(r.URL.Path)))
+	parentExists := func(p string) bool {
+		info, err := os.Stat(filepath.Dir(p))
+		return err == nil && info.IsDir()
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		f, err := os.Open(name)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		defer f.Close()
+		info, _ := f.Stat()
+		http.ServeContent(w, r, name, info.ModTime(), f)
+	case "MKCOL":
+		if _, err := os.Stat(name); err == nil {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		} else if !parentExists(name) {
+			w.WriteHeader(http.StatusConflict)
+		} else if err := os.Mkdir(name, 0755); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case http.MethodPut:
+		if !parentExists(name) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		if err := ioutil.WriteFile(name, data, 0644); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	case "COPY":
+		dest, err := url.Parse(r.Header.Get("Destination"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		destName := filepath.Join(s.dir, filepath.FromSlash(path.Clean(dest.Path)))
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !parentExists(destName) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if err := ioutil.WriteFile(destName, data, 0644); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestWebDAVRoundTrip(t *testing.T) {
+	fake := &fakeWebDAVServer{dir: t.TempDir(), username: "user", password: "password"}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	base := strings.Replace(server.URL, HTTPPrefix+"://", WebDAVPrefix+"://", 1)
+
+	s := &Storage{}
+	if err := s.SetCredential(`{"webdav-username":"user","webdav-password":"password"}`); err != nil {
+		t.Fatal(err)
+	}
+
+	content := "line1\nline2\n"
+	localPath := filepath.Join(t.TempDir(), "index.txt")
+	if err := ioutil.WriteFile(localPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the parent collections are created by the upload
+	if err := s.Upload(localPath, base+"/data/train/index.txt"); err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(fake.dir, "data", "train", "index.txt")); err != nil || string(data) != content {
+		t.Fatalf("unexpected uploaded file %q, %v", data, err)
+	}
+
+	// the same collections exist at the second upload
+	if err := s.Upload(localPath, base+"/data/train/index2.txt"); err != nil {
+		t.Fatalf("failed to upload again: %v", err)
+	}
+
+	// the objects of the same server are copied by the server
+	if err := s.Upload(base+"/data/train/index.txt", base+"/backup/index.txt"); err != nil {
+		t.Fatalf("failed to copy: %v", err)
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(fake.dir, "backup", "index.txt")); err != nil || string(data) != content {
+		t.Fatalf("unexpected copied file %q, %v", data, err)
+	}
+
+	b, err := s.backend(WebDAVPrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	downloaded := filepath.Join(t.TempDir(), "downloaded.txt")
+	if err := b.Download(base+"/backup/index.txt", downloaded); err != nil {
+		t.Fatalf("failed to download: %v", err)
+	}
+	if data, _ := ioutil.ReadFile(downloaded); string(data) != content {
+		t.Errorf("expected %q downloaded, got %q", content, data)
+	}
+
+	reader, err := b.(RangeReader).OpenRange(base+"/backup/index.txt", 6)
+	if err != nil {
+		t.Fatalf("failed to open range: %v", err)
+	}
+	data, _ := ioutil.ReadAll(reader)
+	reader.Close()
+	if string(data) != "line2\n" {
+		t.Errorf("expected the content after the offset, got %q", data)
+	}
+
+	if version, err := b.(Versioner).Version(base + "/backup/index.txt"); err != nil || version == "" {
+		t.Errorf("expected the version of the file, got %q, %v", version, err)
+	}
+
+	if err := b.Download(base+"/missing.txt", downloaded); err == nil {
+		t.Errorf("expected error of missing file")
+	}
+	if err := b.Copy(base+"/backup/index.txt", "webdav://other:8080/index.txt"); err == nil {
+		t.Errorf("expected error of copy across servers")
+	}
+	if err := b.Copy(base+"/backup/index.txt", "s3://bucket/index.txt"); err == nil {
+		t.Errorf("expected error of the url not webdav")
+	}
+}
+
+func TestWebDAVUnauthorized(t *testing.T) {
+	fake := &fakeWebDAVServer{dir: t.TempDir(), username: "user", password: "password"}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	base := strings.Replace(server.URL, HTTPPrefix+"://", WebDAVPrefix+"://", 1)
+
+	localPath := filepath.Join(t.TempDir(), "index.txt")
+	if err := ioutil.WriteFile(localPath, []byte("line1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := newWebDAVBackend(map[string]string{WebDAVUsernameKey: "user", WebDAVPasswordKey: "wrong"})
+	if err := b.Upload(localPath, base+"/data/index.txt"); err == nil {
+		t.Errorf("expected error of the wrong password")
+	}
+	if err := b.Download(base+"/data/index.txt", localPath); err == nil {
+		t.Errorf("expected error of the wrong password")
+	}
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+	if len(fake.methods) == 0 || fake.methods[0] != "MKCOL /data/" {
+		t.Errorf("expected the collection created first, got %v", fake.methods)
+	}
+}
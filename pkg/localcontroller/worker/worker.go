@@ -46,3 +46,44 @@ const (
 	// FailedStatus is the failed status about worker
 	FailedStatus = "failed"
 )
+
+// StreamFrame is a frame of the stream between a worker and LC, which is sent as a JSON text message.
+// The worker sends the messages and LC sends the commands, each of which is acknowledged with its ID.
+type StreamFrame struct {
+	// Type is the frame type, include message/command/ack
+	Type string `json:"type"`
+	// ID is the sequence number of the message or the command of the sender
+	ID uint64 `json:"id,omitempty"`
+	// Message is the worker message of the message frame
+	Message *MessageContent `json:"message,omitempty"`
+	// Command is the LC command of the command frame
+	Command *Command `json:"command,omitempty"`
+	// Error is why the message is rejected, in the ack frame
+	Error string `json:"error,omitempty"`
+}
+
+// Command defines the command sent from LC to workers
+type Command struct {
+	// Name is the command name, e.g. update-model/stop/update-hem/upload-hard-examples
+	Name string `json:"name"`
+	// Parameters are the parameters of the command
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+const (
+	// MessageFrame is the frame of the worker message
+	MessageFrame = "message"
+	// CommandFrame is the frame of the LC command
+	CommandFrame = "command"
+	// AckFrame is the frame acknowledging the message or the command with the same ID
+	AckFrame = "ack"
+
+	// UpdateModelCommand tells the worker a new model is available
+	UpdateModelCommand = "update-model"
+	// StopCommand tells the worker to stop
+	StopCommand = "stop"
+	// UpdateHEMCommand tells the worker to change the parameters of the hard example mining
+	UpdateHEMCommand = "update-hem"
+	// UploadHardExamplesCommand tells the worker to upload the hard examples now
+	UploadHardExamplesCommand = "upload-hard-examples"
+)
@@ -0,0 +1,278 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCheckPeriodSeconds is the default interval in seconds between two checks
+	DefaultCheckPeriodSeconds = 60
+)
+
+// Sample is a metric value observed at a check
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// State is the history of a checker, which can be persisted across restarts
+type State struct {
+	LastCheckTime time.Time `json:"lastCheckTime,omitempty"`
+	LastFireTime  time.Time `json:"lastFireTime,omitempty"`
+	// LastResult is true if the condition held at the last check
+	LastResult bool `json:"lastResult,omitempty"`
+	// Consecutive is the number of consecutive checks the condition held
+	Consecutive int `json:"consecutive,omitempty"`
+	// History is the samples of the metrics referenced by rate triggers
+	History map[string][]Sample `json:"history,omitempty"`
+}
+
+// Checker evaluates a trigger at its check period, and keeps the history
+// needed by the stateful triggers: consecutive checks, cooldown and rate.
+type Checker struct {
+	// Period is the interval between two checks
+	Period time.Duration
+	// ConsecutiveChecks is the number of consecutive checks the condition
+	// must hold before firing
+	ConsecutiveChecks int
+	// Cooldown is the minimum interval since the last fire
+	Cooldown time.Duration
+
+	Condition Base
+
+	lock  sync.Mutex
+	state State
+	rates []*RateTrigger
+	now   func() time.Time
+}
+
+// NewChecker creates a checker of the condition
+func NewChecker(condition Base, period time.Duration, consecutiveChecks int, cooldown time.Duration) *Checker {
+	if period <= 0 {
+		period = DefaultCheckPeriodSeconds * time.Second
+	}
+	if consecutiveChecks <= 0 {
+		consecutiveChecks = 1
+	}
+
+	c := &Checker{
+		Period:            period,
+		ConsecutiveChecks: consecutiveChecks,
+		Cooldown:          cooldown,
+		Condition:         condition,
+		state:             State{History: make(map[string][]Sample)},
+		now:               time.Now,
+	}
+
+	walk(condition, func(t Base) {
+		switch t := t.(type) {
+		case *RateTrigger:
+			t.checker = c
+			c.rates = append(c.rates, t)
+		case *TimerRangeTrigger:
+			t.checker = c
+		}
+	})
+
+	return c
+}
+
+// Due returns true if the check period has elapsed since the last check
+func (c *Checker) Due() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.state.LastCheckTime.IsZero() || c.now().Sub(c.state.LastCheckTime) >= c.Period
+}
+
+// Until returns how long until the check is due, zero if it's due now
+func (c *Checker) Until() time.Duration {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.state.LastCheckTime.IsZero() {
+		return 0
+	}
+	if wait := c.Period - c.now().Sub(c.state.LastCheckTime); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// Trigger checks the condition, and returns true when it has held for the
+// consecutive checks and the cooldown since the last fire has elapsed.
+func (c *Checker) Trigger(stats map[string]interface{}) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	c.state.LastCheckTime = now
+	c.record(now, stats)
+
+	c.state.LastResult = c.Condition == nil || c.Condition.Trigger(stats)
+	if !c.state.LastResult {
+		c.state.Consecutive = 0
+		return false
+	}
+
+	c.state.Consecutive++
+	if c.state.Consecutive < c.ConsecutiveChecks {
+		return false
+	}
+
+	if !c.state.LastFireTime.IsZero() && now.Sub(c.state.LastFireTime) < c.Cooldown {
+		return false
+	}
+
+	c.state.Consecutive = 0
+	c.state.LastFireTime = now
+	return true
+}
+
+// record appends the metric values referenced by rate triggers to the history,
+// and drops the samples out of the window
+func (c *Checker) record(now time.Time, stats map[string]interface{}) {
+	windows := make(map[string]time.Duration)
+	for _, rt := range c.rates {
+		if rt.Window > windows[rt.Metric] {
+			windows[rt.Metric] = rt.Window
+		}
+	}
+
+	for metric, window := range windows {
+		samples := c.state.History[metric]
+		if value, ok := metricValue(stats, metric); ok {
+			samples = append(samples, Sample{Time: now, Value: value})
+		}
+
+		i := 0
+		for i < len(samples) && now.Sub(samples[i].Time) > window {
+			i++
+		}
+		c.state.History[metric] = samples[i:]
+	}
+}
+
+// history returns the samples of the metric, the caller must hold the lock
+func (c *Checker) history(metric string) []Sample {
+	return c.state.History[metric]
+}
+
+// State returns the serialized state of the checker
+func (c *Checker) State() ([]byte, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return json.Marshal(c.state)
+}
+
+// Status returns a copy of the state of the checker
+func (c *Checker) Status() State {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	state := c.state
+	state.History = make(map[string][]Sample, len(c.state.History))
+	for metric, samples := range c.state.History {
+		state.History[metric] = append([]Sample(nil), samples...)
+	}
+	return state
+}
+
+// Restore restores the state of the checker serialized by State
+func (c *Checker) Restore(data []byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if state.History == nil {
+		state.History = make(map[string][]Sample)
+	}
+
+	c.state = state
+	return nil
+}
+
+// Inherit takes over the state of the previous checker, used when the trigger is updated
+func (c *Checker) Inherit(previous *Checker) error {
+	state, err := previous.State()
+	if err != nil {
+		return err
+	}
+	return c.Restore(state)
+}
+
+// RateTrigger compares the rate of change per second of the metric over the window
+type RateTrigger struct {
+	Operator  string
+	Metric    string
+	Window    time.Duration
+	Threshold float64
+
+	checker *Checker
+}
+
+func (rt *RateTrigger) Trigger(stats map[string]interface{}) bool {
+	if rt.checker == nil {
+		// the history is only kept by a checker
+		return false
+	}
+
+	samples := rt.checker.history(rt.Metric)
+	if len(samples) < 2 {
+		return false
+	}
+
+	first := samples[0]
+	last := samples[len(samples)-1]
+	elapsed := last.Time.Sub(first.Time).Seconds()
+	if elapsed <= 0 {
+		return false
+	}
+
+	return compare(rt.Operator, (last.Value-first.Value)/elapsed, rt.Threshold)
+}
+
+// walk calls f for each trigger in the tree
+func walk(t Base, f func(Base)) {
+	if t == nil {
+		return
+	}
+
+	f(t)
+	switch t := t.(type) {
+	case *AndTrigger:
+		for _, sub := range t.Triggers {
+			walk(sub, f)
+		}
+	case *OrTrigger:
+		for _, sub := range t.Triggers {
+			walk(sub, f)
+		}
+	case *NotTrigger:
+		walk(t.Operand, f)
+	case *Checker:
+		walk(t.Condition, f)
+	}
+}
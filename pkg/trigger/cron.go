@@ -0,0 +1,191 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed standard 5-field cron expression:
+// minute, hour, day of month, month and day of week.
+type CronSchedule struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+
+	// whether day of month or day of week is restricted,
+	// if both are restricted, either one matching is enough as cron does.
+	daysRestricted     bool
+	weekdaysRestricted bool
+}
+
+type cronField struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{min: 0, max: 59}
+	hourField   = cronField{min: 0, max: 23}
+	dayField    = cronField{min: 1, max: 31}
+	monthField  = cronField{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is also sunday
+	weekdayField = cronField{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+
+	cronMacros = map[string]string{
+		"@hourly":  "0 * * * *",
+		"@daily":   "0 0 * * *",
+		"@weekly":  "0 0 * * 0",
+		"@monthly": "0 0 1 * *",
+		"@yearly":  "0 0 1 1 *",
+	}
+)
+
+// ParseCron parses the standard 5-field cron expression, e.g. "0 1 * * sat,sun",
+// and the macros @hourly, @daily, @weekly, @monthly and @yearly.
+func ParseCron(expr string) (*CronSchedule, error) {
+	if macro, ok := cronMacros[strings.TrimSpace(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q, expected 5 fields", expr)
+	}
+
+	var err error
+	cs := &CronSchedule{}
+	if cs.minutes, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if cs.hours, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if cs.days, err = dayField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if cs.months, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if cs.weekdays, err = weekdayField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if cs.weekdays&(1<<7) != 0 {
+		cs.weekdays |= 1
+	}
+
+	cs.daysRestricted = fields[2] != "*"
+	cs.weekdaysRestricted = fields[4] != "*"
+	return cs, nil
+}
+
+// Match returns true if the minute of t matches the schedule
+func (cs *CronSchedule) Match(t time.Time) bool {
+	if cs.minutes&(1<<uint(t.Minute())) == 0 ||
+		cs.hours&(1<<uint(t.Hour())) == 0 ||
+		cs.months&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	dayMatched := cs.days&(1<<uint(t.Day())) != 0
+	weekdayMatched := cs.weekdays&(1<<uint(t.Weekday())) != 0
+	if cs.daysRestricted && cs.weekdaysRestricted {
+		return dayMatched || weekdayMatched
+	}
+	return dayMatched && weekdayMatched
+}
+
+// ActiveWithin returns true if the schedule matched in the duration up to t
+func (cs *CronSchedule) ActiveWithin(t time.Time, duration time.Duration) bool {
+	t = t.Truncate(time.Minute)
+	for elapsed := time.Duration(0); elapsed < duration; elapsed += time.Minute {
+		if cs.Match(t.Add(-elapsed)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		b, err := f.parsePart(part)
+		if err != nil {
+			return 0, fmt.Errorf("invalid cron field %q: %w", field, err)
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+// parsePart parses "*", "a", "a-b", "*/n" and "a-b/n"
+func (f cronField) parsePart(part string) (uint64, error) {
+	step := 1
+	if i := strings.Index(part, "/"); i != -1 {
+		var err error
+		if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid step %q", part[i+1:])
+		}
+		part = part[:i]
+	}
+
+	start, end := f.min, f.max
+	if part != "*" {
+		var err error
+		bounds := strings.SplitN(part, "-", 2)
+		if start, err = f.value(bounds[0]); err != nil {
+			return 0, err
+		}
+		end = start
+		if len(bounds) == 2 {
+			if end, err = f.value(bounds[1]); err != nil {
+				return 0, err
+			}
+		}
+		if start > end {
+			return 0, fmt.Errorf("invalid range %q", part)
+		}
+	}
+
+	var bits uint64
+	for v := start; v <= end; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+func (f cronField) value(v string) (int, error) {
+	if n, ok := f.names[strings.ToLower(v)]; ok {
+		return n, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("invalid value %q, expected in [%d, %d]", v, f.min, f.max)
+	}
+	return n, nil
+}
@@ -0,0 +1,441 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ParseExpression compiles a boolean trigger expression into a tree of triggers.
+// The grammar is:
+//
+//	expr       := term { ("or" | "||") term }
+//	term       := factor { ("and" | "&&") factor }
+//	factor     := ("not" | "!") factor | "(" expr ")" | comparison | timerange
+//	comparison := (metric | rate) operator number
+//	rate       := "rate" "(" metric "," seconds ")"
+//	timerange  := ("time" | "hour") "in" HH:MM "-" HH:MM
+//
+// e.g. "num_of_samples > 500 or (precision_delta[0] > 0.02 and time in 01:00-05:00)".
+// rate is the change per second of the metric over the window in seconds, which is
+// only evaluated under a Checker keeping the history.
+func ParseExpression(expr string) (Base, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	t, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.eof() {
+		return nil, fmt.Errorf("unexpected token %q in expression %q", p.peek(), expr)
+	}
+	return t, nil
+}
+
+// Metrics returns the sorted metric names referenced by the trigger,
+// with any index suffix removed, e.g. "precision_delta[0]" => "precision_delta".
+func Metrics(t Base) []string {
+	seen := make(map[string]bool)
+	collectMetrics(t, seen)
+
+	var metrics []string
+	for m := range seen {
+		metrics = append(metrics, m)
+	}
+	sort.Strings(metrics)
+	return metrics
+}
+
+func collectMetrics(t Base, seen map[string]bool) {
+	walk(t, func(t Base) {
+		switch t := t.(type) {
+		case *BinaryTrigger:
+			seen[baseMetric(t.Metric)] = true
+		case *RateTrigger:
+			seen[baseMetric(t.Metric)] = true
+		}
+	})
+}
+
+func baseMetric(metric string) string {
+	if left := strings.Index(metric, "["); left != -1 {
+		return strings.TrimSpace(metric[:left])
+	}
+	return strings.TrimSpace(metric)
+}
+
+// ValidateMetrics returns an error if the trigger references a metric
+// that is not one of the known metrics.
+func ValidateMetrics(t Base, known ...string) error {
+	knownSet := make(map[string]bool)
+	for _, k := range known {
+		knownSet[k] = true
+	}
+
+	var unknown []string
+	for _, m := range Metrics(t) {
+		if !knownSet[m] {
+			unknown = append(unknown, m)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown metrics %v, supported metrics are %v", unknown, known)
+	}
+	return nil
+}
+
+// Validate checks the trigger expression, or the metric of the condition when the
+// expression is empty, and that only known metrics are referenced if any are given.
+func Validate(expression string, conditionMetric string, known ...string) error {
+	var t Base
+	if expression != "" {
+		var err error
+		if t, err = ParseExpression(expression); err != nil {
+			return fmt.Errorf("invalid expression %q: %w", expression, err)
+		}
+	} else {
+		if conditionMetric == "" {
+			return fmt.Errorf("either expression or condition must be specified")
+		}
+		t = &BinaryTrigger{Metric: conditionMetric}
+	}
+
+	if len(known) == 0 {
+		return nil
+	}
+	return ValidateMetrics(t, known...)
+}
+
+// Evaluate is like t.Trigger(stats), but returns an error instead of false
+// when the trigger references a metric missing in stats.
+func Evaluate(t Base, stats map[string]interface{}) (bool, error) {
+	var known []string
+	for k := range stats {
+		known = append(known, k)
+	}
+	sort.Strings(known)
+
+	if err := ValidateMetrics(t, known...); err != nil {
+		return false, err
+	}
+	return t.Trigger(stats), nil
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() string {
+	if p.eof() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *parser) accept(candidates ...string) bool {
+	t := strings.ToLower(p.peek())
+	for _, c := range candidates {
+		if t == c {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseOr() (Base, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	triggers := []Base{left}
+	for p.accept("or", "||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		triggers = append(triggers, right)
+	}
+
+	if len(triggers) == 1 {
+		return left, nil
+	}
+	return &OrTrigger{Triggers: triggers}, nil
+}
+
+func (p *parser) parseAnd() (Base, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	triggers := []Base{left}
+	for p.accept("and", "&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		triggers = append(triggers, right)
+	}
+
+	if len(triggers) == 1 {
+		return left, nil
+	}
+	return &AndTrigger{Triggers: triggers}, nil
+}
+
+func (p *parser) parseUnary() (Base, error) {
+	if p.accept("not", "!") {
+		t, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotTrigger{Operand: t}, nil
+	}
+
+	if p.accept("(") {
+		t, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing ')' at %q", p.peek())
+		}
+		return t, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Base, error) {
+	if p.eof() {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	name := p.next()
+	if !isIdentifier(name) {
+		return nil, fmt.Errorf("expected metric name, got %q", name)
+	}
+
+	lower := strings.ToLower(name)
+	if (lower == "time" || lower == "hour") && strings.ToLower(p.peek()) == "in" {
+		p.next()
+		return p.parseTimeRange()
+	}
+
+	var window time.Duration
+	isRate := lower == "rate" && p.peek() == "("
+	if isRate {
+		var err error
+		if name, window, err = p.parseRate(); err != nil {
+			return nil, err
+		}
+	}
+
+	op := p.next()
+	if !isOperator(op) {
+		return nil, fmt.Errorf("invalid operator %q for metric %q", op, name)
+	}
+
+	value := p.next()
+	threshold, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold %q for metric %q", value, name)
+	}
+
+	if isRate {
+		return &RateTrigger{
+			Operator:  op,
+			Metric:    name,
+			Window:    window,
+			Threshold: threshold,
+		}, nil
+	}
+
+	return &BinaryTrigger{
+		Operator:  op,
+		Metric:    name,
+		Threshold: threshold,
+	}, nil
+}
+
+func (p *parser) parseRate() (string, time.Duration, error) {
+	// skip "("
+	p.next()
+
+	metric := p.next()
+	if !isIdentifier(metric) {
+		return "", 0, fmt.Errorf("expected metric name in rate, got %q", metric)
+	}
+
+	if !p.accept(",") {
+		return "", 0, fmt.Errorf("expected ',' after metric %q in rate", metric)
+	}
+
+	value := p.next()
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return "", 0, fmt.Errorf("invalid window %q of rate, expected positive seconds", value)
+	}
+
+	if !p.accept(")") {
+		return "", 0, fmt.Errorf("missing ')' in rate of metric %q", metric)
+	}
+
+	return metric, time.Duration(seconds) * time.Second, nil
+}
+
+func (p *parser) parseTimeRange() (Base, error) {
+	start := p.next()
+	if !p.accept("-") {
+		return nil, fmt.Errorf("invalid time range at %q, expected HH:MM-HH:MM", start)
+	}
+	end := p.next()
+
+	for _, v := range []string{start, end} {
+		if err := validateClock(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return &TimerRangeTrigger{
+		Start: start,
+		End:   end,
+	}, nil
+}
+
+func validateClock(v string) error {
+	if len(v) != 5 || v[2] != ':' {
+		return fmt.Errorf("invalid time %q, expected HH:MM", v)
+	}
+	hour, err1 := strconv.Atoi(v[:2])
+	minute, err2 := strconv.Atoi(v[3:])
+	if err1 != nil || err2 != nil || hour > 23 || minute > 59 {
+		return fmt.Errorf("invalid time %q, expected HH:MM", v)
+	}
+	return nil
+}
+
+func isOperator(op string) bool {
+	switch op {
+	case "gt", ">", "ge", ">=", "eq", "=", "==", "ne", "!=", "le", "<=", "lt", "<":
+		return true
+	}
+	return false
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	r := rune(s[0])
+	return unicode.IsLetter(r) || r == '_'
+}
+
+// tokenize splits the expression into identifiers (including an optional
+// "[index]" suffix), numbers, clock times, operators and parentheses.
+func tokenize(expr string) ([]string, error) {
+	var tokens []string
+	i := 0
+	for i < len(expr) {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '-' && i+1 < len(expr) && isNumberStart(expr[i+1]) &&
+			len(tokens) > 0 && isOperator(tokens[len(tokens)-1]):
+			// the sign of the negative threshold, e.g. "delta > -0.5"
+			j := scanNumber(expr, i+1)
+			tokens = append(tokens, expr[i:j])
+			i = j
+		case c == '(' || c == ')' || c == '-' || c == ',':
+			tokens = append(tokens, string(c))
+			i++
+		case strings.HasPrefix(expr[i:], "&&"), strings.HasPrefix(expr[i:], "||"),
+			strings.HasPrefix(expr[i:], ">="), strings.HasPrefix(expr[i:], "<="),
+			strings.HasPrefix(expr[i:], "=="), strings.HasPrefix(expr[i:], "!="):
+			tokens = append(tokens, expr[i:i+2])
+			i += 2
+		case c == '>' || c == '<' || c == '=' || c == '!':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(expr) && (expr[j] == '_' || unicode.IsLetter(rune(expr[j])) || unicode.IsDigit(rune(expr[j]))) {
+				j++
+			}
+			if j < len(expr) && expr[j] == '[' {
+				end := strings.Index(expr[j:], "]")
+				if end == -1 {
+					return nil, fmt.Errorf("missing ']' in %q", expr[i:])
+				}
+				j += end + 1
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		case isNumberStart(c):
+			j := scanNumber(expr, i)
+			tokens = append(tokens, expr[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("invalid character %q in expression %q", c, expr)
+		}
+	}
+	return tokens, nil
+}
+
+func isNumberStart(c byte) bool {
+	return unicode.IsDigit(rune(c)) || c == '.'
+}
+
+// scanNumber returns the end of the number or the clock starting at i,
+// the sign of the exponent is part of the number, e.g. "1e-3"
+func scanNumber(expr string, i int) int {
+	j := i
+	for j < len(expr) {
+		c := expr[j]
+		isExponentSign := (c == '-' || c == '+') && j > i && (expr[j-1] == 'e' || expr[j-1] == 'E')
+		if !unicode.IsDigit(rune(c)) && c != '.' && c != ':' && c != 'e' && c != 'E' && !isExponentSign {
+			break
+		}
+		j++
+	}
+	return j
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TimerDaily is the type of daily range, e.g. [23:00, 01:00],
+	// which can be limited to some weekdays.
+	TimerDaily = "daily"
+	// TimerWeekly is the type of weekly range, e.g. [Sat 00:00, Sun 23:59]
+	TimerWeekly = "weekly"
+	// TimerMonthly is the type of monthly range by day of month, e.g. [01 00:00, 03 06:00]
+	TimerMonthly = "monthly"
+	// TimerCron is the type of window opened by the cron expression for a duration
+	TimerCron = "cron"
+
+	minutesPerDay = 24 * 60
+)
+
+// TimerConfig is the config of the timer range trigger
+type TimerConfig struct {
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+	Type  string `json:"type,omitempty"`
+	// Timezone is the IANA time zone name, e.g. "Asia/Shanghai", the local zone of LC if empty
+	Timezone string `json:"timezone,omitempty"`
+	// Weekdays limits the daily range to the weekdays, e.g. ["Sat", "Sun"]
+	Weekdays []string `json:"weekdays,omitempty"`
+	// Cron is the standard 5-field cron expression opening the window of cron type
+	Cron string `json:"cron,omitempty"`
+	// DurationSeconds is the length of the window opened by the cron expression
+	DurationSeconds int `json:"durationSeconds,omitempty"`
+}
+
+// TimerRangeTrigger fires when the current time is in the range
+type TimerRangeTrigger struct {
+	Start string
+	End   string
+	Type  string
+
+	Location *time.Location
+	Weekdays map[time.Weekday]bool
+	Cron     *CronSchedule
+	Duration time.Duration
+
+	// start and end of weekly/monthly range in minutes
+	startMinutes int
+	endMinutes   int
+
+	checker *Checker
+}
+
+// NewTimerRangeTrigger validates the config and creates the timer range trigger
+func NewTimerRangeTrigger(config TimerConfig) (*TimerRangeTrigger, error) {
+	tt := &TimerRangeTrigger{
+		Start: config.Start,
+		End:   config.End,
+		Type:  config.Type,
+	}
+
+	if config.Timezone != "" {
+		loc, err := time.LoadLocation(config.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", config.Timezone, err)
+		}
+		tt.Location = loc
+	}
+
+	if len(config.Weekdays) > 0 {
+		tt.Weekdays = make(map[time.Weekday]bool)
+		for _, d := range config.Weekdays {
+			wd, err := parseWeekday(d)
+			if err != nil {
+				return nil, err
+			}
+			tt.Weekdays[wd] = true
+		}
+	}
+
+	var err error
+	switch config.Type {
+	case "", TimerDaily:
+		for _, v := range []string{config.Start, config.End} {
+			if err := validateClock(v); err != nil {
+				return nil, err
+			}
+		}
+	case TimerWeekly:
+		if tt.startMinutes, err = parseWeeklyTime(config.Start); err != nil {
+			return nil, err
+		}
+		if tt.endMinutes, err = parseWeeklyTime(config.End); err != nil {
+			return nil, err
+		}
+	case TimerMonthly:
+		if tt.startMinutes, err = parseMonthlyTime(config.Start); err != nil {
+			return nil, err
+		}
+		if tt.endMinutes, err = parseMonthlyTime(config.End); err != nil {
+			return nil, err
+		}
+	case TimerCron:
+		if tt.Cron, err = ParseCron(config.Cron); err != nil {
+			return nil, err
+		}
+		if config.DurationSeconds <= 0 {
+			return nil, fmt.Errorf("durationSeconds must be positive for cron timer")
+		}
+		tt.Duration = time.Duration(config.DurationSeconds) * time.Second
+	default:
+		return nil, fmt.Errorf("invalid timer type %q, expected one of %v",
+			config.Type, []string{TimerDaily, TimerWeekly, TimerMonthly, TimerCron})
+	}
+
+	if len(tt.Weekdays) > 0 && config.Type != "" && config.Type != TimerDaily {
+		return nil, fmt.Errorf("weekdays is only supported by %s timer", TimerDaily)
+	}
+
+	return tt, nil
+}
+
+func (tt *TimerRangeTrigger) currentTime() time.Time {
+	var now time.Time
+	if tt.checker != nil {
+		now = tt.checker.now()
+	} else {
+		now = time.Now()
+	}
+
+	if tt.Location != nil {
+		now = now.In(tt.Location)
+	}
+	return now
+}
+
+func (tt *TimerRangeTrigger) Trigger(stats map[string]interface{}) bool {
+	now := tt.currentTime()
+
+	switch tt.Type {
+	case TimerWeekly:
+		v := int(now.Weekday())*minutesPerDay + now.Hour()*60 + now.Minute()
+		return inRange(tt.startMinutes, tt.endMinutes, v)
+	case TimerMonthly:
+		v := now.Day()*minutesPerDay + now.Hour()*60 + now.Minute()
+		return inRange(tt.startMinutes, tt.endMinutes, v)
+	case TimerCron:
+		return tt.Cron != nil && tt.Cron.ActiveWithin(now, tt.Duration)
+	}
+
+	if len(tt.Weekdays) > 0 && !tt.Weekdays[now.Weekday()] {
+		return false
+	}
+
+	v := now.Format("15:04")
+	start := tt.Start
+	end := tt.End
+	if start > end {
+		// for daily type: [23:00, 01:00]
+		return start <= v || v <= end
+	}
+
+	// for daily type: [01:00, 02:00]
+	return start <= v && v <= end
+}
+
+func inRange(start, end, v int) bool {
+	if start > end {
+		// wrap around, e.g. weekly type: [Sat 00:00, Mon 06:00]
+		return start <= v || v <= end
+	}
+	return start <= v && v <= end
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+func parseWeekday(v string) (time.Weekday, error) {
+	wd, ok := weekdayNames[strings.ToLower(strings.TrimSpace(v))]
+	if !ok {
+		return 0, fmt.Errorf("invalid weekday %q", v)
+	}
+	return wd, nil
+}
+
+// clockMinutes converts "HH:MM" to minutes of the day
+func clockMinutes(v string) (int, error) {
+	if err := validateClock(v); err != nil {
+		return 0, err
+	}
+	hour, _ := strconv.Atoi(v[:2])
+	minute, _ := strconv.Atoi(v[3:])
+	return hour*60 + minute, nil
+}
+
+// parseWeeklyTime converts "Mon 15:04" to minutes of the week
+func parseWeeklyTime(v string) (int, error) {
+	fields := strings.Fields(v)
+	if len(fields) != 2 {
+		return 0, fmt.Errorf("invalid weekly time %q, expected e.g. \"Sat 00:00\"", v)
+	}
+
+	wd, err := parseWeekday(fields[0])
+	if err != nil {
+		return 0, err
+	}
+	minutes, err := clockMinutes(fields[1])
+	if err != nil {
+		return 0, err
+	}
+	return int(wd)*minutesPerDay + minutes, nil
+}
+
+// parseMonthlyTime converts "02 15:04" to minutes of the month
+func parseMonthlyTime(v string) (int, error) {
+	fields := strings.Fields(v)
+	if len(fields) != 2 {
+		return 0, fmt.Errorf("invalid monthly time %q, expected e.g. \"01 00:00\"", v)
+	}
+
+	day, err := strconv.Atoi(fields[0])
+	if err != nil || day < 1 || day > 31 {
+		return 0, fmt.Errorf("invalid day of month in %q", v)
+	}
+	minutes, err := clockMinutes(fields[1])
+	if err != nil {
+		return 0, err
+	}
+	return day*minutesPerDay + minutes, nil
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package trigger implements the train and deploy triggers of the jobs,
+// which are validated by GM and checked by LC.
+package trigger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NumOfSamplesMetric is the metric of the number of new samples, provided to train triggers
+const NumOfSamplesMetric = "num_of_samples"
+
+type Base interface {
+	Trigger(stats map[string]interface{}) bool
+}
+
+type BinaryTrigger struct {
+	Operator  string
+	Metric    string
+	Threshold float64
+}
+
+func convertFloat(v interface{}) (float64, error) {
+	var realValue float64
+	var err error
+	switch v := v.(type) {
+	case int:
+		realValue = float64(v)
+	case float32:
+		realValue = float64(v)
+	case float64:
+		realValue = v
+	case string:
+		realValue, err = strconv.ParseFloat(v, 64)
+	}
+	return realValue, err
+}
+
+// metricValue gets the float value of the metric from stats, e.g. metric = precisions[3]
+func metricValue(stats map[string]interface{}, metric string) (float64, bool) {
+	var value interface{}
+	var ok bool
+	left := strings.Index(metric, "[")
+	if left == -1 {
+		value, ok = stats[metric]
+		if !ok {
+			return 0, false
+		}
+	} else {
+		// e.g. metric = precisions[3]
+		right := strings.LastIndex(metric, "]")
+		topMetric := strings.TrimSpace(metric[:left])
+		topValue, ok := stats[topMetric]
+		if !ok {
+			return 0, false
+		}
+
+		// only support the integer index
+		subMetric := metric[left+1 : right]
+		idx, err := strconv.Atoi(subMetric)
+		if err != nil {
+			return 0, false
+		}
+
+		s := reflect.ValueOf(topValue)
+		switch s.Kind() {
+		case reflect.Slice, reflect.Array:
+		default:
+			return 0, false
+		}
+
+		if idx >= s.Len() {
+			return 0, false
+		}
+		value = s.Index(idx).Interface()
+	}
+	realValue, err := convertFloat(value)
+	if err != nil {
+		return 0, false
+	}
+	return realValue, true
+}
+
+func compare(operator string, realValue float64, threshold float64) bool {
+	isEqual := math.Abs(realValue-threshold) < 1e-6
+
+	switch operator {
+	case "gt", ">":
+		return !isEqual && realValue > threshold
+	case "ge", ">=":
+		return isEqual || realValue >= threshold
+	case "eq", "=", "==":
+		return isEqual
+	case "ne", "!=":
+		return !isEqual
+	case "le", "<=":
+		return isEqual || realValue <= threshold
+	case "lt", "<":
+		return !isEqual && realValue < threshold
+	default:
+		return false
+	}
+}
+
+func (bt *BinaryTrigger) Trigger(stats map[string]interface{}) bool {
+	realValue, ok := metricValue(stats, bt.Metric)
+	if !ok {
+		return false
+	}
+
+	return compare(bt.Operator, realValue, bt.Threshold)
+}
+
+type AndTrigger struct {
+	Triggers []Base
+}
+
+func (at *AndTrigger) Trigger(stats map[string]interface{}) bool {
+	for _, t := range at.Triggers {
+		if !t.Trigger(stats) {
+			return false
+		}
+	}
+	return true
+}
+
+type OrTrigger struct {
+	Triggers []Base
+}
+
+func (ot *OrTrigger) Trigger(stats map[string]interface{}) bool {
+	for _, t := range ot.Triggers {
+		if t.Trigger(stats) {
+			return true
+		}
+	}
+	return false
+}
+
+type NotTrigger struct {
+	Operand Base
+}
+
+func (nt *NotTrigger) Trigger(stats map[string]interface{}) bool {
+	return !nt.Operand.Trigger(stats)
+}
+
+func newAndTrigger(triggers ...Base) *AndTrigger {
+	var valid []Base
+	for _, t := range triggers {
+		if t != nil {
+			valid = append(valid, t)
+		}
+	}
+	return &AndTrigger{
+		Triggers: valid,
+	}
+}
+
+// NewTrigger creates the checker of the trigger config
+func NewTrigger(trigger map[string]interface{}) (*Checker, error) {
+	checkPeriodSeconds, err := intValue(trigger, "checkPeriodSeconds", DefaultCheckPeriodSeconds)
+	if err != nil {
+		return nil, err
+	}
+	consecutiveChecks, err := intValue(trigger, "consecutiveChecks", 1)
+	if err != nil {
+		return nil, err
+	}
+	cooldownSeconds, err := intValue(trigger, "cooldownSeconds", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditionTrigger Base
+	if exprVal, ok := trigger["expression"]; ok && exprVal != "" {
+		expr, ok := exprVal.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid expression value:%v", exprVal)
+		}
+
+		conditionTrigger, err = ParseExpression(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression %q: %w", expr, err)
+		}
+	} else if condVal, ok := trigger["condition"]; ok {
+		cond, ok := condVal.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid condition value:%v",
+				condVal)
+		}
+
+		threshold, err := convertFloat(cond["threshold"])
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold value:%v", cond["threshold"])
+		}
+		conditionTrigger = &BinaryTrigger{
+			Operator:  cond["operator"].(string),
+			Metric:    cond["metric"].(string),
+			Threshold: threshold,
+		}
+	}
+	var timerTrigger Base
+	if timerVal, ok := trigger["timer"]; ok {
+		var config TimerConfig
+		switch t := timerVal.(type) {
+		case map[string]interface{}, map[string]string:
+			var data []byte
+			if data, err = json.Marshal(t); err == nil {
+				err = json.Unmarshal(data, &config)
+			}
+		default:
+			err = fmt.Errorf("invalid timer %v", timerVal)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid timer %v: %w", timerVal, err)
+		}
+
+		if timerTrigger, err = NewTimerRangeTrigger(config); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewChecker(newAndTrigger(timerTrigger, conditionTrigger),
+		time.Duration(checkPeriodSeconds)*time.Second,
+		consecutiveChecks,
+		time.Duration(cooldownSeconds)*time.Second), nil
+}
+
+// intValue gets the integer value of the key in the trigger config
+func intValue(trigger map[string]interface{}, key string, defaultValue int) (int, error) {
+	v, ok := trigger[key]
+	if !ok {
+		return defaultValue, nil
+	}
+
+	value, err := convertFloat(v)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid %s value:%v", key, v)
+	}
+	if value == 0 {
+		return defaultValue, nil
+	}
+	return int(value), nil
+}
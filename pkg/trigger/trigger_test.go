@@ -0,0 +1,539 @@
+/*
+Copyright 2021 The KubeEdge Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import "testing"
+import "time"
+
+func TestTimerInRange(t *testing.T) {
+	now := time.Now()
+	hour, _ := time.ParseDuration("1h")
+
+	var timerTest = []struct {
+		start    time.Time
+		end      time.Time
+		expected bool
+	}{
+		{now.Add(-hour), now.Add(hour), true},
+		{now.Add(-2 * hour), now.Add(-hour), false},
+		{now.Add(hour), now.Add(2 * hour), false},
+		{now.Add(hour), now.Add(-hour), false},
+		{now.Add(2 * hour), now.Add(hour), true},
+	}
+
+	for _, tt := range timerTest {
+		var timer = make(map[string]string)
+		timer["start"] = tt.start.Format("15:04")
+		timer["end"] = tt.end.Format("15:04")
+
+		triggerExpr := map[string]interface{}{
+			"timer": timer,
+		}
+		tg, _ := NewTrigger(triggerExpr)
+		stats := make(map[string]interface{})
+		if tg.Trigger(stats) != tt.expected {
+			t.Errorf("failed to trigger timer %v, expected=%v", timer, tt.expected)
+		}
+	}
+}
+
+func TestCondition(t *testing.T) {
+	metric := "numOfSamples"
+	stats := map[string]interface{}{
+		metric: 500,
+	}
+	var samplesTest = []struct {
+		operator  string
+		threshold interface{}
+		expected  bool
+	}{
+		{"gt", 499, true},
+		{">", 499, true},
+		{"gt", 500, false},
+		{">", 500, false},
+		{"ge", 501, false},
+		{">=", 501, false},
+		{"ge", 500, true},
+		{">=", 500, true},
+		{"eq", 500, true},
+		{"=", 500, true},
+		{"=", 501, false},
+		{"!=", 500, false},
+		{"!=", 501, true},
+		{"lt", 501, true},
+		{"<", 501, true},
+		{"lt", 500, false},
+		{"<", 500, false},
+		{"le", 501, true},
+		{"<=", 501, true},
+		{"le", 500, true},
+		{"<=", 500, true},
+		{"le", 499, false},
+		{"<=", 499, false},
+	}
+
+	for _, st := range samplesTest {
+		var condition = make(map[string]interface{})
+		condition["operator"] = st.operator
+		condition["metric"] = metric
+		condition["threshold"] = st.threshold
+
+		trigger := map[string]interface{}{
+			"condition": condition,
+		}
+		tg, _ := NewTrigger(trigger)
+		if st.expected != tg.Trigger(stats) {
+			t.Errorf("failed to trigger condition:%+v, expected:%v", condition, st.expected)
+		}
+	}
+}
+
+func TestIndexCondition(t *testing.T) {
+	metric := "pricision_delta"
+	subMetric := metric + "[1]"
+
+	stats := map[string]interface{}{
+		metric: []float32{0.1, 0.2},
+	}
+	var samplesTest = []struct {
+		operator  string
+		threshold float32
+		expected  bool
+	}{
+		{"gt", 0.3, false},
+		{"gt", 0.2, false},
+		{"gt", 0.1, true},
+		{"ge", 0.2, true},
+	}
+
+	for _, st := range samplesTest {
+		var condition = make(map[string]interface{})
+		condition["operator"] = st.operator
+		condition["metric"] = subMetric
+		condition["threshold"] = st.threshold
+
+		trigger := map[string]interface{}{
+			"condition": condition,
+		}
+		tg, _ := NewTrigger(trigger)
+		if st.expected != tg.Trigger(stats) {
+			t.Errorf("failed to trigger condition:%+v, expected:%v", condition, st.expected)
+		}
+	}
+}
+
+func TestTimerAndCondition(t *testing.T) {
+	now := time.Now()
+	hour, _ := time.ParseDuration("1h")
+	metric := "numOfSamples"
+	stats := map[string]interface{}{
+		metric: 500,
+	}
+	var samplesTest = []struct {
+		start     time.Time
+		end       time.Time
+		operator  string
+		threshold int
+		expected  bool
+	}{
+		{now.Add(-hour), now.Add(hour), "ge", 500, true},
+		{now.Add(-hour), now.Add(hour), "ge", 600, false},
+		{now.Add(-2 * hour), now.Add(-hour), "ge", 500, false},
+	}
+	for _, st := range samplesTest {
+		var timer = make(map[string]interface{})
+		timer["start"] = st.start.Format("15:04")
+		timer["end"] = st.end.Format("15:04")
+		var condition = make(map[string]interface{})
+		condition["operator"] = st.operator
+		condition["metric"] = metric
+		condition["threshold"] = st.threshold
+
+		trigger := map[string]interface{}{
+			"timer":     timer,
+			"condition": condition,
+		}
+		tg, _ := NewTrigger(trigger)
+		if tg.Trigger(stats) != st.expected {
+			t.Errorf("failed to trigger %v, expected=%v", trigger, st.expected)
+		}
+	}
+}
+
+func TestExpression(t *testing.T) {
+	now := time.Now()
+	hour, _ := time.ParseDuration("1h")
+	inRange := now.Add(-hour).Format("15:04") + "-" + now.Add(hour).Format("15:04")
+	outRange := now.Add(hour).Format("15:04") + "-" + now.Add(2*hour).Format("15:04")
+
+	stats := map[string]interface{}{
+		"num_of_samples":  300,
+		"precision_delta": []float64{0.03, 0.01},
+		"loss":            0.0005,
+		"delta":           -0.2,
+	}
+	var exprTest = []struct {
+		expr     string
+		expected bool
+	}{
+		{"num_of_samples > 500", false},
+		{"num_of_samples >= 300", true},
+		{"not num_of_samples > 500", true},
+		{"!(num_of_samples > 500)", true},
+		{"num_of_samples > 500 or precision_delta[0] > 0.02", true},
+		{"num_of_samples > 500 || precision_delta[1] > 0.02", false},
+		{"num_of_samples > 100 and precision_delta[1] > 0.02", false},
+		{"num_of_samples > 100 && precision_delta[1] gt -0.5", true},
+		{"loss < 1e-3", true},
+		{"loss < 1E-4 or loss > 1e+0", false},
+		{"delta > -0.5", true},
+		{"delta < -1e-2 and not delta<-0.5", true},
+		{"num_of_samples > 500 or (precision_delta[0] > 0.02 and time in " + inRange + ")", true},
+		{"num_of_samples > 500 or (precision_delta[0] > 0.02 and hour in " + outRange + ")", false},
+		{"num_of_samples > 500 OR NOT time in " + outRange, true},
+	}
+
+	for _, et := range exprTest {
+		tg, err := NewTrigger(map[string]interface{}{
+			"expression": et.expr,
+		})
+		if err != nil {
+			t.Errorf("failed to parse expression %q: %v", et.expr, err)
+			continue
+		}
+		if tg.Trigger(stats) != et.expected {
+			t.Errorf("failed to trigger expression %q, expected=%v", et.expr, et.expected)
+		}
+	}
+}
+
+func TestInvalidExpression(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"num_of_samples >",
+		"num_of_samples ~ 3",
+		"(num_of_samples > 3",
+		"num_of_samples > 3 and",
+		"num_of_samples > 3 num_of_samples < 5",
+		"time in 25:00-01:00",
+		"time in 01:00",
+	} {
+		if _, err := ParseExpression(expr); err == nil {
+			t.Errorf("expected error for expression %q", expr)
+		}
+	}
+}
+
+func TestValidateMetrics(t *testing.T) {
+	tg, err := ParseExpression("num_of_samples > 500 or not (precision_delta[0] > 0.02 and recall > 0.5)")
+	if err != nil {
+		t.Fatalf("failed to parse expression: %v", err)
+	}
+
+	metrics := Metrics(tg)
+	if len(metrics) != 3 || metrics[0] != "num_of_samples" || metrics[1] != "precision_delta" || metrics[2] != "recall" {
+		t.Errorf("unexpected metrics %v", metrics)
+	}
+
+	if err := ValidateMetrics(tg, NumOfSamplesMetric, "precision_delta", "recall"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateMetrics(tg, NumOfSamplesMetric); err == nil {
+		t.Errorf("expected error for unknown metrics")
+	}
+
+	if _, err := Evaluate(tg, map[string]interface{}{NumOfSamplesMetric: 600}); err == nil {
+		t.Errorf("expected error for missing metrics")
+	}
+	if err := Validate("", "num_of_sample", NumOfSamplesMetric); err == nil {
+		t.Errorf("expected error for unknown condition metric")
+	}
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Step(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestCheckerPeriod(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	tg, err := NewTrigger(map[string]interface{}{
+		"checkPeriodSeconds": float64(30),
+		"expression":         "num_of_samples > 10",
+	})
+	if err != nil {
+		t.Fatalf("failed to create trigger: %v", err)
+	}
+	tg.now = clock.Now
+
+	stats := map[string]interface{}{NumOfSamplesMetric: 20}
+	if tg.Until() != 0 {
+		t.Errorf("expected the first check due now, got %v", tg.Until())
+	}
+	if !tg.Due() || !tg.Trigger(stats) {
+		t.Fatalf("expected the first check to be due and fired")
+	}
+
+	clock.Step(10 * time.Second)
+	if tg.Due() {
+		t.Errorf("expected no check due within the period")
+	}
+	if tg.Until() != 20*time.Second {
+		t.Errorf("expected the check due in 20s, got %v", tg.Until())
+	}
+
+	clock.Step(30 * time.Second)
+	if !tg.Due() {
+		t.Errorf("expected check due after the period")
+	}
+	if tg.Until() != 0 {
+		t.Errorf("expected the overdue check due now, got %v", tg.Until())
+	}
+}
+
+func TestCheckerConsecutiveAndCooldown(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	tg, err := NewTrigger(map[string]interface{}{
+		"expression":        "num_of_samples > 10",
+		"consecutiveChecks": 3,
+		"cooldownSeconds":   300,
+	})
+	if err != nil {
+		t.Fatalf("failed to create trigger: %v", err)
+	}
+	tg.now = clock.Now
+
+	check := func(samples int) bool {
+		clock.Step(time.Minute)
+		return tg.Trigger(map[string]interface{}{NumOfSamplesMetric: samples})
+	}
+
+	var checks = []struct {
+		samples  int
+		expected bool
+	}{
+		{20, false},
+		{20, false},
+		// reset the consecutive checks
+		{5, false},
+		{20, false},
+		{20, false},
+		{20, true},
+		// in cooldown
+		{20, false},
+		{20, false},
+		{20, false},
+		{20, false},
+		// out of cooldown, held for 3 checks
+		{20, true},
+	}
+
+	for i, c := range checks {
+		if check(c.samples) != c.expected {
+			t.Errorf("check %d: expected=%v", i, c.expected)
+		}
+		// the condition held even if not fired
+		if state := tg.Status(); state.LastResult != (c.samples > 10) || !state.LastCheckTime.Equal(clock.Now()) {
+			t.Errorf("check %d: unexpected status %+v", i, state)
+		}
+	}
+}
+
+func TestCheckerRateAndState(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	config := map[string]interface{}{
+		"expression": "rate(num_of_samples, 600) > 1",
+	}
+	tg, err := NewTrigger(config)
+	if err != nil {
+		t.Fatalf("failed to create trigger: %v", err)
+	}
+	tg.now = clock.Now
+
+	if tg.Trigger(map[string]interface{}{NumOfSamplesMetric: 0}) {
+		t.Errorf("expected no fire without history")
+	}
+
+	clock.Step(time.Minute)
+	if tg.Trigger(map[string]interface{}{NumOfSamplesMetric: 30}) {
+		t.Errorf("expected no fire with rate 0.5/s")
+	}
+
+	state, err := tg.State()
+	if err != nil {
+		t.Fatalf("failed to get state: %v", err)
+	}
+
+	// restore the history into a new trigger, e.g. after restart
+	restored, err := NewTrigger(config)
+	if err != nil {
+		t.Fatalf("failed to create trigger: %v", err)
+	}
+	restored.now = clock.Now
+	if err := restored.Restore(state); err != nil {
+		t.Fatalf("failed to restore state: %v", err)
+	}
+
+	clock.Step(time.Minute)
+	if !restored.Trigger(map[string]interface{}{NumOfSamplesMetric: 300}) {
+		t.Errorf("expected fire with rate 2.5/s")
+	}
+
+	// the samples out of the window are dropped
+	clock.Step(20 * time.Minute)
+	if restored.Trigger(map[string]interface{}{NumOfSamplesMetric: 2000}) {
+		t.Errorf("expected no fire with history out of the window")
+	}
+}
+
+func TestCheckerInherit(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	newChecker := func(expression string) *Checker {
+		tg, err := NewTrigger(map[string]interface{}{
+			"expression":        expression,
+			"consecutiveChecks": 2,
+			"cooldownSeconds":   300,
+		})
+		if err != nil {
+			t.Fatalf("failed to create trigger: %v", err)
+		}
+		tg.now = clock.Now
+		return tg
+	}
+
+	tg := newChecker("num_of_samples > 100")
+	clock.Step(time.Minute)
+	if tg.Trigger(map[string]interface{}{NumOfSamplesMetric: 200}) {
+		t.Errorf("expected no fire before the consecutive checks")
+	}
+
+	// the threshold is updated, the consecutive checks are kept
+	updated := newChecker("num_of_samples > 50")
+	if err := updated.Inherit(tg); err != nil {
+		t.Fatalf("failed to inherit state: %v", err)
+	}
+	clock.Step(time.Minute)
+	if !updated.Trigger(map[string]interface{}{NumOfSamplesMetric: 60}) {
+		t.Errorf("expected fire with the new threshold held for 2 checks")
+	}
+
+	// the cooldown since the last fire is kept
+	again := newChecker("num_of_samples > 10")
+	if err := again.Inherit(updated); err != nil {
+		t.Fatalf("failed to inherit state: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		clock.Step(time.Minute)
+		if again.Trigger(map[string]interface{}{NumOfSamplesMetric: 60}) {
+			t.Errorf("check %d: expected no fire in cooldown", i)
+		}
+	}
+}
+
+func TestTimerTypes(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+
+	// 2021-10-16 is Saturday
+	saturday := time.Date(2021, 10, 16, 2, 30, 0, 0, shanghai)
+
+	var timerTest = []struct {
+		name     string
+		timer    map[string]interface{}
+		now      time.Time
+		expected bool
+	}{
+		{"daily in timezone", map[string]interface{}{
+			"start": "01:00", "end": "05:00", "timezone": "Asia/Shanghai",
+		}, saturday, true},
+		{"daily out of timezone", map[string]interface{}{
+			"start": "01:00", "end": "05:00", "timezone": "Europe/London",
+		}, saturday, false},
+		{"daily on weekend", map[string]interface{}{
+			"start": "01:00", "end": "05:00", "timezone": "Asia/Shanghai",
+			"weekdays": []interface{}{"Sat", "Sun"},
+		}, saturday, true},
+		{"daily on workdays", map[string]interface{}{
+			"start": "01:00", "end": "05:00", "timezone": "Asia/Shanghai",
+			"weekdays": []interface{}{"mon", "tue", "wed", "thu", "fri"},
+		}, saturday, false},
+		{"weekly", map[string]interface{}{
+			"type": "weekly", "start": "Fri 20:00", "end": "Mon 06:00", "timezone": "Asia/Shanghai",
+		}, saturday, true},
+		{"weekly out of range", map[string]interface{}{
+			"type": "weekly", "start": "Mon 00:00", "end": "Fri 23:59", "timezone": "Asia/Shanghai",
+		}, saturday, false},
+		{"monthly", map[string]interface{}{
+			"type": "monthly", "start": "15 00:00", "end": "17 00:00", "timezone": "Asia/Shanghai",
+		}, saturday, true},
+		{"monthly out of range", map[string]interface{}{
+			"type": "monthly", "start": "01 00:00", "end": "03 00:00", "timezone": "Asia/Shanghai",
+		}, saturday, false},
+		{"cron window", map[string]interface{}{
+			"type": "cron", "cron": "0 1 * * sat,sun", "durationSeconds": 3 * 3600, "timezone": "Asia/Shanghai",
+		}, saturday, true},
+		{"cron window closed", map[string]interface{}{
+			"type": "cron", "cron": "0 1 * * sat,sun", "durationSeconds": 3600, "timezone": "Asia/Shanghai",
+		}, saturday, false},
+		{"cron on workdays", map[string]interface{}{
+			"type": "cron", "cron": "0 1 * * 1-5", "durationSeconds": 3 * 3600, "timezone": "Asia/Shanghai",
+		}, saturday, false},
+	}
+
+	for _, tt := range timerTest {
+		clock := &fakeClock{now: tt.now}
+		tg, err := NewTrigger(map[string]interface{}{
+			"timer": tt.timer,
+		})
+		if err != nil {
+			t.Errorf("%s: failed to create trigger: %v", tt.name, err)
+			continue
+		}
+		tg.now = clock.Now
+
+		if tg.Trigger(nil) != tt.expected {
+			t.Errorf("%s: failed to trigger timer %v, expected=%v", tt.name, tt.timer, tt.expected)
+		}
+	}
+}
+
+func TestInvalidTimer(t *testing.T) {
+	for _, config := range []TimerConfig{
+		{Start: "1:00", End: "05:00"},
+		{Start: "01:00", End: "05:00", Timezone: "Mars/Olympus"},
+		{Start: "01:00", End: "05:00", Weekdays: []string{"Someday"}},
+		{Type: "hourly"},
+		{Type: TimerWeekly, Start: "Sat", End: "Sun 01:00"},
+		{Type: TimerMonthly, Start: "32 00:00", End: "01 00:00"},
+		{Type: TimerCron, Cron: "0 1 * *", DurationSeconds: 60},
+		{Type: TimerCron, Cron: "61 1 * * *", DurationSeconds: 60},
+		{Type: TimerCron, Cron: "@daily"},
+	} {
+		if _, err := NewTimerRangeTrigger(config); err == nil {
+			t.Errorf("expected error for timer %+v", config)
+		}
+	}
+}